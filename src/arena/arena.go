@@ -2,8 +2,6 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build goexperiment.arenas
-
 /*
 The arena package provides the ability to allocate memory for a collection
 of Go values and free that space manually all at once, safely. The purpose
@@ -25,6 +23,15 @@ error message, but this package reserves the right to not force a fault on
 freed memory. That means a valid implementation of this package is to just
 allocate all memory the way the runtime normally would, and in fact, it
 reserves the right to occasionally do so for some Go values.
+
+Values are allocated in an arena with [New] and [MakeSlice], strings with
+[String], hash maps with [NewMap], and byte buffers, which build strings
+without copying them, with [NewBuffer].
+
+Setting GODEBUG=arenacheck=1 makes accesses to values allocated in freed
+arenas fault deterministically, and the crash reports include the stacks of
+the allocation of the value and of the call to Free. See the runtime package
+documentation for details.
 */
 package arena
 
@@ -75,6 +82,17 @@ func MakeSlice[T any](a *Arena, len, cap int) []T {
 	return sl[:len]
 }
 
+// String returns a copy of s allocated in the provided arena, as a string.
+// The string must not be used after the arena is freed.
+func String[S ~string | ~[]byte](a *Arena, s S) string {
+	if len(s) == 0 {
+		return ""
+	}
+	b := MakeSlice[byte](a, len(s), len(s))
+	copy(b, s)
+	return unsafe.String(&b[0], len(b))
+}
+
 // Clone makes a shallow copy of the input value that is no longer bound to any
 // arena it may have been allocated from, returning the copy. If it was not
 // allocated from an arena, it is returned untouched. This function is useful
@@ -2,12 +2,16 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build goexperiment.arenas
-
 package arena_test
 
 import (
 	"arena"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -40,3 +44,117 @@ func TestSmokeLarge(t *testing.T) {
 		_ = arena.New[T2](a)
 	}
 }
+
+func TestString(t *testing.T) {
+	a := arena.NewArena()
+	defer a.Free()
+
+	b := []byte("hello")
+	s := arena.String(a, b)
+	b[0] = 'j'
+	if s != "hello" {
+		t.Errorf("String = %q, want %q", s, "hello")
+	}
+	if s := arena.String(a, "world"); s != "world" {
+		t.Errorf("String = %q, want %q", s, "world")
+	}
+	if s := arena.String(a, ""); s != "" {
+		t.Errorf("String = %q, want empty", s)
+	}
+}
+
+func TestMap(t *testing.T) {
+	a := arena.NewArena()
+	defer a.Free()
+
+	m := arena.NewMap[int, string](a, 0)
+	want := make(map[int]string)
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range 10000 {
+		k := r.IntN(1000)
+		switch r.IntN(3) {
+		case 0, 1:
+			v := strconv.Itoa(i)
+			m.Set(k, v)
+			want[k] = v
+		case 2:
+			m.Delete(k)
+			delete(want, k)
+		}
+		if m.Len() != len(want) {
+			t.Fatalf("after %d operations: Len = %d, want %d", i+1, m.Len(), len(want))
+		}
+	}
+	for k := range 1000 {
+		v, ok := m.Get(k)
+		if wv, wok := want[k]; v != wv || ok != wok {
+			t.Errorf("Get(%d) = %q, %v, want %q, %v", k, v, ok, wv, wok)
+		}
+	}
+	got := make(map[int]string)
+	for k, v := range m.All() {
+		if _, dup := got[k]; dup {
+			t.Errorf("All produced key %d twice", k)
+		}
+		got[k] = v
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("All produced %v, want %v", got, want)
+	}
+	m.Clear()
+	if _, ok := m.Get(1); ok || m.Len() != 0 {
+		t.Errorf("after Clear: Len = %d, Get(1) found = %v", m.Len(), ok)
+	}
+}
+
+func TestMapNaN(t *testing.T) {
+	a := arena.NewArena()
+	defer a.Free()
+
+	m := arena.NewMap[float64, int](a, 4)
+	m.Set(math.NaN(), 1)
+	m.Set(math.NaN(), 2)
+	if m.Len() != 2 {
+		t.Errorf("Len = %d, want 2", m.Len())
+	}
+	if _, ok := m.Get(math.NaN()); ok {
+		t.Errorf("Get(NaN) found an entry")
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	a := arena.NewArena()
+	defer a.Free()
+
+	b := arena.NewBuffer(a)
+	var want strings.Builder
+	var strs []string
+	for i := range 1000 {
+		s := strconv.Itoa(i)
+		b.WriteString(s)
+		b.WriteByte(' ')
+		b.Write([]byte{'x'})
+		b.WriteRune('é')
+		fmt.Fprintf(&want, "%s x%c", s, 'é')
+		strs = append(strs, b.String())
+	}
+	if b.String() != want.String() || string(b.Bytes()) != want.String() || b.Len() != want.Len() {
+		t.Fatalf("buffer contents differ from %q", want.String())
+	}
+	// Strings returned by String are not modified by later writes.
+	for _, s := range strs {
+		if !strings.HasPrefix(want.String(), s) {
+			t.Fatalf("String returned %q, not a prefix of the contents", s)
+		}
+	}
+	s := b.String()
+	b.Reset()
+	b.WriteString("reset")
+	if b.String() != "reset" || s != want.String() {
+		t.Errorf("after Reset: buffer holds %q, previous string is %q", b.String(), s)
+	}
+	b.Grow(100)
+	if b.Cap()-b.Len() < 100 {
+		t.Errorf("after Grow(100): Len = %d, Cap = %d", b.Len(), b.Cap())
+	}
+}
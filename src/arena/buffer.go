@@ -0,0 +1,102 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package arena
+
+import (
+	"unicode/utf8"
+	"unsafe"
+)
+
+// A Buffer is a variable-sized buffer of bytes allocated in an arena. Like
+// a strings.Builder, it builds strings without copying them, and like a
+// bytes.Buffer, it gives access to its contents as a byte slice. It must not
+// be used after the arena is freed and, like the arena, it must not be used
+// concurrently by multiple goroutines.
+//
+// A Buffer never releases memory: when it grows, its previous contents
+// remain in the arena until the arena is freed.
+type Buffer struct {
+	a   *Arena
+	buf []byte
+}
+
+// NewBuffer creates a new empty Buffer in the provided arena.
+func NewBuffer(a *Arena) *Buffer {
+	b := New[Buffer](a)
+	b.a = a
+	return b
+}
+
+// Len returns the number of bytes in the buffer.
+func (b *Buffer) Len() int { return len(b.buf) }
+
+// Cap returns the capacity of the buffer.
+func (b *Buffer) Cap() int { return cap(b.buf) }
+
+// Bytes returns the contents of the buffer. The slice aliases the buffer
+// content: modifying it modifies the strings returned by String.
+func (b *Buffer) Bytes() []byte { return b.buf }
+
+// String returns the contents of the buffer as a string, without copying
+// them. The string remains valid, and unchanged, until the arena is freed.
+func (b *Buffer) String() string {
+	return unsafe.String(unsafe.SliceData(b.buf), len(b.buf))
+}
+
+// Reset resets the buffer to be empty. Unlike bytes.Buffer.Reset, it does
+// not reuse the memory of the buffer, so the strings returned by String
+// remain valid.
+func (b *Buffer) Reset() {
+	b.buf = nil
+}
+
+// Grow grows the buffer's capacity, if necessary, to guarantee space for
+// another n bytes. If n is negative, Grow panics.
+func (b *Buffer) Grow(n int) {
+	if n < 0 {
+		panic("arena.Buffer.Grow: negative count")
+	}
+	if cap(b.buf)-len(b.buf) < n {
+		b.grow(n)
+	}
+}
+
+func (b *Buffer) grow(n int) {
+	buf := MakeSlice[byte](b.a, len(b.buf), max(2*cap(b.buf), len(b.buf)+n, 64))
+	copy(buf, b.buf)
+	b.buf = buf
+}
+
+// Write appends the contents of p to the buffer. It always returns
+// len(p), nil.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.Grow(len(p))
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// WriteString appends the contents of s to the buffer. It always returns
+// len(s), nil.
+func (b *Buffer) WriteString(s string) (int, error) {
+	b.Grow(len(s))
+	b.buf = append(b.buf, s...)
+	return len(s), nil
+}
+
+// WriteByte appends the byte c to the buffer. It always returns nil.
+func (b *Buffer) WriteByte(c byte) error {
+	b.Grow(1)
+	b.buf = append(b.buf, c)
+	return nil
+}
+
+// WriteRune appends the UTF-8 encoding of Unicode code point r to the
+// buffer. It returns the length of r and a nil error.
+func (b *Buffer) WriteRune(r rune) (int, error) {
+	n := len(b.buf)
+	b.Grow(utf8.UTFMax)
+	b.buf = utf8.AppendRune(b.buf, r)
+	return len(b.buf) - n, nil
+}
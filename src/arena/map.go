@@ -0,0 +1,154 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package arena
+
+import (
+	"internal/abi"
+	"iter"
+	"unsafe"
+)
+
+// A Map is a hash map whose memory is allocated in an arena. It must not be
+// used after the arena is freed and, like the arena, it must not be used
+// concurrently by multiple goroutines.
+//
+// Unlike a Go map, a Map never releases memory: when it grows, its previous
+// table remains in the arena until the arena is freed.
+type Map[K comparable, V any] struct {
+	a     *Arena
+	seed  uintptr
+	slots []mapSlot[K, V] // len is 0 or a power of 2
+	count int             // number of full slots
+	used  int             // number of full or deleted slots
+}
+
+type mapSlot[K comparable, V any] struct {
+	state uint8
+	key   K
+	val   V
+}
+
+// Slot states.
+const (
+	slotEmpty = iota
+	slotFull
+	slotDeleted
+)
+
+// NewMap creates a new empty Map in the provided arena, with space for
+// approximately hint entries.
+func NewMap[K comparable, V any](a *Arena, hint int) *Map[K, V] {
+	m := New[Map[K, V]](a)
+	m.a = a
+	m.seed = uintptr(runtime_rand())
+	if hint > 0 {
+		m.resize(hint)
+	}
+	return m
+}
+
+// Len returns the number of entries in m.
+func (m *Map[K, V]) Len() int {
+	return m.count
+}
+
+// Get returns the value stored in m for key and whether it was found.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if i := m.find(key); i >= 0 {
+		return m.slots[i].val, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set sets the value stored in m for key to val.
+func (m *Map[K, V]) Set(key K, val V) {
+	if i := m.find(key); i >= 0 {
+		m.slots[i].val = val
+		return
+	}
+	if (m.used+1)*8 > len(m.slots)*7 {
+		m.resize(m.count + 1)
+	}
+	mask := len(m.slots) - 1
+	i := int(m.hash(key)) & mask
+	for m.slots[i].state == slotFull {
+		i = (i + 1) & mask
+	}
+	if m.slots[i].state == slotEmpty {
+		m.used++
+	}
+	m.slots[i] = mapSlot[K, V]{slotFull, key, val}
+	m.count++
+}
+
+// Delete removes the entry for key from m, if any.
+func (m *Map[K, V]) Delete(key K) {
+	if i := m.find(key); i >= 0 {
+		m.slots[i] = mapSlot[K, V]{state: slotDeleted}
+		m.count--
+	}
+}
+
+// Clear removes all the entries of m.
+func (m *Map[K, V]) Clear() {
+	clear(m.slots)
+	m.count = 0
+	m.used = 0
+}
+
+// All returns an iterator over the entries of m. Unlike for Go maps, the
+// iteration order is not randomized for each iteration. If m is modified
+// during the iteration, entries may be skipped or produced more than once.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		slots := m.slots
+		for i := range slots {
+			if slots[i].state == slotFull && !yield(slots[i].key, slots[i].val) {
+				return
+			}
+		}
+	}
+}
+
+// find returns the index of the full slot of key, or -1.
+func (m *Map[K, V]) find(key K) int {
+	if m.count == 0 {
+		return -1
+	}
+	mask := len(m.slots) - 1
+	for i := int(m.hash(key)) & mask; m.slots[i].state != slotEmpty; i = (i + 1) & mask {
+		if m.slots[i].state == slotFull && m.slots[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Map[K, V]) hash(key K) uintptr {
+	var goMap map[K]V
+	hasher := (*abi.MapType)(unsafe.Pointer(abi.TypeOf(goMap))).Hasher
+	return hasher(abi.NoEscape(unsafe.Pointer(&key)), m.seed)
+}
+
+// resize moves the entries of m to a new table with space for n entries.
+func (m *Map[K, V]) resize(n int) {
+	size := 8
+	for size*7 < n*8*2 {
+		size *= 2
+	}
+	old := m.slots
+	m.slots = MakeSlice[mapSlot[K, V]](m.a, size, size)
+	m.count = 0
+	m.used = 0
+	for i := range old {
+		if old[i].state == slotFull {
+			m.Set(old[i].key, old[i].val)
+		}
+	}
+}
+
+//go:linkname runtime_rand runtime.rand
+func runtime_rand() uint64
@@ -45,7 +45,7 @@ func TestASAN(t *testing.T) {
 		{src: "asan_global5.go"},
 		{src: "asan_global_asm"},
 		{src: "asan_global_asm2_fail", memoryAccessError: "global-buffer-overflow", errorLocation: "main.go:17"},
-		{src: "arena_fail.go", memoryAccessError: "use-after-poison", errorLocation: "arena_fail.go:26", experiments: []string{"arenas"}},
+		{src: "arena_fail.go", memoryAccessError: "use-after-poison", errorLocation: "arena_fail.go:26"},
 	}
 	for _, tc := range cases {
 		tc := tc
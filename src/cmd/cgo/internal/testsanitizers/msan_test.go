@@ -59,7 +59,7 @@ func TestMSAN(t *testing.T) {
 		// fail because of a fault. However, we don't care what kind of error we
 		// get here, just that we get an error. This is an MSAN test because without
 		// MSAN it would not fail deterministically.
-		{src: "arena_fail.go", wantErr: true, experiments: []string{"arenas"}},
+		{src: "arena_fail.go", wantErr: true},
 	}
 	for _, tc := range cases {
 		tc := tc
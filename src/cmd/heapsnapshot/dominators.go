@@ -0,0 +1,157 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// A graph is a directed graph whose nodes are numbered from 0 to n-1,
+// with the successors of node v in succ[start[v]:start[v+1]].
+type graph struct {
+	start []int
+	succ  []int
+}
+
+// newGraph returns a graph of n nodes with the edges produced by edges,
+// which calls add for each edge.
+func newGraph(n int, edges func(add func(v, w int))) *graph {
+	g := &graph{start: make([]int, n+1)}
+	edges(func(v, w int) { g.start[v+1]++ })
+	for v := range n {
+		g.start[v+1] += g.start[v]
+	}
+	g.succ = make([]int, g.start[n])
+	next := make([]int, n)
+	copy(next, g.start)
+	edges(func(v, w int) {
+		g.succ[next[v]] = w
+		next[v]++
+	})
+	return g
+}
+
+func (g *graph) len() int { return len(g.start) - 1 }
+
+func (g *graph) successors(v int) []int {
+	return g.succ[g.start[v]:g.start[v+1]]
+}
+
+// dominators returns the immediate dominator of each node of g, as seen
+// from node 0, and the nodes reachable from node 0 in depth-first order.
+// The immediate dominator of node 0 is 0 and that of unreachable nodes is -1.
+//
+// It uses the simple version of the algorithm of Lengauer and Tarjan,
+// "A Fast Algorithm for Finding Dominators in a Flowgraph", 1979,
+// without recursion, since heap graphs can be very deep.
+func dominators(g *graph) (idom, order []int) {
+	n := g.len()
+
+	// Number the nodes in depth-first order. The following slices are
+	// indexed by those numbers rather than by node.
+	num := make([]int, n)
+	for i := range num {
+		num[i] = -1
+	}
+	var parent []int
+	type dfsFrame struct{ v, next int }
+	stack := []dfsFrame{{0, 0}}
+	num[0] = 0
+	order = append(order, 0)
+	parent = append(parent, -1)
+	for len(stack) > 0 {
+		f := &stack[len(stack)-1]
+		succ := g.successors(f.v)
+		if f.next == len(succ) {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		w := succ[f.next]
+		f.next++
+		if num[w] < 0 {
+			num[w] = len(order)
+			order = append(order, w)
+			parent = append(parent, num[f.v])
+			stack = append(stack, dfsFrame{w, 0})
+		}
+	}
+	m := len(order)
+
+	// Predecessors of the reachable nodes.
+	pred := newGraph(m, func(add func(v, w int)) {
+		for i, v := range order {
+			for _, w := range g.successors(v) {
+				add(num[w], i)
+			}
+		}
+	})
+
+	semi := make([]int, m)
+	label := make([]int, m)
+	ancestor := make([]int, m)
+	dom := make([]int, m)
+	for i := range m {
+		semi[i] = i
+		label[i] = i
+		ancestor[i] = -1
+	}
+	// bucket holds the nodes whose semidominator is i, as linked lists.
+	bucket := make([]int, m)
+	bucketNext := make([]int, m)
+	for i := range bucket {
+		bucket[i] = -1
+	}
+	var path []int
+	eval := func(v int) int {
+		if ancestor[v] < 0 {
+			return v
+		}
+		// Compress the path from v to the root of its tree in the forest,
+		// starting from the top.
+		path = path[:0]
+		for u := v; ancestor[ancestor[u]] >= 0; u = ancestor[u] {
+			path = append(path, u)
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			u := path[i]
+			a := ancestor[u]
+			if semi[label[a]] < semi[label[u]] {
+				label[u] = label[a]
+			}
+			ancestor[u] = ancestor[a]
+		}
+		return label[v]
+	}
+
+	for w := m - 1; w > 0; w-- {
+		for _, v := range pred.successors(w) {
+			if u := eval(v); semi[u] < semi[w] {
+				semi[w] = semi[u]
+			}
+		}
+		bucketNext[w] = bucket[semi[w]]
+		bucket[semi[w]] = w
+		p := parent[w]
+		ancestor[w] = p
+		for v := bucket[p]; v >= 0; v = bucketNext[v] {
+			if u := eval(v); semi[u] < semi[v] {
+				dom[v] = u
+			} else {
+				dom[v] = p
+			}
+		}
+		bucket[p] = -1
+	}
+	for w := 1; w < m; w++ {
+		if dom[w] != semi[w] {
+			dom[w] = dom[dom[w]]
+		}
+	}
+
+	idom = make([]int, n)
+	for i := range idom {
+		idom[i] = -1
+	}
+	for w := range m {
+		idom[order[w]] = order[dom[w]]
+	}
+	return idom, order
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand/v2"
+	"runtime/debug/heapsnapshot"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func edgeGraph(n int, edges [][2]int) *graph {
+	return newGraph(n, func(add func(v, w int)) {
+		for _, e := range edges {
+			add(e[0], e[1])
+		}
+	})
+}
+
+func TestDominators(t *testing.T) {
+	// The example of Lengauer and Tarjan, with R, A, B, ... numbered
+	// 0, 1, 2, ..., and an unreachable node 13.
+	const (
+		R = iota
+		A
+		B
+		C
+		D
+		E
+		F
+		G
+		H
+		I
+		J
+		K
+		L
+		U
+	)
+	g := edgeGraph(14, [][2]int{
+		{R, A}, {R, B}, {R, C},
+		{A, D},
+		{B, A}, {B, D}, {B, E},
+		{C, F}, {C, G},
+		{D, L},
+		{E, H},
+		{F, I},
+		{G, I}, {G, J},
+		{H, E}, {H, K},
+		{I, K},
+		{J, I},
+		{K, I}, {K, R},
+		{L, H},
+		{U, A},
+	})
+	idom, order := dominators(g)
+	want := []int{R, R, R, R, R, R, C, C, R, R, G, R, D, -1}
+	if !slices.Equal(idom, want) {
+		t.Errorf("idom = %v, want %v", idom, want)
+	}
+	if len(order) != 13 || order[0] != R {
+		t.Errorf("order = %v, want the 13 reachable nodes starting with 0", order)
+	}
+}
+
+// naiveDominators computes the immediate dominators of g by removing each
+// node in turn and finding the nodes that become unreachable.
+func naiveDominators(g *graph) []int {
+	n := g.len()
+	reach := func(skip int) []bool {
+		seen := make([]bool, n)
+		if skip == 0 {
+			return seen
+		}
+		stack := []int{0}
+		seen[0] = true
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, w := range g.successors(v) {
+				if w != skip && !seen[w] {
+					seen[w] = true
+					stack = append(stack, w)
+				}
+			}
+		}
+		return seen
+	}
+	all := reach(-1)
+	// dom[v] lists the dominators of v other than v.
+	dom := make([][]int, n)
+	for d := range n {
+		seen := reach(d)
+		for v := range n {
+			if v != d && all[v] && !seen[v] {
+				dom[v] = append(dom[v], d)
+			}
+		}
+	}
+	idom := make([]int, n)
+	for v := range n {
+		idom[v] = -1
+		if !all[v] {
+			continue
+		}
+		if v == 0 {
+			idom[v] = 0
+			continue
+		}
+		// The immediate dominator is the dominator dominated by all the others.
+		for _, d := range dom[v] {
+			if len(dom[d]) == len(dom[v])-1 {
+				idom[v] = d
+			}
+		}
+	}
+	return idom
+}
+
+func TestDominatorsRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 200 {
+		n := 1 + r.IntN(30)
+		var edges [][2]int
+		for range r.IntN(3 * n) {
+			edges = append(edges, [2]int{r.IntN(n), r.IntN(n)})
+		}
+		g := edgeGraph(n, edges)
+		idom, _ := dominators(g)
+		if want := naiveDominators(g); !slices.Equal(idom, want) {
+			t.Fatalf("graph %v: idom = %v, want %v", edges, idom, want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	typ := &heapsnapshot.Type{ID: 1, Name: "main.T", Size: 16}
+	s := &heapsnapshot.Snapshot{
+		GoVersion: "go1.27",
+		GOOS:      "linux",
+		GOARCH:    "amd64",
+		Types:     []*heapsnapshot.Type{typ},
+		// A list of three main.T retaining a 100-byte object, and an
+		// object shared by the list and a stack frame.
+		Objects: []heapsnapshot.Object{
+			{Addr: 0x100, Size: 16, Type: typ, Edges: []heapsnapshot.Edge{{To: 0x200}}},
+			{Addr: 0x200, Size: 16, Type: typ, Edges: []heapsnapshot.Edge{{To: 0x300}, {To: 0x500}}},
+			{Addr: 0x300, Size: 16, Type: typ, Edges: []heapsnapshot.Edge{{To: 0x400}}},
+			{Addr: 0x400, Size: 100},
+			{Addr: 0x500, Size: 8},
+			{Addr: 0x600, Size: 8}, // unreachable
+		},
+		Roots: []heapsnapshot.Root{
+			{Kind: heapsnapshot.RootGlobal, Description: "bss", Edges: []heapsnapshot.Edge{{To: 0x100}}},
+			{Kind: heapsnapshot.RootStack, Description: "goroutine 1 main.main", Edges: []heapsnapshot.Edge{{To: 0x500}}},
+		},
+	}
+	a := analyze(s)
+	if a.unreachable != 1 || a.unreachableBytes != 8 {
+		t.Errorf("unreachable = %d objects, %d bytes, want 1, 8", a.unreachable, a.unreachableBytes)
+	}
+	wantRetained := []uint64{156, 148, 0, 148, 132, 116, 100, 8, 0}
+	if !slices.Equal(a.retained, wantRetained) {
+		t.Errorf("retained = %v, want %v", a.retained, wantRetained)
+	}
+	wantTypes := []typeStats{
+		{"main.T", 3, 48, 148},
+		{"<100-byte object>", 1, 100, 100},
+		{"<8-byte object>", 2, 16, 8},
+	}
+	if !slices.Equal(a.types, wantTypes) {
+		t.Errorf("types = %v, want %v", a.types, wantTypes)
+	}
+	if got := a.root(3 + 3); got != "bss" {
+		t.Errorf("root of the 100-byte object is %q, want bss", got)
+	}
+	if got := a.root(3 + 4); got != "several roots" {
+		t.Errorf("root of the shared object is %q, want several roots", got)
+	}
+
+	var b strings.Builder
+	report(&b, a, 2)
+	for _, want := range []string{
+		"heap snapshot of go1.27 linux/amd64: 6 objects, 164 bytes\n",
+		"unreachable: 1 objects, 8 bytes\n",
+		"         148           48          3  main.T\n",
+		"         148  global     bss\n",
+		"         148           16  0x100               main.T (bss)\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, b.String())
+		}
+	}
+}
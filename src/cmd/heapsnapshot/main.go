@@ -0,0 +1,266 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Heapsnapshot analyzes heap snapshots written by
+// runtime/debug.WriteHeapSnapshot.
+//
+// Usage:
+//
+//	go tool heapsnapshot [-n count] snapshot
+//
+// Heapsnapshot computes the dominator tree of the heap: an object X
+// dominates an object Y if every path from the roots of the heap to Y goes
+// through X. The retained size of an object is the total size of the
+// objects it dominates, including itself, which is the memory that would
+// be freed if the object became unreachable.
+//
+// Heapsnapshot prints the types of the heap objects by retained size,
+// then the roots and the objects with the largest retained sizes. The
+// retained size of a type is the total retained size of the objects of
+// that type that are not dominated by another object of the same type.
+// Objects whose type is unknown are grouped by size.
+//
+// The -n flag sets the number of lines of each table, 20 by default.
+//
+// Objects not reachable from any root are garbage that the last garbage
+// collection did not free yet. They are reported in the summary and
+// otherwise ignored.
+package main
+
+import (
+	"bufio"
+	"cmd/internal/objabi"
+	"cmd/internal/telemetry/counter"
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime/debug/heapsnapshot"
+	"slices"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: go tool heapsnapshot [-n count] snapshot\n\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+var top = flag.Int("n", 20, "number of lines of each table")
+
+func main() {
+	objabi.AddVersionFlag()
+
+	log.SetFlags(0)
+	log.SetPrefix("heapsnapshot: ")
+	counter.Open()
+
+	flag.Usage = usage
+	flag.Parse()
+	counter.Inc("heapsnapshot/invocations")
+	counter.CountFlags("heapsnapshot/flag:", *flag.CommandLine)
+	if flag.NArg() != 1 {
+		usage()
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := heapsnapshot.Read(bufio.NewReader(f))
+	f.Close()
+	if err != nil {
+		log.Fatalf("reading %s: %v", flag.Arg(0), err)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	report(w, analyze(s), *top)
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// An analysis is the dominator tree of a heap snapshot.
+//
+// The nodes of the heap graph are a virtual root, numbered 0, which
+// points to the roots of the snapshot, numbered from 1, followed by the
+// objects of the snapshot.
+type analysis struct {
+	s        *heapsnapshot.Snapshot
+	idom     []int    // immediate dominator of each node, or -1 if unreachable
+	retained []uint64 // retained size of each node
+
+	unreachable      int    // number of unreachable objects
+	unreachableBytes uint64 // size of unreachable objects
+
+	types []typeStats
+}
+
+type typeStats struct {
+	name     string
+	count    int
+	bytes    uint64
+	retained uint64
+}
+
+func analyze(s *heapsnapshot.Snapshot) *analysis {
+	a := &analysis{s: s}
+	nroots := len(s.Roots)
+	objNode := func(addr uint64) int {
+		if i := s.Lookup(addr); i >= 0 {
+			return 1 + nroots + i
+		}
+		return -1
+	}
+	g := newGraph(1+nroots+len(s.Objects), func(add func(v, w int)) {
+		for i, r := range s.Roots {
+			add(0, 1+i)
+			for _, e := range r.Edges {
+				if w := objNode(e.To); w >= 0 {
+					add(1+i, w)
+				}
+			}
+		}
+		for i, o := range s.Objects {
+			for _, e := range o.Edges {
+				if w := objNode(e.To); w >= 0 {
+					add(1+nroots+i, w)
+				}
+			}
+		}
+	})
+	idom, order := dominators(g)
+	a.idom = idom
+
+	// Retained sizes, from the leaves of the dominator tree up, using
+	// the fact that a node comes after its dominators in depth-first order.
+	a.retained = make([]uint64, len(idom))
+	for i, o := range s.Objects {
+		if idom[1+nroots+i] < 0 {
+			a.unreachable++
+			a.unreachableBytes += o.Size
+		}
+	}
+	for _, v := range slices.Backward(order[1:]) {
+		if v > nroots {
+			a.retained[v] += s.Objects[v-1-nroots].Size
+		}
+		a.retained[idom[v]] += a.retained[v]
+	}
+
+	// Statistics by type, walking the dominator tree to find the objects
+	// not dominated by an object of the same type.
+	index := make(map[string]int)
+	typeOf := make([]int, len(s.Objects))
+	for i, o := range s.Objects {
+		name := typeName(o)
+		t, ok := index[name]
+		if !ok {
+			t = len(a.types)
+			index[name] = t
+			a.types = append(a.types, typeStats{name: name})
+		}
+		typeOf[i] = t
+		a.types[t].count++
+		a.types[t].bytes += o.Size
+	}
+	tree := newGraph(len(idom), func(add func(v, w int)) {
+		for _, v := range order[1:] {
+			add(idom[v], v)
+		}
+	})
+	depth := make([]int, len(a.types)) // number of dominators of each type
+	type frame struct{ v, next int }
+	stack := []frame{{0, 0}}
+	for len(stack) > 0 {
+		f := &stack[len(stack)-1]
+		children := tree.successors(f.v)
+		if f.next == len(children) {
+			if f.v > nroots {
+				depth[typeOf[f.v-1-nroots]]--
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		v := children[f.next]
+		f.next++
+		if v > nroots {
+			t := typeOf[v-1-nroots]
+			if depth[t] == 0 {
+				a.types[t].retained += a.retained[v]
+			}
+			depth[t]++
+		}
+		stack = append(stack, frame{v, 0})
+	}
+	slices.SortStableFunc(a.types, func(x, y typeStats) int {
+		return cmp.Compare(y.retained, x.retained)
+	})
+	return a
+}
+
+func typeName(o heapsnapshot.Object) string {
+	if o.Type == nil {
+		return fmt.Sprintf("<%d-byte object>", o.Size)
+	}
+	return o.Type.Name
+}
+
+// root returns the description of the root that retains node v.
+func (a *analysis) root(v int) string {
+	for a.idom[v] != 0 {
+		v = a.idom[v]
+	}
+	if v > len(a.s.Roots) {
+		return "several roots"
+	}
+	return a.s.Roots[v-1].Description
+}
+
+// largest returns the n nodes in [lo, hi) with the largest retained sizes.
+func (a *analysis) largest(lo, hi, n int) []int {
+	var nodes []int
+	for v := lo; v < hi; v++ {
+		if a.idom[v] >= 0 && a.retained[v] > 0 {
+			nodes = append(nodes, v)
+		}
+	}
+	slices.SortStableFunc(nodes, func(x, y int) int {
+		return cmp.Compare(a.retained[y], a.retained[x])
+	})
+	return nodes[:min(n, len(nodes))]
+}
+
+func report(w io.Writer, a *analysis, n int) {
+	s := a.s
+	var bytes uint64
+	for _, o := range s.Objects {
+		bytes += o.Size
+	}
+	fmt.Fprintf(w, "heap snapshot of %s %s/%s: %d objects, %d bytes\n", s.GoVersion, s.GOOS, s.GOARCH, len(s.Objects), bytes)
+	if a.unreachable > 0 {
+		fmt.Fprintf(w, "unreachable: %d objects, %d bytes\n", a.unreachable, a.unreachableBytes)
+	}
+
+	fmt.Fprintf(w, "\ntypes by retained size:\n")
+	fmt.Fprintf(w, "%12s %12s %10s  %s\n", "retained", "bytes", "count", "type")
+	for _, t := range a.types[:min(n, len(a.types))] {
+		fmt.Fprintf(w, "%12d %12d %10d  %s\n", t.retained, t.bytes, t.count, t.name)
+	}
+
+	nroots := len(s.Roots)
+	fmt.Fprintf(w, "\nroots by retained size:\n")
+	fmt.Fprintf(w, "%12s  %-9s  %s\n", "retained", "kind", "root")
+	for _, v := range a.largest(1, 1+nroots, n) {
+		r := s.Roots[v-1]
+		fmt.Fprintf(w, "%12d  %-9s  %s\n", a.retained[v], r.Kind, r.Description)
+	}
+
+	fmt.Fprintf(w, "\nobjects by retained size:\n")
+	fmt.Fprintf(w, "%12s %12s  %-18s  %s\n", "retained", "bytes", "address", "type (retained by)")
+	for _, v := range a.largest(1+nroots, len(a.idom), n) {
+		o := s.Objects[v-1-nroots]
+		fmt.Fprintf(w, "%12d %12d  %#-18x  %s (%s)\n", a.retained[v], o.Size, o.Addr, typeName(o), a.root(v))
+	}
+}
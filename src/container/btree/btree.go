@@ -0,0 +1,688 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package btree implements ordered maps and sets as B-trees.
+//
+// A [Map] holds key-value pairs sorted by key, according to the natural
+// order of the keys for [NewMap] or to a comparison function for
+// [NewMapFunc]. Besides lookups and updates in O(log n) time, it supports
+// iteration in key order, in either direction and over ranges of keys,
+// and queries for the neighbors of a key. A [Set] does the same for keys
+// alone.
+//
+// Cloning a Map or a Set takes constant time: the clone shares the nodes
+// of the tree with the original, and each of them copies a node the first
+// time it modifies it.
+package btree
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"sync/atomic"
+)
+
+const (
+	// degree is the minimum number of children of the internal nodes
+	// of the tree, other than the root.
+	degree   = 16
+	maxItems = 2*degree - 1 // maximum number of items in a node
+	minItems = degree - 1   // minimum number of items in a node, other than the root
+)
+
+// A Map is an ordered map from keys of type K to values of type V. The
+// zero value is not usable; use [NewMap] or [NewMapFunc].
+//
+// A Map is not safe for concurrent use by multiple goroutines if any of
+// them modifies it, but different clones of a Map may be used
+// concurrently.
+type Map[K, V any] struct {
+	root   *node[K, V]
+	length int
+	cmp    func(K, K) int
+
+	// cow identifies the nodes owned by the Map, which it may modify
+	// in place. Other nodes are shared with clones and are copied
+	// before modification.
+	cow *owner
+
+	// clones counts the calls to Clone, iters counts the iterations
+	// started over the Map, and active counts those in progress. Clones
+	// and iterations share the nodes of the Map, which gives them up
+	// before its next modification. They only change these counters,
+	// atomically, so that they don't race with each other.
+	clones atomic.Uint64
+	iters  atomic.Uint64
+	active atomic.Int64
+
+	// cowClones and cowIters are the values of clones and iters when
+	// cow was made.
+	cowClones, cowIters uint64
+}
+
+// An owner identifies the owner of nodes. It is not empty, so that
+// different owners have different addresses.
+type owner struct {
+	_ byte
+}
+
+// A node is a node of a B-tree. Leaves have no children; internal nodes
+// have one more child than items, with the keys of children[i] between
+// keys[i-1] and keys[i].
+type node[K, V any] struct {
+	keys     []K
+	vals     []V
+	children []*node[K, V]
+	cow      *owner
+}
+
+// NewMap returns an empty Map ordered by [cmp.Compare].
+func NewMap[K cmp.Ordered, V any]() *Map[K, V] {
+	return NewMapFunc[K, V](cmp.Compare[K])
+}
+
+// NewMapFunc returns an empty Map ordered by cmp, which must be a strict
+// weak ordering as for [slices.SortFunc]. Keys that compare equal are
+// the same key.
+func NewMapFunc[K, V any](cmp func(a, b K) int) *Map[K, V] {
+	return &Map[K, V]{cmp: cmp, cow: new(owner)}
+}
+
+// Len returns the number of keys in m.
+func (m *Map[K, V]) Len() int {
+	return m.length
+}
+
+// Clone returns a copy of m, in constant time. m and its clone share
+// their nodes until either modifies them.
+func (m *Map[K, V]) Clone() *Map[K, V] {
+	c := new(Map[K, V])
+	m.cloneTo(c)
+	return c
+}
+
+// cloneTo makes c, which must be a zero Map, a clone of m.
+func (m *Map[K, V]) cloneTo(c *Map[K, V]) {
+	// Both maps now share all the nodes: the next modification of m
+	// gives them up.
+	m.clones.Add(1)
+	c.root, c.length, c.cmp, c.cow = m.root, m.length, m.cmp, new(owner)
+}
+
+// own prepares m for the modification of its nodes. If a clone or an
+// iteration in progress shares the nodes owned by m, m gives them up,
+// so that later changes copy them.
+func (m *Map[K, V]) own() {
+	clones, iters := m.clones.Load(), m.iters.Load()
+	if clones != m.cowClones || iters != m.cowIters && m.active.Load() > 0 {
+		m.cow = new(owner)
+		m.cowClones, m.cowIters = clones, iters
+	}
+}
+
+// Clear removes all the keys from m.
+func (m *Map[K, V]) Clear() {
+	m.root = nil
+	m.length = 0
+}
+
+// find returns the index of the first key of n greater than or equal to
+// k, and whether it is equal to k.
+func (n *node[K, V]) find(k K, cmp func(K, K) int) (int, bool) {
+	return slices.BinarySearchFunc(n.keys, k, cmp)
+}
+
+// Get returns the value of k in m and whether it is present.
+func (m *Map[K, V]) Get(k K) (v V, ok bool) {
+	for n := m.root; n != nil; {
+		i, found := n.find(k, m.cmp)
+		if found {
+			return n.vals[i], true
+		}
+		if n.children == nil {
+			break
+		}
+		n = n.children[i]
+	}
+	return v, false
+}
+
+// Contains reports whether k is present in m.
+func (m *Map[K, V]) Contains(k K) bool {
+	_, ok := m.Get(k)
+	return ok
+}
+
+// Min returns the smallest key in m and its value. ok is false if m is
+// empty.
+func (m *Map[K, V]) Min() (k K, v V, ok bool) {
+	n := m.root
+	if n == nil {
+		return k, v, false
+	}
+	for n.children != nil {
+		n = n.children[0]
+	}
+	return n.keys[0], n.vals[0], true
+}
+
+// Max returns the largest key in m and its value. ok is false if m is
+// empty.
+func (m *Map[K, V]) Max() (k K, v V, ok bool) {
+	n := m.root
+	if n == nil {
+		return k, v, false
+	}
+	for n.children != nil {
+		n = n.children[len(n.children)-1]
+	}
+	return n.keys[len(n.keys)-1], n.vals[len(n.vals)-1], true
+}
+
+// Floor returns the largest key in m less than or equal to k, and its
+// value. ok is false if there is no such key.
+func (m *Map[K, V]) Floor(k K) (key K, v V, ok bool) {
+	for n := m.root; n != nil; {
+		i, found := n.find(k, m.cmp)
+		if found {
+			return n.keys[i], n.vals[i], true
+		}
+		// n.keys[i-1] < k < n.keys[i].
+		if i > 0 {
+			key, v, ok = n.keys[i-1], n.vals[i-1], true
+		}
+		if n.children == nil {
+			break
+		}
+		n = n.children[i]
+	}
+	return key, v, ok
+}
+
+// Ceiling returns the smallest key in m greater than or equal to k, and
+// its value. ok is false if there is no such key.
+func (m *Map[K, V]) Ceiling(k K) (key K, v V, ok bool) {
+	for n := m.root; n != nil; {
+		i, found := n.find(k, m.cmp)
+		if found {
+			return n.keys[i], n.vals[i], true
+		}
+		// n.keys[i-1] < k < n.keys[i].
+		if i < len(n.keys) {
+			key, v, ok = n.keys[i], n.vals[i], true
+		}
+		if n.children == nil {
+			break
+		}
+		n = n.children[i]
+	}
+	return key, v, ok
+}
+
+// newNode returns an empty node owned by m.
+func (m *Map[K, V]) newNode() *node[K, V] {
+	return &node[K, V]{
+		keys: make([]K, 0, maxItems),
+		vals: make([]V, 0, maxItems),
+		cow:  m.cow,
+	}
+}
+
+// mutable returns n if m owns it, and otherwise a copy of n owned by m.
+func (m *Map[K, V]) mutable(n *node[K, V]) *node[K, V] {
+	if n.cow == m.cow {
+		return n
+	}
+	c := m.newNode()
+	c.keys = append(c.keys, n.keys...)
+	c.vals = append(c.vals, n.vals...)
+	if n.children != nil {
+		c.children = make([]*node[K, V], len(n.children), maxItems+1)
+		copy(c.children, n.children)
+	}
+	return c
+}
+
+// mutableChild makes the i'th child of n, which m must own, mutable.
+func (m *Map[K, V]) mutableChild(n *node[K, V], i int) *node[K, V] {
+	c := m.mutable(n.children[i])
+	n.children[i] = c
+	return c
+}
+
+// Set sets the value of k in m to v.
+func (m *Map[K, V]) Set(k K, v V) {
+	m.own()
+	if m.root == nil {
+		m.root = m.newNode()
+	} else {
+		m.root = m.mutable(m.root)
+		if len(m.root.keys) >= maxItems {
+			// Split the root, adding a level to the tree.
+			old := m.root
+			mk, mv, right := old.split(m, maxItems/2)
+			m.root = m.newNode()
+			m.root.keys = append(m.root.keys, mk)
+			m.root.vals = append(m.root.vals, mv)
+			m.root.children = make([]*node[K, V], 0, maxItems+1)
+			m.root.children = append(m.root.children, old, right)
+		}
+	}
+	if m.root.insert(m, k, v) {
+		m.length++
+	}
+}
+
+// split splits n, which m must own, at item i. It returns item i and a
+// new node with the items and children after it, and leaves those
+// before it in n.
+func (n *node[K, V]) split(m *Map[K, V], i int) (K, V, *node[K, V]) {
+	k, v := n.keys[i], n.vals[i]
+	right := m.newNode()
+	right.keys = append(right.keys, n.keys[i+1:]...)
+	right.vals = append(right.vals, n.vals[i+1:]...)
+	n.keys = truncate(n.keys, i)
+	n.vals = truncate(n.vals, i)
+	if n.children != nil {
+		right.children = make([]*node[K, V], 0, maxItems+1)
+		right.children = append(right.children, n.children[i+1:]...)
+		n.children = truncate(n.children, i+1)
+	}
+	return k, v, right
+}
+
+// truncate returns s[:n], clearing the elements after them so that
+// they can be garbage collected.
+func truncate[S ~[]E, E any](s S, n int) S {
+	clear(s[n:])
+	return s[:n]
+}
+
+// insert sets the value of k to v in the subtree of n, which m must own
+// and which must not be full. It reports whether k is a new key.
+func (n *node[K, V]) insert(m *Map[K, V], k K, v V) bool {
+	i, found := n.find(k, m.cmp)
+	if found {
+		n.keys[i] = k
+		n.vals[i] = v
+		return false
+	}
+	if n.children == nil {
+		n.keys = slices.Insert(n.keys, i, k)
+		n.vals = slices.Insert(n.vals, i, v)
+		return true
+	}
+	child := m.mutableChild(n, i)
+	if len(child.keys) >= maxItems {
+		// Split the child, so that there is room for the
+		// item it may push up.
+		mk, mv, right := child.split(m, maxItems/2)
+		n.keys = slices.Insert(n.keys, i, mk)
+		n.vals = slices.Insert(n.vals, i, mv)
+		n.children = slices.Insert(n.children, i+1, right)
+		switch c := m.cmp(k, mk); {
+		case c == 0:
+			n.keys[i] = k
+			n.vals[i] = v
+			return false
+		case c > 0:
+			child = right
+		}
+	}
+	return child.insert(m, k, v)
+}
+
+// Delete removes k from m. It returns the value of k and whether k was
+// present.
+func (m *Map[K, V]) Delete(k K) (v V, ok bool) {
+	if m.root == nil {
+		return v, false
+	}
+	m.own()
+	m.root = m.mutable(m.root)
+	v, ok = m.root.remove(m, k, false)
+	if len(m.root.keys) == 0 {
+		// Remove a level from the tree.
+		if m.root.children == nil {
+			m.root = nil
+		} else {
+			m.root = m.root.children[0]
+		}
+	}
+	if ok {
+		m.length--
+	}
+	return v, ok
+}
+
+// remove removes k from the subtree of n, which m must own, or the
+// largest key of the subtree if max is set. It returns the removed key
+// and value. Unless n is the root, it must have more than minItems items.
+func (n *node[K, V]) remove(m *Map[K, V], k K, max bool) (V, bool) {
+	var i int
+	var found bool
+	if max {
+		i = len(n.keys)
+		if n.children == nil {
+			i--
+			found = true
+		}
+	} else {
+		i, found = n.find(k, m.cmp)
+	}
+
+	if n.children == nil {
+		if !found {
+			var zero V
+			return zero, false
+		}
+		v := n.vals[i]
+		n.keys = slices.Delete(n.keys, i, i+1)
+		n.vals = slices.Delete(n.vals, i, i+1)
+		return v, true
+	}
+
+	if len(n.children[i].keys) <= minItems {
+		// Make sure the child has an item to spare, and retry.
+		n.growChild(m, i)
+		return n.remove(m, k, max)
+	}
+	child := m.mutableChild(n, i)
+	if found {
+		// Replace the item with its predecessor, the largest item of
+		// the child before it.
+		v := n.vals[i]
+		n.keys[i], n.vals[i] = child.removeMax(m)
+		return v, true
+	}
+	return child.remove(m, k, max)
+}
+
+// removeMax removes the largest item from the subtree of n, which m must
+// own, and returns it.
+func (n *node[K, V]) removeMax(m *Map[K, V]) (K, V) {
+	for n.children != nil {
+		i := len(n.keys)
+		if len(n.children[i].keys) <= minItems {
+			n.growChild(m, i)
+			continue
+		}
+		n = m.mutableChild(n, i)
+	}
+	k, v := n.keys[len(n.keys)-1], n.vals[len(n.vals)-1]
+	n.keys = truncate(n.keys, len(n.keys)-1)
+	n.vals = truncate(n.vals, len(n.vals)-1)
+	return k, v
+}
+
+// growChild adds an item to the i'th child of n, which m must own, by
+// moving one from a sibling through n or by merging it with a sibling.
+func (n *node[K, V]) growChild(m *Map[K, V], i int) {
+	switch {
+	case i > 0 && len(n.children[i-1].keys) > minItems:
+		// Rotate an item from the left sibling.
+		child := m.mutableChild(n, i)
+		left := m.mutableChild(n, i-1)
+		last := len(left.keys) - 1
+		child.keys = slices.Insert(child.keys, 0, n.keys[i-1])
+		child.vals = slices.Insert(child.vals, 0, n.vals[i-1])
+		n.keys[i-1], n.vals[i-1] = left.keys[last], left.vals[last]
+		left.keys = truncate(left.keys, last)
+		left.vals = truncate(left.vals, last)
+		if left.children != nil {
+			child.children = slices.Insert(child.children, 0, left.children[last+1])
+			left.children = truncate(left.children, last+1)
+		}
+
+	case i < len(n.keys) && len(n.children[i+1].keys) > minItems:
+		// Rotate an item from the right sibling.
+		child := m.mutableChild(n, i)
+		right := m.mutableChild(n, i+1)
+		child.keys = append(child.keys, n.keys[i])
+		child.vals = append(child.vals, n.vals[i])
+		n.keys[i], n.vals[i] = right.keys[0], right.vals[0]
+		right.keys = slices.Delete(right.keys, 0, 1)
+		right.vals = slices.Delete(right.vals, 0, 1)
+		if right.children != nil {
+			child.children = append(child.children, right.children[0])
+			right.children = slices.Delete(right.children, 0, 1)
+		}
+
+	default:
+		// Merge the child with a sibling and the item between them.
+		if i >= len(n.keys) {
+			i--
+		}
+		child := m.mutableChild(n, i)
+		right := n.children[i+1]
+		child.keys = append(child.keys, n.keys[i])
+		child.keys = append(child.keys, right.keys...)
+		child.vals = append(child.vals, n.vals[i])
+		child.vals = append(child.vals, right.vals...)
+		child.children = append(child.children, right.children...)
+		n.keys = slices.Delete(n.keys, i, i+1)
+		n.vals = slices.Delete(n.vals, i, i+1)
+		n.children = slices.Delete(n.children, i+1, i+2)
+	}
+}
+
+// Insert adds the key-value pairs from seq to m. If a key occurs more
+// than once, the last value wins.
+//
+// If m is empty, Insert builds the tree in one pass, which takes linear
+// time if the keys of seq are in increasing order.
+func (m *Map[K, V]) Insert(seq iter.Seq2[K, V]) {
+	if m.length > 0 {
+		for k, v := range seq {
+			m.Set(k, v)
+		}
+		return
+	}
+
+	type item struct {
+		k K
+		v V
+	}
+	var items []item
+	sorted := true
+	for k, v := range seq {
+		if len(items) > 0 && sorted && m.cmp(items[len(items)-1].k, k) >= 0 {
+			sorted = false
+		}
+		items = append(items, item{k, v})
+	}
+	if !sorted {
+		slices.SortStableFunc(items, func(a, b item) int { return m.cmp(a.k, b.k) })
+		// Keep the last of equal keys.
+		j := 0
+		for i := range items {
+			if j > 0 && m.cmp(items[j-1].k, items[i].k) == 0 {
+				j--
+			}
+			items[j] = items[i]
+			j++
+		}
+		items = truncate(items, j)
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	keys := make([]K, len(items))
+	vals := make([]V, len(items))
+	for i, it := range items {
+		keys[i], vals[i] = it.k, it.v
+	}
+	height, capacity := 1, maxItems
+	for capacity < len(keys) {
+		height++
+		capacity = capacity*(maxItems+1) + maxItems
+	}
+	m.root = m.build(keys, vals, height)
+	m.length = len(keys)
+}
+
+// build returns a tree of the given height with the sorted keys and vals.
+// There must be enough of them for the nodes to have at least minItems
+// items, except at the root, and not too many for them to have at most
+// maxItems items.
+func (m *Map[K, V]) build(keys []K, vals []V, height int) *node[K, V] {
+	n := m.newNode()
+	if height == 1 {
+		n.keys = append(n.keys, keys...)
+		n.vals = append(n.vals, vals...)
+		return n
+	}
+
+	// Use as few children as possible, and spread the items
+	// evenly between them. Since the children of a node at least
+	// half full have at least half their capacity, they are at
+	// least minItems full.
+	childCap := maxItems
+	for h := 2; h < height; h++ {
+		childCap = childCap*(maxItems+1) + maxItems
+	}
+	nchild := (len(keys) + 1 + childCap) / (childCap + 1)
+	nchild = max(nchild, 2)
+	perChild := len(keys) - (nchild - 1) // items in children
+	n.children = make([]*node[K, V], 0, maxItems+1)
+	start := 0
+	for c := range nchild {
+		size := perChild / nchild
+		if c < perChild%nchild {
+			size++
+		}
+		n.children = append(n.children, m.build(keys[start:start+size], vals[start:start+size], height-1))
+		start += size
+		if c < nchild-1 {
+			n.keys = append(n.keys, keys[start])
+			n.vals = append(n.vals, vals[start])
+			start++
+		}
+	}
+	return n
+}
+
+// snapshot returns the root of m for an iteration, and makes the nodes
+// of m read-only for m until the iteration ends, so that the iteration
+// isn't affected by changes to m. The caller must call done at the end
+// of the iteration.
+func (m *Map[K, V]) snapshot() *node[K, V] {
+	m.active.Add(1)
+	m.iters.Add(1)
+	return m.root
+}
+
+// done ends an iteration started by snapshot.
+func (m *Map[K, V]) done() {
+	m.active.Add(-1)
+}
+
+// All returns an iterator over the key-value pairs in m, in increasing
+// order of keys. The iterator iterates over the contents of m when it
+// starts: changes to m during the iteration are not observed.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		root := m.snapshot()
+		defer m.done()
+		if root != nil {
+			root.ascend(m.cmp, nil, nil, yield)
+		}
+	}
+}
+
+// Backward returns an iterator over the key-value pairs in m, in
+// decreasing order of keys. Like [Map.All], it iterates over the
+// contents of m when it starts.
+func (m *Map[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		root := m.snapshot()
+		defer m.done()
+		if root != nil {
+			root.descend(yield)
+		}
+	}
+}
+
+// Keys returns an iterator over the keys in m, in increasing order.
+// Like [Map.All], it iterates over the contents of m when it starts.
+func (m *Map[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values in m, in increasing order
+// of keys. Like [Map.All], it iterates over the contents of m when it
+// starts.
+func (m *Map[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the key-value pairs in m with keys
+// greater than or equal to lo and less than hi, in increasing order of
+// keys. Like [Map.All], it iterates over the contents of m when it
+// starts.
+func (m *Map[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		root := m.snapshot()
+		defer m.done()
+		if root != nil {
+			root.ascend(m.cmp, &lo, &hi, yield)
+		}
+	}
+}
+
+// ascend calls yield for the items in the subtree of n in increasing
+// order, starting at the first key greater than or equal to *lo if lo is
+// not nil, and stopping before *hi if hi is not nil. It returns false if
+// it stopped early.
+func (n *node[K, V]) ascend(cmp func(K, K) int, lo, hi *K, yield func(K, V) bool) bool {
+	i := 0
+	if lo != nil {
+		i, _ = n.find(*lo, cmp)
+	}
+	for ; i <= len(n.keys); i++ {
+		if n.children != nil && !n.children[i].ascend(cmp, lo, hi, yield) {
+			return false
+		}
+		if i == len(n.keys) {
+			break
+		}
+		if hi != nil && cmp(n.keys[i], *hi) >= 0 {
+			return false
+		}
+		if !yield(n.keys[i], n.vals[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// descend calls yield for the items in the subtree of n in decreasing
+// order. It returns false if yield stopped the iteration.
+func (n *node[K, V]) descend(yield func(K, V) bool) bool {
+	for i := len(n.keys); i >= 0; i-- {
+		if n.children != nil && !n.children[i].descend(yield) {
+			return false
+		}
+		if i == 0 {
+			break
+		}
+		if !yield(n.keys[i-1], n.vals[i-1]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,391 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// checkMap checks the invariants of the tree of m and that it holds the
+// pairs of want.
+func checkMap(t *testing.T, m *Map[int, int], want map[int]int) {
+	t.Helper()
+	if m.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", m.Len(), len(want))
+	}
+	if m.root != nil {
+		n, _ := checkNode(t, m.root, true, nil, nil)
+		if n != len(want) {
+			t.Fatalf("tree has %d items, want %d", n, len(want))
+		}
+	}
+	keys := slices.Sorted(maps.Keys(want))
+	var got []int
+	for k, v := range m.All() {
+		if v != want[k] {
+			t.Fatalf("All() yields %d: %d, want %d: %d", k, v, k, want[k])
+		}
+		got = append(got, k)
+	}
+	if !slices.Equal(got, keys) {
+		t.Fatalf("All() yields keys %v, want %v", got, keys)
+	}
+	got = got[:0]
+	for k := range m.Backward() {
+		got = append(got, k)
+	}
+	slices.Reverse(keys)
+	if !slices.Equal(got, keys) {
+		t.Fatalf("Backward() yields keys %v, want %v", got, keys)
+	}
+}
+
+// checkNode checks the subtree of n, whose keys must be between lo and
+// hi when they are not nil, and returns its number of items and height.
+func checkNode(t *testing.T, n *node[int, int], root bool, lo, hi *int) (items, height int) {
+	t.Helper()
+	if len(n.keys) != len(n.vals) {
+		t.Fatalf("node has %d keys and %d values", len(n.keys), len(n.vals))
+	}
+	if len(n.keys) > maxItems || len(n.keys) == 0 || !root && len(n.keys) < minItems {
+		t.Fatalf("node has %d items", len(n.keys))
+	}
+	for i, k := range n.keys {
+		if i > 0 && n.keys[i-1] >= k || lo != nil && k <= *lo || hi != nil && k >= *hi {
+			t.Fatalf("node keys %v out of order in (%v, %v)", n.keys, lo, hi)
+		}
+	}
+	items = len(n.keys)
+	if n.children == nil {
+		return items, 1
+	}
+	if len(n.children) != len(n.keys)+1 {
+		t.Fatalf("node has %d keys and %d children", len(n.keys), len(n.children))
+	}
+	for i, c := range n.children {
+		clo, chi := lo, hi
+		if i > 0 {
+			clo = &n.keys[i-1]
+		}
+		if i < len(n.keys) {
+			chi = &n.keys[i]
+		}
+		ci, ch := checkNode(t, c, false, clo, chi)
+		if i > 0 && ch != height-1 {
+			t.Fatalf("children of node have different heights")
+		}
+		items += ci
+		height = ch + 1
+	}
+	return items, height
+}
+
+func TestMap(t *testing.T) {
+	m := NewMap[int, int]()
+	want := make(map[int]int)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range 20000 {
+		k := rng.IntN(3000)
+		switch rng.IntN(3) {
+		case 0, 1:
+			m.Set(k, i)
+			want[k] = i
+		case 2:
+			v, ok := m.Delete(k)
+			wv, wok := want[k]
+			if v != wv || ok != wok {
+				t.Fatalf("Delete(%d) = %d, %v, want %d, %v", k, v, ok, wv, wok)
+			}
+			delete(want, k)
+		}
+		if i%1000 == 0 {
+			checkMap(t, m, want)
+		}
+		v, ok := m.Get(k)
+		wv, wok := want[k]
+		if v != wv || ok != wok {
+			t.Fatalf("Get(%d) = %d, %v, want %d, %v", k, v, ok, wv, wok)
+		}
+	}
+	checkMap(t, m, want)
+	for k := range want {
+		if _, ok := m.Delete(k); !ok {
+			t.Fatalf("Delete(%d) didn't find the key", k)
+		}
+	}
+	checkMap(t, m, map[int]int{})
+	if m.root != nil {
+		t.Errorf("empty Map has a root")
+	}
+}
+
+func TestMapQueries(t *testing.T) {
+	m := NewMap[int, string]()
+	if _, _, ok := m.Min(); ok {
+		t.Errorf("Min() of empty Map succeeded")
+	}
+	for i := range 1000 {
+		m.Set(2*i, strings.Repeat("x", i%5))
+	}
+	if k, _, _ := m.Min(); k != 0 {
+		t.Errorf("Min() = %d, want 0", k)
+	}
+	if k, _, _ := m.Max(); k != 1998 {
+		t.Errorf("Max() = %d, want 1998", k)
+	}
+	for k := -1; k <= 2000; k++ {
+		wantFloor, wantFloorOK := k&^1, k >= 0
+		if k > 1998 {
+			wantFloor = 1998
+		}
+		if got, _, ok := m.Floor(k); got != wantFloor && wantFloorOK || ok != wantFloorOK {
+			t.Errorf("Floor(%d) = %d, %v, want %d, %v", k, got, ok, wantFloor, wantFloorOK)
+		}
+		wantCeil, wantCeilOK := (k+1)&^1, k <= 1998
+		if got, _, ok := m.Ceiling(k); got != wantCeil && wantCeilOK || ok != wantCeilOK {
+			t.Errorf("Ceiling(%d) = %d, %v, want %d, %v", k, got, ok, wantCeil, wantCeilOK)
+		}
+	}
+
+	var got []int
+	for k := range m.Range(11, 21) {
+		got = append(got, k)
+	}
+	if want := []int{12, 14, 16, 18, 20}; !slices.Equal(got, want) {
+		t.Errorf("Range(11, 21) yields %v, want %v", got, want)
+	}
+	got = got[:0]
+	for k := range m.Range(1500, 1000) {
+		got = append(got, k)
+	}
+	if len(got) != 0 {
+		t.Errorf("Range(1500, 1000) yields %v, want nothing", got)
+	}
+	got = got[:0]
+	for k := range m.Keys() {
+		if k == 6 {
+			break
+		}
+		got = append(got, k)
+	}
+	if want := []int{0, 2, 4}; !slices.Equal(got, want) {
+		t.Errorf("Keys() until 6 yields %v, want %v", got, want)
+	}
+}
+
+func TestMapClone(t *testing.T) {
+	m := NewMap[int, int]()
+	want := make(map[int]int)
+	for i := range 5000 {
+		m.Set(i, i)
+		want[i] = i
+	}
+	c := m.Clone()
+	wantClone := maps.Clone(want)
+	for i := range 5000 {
+		if i%3 == 0 {
+			m.Delete(i)
+			delete(want, i)
+		}
+		if i%5 == 0 {
+			c.Set(i, -i)
+			wantClone[i] = -i
+		}
+	}
+	checkMap(t, m, want)
+	checkMap(t, c, wantClone)
+
+	c2 := c.Clone()
+	c2.Clear()
+	checkMap(t, c, wantClone)
+}
+
+func TestMapModifyDuringIteration(t *testing.T) {
+	m := NewMap[int, int]()
+	for i := range 1000 {
+		m.Set(i, i)
+	}
+	n := 0
+	for k := range m.All() {
+		// Changes during the iteration are not observed.
+		m.Delete(k + 1)
+		m.Set(k+10000, k)
+		n++
+	}
+	if n != 1000 {
+		t.Errorf("iterated over %d keys, want 1000", n)
+	}
+}
+
+func TestMapConcurrentReads(t *testing.T) {
+	m := NewMap[int, int]()
+	want := make(map[int]int)
+	for i := range 1000 {
+		m.Set(i, i)
+		want[i] = i
+	}
+	// Reading m from several goroutines must not race, under -race.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			n := 0
+			for range m.All() {
+				n++
+			}
+			for range m.Backward() {
+				n++
+			}
+			for range m.Range(100, 200) {
+				n++
+			}
+			if n != 2100 {
+				t.Errorf("iterated over %d keys, want 2100", n)
+			}
+			m.Clone()
+		})
+	}
+	wg.Wait()
+	m.Set(-1, -1)
+	want[-1] = -1
+	checkMap(t, m, want)
+
+	// Once the iterations are over and m was modified, further
+	// iterations don't make m copy its nodes.
+	cow := m.cow
+	for range m.All() {
+	}
+	m.Set(-2, -2)
+	if m.cow != cow || m.root.cow != cow {
+		t.Errorf("Set after an iteration copied the root")
+	}
+}
+
+func TestMapInsert(t *testing.T) {
+	for _, n := range []int{0, 1, 31, 32, 100, 1000, 1023, 1024, 1025, 40000} {
+		// Sorted input builds the tree.
+		m := NewMap[int, int]()
+		want := make(map[int]int)
+		m.Insert(func(yield func(int, int) bool) {
+			for i := range n {
+				want[i] = i
+				if !yield(i, i) {
+					return
+				}
+			}
+		})
+		checkMap(t, m, want)
+
+		// Unsorted input with duplicates, into an empty and a
+		// non-empty map.
+		rng := rand.New(rand.NewPCG(uint64(n), 0))
+		pairs := make([][2]int, n)
+		for i := range pairs {
+			pairs[i] = [2]int{rng.IntN(n/2 + 1), i}
+		}
+		seq := func(yield func(int, int) bool) {
+			for _, p := range pairs {
+				if !yield(p[0], p[1]) {
+					return
+				}
+			}
+		}
+		want = make(map[int]int)
+		for _, p := range pairs {
+			want[p[0]] = p[1]
+		}
+		m = NewMap[int, int]()
+		m.Insert(seq)
+		checkMap(t, m, want)
+		m.Insert(seq)
+		checkMap(t, m, want)
+	}
+}
+
+func TestMapFunc(t *testing.T) {
+	m := NewMapFunc[string, int](func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	m.Set("b", 1)
+	m.Set("A", 2)
+	m.Set("B", 3)
+	var got []string
+	for k, v := range m.All() {
+		got = append(got, k+":"+string(rune('0'+v)))
+	}
+	if want := []string{"A:2", "B:3"}; !slices.Equal(got, want) {
+		t.Errorf("All() yields %v, want %v", got, want)
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := NewSet[string]()
+	s.Insert(slices.Values([]string{"pear", "apple", "fig", "apple"}))
+	if !s.Add("kiwi") || s.Add("fig") {
+		t.Errorf("Add reports the wrong keys as new")
+	}
+	if got, want := slices.Collect(s.All()), []string{"apple", "fig", "kiwi", "pear"}; !slices.Equal(got, want) {
+		t.Errorf("All() yields %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(s.Backward()), []string{"pear", "kiwi", "fig", "apple"}; !slices.Equal(got, want) {
+		t.Errorf("Backward() yields %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(s.Range("b", "l")), []string{"fig", "kiwi"}; !slices.Equal(got, want) {
+		t.Errorf("Range(b, l) yields %v, want %v", got, want)
+	}
+	if k, ok := s.Floor("grape"); k != "fig" || !ok {
+		t.Errorf("Floor(grape) = %q, %v, want fig, true", k, ok)
+	}
+	if k, ok := s.Ceiling("grape"); k != "kiwi" || !ok {
+		t.Errorf("Ceiling(grape) = %q, %v, want kiwi, true", k, ok)
+	}
+	c := s.Clone()
+	if !s.Delete("fig") || s.Delete("fig") || s.Contains("fig") {
+		t.Errorf("Delete(fig) misbehaves")
+	}
+	if !c.Contains("fig") || c.Len() != 4 || s.Len() != 3 {
+		t.Errorf("clone changed with the original")
+	}
+	if k, _ := c.Min(); k != "apple" {
+		t.Errorf("Min() = %q, want apple", k)
+	}
+	if k, _ := c.Max(); k != "pear" {
+		t.Errorf("Max() = %q, want pear", k)
+	}
+}
+
+func BenchmarkMapSet(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	keys := make([]int, 1<<16)
+	for i := range keys {
+		keys[i] = rng.Int()
+	}
+	m := NewMap[int, int]()
+	i := 0
+	for b.Loop() {
+		m.Set(keys[i&(len(keys)-1)], i)
+		i++
+	}
+}
+
+func BenchmarkMapGet(b *testing.B) {
+	m := NewMap[int, int]()
+	m.Insert(func(yield func(int, int) bool) {
+		for i := range 1 << 16 {
+			if !yield(i, i) {
+				return
+			}
+		}
+	})
+	i := 0
+	for b.Loop() {
+		m.Get(i & (1<<16 - 1))
+		i += 7919
+	}
+}
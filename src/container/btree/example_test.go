@@ -0,0 +1,36 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package btree_test
+
+import (
+	"container/btree"
+	"fmt"
+)
+
+func Example() {
+	// Map the start of address ranges to their owners.
+	owners := btree.NewMap[int, string]()
+	owners.Set(0, "kernel")
+	owners.Set(4096, "heap")
+	owners.Set(65536, "stack")
+
+	// Find the range containing an address.
+	start, owner, _ := owners.Floor(5000)
+	fmt.Println(start, owner)
+
+	for start, owner := range owners.Range(1, 100000) {
+		fmt.Println(start, owner)
+	}
+
+	// Clones are cheap and independent.
+	snapshot := owners.Clone()
+	owners.Delete(4096)
+	fmt.Println(owners.Len(), snapshot.Len())
+	// Output:
+	// 4096 heap
+	// 4096 heap
+	// 65536 stack
+	// 2 3
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// A Set is an ordered set of keys of type K. The zero value is not
+// usable; use [NewSet] or [NewSetFunc].
+//
+// A Set is not safe for concurrent use by multiple goroutines if any of
+// them modifies it, but different clones of a Set may be used
+// concurrently.
+type Set[K any] struct {
+	m Map[K, struct{}]
+}
+
+// NewSet returns an empty Set ordered by [cmp.Compare].
+func NewSet[K cmp.Ordered]() *Set[K] {
+	return NewSetFunc(cmp.Compare[K])
+}
+
+// NewSetFunc returns an empty Set ordered by cmp, which must be a strict
+// weak ordering as for [slices.SortFunc]. Keys that compare equal are
+// the same key.
+func NewSetFunc[K any](cmp func(a, b K) int) *Set[K] {
+	return &Set[K]{Map[K, struct{}]{cmp: cmp, cow: new(owner)}}
+}
+
+// Len returns the number of keys in s.
+func (s *Set[K]) Len() int {
+	return s.m.Len()
+}
+
+// Clone returns a copy of s, in constant time. s and its clone share
+// their nodes until either modifies them.
+func (s *Set[K]) Clone() *Set[K] {
+	c := new(Set[K])
+	s.m.cloneTo(&c.m)
+	return c
+}
+
+// Clear removes all the keys from s.
+func (s *Set[K]) Clear() {
+	s.m.Clear()
+}
+
+// Add adds k to s. It reports whether k was added, that is, whether it
+// was not already present.
+func (s *Set[K]) Add(k K) bool {
+	n := s.m.Len()
+	s.m.Set(k, struct{}{})
+	return s.m.Len() > n
+}
+
+// Delete removes k from s. It reports whether k was present.
+func (s *Set[K]) Delete(k K) bool {
+	_, ok := s.m.Delete(k)
+	return ok
+}
+
+// Contains reports whether k is present in s.
+func (s *Set[K]) Contains(k K) bool {
+	return s.m.Contains(k)
+}
+
+// Min returns the smallest key in s. ok is false if s is empty.
+func (s *Set[K]) Min() (k K, ok bool) {
+	k, _, ok = s.m.Min()
+	return k, ok
+}
+
+// Max returns the largest key in s. ok is false if s is empty.
+func (s *Set[K]) Max() (k K, ok bool) {
+	k, _, ok = s.m.Max()
+	return k, ok
+}
+
+// Floor returns the largest key in s less than or equal to k. ok is
+// false if there is no such key.
+func (s *Set[K]) Floor(k K) (key K, ok bool) {
+	key, _, ok = s.m.Floor(k)
+	return key, ok
+}
+
+// Ceiling returns the smallest key in s greater than or equal to k. ok
+// is false if there is no such key.
+func (s *Set[K]) Ceiling(k K) (key K, ok bool) {
+	key, _, ok = s.m.Ceiling(k)
+	return key, ok
+}
+
+// Insert adds the keys from seq to s. If s is empty, Insert builds the
+// tree in one pass, which takes linear time if the keys of seq are in
+// increasing order.
+func (s *Set[K]) Insert(seq iter.Seq[K]) {
+	s.m.Insert(func(yield func(K, struct{}) bool) {
+		for k := range seq {
+			if !yield(k, struct{}{}) {
+				return
+			}
+		}
+	})
+}
+
+// All returns an iterator over the keys in s, in increasing order. The
+// iterator iterates over the contents of s when it starts: changes to s
+// during the iteration are not observed.
+func (s *Set[K]) All() iter.Seq[K] {
+	return s.m.Keys()
+}
+
+// Backward returns an iterator over the keys in s, in decreasing order.
+// Like [Set.All], it iterates over the contents of s when it starts.
+func (s *Set[K]) Backward() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s.m.Backward() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the keys in s greater than or equal to
+// lo and less than hi, in increasing order. Like [Set.All], it iterates
+// over the contents of s when it starts.
+func (s *Set[K]) Range(lo, hi K) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s.m.Range(lo, hi) {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keystore_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/keystore"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func ExampleFindKey() {
+	dir, err := os.MkdirTemp("", "keystore")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Set up a software token holding a CA key. With a hardware token, the
+	// key would be provisioned on the device, and the Provider registered by
+	// its integration package.
+	token, err := keystore.OpenSoftToken(filepath.Join(dir, "token.pem"), "example")
+	if err != nil {
+		log.Fatal(err)
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := token.ImportKey("ca-key", nil, priv); err != nil {
+		log.Fatal(err)
+	}
+	keystore.Register(token)
+	defer keystore.Unregister(token)
+
+	// Sign a self-signed certificate with the key, referenced by URI.
+	key, err := keystore.FindKey("pkcs11:token=example;object=ca-key;type=private")
+	if err != nil {
+		log.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Example CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(cert.Subject.CommonName)
+	// Output: Example CA
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package keystore defines a common interface to private keys held by key
+// stores such as hardware security modules and smart cards, modeled after
+// the token and object abstractions of PKCS #11.
+//
+// Keys are exposed as [crypto.Signer] (and, when permitted, [crypto.Decrypter])
+// values, so they can be used anywhere the standard library accepts a private
+// key, for example in [crypto/tls.Certificate] or with
+// [crypto/x509.CreateCertificate]. Keys can be looked up across all registered
+// providers with a PKCS #11 URI, as defined in [RFC 7512].
+//
+// This package doesn't implement a PKCS #11 module loader. Integrations with
+// hardware devices implement [Provider] and make it available with
+// [Register]. [SoftToken] is a file-backed software implementation, suitable
+// for tests.
+//
+// [RFC 7512]: https://www.rfc-editor.org/rfc/rfc7512.html
+package keystore
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// TokenInfo describes a token, the device or store holding a set of keys.
+// It corresponds to the CK_TOKEN_INFO structure of PKCS #11.
+type TokenInfo struct {
+	// Label is the application-defined name of the token, matched by the
+	// "token" attribute of a PKCS #11 URI.
+	Label string
+
+	// Manufacturer, Model, and Serial identify the device, and are matched
+	// by the "manufacturer", "model", and "serial" attributes of a PKCS #11
+	// URI.
+	Manufacturer string
+	Model        string
+	Serial       string
+}
+
+// Attributes describes a private key object held by a token.
+type Attributes struct {
+	// Label is the application-defined name of the key, matched by the
+	// "object" attribute of a PKCS #11 URI. It corresponds to CKA_LABEL.
+	Label string
+
+	// ID is the key identifier, matched by the "id" attribute of a PKCS #11
+	// URI. It corresponds to CKA_ID, and is conventionally shared by a
+	// private key and its certificate.
+	ID []byte
+
+	// Sign and Decrypt report whether the key may be used to produce
+	// signatures and to decrypt data, respectively. They correspond to
+	// CKA_SIGN and CKA_DECRYPT.
+	Sign    bool
+	Decrypt bool
+}
+
+// A Key is a private key held by a token. The key material is generally not
+// accessible, and operations are performed by the token.
+//
+// Keys with the Decrypt attribute also implement [crypto.Decrypter].
+type Key interface {
+	crypto.Signer
+
+	// Attributes returns the attributes of the key.
+	Attributes() Attributes
+}
+
+// A Provider gives access to the keys held by a token.
+//
+// Its methods may be called concurrently.
+type Provider interface {
+	// TokenInfo returns information about the token.
+	TokenInfo() TokenInfo
+
+	// Keys returns the private keys held by the token.
+	Keys() ([]Key, error)
+}
+
+// A LoginProvider is a Provider that requires authentication with a PIN
+// before its keys can be used.
+type LoginProvider interface {
+	Provider
+
+	// Login authenticates to the token with the user PIN.
+	Login(pin string) error
+}
+
+var (
+	providersMu sync.Mutex
+	providers   []Provider
+)
+
+// Register makes a provider available to [FindKey]. Providers are searched
+// in the order they were registered. Register panics if p is nil or if it is
+// already registered.
+func Register(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if p == nil {
+		panic("keystore: Register provider is nil")
+	}
+	if slices.Contains(providers, p) {
+		panic("keystore: Register called twice for provider " + p.TokenInfo().Label)
+	}
+	providers = append(providers, p)
+}
+
+// Unregister removes a provider registered with [Register]. It is a no-op if
+// p is not registered.
+func Unregister(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers = slices.DeleteFunc(providers, func(q Provider) bool { return q == p })
+}
+
+// Providers returns the registered providers, in registration order.
+func Providers() []Provider {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	return slices.Clone(providers)
+}
+
+// FindKey returns the private key identified by the PKCS #11 URI uri among
+// the keys of the registered providers.
+//
+// The URI must match exactly one key. Its "type" attribute, if present, must
+// be "private". If it has a "pin-value" query attribute, the PIN is passed to
+// the Login method of matching providers that implement [LoginProvider]
+// before their keys are listed.
+func FindKey(uri string) (Key, error) {
+	u, err := ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != "" && u.Type != "private" {
+		return nil, fmt.Errorf("keystore: URI type %q does not identify a private key", u.Type)
+	}
+
+	var found []Key
+	var errs []error
+	for _, p := range Providers() {
+		if !u.MatchToken(p.TokenInfo()) {
+			continue
+		}
+		if lp, ok := p.(LoginProvider); ok && u.PINValue != "" {
+			if err := lp.Login(u.PINValue); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		keys, err := p.Keys()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, k := range keys {
+			if u.MatchKey(k.Attributes()) {
+				found = append(found, k)
+			}
+		}
+	}
+
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) > 1:
+		return nil, fmt.Errorf("keystore: %s matches %d keys", u.Redacted(), len(found))
+	case len(errs) > 0:
+		return nil, fmt.Errorf("keystore: no key matches %s: %w", u.Redacted(), errors.Join(errs...))
+	default:
+		return nil, fmt.Errorf("keystore: no key matches %s", u.Redacted())
+	}
+}
+
+// MatchToken reports whether the token attributes of u match info. Absent
+// attributes match any value.
+func (u *URI) MatchToken(info TokenInfo) bool {
+	return (u.Token == "" || u.Token == info.Label) &&
+		(u.Manufacturer == "" || u.Manufacturer == info.Manufacturer) &&
+		(u.Model == "" || u.Model == info.Model) &&
+		(u.Serial == "" || u.Serial == info.Serial)
+}
+
+// MatchKey reports whether the object attributes of u match attrs. Absent
+// attributes match any value. The "type" attribute is not considered.
+func (u *URI) MatchKey(attrs Attributes) bool {
+	return (u.Object == "" || u.Object == attrs.Label) &&
+		(u.ID == nil || bytes.Equal(u.ID, attrs.ID))
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keystore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// A SoftToken is a [Provider] that keeps its keys in a file, as a sequence
+// of PEM "PRIVATE KEY" blocks holding PKCS #8 encoded keys. The Label and ID
+// attributes of each key are stored in the "Label" and "ID" (hex-encoded)
+// PEM headers.
+//
+// The keys are stored unencrypted, and are only protected by the
+// permissions of the file. SoftToken is meant for tests and development, as
+// a stand-in for a hardware token.
+type SoftToken struct {
+	path string
+	info TokenInfo
+
+	mu   sync.Mutex
+	keys []*softKey
+}
+
+// OpenSoftToken opens the software token stored in the file at path, creating
+// an empty token if the file doesn't exist. The label is reported as the
+// [TokenInfo] Label of the token, with the manufacturer "Go" and the model
+// "SoftToken".
+func OpenSoftToken(path, label string) (*SoftToken, error) {
+	t := &SoftToken{
+		path: path,
+		info: TokenInfo{Label: label, Manufacturer: "Go", Model: "SoftToken"},
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return t, t.save()
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PRIVATE KEY" {
+			continue
+		}
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("keystore: parsing key in %s: %w", path, err)
+		}
+		var id []byte
+		if h, ok := block.Headers["ID"]; ok {
+			if id, err = hex.DecodeString(h); err != nil {
+				return nil, fmt.Errorf("keystore: invalid key ID in %s: %w", path, err)
+			}
+		}
+		sk, err := newSoftKey(block.Headers["Label"], id, k)
+		if err != nil {
+			return nil, err
+		}
+		t.keys = append(t.keys, sk)
+	}
+	return t, nil
+}
+
+// TokenInfo implements [Provider].
+func (t *SoftToken) TokenInfo() TokenInfo {
+	return t.info
+}
+
+// Keys implements [Provider].
+func (t *SoftToken) Keys() ([]Key, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys := make([]Key, 0, len(t.keys))
+	for _, k := range t.keys {
+		keys = append(keys, k.k)
+	}
+	return keys, nil
+}
+
+// ImportKey stores key in the token with the given attributes, and returns
+// the new token key. key must be an *[rsa.PrivateKey], an *[ecdsa.PrivateKey],
+// or an [ed25519.PrivateKey]. RSA keys have the Decrypt attribute.
+//
+// It is an error to import a key with the same label and ID as an existing
+// key.
+func (t *SoftToken) ImportKey(label string, id []byte, key crypto.Signer) (Key, error) {
+	sk, err := newSoftKey(label, id, key)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, k := range t.keys {
+		if k.attrs.Label == label && bytes.Equal(k.attrs.ID, id) {
+			return nil, fmt.Errorf("keystore: token already holds a key with label %q and ID %x", label, id)
+		}
+	}
+	t.keys = append(t.keys, sk)
+	if err := t.save(); err != nil {
+		t.keys = t.keys[:len(t.keys)-1]
+		return nil, err
+	}
+	return sk.k, nil
+}
+
+// DeleteKey removes key, which must have been returned by the Keys or
+// ImportKey methods of t, from the token.
+func (t *SoftToken) DeleteKey(key Key) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, k := range t.keys {
+		if k.k == key {
+			keys := t.keys
+			t.keys = append(keys[:i:i], keys[i+1:]...)
+			if err := t.save(); err != nil {
+				t.keys = keys
+				return err
+			}
+			return nil
+		}
+	}
+	return errors.New("keystore: key not found in token")
+}
+
+// save writes the keys to the token file, replacing it atomically.
+func (t *SoftToken) save() error {
+	var buf bytes.Buffer
+	for _, k := range t.keys {
+		der, err := x509.MarshalPKCS8PrivateKey(k.priv)
+		if err != nil {
+			return err
+		}
+		block := &pem.Block{Type: "PRIVATE KEY", Headers: map[string]string{}, Bytes: der}
+		if k.attrs.Label != "" {
+			block.Headers["Label"] = k.attrs.Label
+		}
+		if k.attrs.ID != nil {
+			block.Headers["ID"] = hex.EncodeToString(k.attrs.ID)
+		}
+		if err := pem.Encode(&buf, block); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(t.path), filepath.Base(t.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), t.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// A softKey is a key held by a SoftToken.
+type softKey struct {
+	priv  crypto.Signer
+	attrs Attributes
+	k     Key // the Key exposing priv, returned on each call to Keys
+}
+
+func newSoftKey(label string, id []byte, priv any) (*softKey, error) {
+	sk := &softKey{attrs: Attributes{Label: label, ID: bytes.Clone(id), Sign: true}}
+	switch priv := priv.(type) {
+	case *rsa.PrivateKey:
+		sk.priv = priv
+		sk.attrs.Decrypt = true
+		sk.k = &softDecrypter{softSigner{sk}}
+	case *ecdsa.PrivateKey, ed25519.PrivateKey:
+		sk.priv = priv.(crypto.Signer)
+		sk.k = &softSigner{sk}
+	default:
+		return nil, fmt.Errorf("keystore: unsupported private key type %T", priv)
+	}
+	return sk, nil
+}
+
+// softSigner is the Key implementation of SoftToken. It doesn't expose the
+// underlying private key, like a hardware token wouldn't.
+type softSigner struct {
+	sk *softKey
+}
+
+func (s *softSigner) Public() crypto.PublicKey {
+	return s.sk.priv.Public()
+}
+
+func (s *softSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.sk.priv.Sign(rand, digest, opts)
+}
+
+func (s *softSigner) Attributes() Attributes {
+	attrs := s.sk.attrs
+	attrs.ID = bytes.Clone(attrs.ID)
+	return attrs
+}
+
+// softDecrypter is a softSigner that also implements crypto.Decrypter.
+type softDecrypter struct {
+	softSigner
+}
+
+func (s *softDecrypter) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	return s.sk.priv.(crypto.Decrypter).Decrypt(rand, msg, opts)
+}
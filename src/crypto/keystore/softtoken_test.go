@@ -0,0 +1,221 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keystore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKeys(t *testing.T) map[string]crypto.Signer {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"rsa": rsaKey, "ecdsa": ecKey, "ed25519": edKey}
+}
+
+func TestSoftToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.pem")
+	token, err := OpenSoftToken(path, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := testKeys(t)
+	for label, k := range keys {
+		if _, err := token.ImportKey(label, []byte(label), k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := token.ImportKey("rsa", []byte("rsa"), keys["rsa"]); err == nil {
+		t.Error("ImportKey succeeded for a duplicate key")
+	}
+
+	// Reopen the token, to check the keys were persisted.
+	token, err = OpenSoftToken(path, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenKeys, err := token.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokenKeys) != len(keys) {
+		t.Fatalf("got %d keys, want %d", len(tokenKeys), len(keys))
+	}
+	for _, k := range tokenKeys {
+		attrs := k.Attributes()
+		priv, ok := keys[attrs.Label]
+		if !ok {
+			t.Fatalf("unexpected key %q", attrs.Label)
+		}
+		if !bytes.Equal(attrs.ID, []byte(attrs.Label)) {
+			t.Errorf("key %q: ID = %q", attrs.Label, attrs.ID)
+		}
+		if !priv.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(k.Public()) {
+			t.Errorf("key %q: public key doesn't match", attrs.Label)
+		}
+		if !attrs.Sign {
+			t.Errorf("key %q: Sign = false", attrs.Label)
+		}
+		_, isDecrypter := k.(crypto.Decrypter)
+		if attrs.Decrypt != (attrs.Label == "rsa") || isDecrypter != attrs.Decrypt {
+			t.Errorf("key %q: Decrypt = %v, implements crypto.Decrypter = %v", attrs.Label, attrs.Decrypt, isDecrypter)
+		}
+
+		msg := []byte("hello")
+		digest := sha256.Sum256(msg)
+		switch pub := k.Public().(type) {
+		case *rsa.PublicKey:
+			sig, err := k.Sign(rand.Reader, digest[:], crypto.SHA256)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+				t.Errorf("RSA signature: %v", err)
+			}
+			ct, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, msg, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pt, err := k.(crypto.Decrypter).Decrypt(rand.Reader, ct, &rsa.OAEPOptions{Hash: crypto.SHA256})
+			if err != nil || !bytes.Equal(pt, msg) {
+				t.Errorf("RSA decryption = %q, %v", pt, err)
+			}
+		case *ecdsa.PublicKey:
+			sig, err := k.Sign(rand.Reader, digest[:], crypto.SHA256)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+				t.Error("ECDSA signature is invalid")
+			}
+		case ed25519.PublicKey:
+			sig, err := k.Sign(rand.Reader, msg, crypto.Hash(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ed25519.Verify(pub, msg, sig) {
+				t.Error("Ed25519 signature is invalid")
+			}
+		}
+	}
+
+	if err := token.DeleteKey(tokenKeys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := token.DeleteKey(tokenKeys[0]); err == nil {
+		t.Error("DeleteKey succeeded twice")
+	}
+	token, err = OpenSoftToken(path, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokenKeys, _ := token.Keys(); len(tokenKeys) != len(keys)-1 {
+		t.Errorf("got %d keys after DeleteKey, want %d", len(tokenKeys), len(keys)-1)
+	}
+}
+
+// loginProvider is a LoginProvider wrapping a SoftToken.
+type loginProvider struct {
+	*SoftToken
+	pin      string
+	loggedIn bool
+}
+
+func (p *loginProvider) Login(pin string) error {
+	if pin != p.pin {
+		return errors.New("incorrect PIN")
+	}
+	p.loggedIn = true
+	return nil
+}
+
+func (p *loginProvider) Keys() ([]Key, error) {
+	if !p.loggedIn {
+		return nil, errors.New("not logged in")
+	}
+	return p.SoftToken.Keys()
+}
+
+func TestFindKey(t *testing.T) {
+	keys := testKeys(t)
+	dir := t.TempDir()
+	token1, err := OpenSoftToken(filepath.Join(dir, "token1.pem"), "token1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := OpenSoftToken(filepath.Join(dir, "token2.pem"), "token2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for label, k := range keys {
+		if _, err := token1.ImportKey(label, []byte{1}, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := token2.ImportKey("rsa", []byte{2}, keys["rsa"]); err != nil {
+		t.Fatal(err)
+	}
+	locked := &loginProvider{SoftToken: token2, pin: "1234"}
+	Register(token1)
+	defer Unregister(token1)
+	Register(locked)
+	defer Unregister(locked)
+
+	for _, tt := range []struct {
+		uri       string
+		wantLabel string
+		wantErr   string
+	}{
+		{uri: "pkcs11:object=ecdsa", wantLabel: "ecdsa"},
+		{uri: "pkcs11:token=token1;object=ed25519;type=private", wantLabel: "ed25519"},
+		{uri: "pkcs11:object=rsa", wantLabel: "rsa"}, // token2 fails, but token1 matches
+		{uri: "pkcs11:object=rsa;id=%02", wantErr: "not logged in"},
+		{uri: "pkcs11:object=rsa;id=%02?pin-value=0000", wantErr: "incorrect PIN"},
+		{uri: "pkcs11:object=rsa?pin-value=1234", wantErr: "matches 2 keys"},
+		{uri: "pkcs11:object=rsa;id=%02?pin-value=1234", wantLabel: "rsa"},
+		{uri: "pkcs11:token=token2;model=SoftToken;object=rsa", wantLabel: "rsa"},
+		{uri: "pkcs11:model=HSM;object=rsa", wantErr: "no key matches"},
+		{uri: "pkcs11:object=ecdsa;type=cert", wantErr: "does not identify a private key"},
+		{uri: "pkcs11:object=missing", wantErr: "no key matches"},
+	} {
+		k, err := FindKey(tt.uri)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FindKey(%q) error = %v, want %q", tt.uri, err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "pin-value") {
+				t.Errorf("FindKey(%q) error %q includes the PIN", tt.uri, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindKey(%q): %v", tt.uri, err)
+			continue
+		}
+		if label := k.Attributes().Label; label != tt.wantLabel {
+			t.Errorf("FindKey(%q) = key %q, want %q", tt.uri, label, tt.wantLabel)
+		}
+	}
+}
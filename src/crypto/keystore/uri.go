@@ -0,0 +1,196 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A URI is a parsed PKCS #11 URI, as specified by RFC 7512, such as
+//
+//	pkcs11:token=My%20Token;object=server-key;type=private
+//
+// Empty fields are absent from the URI.
+type URI struct {
+	// Token attributes, see [TokenInfo].
+	Token        string
+	Manufacturer string
+	Model        string
+	Serial       string
+
+	// Object attributes, see [Attributes]. Type is one of "public",
+	// "private", "cert", "secret-key", or "data".
+	Object string
+	ID     []byte
+	Type   string
+
+	// PINValue is the value of the "pin-value" query attribute.
+	PINValue string
+}
+
+// ParseURI parses a PKCS #11 URI.
+//
+// Path attributes other than the token and object attributes of [URI],
+// including the library and slot attributes of RFC 7512, are rejected, since
+// matching them is not supported. Query attributes other than "pin-value" are
+// ignored.
+func ParseURI(s string) (*URI, error) {
+	rest, ok := strings.CutPrefix(s, "pkcs11:")
+	if !ok {
+		return nil, errors.New("keystore: URI scheme is not pkcs11")
+	}
+	path, query, _ := strings.Cut(rest, "?")
+
+	u := &URI{}
+	seen := make(map[string]bool)
+	for attr := range strings.SplitSeq(path, ";") {
+		if attr == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(attr, "=")
+		if !ok {
+			return nil, fmt.Errorf("keystore: malformed URI attribute %q", attr)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("keystore: duplicate URI attribute %q", name)
+		}
+		seen[name] = true
+		v, err := unescape(value)
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "token":
+			u.Token = v
+		case "manufacturer":
+			u.Manufacturer = v
+		case "model":
+			u.Model = v
+		case "serial":
+			u.Serial = v
+		case "object":
+			u.Object = v
+		case "id":
+			u.ID = []byte(v)
+		case "type":
+			switch v {
+			case "public", "private", "cert", "secret-key", "data":
+			default:
+				return nil, fmt.Errorf("keystore: unknown URI object type %q", v)
+			}
+			u.Type = v
+		default:
+			return nil, fmt.Errorf("keystore: unsupported URI attribute %q", name)
+		}
+	}
+
+	for attr := range strings.SplitSeq(query, "&") {
+		name, value, _ := strings.Cut(attr, "=")
+		if name != "pin-value" {
+			continue
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("keystore: duplicate URI attribute %q", name)
+		}
+		seen[name] = true
+		v, err := unescape(value)
+		if err != nil {
+			return nil, err
+		}
+		u.PINValue = v
+	}
+	return u, nil
+}
+
+// String returns the URI in canonical form, with the attributes in the order
+// of the fields of [URI] and the id attribute fully percent-encoded.
+func (u *URI) String() string {
+	s := u.path()
+	if u.PINValue != "" {
+		s += "?pin-value=" + escape(u.PINValue)
+	}
+	return s
+}
+
+// Redacted is like [URI.String] but omits the PIN, and is suitable for
+// logging and error messages.
+func (u *URI) Redacted() string {
+	return u.path()
+}
+
+func (u *URI) path() string {
+	var attrs []string
+	add := func(name, value string) {
+		if value != "" {
+			attrs = append(attrs, name+"="+escape(value))
+		}
+	}
+	add("token", u.Token)
+	add("manufacturer", u.Manufacturer)
+	add("model", u.Model)
+	add("serial", u.Serial)
+	add("object", u.Object)
+	if u.ID != nil {
+		var b strings.Builder
+		for _, c := range u.ID {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+		attrs = append(attrs, "id="+b.String())
+	}
+	add("type", u.Type)
+	return "pkcs11:" + strings.Join(attrs, ";")
+}
+
+// escape percent-encodes all bytes of s other than the RFC 3986 unreserved
+// characters.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b = append(b, s[i])
+			continue
+		}
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return "", fmt.Errorf("keystore: invalid URI escape %q", s[i:min(i+3, len(s))])
+		}
+		b = append(b, unhex(s[i+1])<<4|unhex(s[i+2]))
+		i += 2
+	}
+	return string(b), nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keystore
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURI(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want *URI
+		out  string // canonical form, if different from in
+	}{
+		{
+			in:   "pkcs11:",
+			want: &URI{},
+		},
+		{
+			in:   "pkcs11:object=my-key",
+			want: &URI{Object: "my-key"},
+		},
+		{
+			in:   "pkcs11:token=The%20Software%20PKCS%2311%20Softtoken;manufacturer=Snake%20Oil,%20Inc.;model=1.0;object=my-certificate;type=cert;id=%69%95%3E%5C%F4%BD%EC%91;serial=",
+			want: &URI{Token: "The Software PKCS#11 Softtoken", Manufacturer: "Snake Oil, Inc.", Model: "1.0", Object: "my-certificate", Type: "cert", ID: []byte{0x69, 0x95, 0x3e, 0x5c, 0xf4, 0xbd, 0xec, 0x91}},
+			out:  "pkcs11:token=The%20Software%20PKCS%2311%20Softtoken;manufacturer=Snake%20Oil%2C%20Inc.;model=1.0;object=my-certificate;id=%69%95%3E%5C%F4%BD%EC%91;type=cert",
+		},
+		{
+			in:   "pkcs11:object=my-key;type=private?pin-value=1234&module-name=p11-kit",
+			want: &URI{Object: "my-key", Type: "private", PINValue: "1234"},
+			out:  "pkcs11:object=my-key;type=private?pin-value=1234",
+		},
+		{
+			in:   "pkcs11:id=",
+			want: &URI{ID: []byte{}},
+		},
+	} {
+		u, err := ParseURI(tt.in)
+		if err != nil {
+			t.Errorf("ParseURI(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(u, tt.want) {
+			t.Errorf("ParseURI(%q) = %+v, want %+v", tt.in, u, tt.want)
+		}
+		out := tt.out
+		if out == "" {
+			out = tt.in
+		}
+		if got := u.String(); got != out {
+			t.Errorf("ParseURI(%q).String() = %q, want %q", tt.in, got, out)
+		}
+	}
+}
+
+func TestParseURIErrors(t *testing.T) {
+	for _, in := range []string{
+		"pkcs12:object=foo",
+		"object=foo",
+		"pkcs11:object",
+		"pkcs11:object=a;object=b",
+		"pkcs11:type=key",
+		"pkcs11:slot-id=1",
+		"pkcs11:object=%4",
+		"pkcs11:object=%zz",
+		"pkcs11:?pin-value=1&pin-value=2",
+	} {
+		if u, err := ParseURI(in); err == nil {
+			t.Errorf("ParseURI(%q) = %+v, want error", in, u)
+		}
+	}
+}
+
+func TestURIRedacted(t *testing.T) {
+	u := &URI{Object: "key", PINValue: "secret"}
+	if got, want := u.Redacted(), "pkcs11:object=key"; got != want {
+		t.Errorf("Redacted() = %q, want %q", got, want)
+	}
+}
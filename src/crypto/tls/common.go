@@ -36,6 +36,12 @@ const (
 	VersionTLS12 = 0x0303
 	VersionTLS13 = 0x0304
 
+	// VersionDTLS12 and VersionDTLS13 are DTLS 1.2 and DTLS 1.3, negotiated
+	// by the connections returned by [DTLSClient] and [DTLSServer]. See RFC
+	// 6347 and RFC 9147.
+	VersionDTLS12 = 0xfefd
+	VersionDTLS13 = 0xfefc
+
 	// Deprecated: SSLv3 is cryptographically broken, and is no longer
 	// supported by this package. See golang.org/issue/32716.
 	VersionSSL30 = 0x0300
@@ -56,6 +62,10 @@ func VersionName(version uint16) string {
 		return "TLS 1.2"
 	case VersionTLS13:
 		return "TLS 1.3"
+	case VersionDTLS12:
+		return "DTLS 1.2"
+	case VersionDTLS13:
+		return "DTLS 1.3"
 	default:
 		return fmt.Sprintf("0x%04X", version)
 	}
@@ -79,6 +89,7 @@ const (
 	recordTypeAlert            recordType = 21
 	recordTypeHandshake        recordType = 22
 	recordTypeApplicationData  recordType = 23
+	recordTypeACK              recordType = 26 // DTLS 1.3 only, see RFC 9147, Section 7
 )
 
 // TLS handshake message types.
@@ -86,6 +97,7 @@ const (
 	typeHelloRequest        uint8 = 0
 	typeClientHello         uint8 = 1
 	typeServerHello         uint8 = 2
+	typeHelloVerifyRequest  uint8 = 3 // DTLS 1.2 only, see RFC 6347, Section 4.2.1
 	typeNewSessionTicket    uint8 = 4
 	typeEndOfEarlyData      uint8 = 5
 	typeEncryptedExtensions uint8 = 8
@@ -115,6 +127,8 @@ const (
 	extensionSignatureAlgorithms     uint16 = 13
 	extensionALPN                    uint16 = 16
 	extensionSCT                     uint16 = 18
+	extensionClientCertificateType   uint16 = 19
+	extensionServerCertificateType   uint16 = 20
 	extensionExtendedMasterSecret    uint16 = 23
 	extensionSessionTicket           uint16 = 35
 	extensionPreSharedKey            uint16 = 41
@@ -192,6 +206,49 @@ type pskIdentity struct {
 	obfuscatedTicketAge uint32
 }
 
+// An ExternalPSK is a TLS 1.3 pre-shared key provisioned out of band, rather
+// than established by a previous connection. See RFC 8446, Section 2.2 and
+// RFC 9257.
+//
+// Connections authenticated with an external PSK use the psk_dhe_ke mode, so
+// they still perform an ephemeral key exchange and provide forward secrecy.
+type ExternalPSK struct {
+	// Identity is the PSK identity, which is sent in the clear in the
+	// ClientHello. It must not be empty.
+	Identity []byte
+
+	// Key is the pre-shared secret. It should contain at least 128 bits of
+	// entropy; low-entropy secrets such as passwords must not be used.
+	Key []byte
+
+	// CipherSuite is a TLS 1.3 cipher suite whose hash function the PSK is
+	// associated with. Only cipher suites with the same hash can be
+	// negotiated when using the PSK. If zero, TLS_AES_128_GCM_SHA256 is
+	// assumed, associating the PSK with SHA-256.
+	CipherSuite uint16
+}
+
+// suite returns the TLS 1.3 cipher suite that determines the hash function
+// of psk, or nil if psk.CipherSuite is not a TLS 1.3 cipher suite.
+func (psk *ExternalPSK) suite() *cipherSuiteTLS13 {
+	if psk.CipherSuite == 0 {
+		return cipherSuiteTLS13ByID(TLS_AES_128_GCM_SHA256)
+	}
+	return cipherSuiteTLS13ByID(psk.CipherSuite)
+}
+
+// CertificateType identifies the kind of credential carried in a TLS 1.3
+// Certificate message. See RFC 7250.
+type CertificateType uint8
+
+const (
+	// CertificateTypeX509 is an X.509 certificate chain.
+	CertificateTypeX509 CertificateType = 0
+	// CertificateTypeRawPublicKey is a bare DER-encoded SubjectPublicKeyInfo,
+	// which must be authenticated out of band rather than through a PKI.
+	CertificateTypeRawPublicKey CertificateType = 2
+)
+
 // TLS Elliptic Curve Point Formats
 // https://www.iana.org/assignments/tls-parameters/tls-parameters.xml#tls-parameters-9
 const (
@@ -321,6 +378,15 @@ type ConnectionState struct {
 	// are a server, or if we received a HelloRetryRequest if we are a client.
 	HelloRetryRequest bool
 
+	// PSKIdentity is the identity of the external pre-shared key that
+	// authenticated the connection, if any. See [Config.ExternalPSKs].
+	PSKIdentity []byte
+
+	// PeerRawPublicKey is the public key presented by the peer, if it
+	// authenticated with a raw public key rather than a certificate chain.
+	// See [Config.PeerCertificateTypes].
+	PeerRawPublicKey crypto.PublicKey
+
 	// ekm is a closure exposed via ExportKeyingMaterial.
 	ekm func(label string, context []byte, length int) ([]byte, error)
 
@@ -478,6 +544,11 @@ type ClientHelloInfo struct {
 	// in the ClientHello.
 	Extensions []uint16
 
+	// ServerCertificateTypes lists the kinds of credentials the client
+	// accepts from the server, in order of preference. It is nil if the
+	// client only accepts X.509 certificates. See RFC 7250, Section 4.1.
+	ServerCertificateTypes []CertificateType
+
 	// Conn is the underlying net.Conn for the connection. Do not read
 	// from, or write to, this connection; that will cause the TLS
 	// connection to fail.
@@ -519,6 +590,11 @@ type CertificateRequestInfo struct {
 	// Version is the TLS version that was negotiated for this connection.
 	Version uint16
 
+	// CertificateType is the kind of credential the server expects, as
+	// negotiated with the client_certificate_type extension. See RFC 7250,
+	// Section 4.2.
+	CertificateType CertificateType
+
 	// ctx is the context of the handshake that is in progress.
 	ctx context.Context
 }
@@ -830,6 +906,19 @@ type Config struct {
 	// used for debugging.
 	KeyLogWriter io.Writer
 
+	// HandshakeTrace, if not nil, is called synchronously with events
+	// describing the progress of each handshake, such as the messages
+	// exchanged, the negotiated parameters, and the alerts sent and received.
+	// It can be used to diagnose handshake failures without packet captures.
+	// See [HandshakeEventKind] for the events.
+	//
+	// HandshakeTrace is called from the goroutine running the handshake, and
+	// it must not call methods of the Conn. It is also called for post-handshake
+	// messages and alerts.
+	//
+	// Unlike KeyLogWriter, HandshakeTrace never exposes secrets.
+	HandshakeTrace func(HandshakeEvent)
+
 	// EncryptedClientHelloConfigList is a serialized ECHConfigList. If
 	// provided, clients will attempt to connect to servers using Encrypted
 	// Client Hello (ECH) using one of the provided ECHConfigs.
@@ -900,6 +989,54 @@ type Config struct {
 	// clients, see the EncryptedClientHelloConfigList field.
 	EncryptedClientHelloKeys []EncryptedClientHelloKey
 
+	// ExternalPSKs are TLS 1.3 pre-shared keys provisioned out of band, which
+	// can be used instead of certificates to authenticate both peers.
+	//
+	// A client offers all of ExternalPSKs whose hash matches one of its TLS
+	// 1.3 cipher suites, and does not attempt session resumption when
+	// ExternalPSKs is not empty. If ServerName is empty and
+	// InsecureSkipVerify is false, the handshake fails unless the server
+	// selects one of them. ExternalPSKs can't be used together with
+	// EncryptedClientHelloConfigList.
+	//
+	// A server accepts the first identity offered by the client that matches
+	// an entry of ExternalPSKs or is returned by GetExternalPSK, unless
+	// ClientAuth requires a client certificate. Session tickets are not
+	// issued for connections authenticated with an external PSK.
+	//
+	// The identity of the PSK in use is reported in
+	// [ConnectionState.PSKIdentity].
+	ExternalPSKs []ExternalPSK
+
+	// GetExternalPSK, if not nil, is called by a server for each PSK identity
+	// offered by the client that is not a valid session ticket, and before
+	// ExternalPSKs is consulted. It returns the external PSK for identity, or
+	// nil if identity is unknown. If it returns an error, the handshake is
+	// aborted.
+	//
+	// On the client side this field is not used.
+	GetExternalPSK func(identity []byte) (*ExternalPSK, error)
+
+	// PeerCertificateTypes lists, in order of preference, the kinds of
+	// credentials accepted from the peer in TLS 1.3 handshakes, as negotiated
+	// with the RFC 7250 server_certificate_type and client_certificate_type
+	// extensions. If empty, only CertificateTypeX509 is accepted.
+	//
+	// A peer that presents a CertificateTypeRawPublicKey credential is
+	// authenticated solely by VerifyRawPublicKey, which must then be set.
+	// A client that does not accept CertificateTypeX509 may leave
+	// ServerName empty.
+	//
+	// The credential types sent to the peer are determined by
+	// [Certificate.Type].
+	PeerCertificateTypes []CertificateType
+
+	// VerifyRawPublicKey is called to authenticate a peer that presented a
+	// raw public key (see PeerCertificateTypes), before VerifyConnection. If
+	// it returns a non-nil error, the handshake is aborted and that error
+	// results. It is called regardless of InsecureSkipVerify and ClientAuth.
+	VerifyRawPublicKey func(crypto.PublicKey) error
+
 	// mutex protects sessionTicketKeys and autoSessionTicketKeys.
 	mutex sync.RWMutex
 	// sessionTicketKeys contains zero or more ticket keys. If set, it means
@@ -1029,9 +1166,14 @@ func (c *Config) Clone() *Config {
 		DynamicRecordSizingDisabled:         c.DynamicRecordSizingDisabled,
 		Renegotiation:                       c.Renegotiation,
 		KeyLogWriter:                        c.KeyLogWriter,
+		HandshakeTrace:                      c.HandshakeTrace,
 		EncryptedClientHelloConfigList:      c.EncryptedClientHelloConfigList,
 		EncryptedClientHelloRejectionVerify: c.EncryptedClientHelloRejectionVerify,
 		EncryptedClientHelloKeys:            c.EncryptedClientHelloKeys,
+		ExternalPSKs:                        c.ExternalPSKs,
+		GetExternalPSK:                      c.GetExternalPSK,
+		PeerCertificateTypes:                c.PeerCertificateTypes,
+		VerifyRawPublicKey:                  c.VerifyRawPublicKey,
 		sessionTicketKeys:                   c.sessionTicketKeys,
 		autoSessionTicketKeys:               c.autoSessionTicketKeys,
 	}
@@ -1357,6 +1499,41 @@ func (c *Config) getCertificate(clientHello *ClientHelloInfo) (*Certificate, err
 	return &c.Certificates[0], nil
 }
 
+// externalPSK returns the external PSK with the given identity, first
+// consulting GetExternalPSK and then ExternalPSKs, or nil if there is none.
+func (c *Config) externalPSK(identity []byte) (*ExternalPSK, error) {
+	if c.GetExternalPSK != nil {
+		psk, err := c.GetExternalPSK(identity)
+		if psk != nil || err != nil {
+			return psk, err
+		}
+	}
+	for i := range c.ExternalPSKs {
+		if bytes.Equal(c.ExternalPSKs[i].Identity, identity) {
+			return &c.ExternalPSKs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// acceptsPeerCertificateType reports whether credentials of type t are
+// accepted from the peer, according to c.PeerCertificateTypes.
+func (c *Config) acceptsPeerCertificateType(t CertificateType) bool {
+	if len(c.PeerCertificateTypes) == 0 {
+		return t == CertificateTypeX509
+	}
+	return slices.Contains(c.PeerCertificateTypes, t)
+}
+
+// supportsCertificateType reports whether the client accepts server
+// credentials of type t in protocol version vers.
+func (chi *ClientHelloInfo) supportsCertificateType(vers uint16, t CertificateType) bool {
+	if vers < VersionTLS13 || len(chi.ServerCertificateTypes) == 0 {
+		return t == CertificateTypeX509
+	}
+	return slices.Contains(chi.ServerCertificateTypes, t)
+}
+
 // SupportsCertificate returns nil if the provided certificate is supported by
 // the client that sent the ClientHello. Otherwise, it returns an error
 // describing the reason for the incompatibility.
@@ -1383,9 +1560,13 @@ func (chi *ClientHelloInfo) SupportsCertificate(c *Certificate) error {
 		return errors.New("no mutually supported protocol versions")
 	}
 
+	if !chi.supportsCertificateType(vers, c.Type) {
+		return errors.New("certificate type not accepted by the client")
+	}
+
 	// If the client specified the name they are trying to connect to, the
-	// certificate needs to be valid for it.
-	if chi.ServerName != "" {
+	// certificate needs to be valid for it. Raw public keys carry no names.
+	if chi.ServerName != "" && c.Type != CertificateTypeRawPublicKey {
 		x509Cert, err := c.leaf()
 		if err != nil {
 			return fmt.Errorf("failed to parse certificate: %w", err)
@@ -1526,11 +1707,15 @@ func (chi *ClientHelloInfo) SupportsCertificate(c *Certificate) error {
 // the server that sent the CertificateRequest. Otherwise, it returns an error
 // describing the reason for the incompatibility.
 func (cri *CertificateRequestInfo) SupportsCertificate(c *Certificate) error {
+	if c.Type != cri.CertificateType {
+		return errors.New("certificate type not accepted by the server")
+	}
+
 	if _, err := selectSignatureScheme(cri.Version, c, cri.SignatureSchemes); err != nil {
 		return err
 	}
 
-	if len(cri.AcceptableCAs) == 0 {
+	if len(cri.AcceptableCAs) == 0 || c.Type == CertificateTypeRawPublicKey {
 		return nil
 	}
 
@@ -1632,6 +1817,13 @@ type Certificate struct {
 	// using x509.ParseCertificate to reduce per-handshake processing. If nil,
 	// the leaf certificate will be parsed as needed.
 	Leaf *x509.Certificate
+	// Type is the kind of credential held in Certificate. If it is
+	// CertificateTypeRawPublicKey, Certificate must hold exactly one element,
+	// the DER-encoded SubjectPublicKeyInfo of PrivateKey's public key (as
+	// returned by x509.MarshalPKIXPublicKey), and OCSPStaple,
+	// SignedCertificateTimestamps and Leaf are ignored. Raw public keys are
+	// only sent in TLS 1.3, to peers that accept them. See RFC 7250.
+	Type CertificateType
 }
 
 // leaf returns the parsed leaf certificate, either from c.Leaf or by parsing
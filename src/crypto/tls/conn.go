@@ -9,6 +9,7 @@ package tls
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/cipher"
 	"crypto/subtle"
 	"crypto/x509"
@@ -31,6 +32,7 @@ type Conn struct {
 	isClient    bool
 	handshakeFn func(context.Context) error // (*Conn).clientHandshake or serverHandshake
 	quic        *quicState                  // nil for non-QUIC connections
+	dtls        *dtlsState                  // nil for non-DTLS connections
 
 	// isHandshakeComplete is true if the connection is currently transferring
 	// application data (i.e. is not currently processing a handshake).
@@ -55,6 +57,12 @@ type Conn struct {
 	ocspResponse     []byte   // stapled OCSP response
 	scts             [][]byte // signed certificate timestamps from server
 	peerCertificates []*x509.Certificate
+	// peerRawPublicKey is the peer's public key if it authenticated with a
+	// raw public key (RFC 7250) instead of peerCertificates.
+	peerRawPublicKey crypto.PublicKey
+	// pskIdentity is the identity of the external PSK that authenticated
+	// the connection, if any.
+	pskIdentity []byte
 	// verifiedChains contains the certificate chains that we built, as
 	// opposed to the ones presented by the server.
 	verifiedChains [][]*x509.Certificate
@@ -141,12 +149,21 @@ func (c *Conn) RemoteAddr() net.Addr {
 // A zero value for t means [Conn.Read] and [Conn.Write] will not time out.
 // After a Write has timed out, the TLS state is corrupt and all future writes will return the same error.
 func (c *Conn) SetDeadline(t time.Time) error {
+	if c.dtls != nil {
+		if err := c.dtlsSetReadDeadline(t); err != nil {
+			return err
+		}
+		return c.conn.SetWriteDeadline(t)
+	}
 	return c.conn.SetDeadline(t)
 }
 
 // SetReadDeadline sets the read deadline on the underlying connection.
 // A zero value for t means [Conn.Read] will not time out.
 func (c *Conn) SetReadDeadline(t time.Time) error {
+	if c.dtls != nil {
+		return c.dtlsSetReadDeadline(t)
+	}
 	return c.conn.SetReadDeadline(t)
 }
 
@@ -621,6 +638,9 @@ func (c *Conn) readRecordOrCCS(expectChangeCipherSpec bool) error {
 	if c.quic != nil {
 		return c.in.setErrorLocked(errors.New("tls: internal error: attempted to read record with QUIC transport"))
 	}
+	if c.dtls != nil {
+		return c.dtlsReadRecord(expectChangeCipherSpec)
+	}
 
 	// Read header, payload.
 	if err := c.readFromUntil(c.conn, recordHeaderLen); err != nil {
@@ -717,6 +737,7 @@ func (c *Conn) readRecordOrCCS(expectChangeCipherSpec bool) error {
 		if len(data) != 2 {
 			return c.in.setErrorLocked(c.sendAlert(alertUnexpectedMessage))
 		}
+		c.traceHandshake(HandshakeEvent{Kind: HandshakeAlertReceived, Alert: AlertError(data[1])})
 		if alert(data[1]) == alertCloseNotify {
 			return c.in.setErrorLocked(io.EOF)
 		}
@@ -840,6 +861,7 @@ func (c *Conn) readFromUntil(r io.Reader, n int) error {
 
 // sendAlertLocked sends a TLS alert message.
 func (c *Conn) sendAlertLocked(err alert) error {
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeAlertSent, Alert: AlertError(err)})
 	if c.quic != nil {
 		return c.out.setErrorLocked(&net.OpError{Op: "local error", Err: err})
 	}
@@ -990,6 +1012,9 @@ func (c *Conn) writeRecordLocked(typ recordType, data []byte) (int, error) {
 		}
 		return len(data), nil
 	}
+	if c.dtls != nil {
+		return c.dtlsWriteRecordLocked(typ, data)
+	}
 
 	outBufPtr := outBufPool.Get().(*[]byte)
 	outBuf := *outBufPtr
@@ -1059,10 +1084,16 @@ func (c *Conn) writeHandshakeRecord(msg handshakeMessage, transcript transcriptH
 	if err != nil {
 		return 0, err
 	}
+	if c.dtls != nil {
+		// The DTLS 1.2 transcript includes the message_seq that
+		// dtlsWriteRecordLocked is about to assign to the message.
+		c.dtls.msgSeq[data[0]] = c.dtls.sendSeq
+	}
 	if transcript != nil {
 		transcript.Write(data)
 	}
 
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeMessageSent, MessageType: data[0]})
 	return c.writeRecordLocked(recordTypeHandshake, data)
 }
 
@@ -1126,9 +1157,14 @@ func (c *Conn) unmarshalHandshakeMessage(data []byte, transcript transcriptHash)
 	case typeHelloRequest:
 		m = new(helloRequestMsg)
 	case typeClientHello:
-		m = new(clientHelloMsg)
+		m = &clientHelloMsg{dtls: c.dtls != nil}
 	case typeServerHello:
 		m = new(serverHelloMsg)
+	case typeHelloVerifyRequest:
+		if c.dtls == nil {
+			return nil, c.in.setErrorLocked(c.sendAlert(alertUnexpectedMessage))
+		}
+		m = new(helloVerifyRequestMsg)
 	case typeNewSessionTicket:
 		if c.vers == VersionTLS13 {
 			m = new(newSessionTicketMsgTLS13)
@@ -1181,6 +1217,7 @@ func (c *Conn) unmarshalHandshakeMessage(data []byte, transcript transcriptHash)
 	if !m.unmarshal(data) {
 		return nil, c.in.setErrorLocked(c.sendAlert(alertDecodeError))
 	}
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeMessageReceived, MessageType: data[0]})
 
 	if transcript != nil {
 		transcript.Write(data)
@@ -1231,6 +1268,11 @@ func (c *Conn) Write(b []byte) (int, error) {
 		return 0, errShutdown
 	}
 
+	// Each Write is sent as a single DTLS record, preserving its boundaries.
+	if c.dtls != nil && len(b) > c.dtls.maxPayload() {
+		return 0, errDTLSMessageTooLarge
+	}
+
 	// TLS 1.0 is susceptible to a chosen-plaintext
 	// attack when using block mode ciphers due to predictable IVs.
 	// This can be prevented by splitting each Application Data
@@ -1303,7 +1345,7 @@ func (c *Conn) handleRenegotiation() error {
 // handlePostHandshakeMessage processes a handshake message arrived after the
 // handshake is complete. Up to TLS 1.2, it indicates the start of a renegotiation.
 func (c *Conn) handlePostHandshakeMessage() error {
-	if c.vers != VersionTLS13 {
+	if c.vers != VersionTLS13 && c.dtls == nil {
 		return c.handleRenegotiation()
 	}
 
@@ -1317,6 +1359,10 @@ func (c *Conn) handlePostHandshakeMessage() error {
 		return c.in.setErrorLocked(errors.New("tls: too many non-advancing records"))
 	}
 
+	if c.dtls != nil {
+		return c.dtlsHandlePostHandshakeMessage(msg)
+	}
+
 	switch msg := msg.(type) {
 	case *newSessionTicketMsgTLS13:
 		return c.handleNewSessionTicket(msg)
@@ -1351,6 +1397,7 @@ func (c *Conn) handleKeyUpdate(keyUpdate *keyUpdateMsg) error {
 		if err != nil {
 			return err
 		}
+		c.traceHandshake(HandshakeEvent{Kind: HandshakeMessageSent, MessageType: typeKeyUpdate})
 		_, err = c.writeRecordLocked(recordTypeHandshake, msgBytes)
 		if err != nil {
 			// Surface the error at the next write.
@@ -1617,6 +1664,9 @@ func (c *Conn) connectionStateLocked() ConnectionState {
 	var state ConnectionState
 	state.HandshakeComplete = c.isHandshakeComplete.Load()
 	state.Version = c.vers
+	if c.dtls != nil {
+		state.Version = dtlsVersion(c.vers)
+	}
 	state.NegotiatedProtocol = c.clientProtocol
 	state.DidResume = c.didResume
 	state.HelloRetryRequest = c.didHRR
@@ -1650,9 +1700,20 @@ func (c *Conn) connectionStateLocked() ConnectionState {
 		state.ekm = c.ekm
 	}
 	state.ECHAccepted = c.echAccepted
+	state.PSKIdentity = c.pskIdentity
+	state.PeerRawPublicKey = c.peerRawPublicKey
 	return state
 }
 
+// peerPublicKey returns the public key the peer authenticated with, either
+// as a raw public key or in its leaf certificate.
+func (c *Conn) peerPublicKey() crypto.PublicKey {
+	if c.peerRawPublicKey != nil {
+		return c.peerRawPublicKey
+	}
+	return c.peerCertificates[0].PublicKey
+}
+
 // OCSPResponse returns the stapled OCSP response from the TLS server, if
 // any. (Only valid for client connections.)
 func (c *Conn) OCSPResponse() []byte {
@@ -1692,6 +1753,9 @@ func (c *Conn) setReadTrafficSecret(suite *cipherSuiteTLS13, level QUICEncryptio
 		return errors.New("tls: handshake buffer not empty before setting read traffic secret")
 	}
 	c.in.setTrafficSecret(suite, level, secret)
+	if c.dtls != nil {
+		c.dtlsSetReadEpoch(suite, level, secret)
+	}
 	return nil
 }
 
@@ -1700,4 +1764,7 @@ func (c *Conn) setReadTrafficSecret(suite *cipherSuiteTLS13, level QUICEncryptio
 // to setWriteTrafficSecret happens first so any alerts are sent at the write level.
 func (c *Conn) setWriteTrafficSecret(suite *cipherSuiteTLS13, level QUICEncryptionLevel, secret []byte) {
 	c.out.setTrafficSecret(suite, level, secret)
+	if c.dtls != nil {
+		c.dtlsSetWriteEpoch(suite, level, secret)
+	}
 }
@@ -0,0 +1,451 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// DTLSServer returns a new DTLS 1.2 or DTLS 1.3 server side connection using
+// conn as the underlying datagram transport. See RFC 6347 and RFC 9147.
+//
+// Each Read from conn must return exactly one datagram, and each Write to conn
+// must send exactly one datagram, as is the case for a [net.UDPConn]. To serve
+// multiple clients from a single [net.PacketConn], use [NewDTLSListener].
+//
+// DTLS 1.2 and DTLS 1.3 are negotiated like TLS 1.2 and TLS 1.3, whose
+// handshakes they use, and the config must allow at least one of them. The
+// same configuration is supported, with the exception of session resumption,
+// session tickets, renegotiation, RC4 cipher suites, and Encrypted Client
+// Hello, which are disabled. DTLS 1.0 is not supported, and neither is DTLS in
+// FIPS 140-3 mode. The server always sends a HelloRetryRequest or a
+// HelloVerifyRequest with a cookie, and waits for the client to echo it before
+// doing any expensive work. The connection is still created before that, so
+// servers that accept clients on a shared [net.PacketConn] should use
+// [NewDTLSListener], which answers ClientHello messages without keeping any
+// state until the client proves that it can receive datagrams at its address.
+//
+// The returned Conn reports [VersionDTLS12] or [VersionDTLS13] in
+// [ConnectionState.Version]. Each Write sends a single record, and must not
+// exceed what fits in a 1200-byte datagram; Read returns data from at most one
+// record. Records are not retransmitted after the handshake: like the
+// underlying transport, the connection may lose, duplicate (although replays
+// are detected and discarded), or reorder application data.
+//
+// Lost handshake messages are retransmitted with an exponential backoff
+// starting at one second. Applications should set a deadline to bound the
+// duration of the handshake.
+func DTLSServer(conn net.Conn, config *Config) *Conn {
+	c := &Conn{
+		conn:   conn,
+		config: config,
+		dtls:   newDTLSState(),
+	}
+	c.handshakeFn = c.serverHandshake
+	return c
+}
+
+// DTLSClient returns a new DTLS 1.2 or DTLS 1.3 client side connection using
+// conn as the underlying datagram transport, for example a [net.UDPConn]
+// returned by [net.Dial]. See [DTLSServer] for the details of the DTLS
+// implementation.
+//
+// The last handshake flight, which is the client's one in DTLS 1.3 and the
+// server's one in DTLS 1.2, is retransmitted only in response to
+// retransmissions from the peer, which are processed while the application is
+// reading from the connection. Applications that don't read should delay
+// closing the connection accordingly.
+//
+// The config cannot be nil: users must set either ServerName or
+// InsecureSkipVerify in the config.
+func DTLSClient(conn net.Conn, config *Config) *Conn {
+	c := &Conn{
+		conn:     conn,
+		config:   config,
+		isClient: true,
+		dtls:     newDTLSState(),
+	}
+	c.handshakeFn = c.clientHandshake
+	return c
+}
+
+// dtlsListenerBacklog is the maximum number of connections waiting to be
+// returned by Accept. New clients are ignored when the queue is full.
+const dtlsListenerBacklog = 32
+
+// dtlsPeerQueueLen is the maximum number of datagrams queued for a single
+// peer. Datagrams that don't fit are dropped, like by a full socket buffer.
+const dtlsPeerQueueLen = 32
+
+// dtlsMaxPendingHellos is the maximum number of peers whose ClientHello is
+// fragmented across datagrams that haven't all been received yet, and
+// dtlsMaxPendingHelloLen is the maximum total size of those datagrams for a
+// single peer. When the limit on peers is reached, a random one is dropped.
+const (
+	dtlsMaxPendingHellos   = 64
+	dtlsMaxPendingHelloLen = 16 << 10
+)
+
+// A dtlsListener implements a network listener (net.Listener) for DTLS
+// connections, demultiplexing the datagrams of a net.PacketConn by peer
+// address.
+type dtlsListener struct {
+	pc     net.PacketConn
+	config *Config
+
+	accept    chan *Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu    sync.Mutex
+	peers map[string]*dtlsPeerConn
+	err   error // read error of pc, set before closing closed
+
+	// The following fields are only accessed by readLoop.
+
+	// cookieKey authenticates the stateless cookies sent in response to
+	// ClientHello messages. See dtlsSealCookie.
+	cookieKey []byte
+	// pending holds the datagrams received so far from peers without a
+	// connection, whose ClientHello is not complete yet.
+	pending map[string][][]byte
+	// buf is the read buffer of the cookie probes, see probe.
+	buf []byte
+}
+
+// NewDTLSListener creates a Listener which accepts DTLS connections from
+// clients sending datagrams to pc. Each connection returned by Accept is a
+// *Conn created with [DTLSServer], whose transport carries the datagrams
+// exchanged with a single peer address.
+//
+// A ClientHello from a new peer address is answered with a HelloRetryRequest
+// or a HelloVerifyRequest carrying a stateless cookie, an authenticator of the
+// peer address and of the ClientHello. A new connection is created only when
+// the peer sends back a ClientHello with a valid cookie, proving that it can
+// receive datagrams at its address. Applications should still set a deadline
+// before completing the handshake of accepted connections, and close them if
+// it fails.
+//
+// Closing the listener closes pc, after which the accepted connections can't
+// be used anymore.
+//
+// The configuration config must be non-nil and must include
+// at least one certificate or else set GetCertificate.
+func NewDTLSListener(pc net.PacketConn, config *Config) net.Listener {
+	l := &dtlsListener{
+		pc:        pc,
+		config:    config,
+		accept:    make(chan *Conn, dtlsListenerBacklog),
+		closed:    make(chan struct{}),
+		peers:     make(map[string]*dtlsPeerConn),
+		cookieKey: make([]byte, 32),
+		pending:   make(map[string][][]byte),
+	}
+	if _, err := io.ReadFull(config.rand(), l.cookieKey); err != nil {
+		panic("tls: failed to generate DTLS cookie key: " + err.Error())
+	}
+	go l.readLoop()
+	return l
+}
+
+func (l *dtlsListener) readLoop() {
+	buf := make([]byte, 1<<16)
+	for {
+		n, addr, err := l.pc.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			l.mu.Lock()
+			l.err = err
+			l.mu.Unlock()
+			l.Close()
+			return
+		}
+
+		key := addr.String()
+		l.mu.Lock()
+		p, ok := l.peers[key]
+		l.mu.Unlock()
+		if ok {
+			p.deliver(buf[:n])
+			continue
+		}
+
+		// Only a DTLSPlaintext record can start a connection, see RFC 9147,
+		// Section 4. The rest is up to the handshake.
+		if n < dtlsPlaintextHeaderLen || recordType(buf[0]) != recordTypeHandshake {
+			continue
+		}
+		datagrams := append(l.pending[key], append([]byte(nil), buf[:n]...))
+		delete(l.pending, key)
+		switch err := l.probe(addr, datagrams); err {
+		case io.EOF:
+			// The ClientHello is not complete yet.
+			size := 0
+			for _, d := range datagrams {
+				size += len(d)
+			}
+			if size > dtlsMaxPendingHelloLen {
+				continue
+			}
+			if len(l.pending) >= dtlsMaxPendingHellos {
+				for k := range l.pending {
+					delete(l.pending, k)
+					break
+				}
+			}
+			l.pending[key] = datagrams
+		case errDTLSCookieValid:
+			l.mu.Lock()
+			if len(l.accept) == cap(l.accept) {
+				l.mu.Unlock()
+				continue
+			}
+			p = newDTLSPeerConn(l, addr)
+			l.peers[key] = p
+			c := DTLSServer(p, l.config)
+			c.dtls.cookieKey = l.cookieKey
+			l.accept <- c
+			l.mu.Unlock()
+			for _, d := range datagrams {
+				p.deliver(d)
+			}
+		}
+	}
+}
+
+// probe runs a stateless server handshake on the datagrams received from a
+// peer without a connection. It returns io.EOF if they don't carry a complete
+// ClientHello, errDTLSCookieValid if the ClientHello carries a valid cookie,
+// and otherwise errDTLSCookieSent after answering it with a new cookie.
+func (l *dtlsListener) probe(addr net.Addr, datagrams [][]byte) error {
+	if l.buf == nil {
+		l.buf = make([]byte, 1<<16)
+	}
+	c := DTLSServer(&dtlsProbeConn{l: l, addr: addr, in: datagrams}, l.config)
+	c.dtls.cookieKey = l.cookieKey
+	c.dtls.cookieProbe = true
+	c.dtls.buf = l.buf
+	return c.Handshake()
+}
+
+// dtlsSealCookie returns a cookie carrying state, authenticated with key
+// along with the address of the peer and the random of its ClientHello.
+func dtlsSealCookie(key []byte, addr net.Addr, random, state []byte) []byte {
+	return append(state, dtlsCookieMAC(key, addr, random, state)...)
+}
+
+// dtlsOpenCookie returns the state carried by a cookie sealed by
+// dtlsSealCookie with the same key, address, and random, or false.
+func dtlsOpenCookie(key []byte, addr net.Addr, random, cookie []byte) ([]byte, bool) {
+	if len(cookie) < sha256.Size {
+		return nil, false
+	}
+	state, tag := cookie[:len(cookie)-sha256.Size], cookie[len(cookie)-sha256.Size:]
+	if !hmac.Equal(tag, dtlsCookieMAC(key, addr, random, state)) {
+		return nil, false
+	}
+	return state, true
+}
+
+func dtlsCookieMAC(key []byte, addr net.Addr, random, state []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	a := addr.String()
+	h.Write([]byte{byte(len(a))})
+	h.Write([]byte(a))
+	h.Write([]byte{byte(len(random))})
+	h.Write(random)
+	h.Write(state)
+	return h.Sum(nil)
+}
+
+// Accept waits for and returns the next incoming DTLS connection.
+// The returned connection is of type *Conn.
+func (l *dtlsListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.closed:
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return nil, l.err
+	}
+	return nil, net.ErrClosed
+}
+
+// Close closes the listener and the underlying PacketConn.
+func (l *dtlsListener) Close() error {
+	err := net.ErrClosed
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		err = l.pc.Close()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for _, p := range l.peers {
+			p.closeOnce.Do(func() { close(p.closed) })
+		}
+		clear(l.peers)
+	})
+	return err
+}
+
+func (l *dtlsListener) Addr() net.Addr {
+	return l.pc.LocalAddr()
+}
+
+// A dtlsProbeConn is the net.Conn of a cookie probe, which reads the
+// datagrams received so far from a peer and sends its answers to it.
+type dtlsProbeConn struct {
+	l    *dtlsListener
+	addr net.Addr
+	in   [][]byte
+}
+
+func (p *dtlsProbeConn) Read(b []byte) (int, error) {
+	if len(p.in) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, p.in[0])
+	p.in = p.in[1:]
+	return n, nil
+}
+
+func (p *dtlsProbeConn) Write(b []byte) (int, error) {
+	return p.l.pc.WriteTo(b, p.addr)
+}
+
+func (p *dtlsProbeConn) Close() error                       { return nil }
+func (p *dtlsProbeConn) LocalAddr() net.Addr                { return p.l.pc.LocalAddr() }
+func (p *dtlsProbeConn) RemoteAddr() net.Addr               { return p.addr }
+func (p *dtlsProbeConn) SetDeadline(t time.Time) error      { return nil }
+func (p *dtlsProbeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (p *dtlsProbeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// A dtlsPeerConn is the net.Conn carrying the datagrams exchanged by a
+// dtlsListener with a single peer.
+type dtlsPeerConn struct {
+	l    *dtlsListener
+	addr net.Addr
+
+	in        chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu              sync.Mutex
+	readDeadline    time.Time
+	deadlineChanged chan struct{}
+}
+
+func newDTLSPeerConn(l *dtlsListener, addr net.Addr) *dtlsPeerConn {
+	return &dtlsPeerConn{
+		l:               l,
+		addr:            addr,
+		in:              make(chan []byte, dtlsPeerQueueLen),
+		closed:          make(chan struct{}),
+		deadlineChanged: make(chan struct{}),
+	}
+}
+
+// deliver queues a copy of datagram for reading, or drops it if the queue is
+// full.
+func (p *dtlsPeerConn) deliver(datagram []byte) {
+	select {
+	case p.in <- append([]byte(nil), datagram...):
+	default:
+	}
+}
+
+func (p *dtlsPeerConn) Read(b []byte) (int, error) {
+	for {
+		p.mu.Lock()
+		deadline, changed := p.readDeadline, p.deadlineChanged
+		p.mu.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, os.ErrDeadlineExceeded
+			}
+			timer = time.NewTimer(d)
+			timeout = timer.C
+		}
+
+		n, err, done := 0, error(nil), true
+		select {
+		case datagram := <-p.in:
+			n = copy(b, datagram)
+		case <-p.closed:
+			err = net.ErrClosed
+		case <-timeout:
+			err = os.ErrDeadlineExceeded
+		case <-changed:
+			done = false
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if done {
+			return n, err
+		}
+	}
+}
+
+func (p *dtlsPeerConn) Write(b []byte) (int, error) {
+	select {
+	case <-p.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	return p.l.pc.WriteTo(b, p.addr)
+}
+
+// Close releases the peer address, such that a new datagram from it will be
+// returned by Accept as a new connection.
+func (p *dtlsPeerConn) Close() error {
+	err := net.ErrClosed
+	p.closeOnce.Do(func() {
+		close(p.closed)
+		p.l.mu.Lock()
+		if p.l.peers[p.addr.String()] == p {
+			delete(p.l.peers, p.addr.String())
+		}
+		p.l.mu.Unlock()
+		err = nil
+	})
+	return err
+}
+
+func (p *dtlsPeerConn) LocalAddr() net.Addr  { return p.l.pc.LocalAddr() }
+func (p *dtlsPeerConn) RemoteAddr() net.Addr { return p.addr }
+
+func (p *dtlsPeerConn) SetDeadline(t time.Time) error {
+	return p.SetReadDeadline(t)
+}
+
+func (p *dtlsPeerConn) SetReadDeadline(t time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.readDeadline = t
+	close(p.deadlineChanged)
+	p.deadlineChanged = make(chan struct{})
+	return nil
+}
+
+// SetWriteDeadline is a no-op, since the underlying PacketConn is shared with
+// other peers. Datagram writes don't block for long in practice.
+func (p *dtlsPeerConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
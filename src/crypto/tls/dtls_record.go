@@ -0,0 +1,1030 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"internal/byteorder"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// This file implements the DTLS 1.2 and DTLS 1.3 record layers, see RFC 6347
+// and RFC 9147.
+//
+// DTLS 1.3 computes the handshake transcript over messages with TLS-style
+// headers (RFC 9147, Section 5.2), so the TLS 1.3 handshake state machines are
+// used unchanged: incoming handshake fragments are reassembled in order into
+// c.hand with TLS headers, and outgoing handshake messages are given DTLS
+// headers, fragmented, and retained for retransmission.
+//
+// DTLS 1.2 uses the TLS 1.2 state machines in the same way, but its
+// transcript includes the DTLS headers (RFC 6347, Section 4.2.6), which
+// finishedHash reconstructs from the message_seq recorded in dtlsState.
+// Records are protected like in TLS 1.2, with the epoch and sequence number
+// taking the place of the implicit TLS sequence number.
+
+const (
+	// versionDTLS10 is the server_version of HelloVerifyRequest messages,
+	// regardless of the negotiated version. See RFC 6347, Section 4.2.1.
+	versionDTLS10 = 0xfeff
+
+	// dtlsMaxDatagramSize is the size of the largest datagram we send. It
+	// leaves room for the IP and UDP headers within the IPv6 minimum MTU.
+	dtlsMaxDatagramSize = 1200
+
+	dtlsHandshakeHeaderLen  = 12 // msg_type, length, message_seq, fragment_offset, fragment_length
+	dtlsPlaintextHeaderLen  = 13 // type, legacy_record_version, epoch, sequence_number, length
+	dtlsCiphertextHeaderLen = 5  // unified header with a 16-bit sequence number and a length
+
+	// dtlsMaxPayload is the largest application data payload of a DTLS 1.3
+	// record that fits in a dtlsMaxDatagramSize datagram: all TLS 1.3 AEADs
+	// have a 16 byte tag, and the inner plaintext carries the content type.
+	// DTLS 1.2 records have a larger overhead, see dtlsState.maxPayload.
+	dtlsMaxPayload = dtlsMaxDatagramSize - dtlsCiphertextHeaderLen - 1 - 16
+
+	dtlsMaxTimeout         = 60 * time.Second
+	dtlsMaxRetransmissions = 6
+	// dtlsMinRetransmitInterval rate-limits the retransmissions caused by
+	// the peer retransmitting its own previous flight.
+	dtlsMinRetransmitInterval = 100 * time.Millisecond
+	// dtlsMaxACKRecords is the maximum number of record numbers carried
+	// by an ACK message.
+	dtlsMaxACKRecords = 32
+)
+
+// dtlsInitialTimeout is the initial value of the retransmission timer. See
+// RFC 9147, Section 5.8.2. It's a variable so that tests can shorten it.
+var dtlsInitialTimeout = 1 * time.Second
+
+var (
+	errDTLSTimeout         = errors.New("tls: DTLS handshake timed out waiting for the peer")
+	errDTLSMessageTooLarge = errors.New("tls: message does not fit in a DTLS record")
+	errDTLSVersion         = errors.New("tls: DTLS requires MinVersion and MaxVersion to allow TLS 1.2 or TLS 1.3")
+	// errDTLSFIPS is returned in FIPS 140-3 mode, since the DTLS 1.3 key
+	// schedule is not implemented by the FIPS 140-3 module.
+	errDTLSFIPS = errors.New("tls: DTLS is not supported in FIPS 140-3 mode")
+	// errDTLSCookieSent is returned by the handshake of a stateless server
+	// after it answers a ClientHello without a valid cookie.
+	errDTLSCookieSent = errors.New("tls: DTLS server sent a cookie to verify the client address")
+	// errDTLSCookieValid is returned by the handshake of a cookie probe when
+	// the ClientHello carries a valid cookie. See dtlsListener.
+	errDTLSCookieValid = errors.New("tls: DTLS client echoed a valid cookie")
+)
+
+// dtlsState is the state of the DTLS record layer of a Conn.
+type dtlsState struct {
+	// Read state, protected by c.in.
+	buf      []byte       // datagram read buffer
+	datagram []byte       // unprocessed records of the last datagram read
+	in       []*dtlsEpoch // read epochs, in increasing order
+	recvSeq  uint16       // message_seq of the next expected handshake message
+	reasm    []byte       // TLS-framed message being reassembled, if any
+	reasmLen int          // bytes of the reasm body received in order
+	received []dtlsRecordNumber
+
+	// Write state, protected by c.out.
+	outBuf  []byte
+	out     []*dtlsEpoch // write epochs, in increasing order
+	sendSeq uint16       // message_seq of the next handshake message
+	// flight is the last flight of handshake messages we sent, with DTLS
+	// headers, kept for retransmission until it's acknowledged.
+	flight []dtlsFlightMessage
+	// peerResponded is set when a new handshake message is received from
+	// the peer, such that the next message we send starts a new flight.
+	peerResponded   bool
+	timeout         time.Duration
+	retransmitAt    time.Time
+	retransmissions int
+	lastSent        time.Time
+
+	deadlineMu   sync.Mutex
+	readDeadline time.Time // deadline set by the application
+	connDeadline time.Time // deadline last set on the transport
+
+	// msgSeq is the message_seq of the last handshake message of each type
+	// sent or received, which is part of the DTLS 1.2 transcript. The types of
+	// the messages sent by the client and by the server are distinct, except
+	// for Certificate and Finished, whose transcript is updated before the
+	// peer sends its own. It's only accessed by the handshake.
+	msgSeq map[uint8]uint16
+
+	// cookieKey, if not nil, makes the server stateless until the client
+	// proves that it can receive datagrams at its address: it answers a
+	// ClientHello without a valid cookie and stops, rather than waiting for
+	// the ClientHello with the cookie. See dtlsListener.
+	cookieKey []byte
+	// cookieProbe makes the server also stop at a ClientHello with a valid
+	// cookie, so that the listener can check it before creating a Conn.
+	cookieProbe bool
+}
+
+// A dtlsFlightMessage is a handshake message with a DTLS header, or a DTLS 1.2
+// ChangeCipherSpec if ccs is set, and the epoch it is sent in.
+type dtlsFlightMessage struct {
+	epoch uint16
+	msg   []byte
+	ccs   bool
+}
+
+// dtlsRecordNumber identifies a record for the purpose of acknowledgments.
+// See RFC 9147, Section 7.
+type dtlsRecordNumber struct {
+	epoch uint64
+	seq   uint64
+}
+
+func newDTLSState() *dtlsState {
+	return &dtlsState{
+		in:      []*dtlsEpoch{{}},
+		out:     []*dtlsEpoch{{}},
+		timeout: dtlsInitialTimeout,
+		msgSeq:  make(map[uint8]uint16),
+	}
+}
+
+// dtlsVersion returns the DTLS version that uses the handshake of the TLS
+// version vers, or zero if there is none.
+func dtlsVersion(vers uint16) uint16 {
+	switch vers {
+	case VersionTLS12:
+		return VersionDTLS12
+	case VersionTLS13:
+		return VersionDTLS13
+	}
+	return 0
+}
+
+// tlsVersionForDTLS returns the TLS version whose handshake is used by the DTLS
+// version vers, or zero if it is not supported. DTLS 1.0 is not.
+func tlsVersionForDTLS(vers uint16) uint16 {
+	switch vers {
+	case VersionDTLS12:
+		return VersionTLS12
+	case VersionDTLS13:
+		return VersionTLS13
+	}
+	return 0
+}
+
+// dtlsAllowedCipherSuite reports whether the TLS 1.0–1.2 cipher suite id can
+// be used with DTLS 1.2, which forbids stream ciphers. See RFC 6347, Section
+// 4.1.2.2.
+func dtlsAllowedCipherSuite(id uint16) bool {
+	switch id {
+	case TLS_RSA_WITH_RC4_128_SHA, TLS_ECDHE_RSA_WITH_RC4_128_SHA, TLS_ECDHE_ECDSA_WITH_RC4_128_SHA:
+		return false
+	}
+	return true
+}
+
+// dtlsEpoch holds the keys and the sequence number state of one direction of
+// a DTLS epoch. See RFC 9147, Section 6.1.
+type dtlsEpoch struct {
+	epoch uint16
+	aead  aead // nil for the unprotected epoch 0 and for DTLS 1.2
+	// sn is the record number encryption key, a cipher.Block for AES-based
+	// cipher suites or a ChaCha20 key. See RFC 9147, Section 4.2.3.
+	sn any
+
+	// tls12 holds the keys of a DTLS 1.2 epoch other than 0. They are used as
+	// in TLS 1.2, with the epoch and the record sequence number in place of
+	// the TLS sequence number. See RFC 6347, Section 4.1.2.1.
+	tls12 *halfConn
+	rand  io.Reader // for the explicit IVs of CBC cipher suites
+
+	seq uint64 // next sequence number to send
+
+	// top and window implement the anti-replay window of RFC 9147, Section
+	// 4.5.1. top is one more than the highest sequence number received, and
+	// bit i of window is set if top-1-i was received.
+	top    uint64
+	window uint64
+}
+
+// dtlsEpochForLevel returns the DTLS epoch of the keys of a TLS 1.3
+// encryption level. See RFC 9147, Section 6.1.
+func dtlsEpochForLevel(level QUICEncryptionLevel) uint16 {
+	switch level {
+	case QUICEncryptionLevelEarly:
+		return 1
+	case QUICEncryptionLevelHandshake:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// newDTLSEpoch derives the keys of a DTLS 1.3 epoch from its traffic secret.
+// See RFC 9147, Sections 4.2.3 and 5.9.
+func newDTLSEpoch(suite *cipherSuiteTLS13, epoch uint16, secret []byte) *dtlsEpoch {
+	key := suite.expandLabel(true, secret, "key", nil, suite.keyLen)
+	iv := suite.expandLabel(true, secret, "iv", nil, aeadNonceLength)
+	snKey := suite.expandLabel(true, secret, "sn", nil, suite.keyLen)
+	e := &dtlsEpoch{epoch: epoch, aead: suite.aead(key, iv)}
+	if suite.id == TLS_CHACHA20_POLY1305_SHA256 {
+		e.sn = snKey
+	} else {
+		block, err := aes.NewCipher(snKey)
+		if err != nil {
+			panic(err)
+		}
+		e.sn = block
+	}
+	return e
+}
+
+// newDTLS12Epoch returns the next DTLS 1.2 epoch after e, protected with the
+// cipher and MAC prepared in hc. See RFC 6347, Section 4.1.
+func newDTLS12Epoch(e *dtlsEpoch, hc *halfConn, rand io.Reader) *dtlsEpoch {
+	return &dtlsEpoch{
+		epoch: e.epoch + 1,
+		tls12: &halfConn{version: VersionTLS12, cipher: hc.nextCipher, mac: hc.nextMac},
+		rand:  rand,
+	}
+}
+
+// protected reports whether the records of e are authenticated.
+func (e *dtlsEpoch) protected() bool {
+	return e.aead != nil || e.tls12 != nil
+}
+
+// overhead returns the maximum number of bytes a record adds to its payload.
+func (e *dtlsEpoch) overhead() int {
+	switch {
+	case e.tls12 != nil:
+		n := dtlsPlaintextHeaderLen + e.tls12.explicitNonceLen()
+		switch c := e.tls12.cipher.(type) {
+		case aead:
+			n += c.Overhead()
+		case cbcMode:
+			n += e.tls12.mac.Size() + c.BlockSize()
+		}
+		return n
+	case e.aead == nil:
+		return dtlsPlaintextHeaderLen
+	}
+	return dtlsCiphertextHeaderLen + 1 + e.aead.Overhead()
+}
+
+// recordNumberMask computes the mask of the encrypted sequence number of a
+// record from the first 16 bytes of its ciphertext. See RFC 9147, Section
+// 4.2.3.
+func (e *dtlsEpoch) recordNumberMask(sample []byte) (mask [16]byte) {
+	switch k := e.sn.(type) {
+	case cipher.Block:
+		k.Encrypt(mask[:], sample[:16])
+	case []byte:
+		c, err := chacha20.NewUnauthenticatedCipher(k, sample[4:16])
+		if err != nil {
+			panic(err)
+		}
+		c.SetCounter(byteorder.LEUint32(sample[:4]))
+		c.XORKeyStream(mask[:], mask[:])
+	}
+	return mask
+}
+
+// seal appends to b a record of type typ carrying payload, protected with the
+// keys of e.
+func (e *dtlsEpoch) seal(b []byte, typ recordType, payload []byte) ([]byte, error) {
+	if e.seq >= 1<<48 {
+		return nil, errors.New("tls: DTLS sequence number space exhausted")
+	}
+	seq := e.seq
+	e.seq++
+
+	if e.aead == nil {
+		b = append(b, byte(typ), VersionDTLS12>>8, VersionDTLS12&0xff)
+		b = byteorder.BEAppendUint16(b, e.epoch)
+		b = byteorder.BEAppendUint16(b, uint16(seq>>32))
+		b = byteorder.BEAppendUint32(b, uint32(seq))
+		if e.tls12 == nil {
+			b = byteorder.BEAppendUint16(b, uint16(len(payload)))
+			return append(b, payload...), nil
+		}
+
+		// halfConn.encrypt expects a TLS record header, which also provides
+		// the type, version, and length of the MAC or additional data, while
+		// the epoch and sequence number replace the TLS sequence number. See
+		// RFC 6347, Section 4.1.2.1.
+		byteorder.BEPutUint64(e.tls12.seq[:], uint64(e.epoch)<<48|seq)
+		var hdr [recordHeaderLen]byte
+		hdr[0], hdr[1], hdr[2] = byte(typ), VersionDTLS12>>8, VersionDTLS12&0xff
+		byteorder.BEPutUint16(hdr[3:], uint16(len(payload)))
+		record, err := e.tls12.encrypt(hdr[:], payload, e.rand)
+		if err != nil {
+			return nil, err
+		}
+		return append(b, record[3:]...), nil
+	}
+
+	// Use the unified header with the C bit clear, the S and L bits set,
+	// and the low bits of the epoch. See RFC 9147, Section 4.
+	n := len(payload) + 1 + e.aead.Overhead()
+	var hdr [dtlsCiphertextHeaderLen]byte
+	hdr[0] = 0b00101100 | byte(e.epoch&0b11)
+	byteorder.BEPutUint16(hdr[1:], uint16(seq))
+	byteorder.BEPutUint16(hdr[3:], uint16(n))
+	var nonce [8]byte
+	byteorder.BEPutUint64(nonce[:], uint64(e.epoch)<<48|seq)
+
+	start := len(b)
+	b = append(b, hdr[:]...)
+	inner := make([]byte, 0, len(payload)+1)
+	inner = append(append(inner, payload...), byte(typ))
+	b = e.aead.Seal(b, nonce[:], inner, hdr[:])
+
+	mask := e.recordNumberMask(b[start+dtlsCiphertextHeaderLen:])
+	b[start+1] ^= mask[0]
+	b[start+2] ^= mask[1]
+	return b, nil
+}
+
+// reconstructSeq returns the full sequence number closest to the next
+// expected one that has the given low bits. See RFC 9147, Section 4.2.2.
+func (e *dtlsEpoch) reconstructSeq(low uint64, bits uint) uint64 {
+	size := uint64(1) << bits
+	seq := e.top&^(size-1) | low
+	switch {
+	case seq > e.top+size/2 && seq >= size:
+		seq -= size
+	case seq+size/2 < e.top && seq+size < 1<<48:
+		seq += size
+	}
+	return seq
+}
+
+// markReceived records seq in the anti-replay window, and reports whether it
+// was not received before.
+func (e *dtlsEpoch) markReceived(seq uint64) bool {
+	switch {
+	case seq >= e.top:
+		if shift := seq + 1 - e.top; shift >= 64 {
+			e.window = 0
+		} else {
+			e.window <<= shift
+		}
+		e.window |= 1
+		e.top = seq + 1
+		return true
+	case e.top-1-seq >= 64:
+		return false
+	default:
+		bit := uint64(1) << (e.top - 1 - seq)
+		if e.window&bit != 0 {
+			return false
+		}
+		e.window |= bit
+		return true
+	}
+}
+
+// nextRecord removes the first record from d.datagram and returns its
+// epoch, sequence number, content type and plaintext. Records that can't be
+// parsed, deprotected, or that are replayed are silently discarded, as
+// required by RFC 9147, Section 4.5.2, in which case ok is false.
+func (d *dtlsState) nextRecord() (e *dtlsEpoch, seq uint64, typ recordType, data []byte, ok bool) {
+	b := d.datagram
+	if b[0]&0b11100000 == 0b00100000 {
+		return d.nextCiphertextRecord()
+	}
+
+	if len(b) < dtlsPlaintextHeaderLen {
+		d.datagram = nil
+		return nil, 0, 0, nil, false
+	}
+	n := int(byteorder.BEUint16(b[11:]))
+	if len(b) < dtlsPlaintextHeaderLen+n {
+		d.datagram = nil
+		return nil, 0, 0, nil, false
+	}
+	d.datagram = b[dtlsPlaintextHeaderLen+n:]
+	typ = recordType(b[0])
+	epoch := byteorder.BEUint16(b[3:])
+	for _, in := range d.in {
+		// This header is only used by epoch 0 and by DTLS 1.2 epochs.
+		if in.epoch == epoch && in.aead == nil {
+			e = in
+		}
+	}
+	if e == nil || e.tls12 == nil && typ != recordTypeHandshake && typ != recordTypeAlert && typ != recordTypeChangeCipherSpec {
+		return nil, 0, 0, nil, false
+	}
+	seq = uint64(byteorder.BEUint16(b[5:]))<<32 | uint64(byteorder.BEUint32(b[7:]))
+	data = b[dtlsPlaintextHeaderLen : dtlsPlaintextHeaderLen+n]
+	if e.tls12 != nil {
+		// Turn the end of the header into a TLS record header, see seal.
+		record := b[dtlsPlaintextHeaderLen-recordHeaderLen : dtlsPlaintextHeaderLen+n]
+		record[0], record[1], record[2] = b[0], b[1], b[2]
+		byteorder.BEPutUint64(e.tls12.seq[:], uint64(e.epoch)<<48|seq)
+		var err error
+		if data, typ, err = e.tls12.decrypt(record); err != nil {
+			return nil, 0, 0, nil, false
+		}
+	}
+	if !e.markReceived(seq) {
+		return nil, 0, 0, nil, false
+	}
+	return e, seq, typ, data, true
+}
+
+func (d *dtlsState) nextCiphertextRecord() (e *dtlsEpoch, seq uint64, typ recordType, data []byte, ok bool) {
+	b := d.datagram
+	flags := b[0]
+	// We never negotiate connection IDs.
+	if flags&0b00010000 != 0 {
+		d.datagram = nil
+		return nil, 0, 0, nil, false
+	}
+	seqLen := 1
+	if flags&0b00001000 != 0 {
+		seqLen = 2
+	}
+	hdrLen := 1 + seqLen
+	if flags&0b00000100 != 0 {
+		hdrLen += 2
+	}
+	if len(b) < hdrLen {
+		d.datagram = nil
+		return nil, 0, 0, nil, false
+	}
+	n := len(b) - hdrLen
+	if flags&0b00000100 != 0 {
+		n = int(byteorder.BEUint16(b[1+seqLen:]))
+	}
+	if len(b) < hdrLen+n {
+		d.datagram = nil
+		return nil, 0, 0, nil, false
+	}
+	d.datagram = b[hdrLen+n:]
+	ciphertext := b[hdrLen : hdrLen+n]
+	if len(ciphertext) < 16 {
+		return nil, 0, 0, nil, false
+	}
+
+	for _, epoch := range d.in[1:] {
+		if epoch.epoch&0b11 == uint16(flags&0b11) {
+			e = epoch
+		}
+	}
+	if e == nil {
+		return nil, 0, 0, nil, false
+	}
+
+	var hdr [dtlsCiphertextHeaderLen]byte
+	copy(hdr[:], b[:hdrLen])
+	mask := e.recordNumberMask(ciphertext)
+	var low uint64
+	for i := range seqLen {
+		hdr[1+i] ^= mask[i]
+		low = low<<8 | uint64(hdr[1+i])
+	}
+	seq = e.reconstructSeq(low, uint(8*seqLen))
+
+	var nonce [8]byte
+	byteorder.BEPutUint64(nonce[:], uint64(e.epoch)<<48|seq)
+	plaintext, err := e.aead.Open(ciphertext[:0], nonce[:], ciphertext, hdr[:hdrLen])
+	if err != nil || !e.markReceived(seq) {
+		return nil, 0, 0, nil, false
+	}
+
+	// Strip the padding and extract the content type. See RFC 8446,
+	// Section 5.4.
+	i := len(plaintext) - 1
+	for i >= 0 && plaintext[i] == 0 {
+		i--
+	}
+	if i < 0 {
+		return nil, 0, 0, nil, false
+	}
+	return e, seq, recordType(plaintext[i]), plaintext[:i], true
+}
+
+// dtlsReadRecord is the DTLS version of readRecordOrCCS. It reads records
+// until c.hand grows, c.input is set, an expected DTLS 1.2 ChangeCipherSpec is
+// processed, or an error occurs.
+func (c *Conn) dtlsReadRecord(expectChangeCipherSpec bool) error {
+	d := c.dtls
+	handshakeComplete := c.isHandshakeComplete.Load()
+	for {
+		if len(d.datagram) == 0 {
+			if err := c.dtlsReadDatagram(); err != nil {
+				return err
+			}
+		}
+		e, seq, typ, data, ok := d.nextRecord()
+		if !ok {
+			continue
+		}
+
+		switch typ {
+		case recordTypeAlert:
+			// Once the handshake is complete, ignore unauthenticated alerts,
+			// which would otherwise let anyone tear down the connection.
+			if !e.protected() && handshakeComplete {
+				continue
+			}
+			if len(data) != 2 {
+				return c.in.setErrorLocked(c.sendAlert(alertUnexpectedMessage))
+			}
+			c.traceHandshake(HandshakeEvent{Kind: HandshakeAlertReceived, Alert: AlertError(data[1])})
+			switch alert(data[1]) {
+			case alertCloseNotify:
+				return c.in.setErrorLocked(io.EOF)
+			case alertUserCanceled:
+				continue
+			}
+			return c.in.setErrorLocked(&net.OpError{Op: "remote error", Err: alert(data[1])})
+
+		case recordTypeHandshake:
+			grew, err := c.dtlsHandleHandshake(e, seq, data)
+			if err == nil && grew && expectChangeCipherSpec {
+				err = c.in.setErrorLocked(c.sendAlert(alertUnexpectedMessage))
+			}
+			if err != nil || grew {
+				return err
+			}
+
+		case recordTypeChangeCipherSpec:
+			// In DTLS 1.2, a ChangeCipherSpec switches to the next read epoch.
+			// Unexpected ones, including retransmissions, are dropped like
+			// reordered handshake records would be.
+			if !expectChangeCipherSpec || e != d.in[len(d.in)-1] {
+				continue
+			}
+			if len(data) != 1 || data[0] != 1 {
+				return c.in.setErrorLocked(c.sendAlert(alertDecodeError))
+			}
+			if c.hand.Len() > 0 {
+				return c.in.setErrorLocked(c.sendAlert(alertUnexpectedMessage))
+			}
+			next := newDTLS12Epoch(e, &c.in, c.config.rand())
+			if err := c.in.changeCipherSpec(); err != nil {
+				return c.in.setErrorLocked(c.sendAlert(err.(alert)))
+			}
+			d.in = append(d.in, next)
+			return nil
+
+		case recordTypeApplicationData:
+			// Application data can be reordered ahead of the end of the
+			// handshake, in which case it's dropped like a lost packet.
+			if !handshakeComplete || !e.protected() || c.vers == VersionTLS13 && e.epoch < 3 || len(data) == 0 {
+				continue
+			}
+			// Note that data is owned by d.buf, which is not read into
+			// until c.input is drained.
+			c.retryCount = 0
+			c.input.Reset(data)
+			return nil
+
+		case recordTypeACK:
+			if handshakeComplete && c.vers == VersionTLS13 {
+				// Our last flight was acknowledged, and we don't expect any
+				// more handshake messages from the peer.
+				c.out.Lock()
+				d.flight = nil
+				c.out.Unlock()
+			}
+		}
+	}
+}
+
+// dtlsReadDatagram reads the next datagram into c.dtls.datagram, retransmitting
+// the last flight of the handshake when the retransmission timer expires.
+func (c *Conn) dtlsReadDatagram() error {
+	d := c.dtls
+	if d.buf == nil {
+		d.buf = make([]byte, 1<<16)
+	}
+	for {
+		c.out.Lock()
+		timerArmed := len(d.flight) > 0 && !c.isHandshakeComplete.Load()
+		retransmitAt := d.retransmitAt
+		c.out.Unlock()
+
+		d.deadlineMu.Lock()
+		deadline, useTimer := d.readDeadline, false
+		if timerArmed && (deadline.IsZero() || retransmitAt.Before(deadline)) {
+			deadline, useTimer = retransmitAt, true
+		}
+		if !deadline.Equal(d.connDeadline) {
+			c.conn.SetReadDeadline(deadline)
+			d.connDeadline = deadline
+		}
+		d.deadlineMu.Unlock()
+
+		n, err := c.conn.Read(d.buf)
+		if err != nil {
+			if e, ok := err.(net.Error); ok && e.Timeout() && useTimer && !time.Now().Before(retransmitAt) {
+				c.out.Lock()
+				err := c.dtlsRetransmitLocked(true)
+				c.out.Unlock()
+				if err != nil {
+					return c.in.setErrorLocked(err)
+				}
+				continue
+			}
+			if e, ok := err.(net.Error); !ok || !e.Temporary() {
+				c.in.setErrorLocked(err)
+			}
+			return err
+		}
+		if n > 0 {
+			d.datagram = d.buf[:n]
+			return nil
+		}
+	}
+}
+
+// dtlsSetReadDeadline implements SetReadDeadline for DTLS connections, whose
+// transport deadline is also used for the retransmission timer.
+func (c *Conn) dtlsSetReadDeadline(t time.Time) error {
+	d := c.dtls
+	d.deadlineMu.Lock()
+	defer d.deadlineMu.Unlock()
+	d.readDeadline = t
+	d.connDeadline = t
+	return c.conn.SetReadDeadline(t)
+}
+
+// dtlsHandleHandshake processes the handshake fragments in the plaintext of
+// record seq of epoch e, and reports whether c.hand grew.
+func (c *Conn) dtlsHandleHandshake(e *dtlsEpoch, seq uint64, data []byte) (bool, error) {
+	d := c.dtls
+	grew, accepted := false, false
+	for len(data) > 0 {
+		if len(data) < dtlsHandshakeHeaderLen {
+			return grew, c.dtlsMalformedRecord(e)
+		}
+		typ := data[0]
+		length := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+		msgSeq := byteorder.BEUint16(data[4:])
+		off := int(data[6])<<16 | int(data[7])<<8 | int(data[8])
+		n := int(data[9])<<16 | int(data[10])<<8 | int(data[11])
+		if len(data) < dtlsHandshakeHeaderLen+n || off+n > length {
+			return grew, c.dtlsMalformedRecord(e)
+		}
+		fragment := data[dtlsHandshakeHeaderLen : dtlsHandshakeHeaderLen+n]
+		data = data[dtlsHandshakeHeaderLen+n:]
+
+		if d.cookieKey != nil && e.epoch == 0 && typ == typeClientHello && d.recvSeq == 0 && d.reasm == nil {
+			// Previous ClientHellos may have been answered statelessly, so
+			// continue from the message and record sequence numbers of this
+			// one, like a server that answered them itself would. See RFC
+			// 6347, Section 4.2.2 and RFC 9147, Section 5.2.
+			c.out.Lock()
+			d.recvSeq, d.sendSeq = msgSeq, msgSeq
+			d.out[0].seq = max(d.out[0].seq, seq)
+			c.out.Unlock()
+		}
+
+		if msgSeq < d.recvSeq {
+			if err := c.dtlsPeerRetransmitted(e, seq); err != nil {
+				return grew, err
+			}
+			continue
+		}
+		// Messages received ahead of time are dropped, to be received again
+		// when the peer retransmits them.
+		if msgSeq > d.recvSeq {
+			continue
+		}
+
+		if d.reasm == nil {
+			maxHandshakeSize := maxHandshake
+			if typ == typeCertificate {
+				maxHandshakeSize = maxHandshakeCertificateMsg
+			}
+			if length > maxHandshakeSize {
+				c.sendAlert(alertInternalError)
+				return grew, c.in.setErrorLocked(fmt.Errorf("tls: handshake message of length %d bytes exceeds maximum of %d bytes", length, maxHandshakeSize))
+			}
+			d.reasm = make([]byte, 4+length)
+			d.reasm[0] = typ
+			d.reasm[1], d.reasm[2], d.reasm[3] = byte(length>>16), byte(length>>8), byte(length)
+			d.reasmLen = 0
+		} else if d.reasm[0] != typ || len(d.reasm)-4 != length {
+			return grew, c.dtlsMalformedRecord(e)
+		}
+		accepted = true
+
+		// Fragments are only accepted in order, which is enough to recover
+		// from losses since whole flights are retransmitted.
+		if off <= d.reasmLen && off+n > d.reasmLen {
+			copy(d.reasm[4+off:], fragment)
+			d.reasmLen = off + n
+		}
+		if d.reasmLen < length {
+			continue
+		}
+		c.hand.Write(d.reasm)
+		d.reasm = nil
+		d.msgSeq[typ] = d.recvSeq
+		d.recvSeq++
+		grew = true
+
+		c.out.Lock()
+		d.peerResponded = true
+		c.out.Unlock()
+	}
+
+	if accepted && e.aead != nil {
+		if len(d.received) == dtlsMaxACKRecords {
+			d.received = d.received[1:]
+		}
+		d.received = append(d.received, dtlsRecordNumber{uint64(e.epoch), seq})
+	}
+	return grew, nil
+}
+
+// dtlsMalformedRecord handles a record with an invalid handshake payload. If
+// it was not authenticated, it's dropped, otherwise the connection fails.
+func (c *Conn) dtlsMalformedRecord(e *dtlsEpoch) error {
+	if !e.protected() {
+		return nil
+	}
+	return c.in.setErrorLocked(c.sendAlert(alertDecodeError))
+}
+
+// dtlsPeerRetransmitted handles the reception in record seq of epoch e of a
+// handshake message that was already processed. The peer retransmits when it
+// didn't receive our last flight, which we retransmit in turn, or, once the
+// handshake is complete, when it didn't receive our acknowledgment.
+func (c *Conn) dtlsPeerRetransmitted(e *dtlsEpoch, seq uint64) error {
+	c.out.Lock()
+	defer c.out.Unlock()
+	if len(c.dtls.flight) > 0 {
+		return c.dtlsRetransmitLocked(false)
+	}
+	if c.isHandshakeComplete.Load() && e.aead != nil {
+		return c.dtlsSendACKLocked([]dtlsRecordNumber{{uint64(e.epoch), seq}})
+	}
+	return nil
+}
+
+// dtlsRetransmitLocked resends the last flight of handshake messages. timer is
+// true if the retransmission timer expired, in which case it backs off.
+func (c *Conn) dtlsRetransmitLocked(timer bool) error {
+	d := c.dtls
+	if len(d.flight) == 0 {
+		return nil
+	}
+	now := time.Now()
+	if timer {
+		d.retransmissions++
+		if d.retransmissions > dtlsMaxRetransmissions {
+			return errDTLSTimeout
+		}
+		d.timeout = min(2*d.timeout, dtlsMaxTimeout)
+	} else if now.Sub(d.lastSent) < dtlsMinRetransmitInterval {
+		return nil
+	}
+
+	buffering := c.buffering
+	c.buffering = true
+	for _, m := range d.flight {
+		var err error
+		if m.ccs {
+			err = c.dtlsSendRecordLocked(d.outEpoch(m.epoch), recordTypeChangeCipherSpec, []byte{1})
+		} else {
+			err = c.dtlsSendHandshakeLocked(d.outEpoch(m.epoch), m.msg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !buffering {
+		if _, err := c.flush(); err != nil {
+			return err
+		}
+	}
+	d.lastSent = now
+	d.retransmitAt = now.Add(d.timeout)
+	return nil
+}
+
+// outEpoch returns the write state of the given epoch.
+func (d *dtlsState) outEpoch(epoch uint16) *dtlsEpoch {
+	for _, e := range d.out {
+		if e.epoch == epoch {
+			return e
+		}
+	}
+	panic("tls: internal error: unknown DTLS epoch")
+}
+
+// dtlsWriteRecordLocked is the DTLS version of writeRecordLocked.
+func (c *Conn) dtlsWriteRecordLocked(typ recordType, data []byte) (int, error) {
+	d := c.dtls
+	e := d.out[len(d.out)-1]
+
+	switch typ {
+	case recordTypeChangeCipherSpec:
+		// DTLS 1.3 has no middlebox compatibility mode.
+		if c.vers != VersionTLS12 {
+			return len(data), nil
+		}
+		// In DTLS 1.2, the ChangeCipherSpec is part of the flight, and
+		// switches to the next write epoch.
+		d.startFlight()
+		d.flight = append(d.flight, dtlsFlightMessage{epoch: e.epoch, ccs: true})
+		if err := c.dtlsSendRecordLocked(e, typ, data); err != nil {
+			return 0, err
+		}
+		next := newDTLS12Epoch(e, &c.out, c.config.rand())
+		if err := c.out.changeCipherSpec(); err != nil {
+			return 0, c.sendAlertLocked(err.(alert))
+		}
+		d.out = append(d.out, next)
+		return len(data), nil
+
+	case recordTypeHandshake:
+		d.startFlight()
+		n := len(data)
+		for len(data) > 0 {
+			if len(data) < 4 {
+				return 0, errors.New("tls: internal error: truncated handshake message")
+			}
+			length := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+			if len(data) < 4+length {
+				return 0, errors.New("tls: internal error: truncated handshake message")
+			}
+			msg := make([]byte, dtlsHandshakeHeaderLen+length)
+			copy(msg, data[:4])
+			byteorder.BEPutUint16(msg[4:], d.sendSeq)
+			copy(msg[9:], data[1:4]) // fragment_length, with a zero fragment_offset
+			copy(msg[dtlsHandshakeHeaderLen:], data[4:4+length])
+			data = data[4+length:]
+			d.sendSeq++
+
+			d.flight = append(d.flight, dtlsFlightMessage{epoch: e.epoch, msg: msg})
+			if err := c.dtlsSendHandshakeLocked(e, msg); err != nil {
+				return 0, err
+			}
+		}
+		d.lastSent = time.Now()
+		d.retransmitAt = d.lastSent.Add(d.timeout)
+		return n, nil
+
+	case recordTypeApplicationData:
+		if len(data) == 0 {
+			return 0, nil
+		}
+		if len(data) > d.maxPayload() {
+			return 0, errDTLSMessageTooLarge
+		}
+	}
+
+	if err := c.dtlsSendRecordLocked(e, typ, data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// startFlight discards the last flight if the peer responded to it, such that
+// the next message sent starts a new one.
+func (d *dtlsState) startFlight() {
+	if d.peerResponded || len(d.flight) == 0 {
+		d.flight = nil
+		d.peerResponded = false
+		d.timeout = dtlsInitialTimeout
+		d.retransmissions = 0
+	}
+}
+
+// maxPayload returns the largest payload of a record of the current write
+// epoch that fits in a datagram.
+func (d *dtlsState) maxPayload() int {
+	return dtlsMaxDatagramSize - d.out[len(d.out)-1].overhead()
+}
+
+// dtlsSendHandshakeLocked sends msg, a handshake message with a DTLS header,
+// in as many fragments as necessary to fit each record in a datagram.
+func (c *Conn) dtlsSendHandshakeLocked(e *dtlsEpoch, msg []byte) error {
+	body := msg[dtlsHandshakeHeaderLen:]
+	maxFragment := dtlsMaxDatagramSize - e.overhead() - dtlsHandshakeHeaderLen
+	var fragment []byte
+	for off := 0; off == 0 || off < len(body); {
+		n := min(len(body)-off, maxFragment)
+		fragment = append(fragment[:0], msg[:6]...)
+		fragment = append(fragment, byte(off>>16), byte(off>>8), byte(off))
+		fragment = append(fragment, byte(n>>16), byte(n>>8), byte(n))
+		fragment = append(fragment, body[off:off+n]...)
+		if err := c.dtlsSendRecordLocked(e, recordTypeHandshake, fragment); err != nil {
+			return err
+		}
+		off += n
+		if n == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// dtlsSendRecordLocked protects a record with the keys of e and sends it,
+// packing it with the previous ones in a datagram if c.buffering is set.
+func (c *Conn) dtlsSendRecordLocked(e *dtlsEpoch, typ recordType, payload []byte) error {
+	d := c.dtls
+	var err error
+	d.outBuf, err = e.seal(d.outBuf[:0], typ, payload)
+	if err != nil {
+		return err
+	}
+	if !c.buffering {
+		_, err := c.write(d.outBuf)
+		return err
+	}
+	if len(c.sendBuf) > 0 && len(c.sendBuf)+len(d.outBuf) > dtlsMaxDatagramSize {
+		n, err := c.conn.Write(c.sendBuf)
+		c.bytesSent += int64(n)
+		if err != nil {
+			return err
+		}
+		c.sendBuf = c.sendBuf[:0]
+	}
+	c.sendBuf = append(c.sendBuf, d.outBuf...)
+	return nil
+}
+
+// dtlsSendACKLocked acknowledges the given records. See RFC 9147, Section 7.
+func (c *Conn) dtlsSendACKLocked(records []dtlsRecordNumber) error {
+	if len(records) == 0 {
+		return nil
+	}
+	ack := make([]byte, 2, 2+16*len(records))
+	byteorder.BEPutUint16(ack, uint16(16*len(records)))
+	for _, r := range records {
+		ack = byteorder.BEAppendUint64(ack, r.epoch)
+		ack = byteorder.BEAppendUint64(ack, r.seq)
+	}
+	d := c.dtls
+	return c.dtlsSendRecordLocked(d.out[len(d.out)-1], recordTypeACK, ack)
+}
+
+// dtlsACKReceived acknowledges the handshake records received since the last
+// acknowledgment.
+func (c *Conn) dtlsACKReceived() error {
+	d := c.dtls
+	c.out.Lock()
+	defer c.out.Unlock()
+	err := c.dtlsSendACKLocked(d.received)
+	d.received = nil
+	return err
+}
+
+// dtlsServerHandshakeComplete is called when the server receives the client
+// Finished. The server's last flight is implicitly acknowledged by it, while
+// the client's one must be explicitly acknowledged, as nothing follows it.
+func (c *Conn) dtlsServerHandshakeComplete() error {
+	c.out.Lock()
+	c.dtls.flight = nil
+	c.out.Unlock()
+	return c.dtlsACKReceived()
+}
+
+// dtlsClientHandshakeComplete is called when a DTLS 1.2 client receives the
+// server Finished, which implicitly acknowledges the client's last flight.
+func (c *Conn) dtlsClientHandshakeComplete() {
+	c.out.Lock()
+	c.dtls.flight = nil
+	c.out.Unlock()
+}
+
+// dtlsSetReadEpoch installs the read keys of the given encryption level.
+func (c *Conn) dtlsSetReadEpoch(suite *cipherSuiteTLS13, level QUICEncryptionLevel, secret []byte) {
+	d := c.dtls
+	d.in = append(d.in, newDTLSEpoch(suite, dtlsEpochForLevel(level), secret))
+}
+
+// dtlsSetWriteEpoch installs the write keys of the given encryption level.
+func (c *Conn) dtlsSetWriteEpoch(suite *cipherSuiteTLS13, level QUICEncryptionLevel, secret []byte) {
+	d := c.dtls
+	d.out = append(d.out, newDTLSEpoch(suite, dtlsEpochForLevel(level), secret))
+}
+
+// dtlsHandlePostHandshakeMessage handles msg, a handshake message received
+// after the handshake. Session tickets are acknowledged but ignored, since
+// DTLS connections don't support resumption, and renegotiation is refused.
+func (c *Conn) dtlsHandlePostHandshakeMessage(msg any) error {
+	switch msg.(type) {
+	case *helloRequestMsg:
+		return c.sendAlert(alertNoRenegotiation)
+	case *newSessionTicketMsgTLS13:
+		return c.dtlsACKReceived()
+	case *keyUpdateMsg:
+		c.sendAlert(alertUnexpectedMessage)
+		return c.in.setErrorLocked(errors.New("tls: DTLS key updates are not supported"))
+	}
+	c.sendAlert(alertUnexpectedMessage)
+	return fmt.Errorf("tls: received unexpected handshake message of type %T", msg)
+}
@@ -0,0 +1,659 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lossyConn drops the datagrams written to it for which drop returns true.
+type lossyConn struct {
+	net.Conn
+	mu   sync.Mutex
+	n    int
+	drop func(n int) bool
+}
+
+func (c *lossyConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	c.n++
+	drop := c.drop(c.n)
+	c.mu.Unlock()
+	if drop {
+		return len(b), nil
+	}
+	return c.Conn.Write(b)
+}
+
+// lossyPacketConn drops the datagrams written to it for which drop returns
+// true.
+type lossyPacketConn struct {
+	net.PacketConn
+	mu   sync.Mutex
+	n    int
+	drop func(n int) bool
+}
+
+func (c *lossyPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	c.mu.Lock()
+	c.n++
+	drop := c.drop(c.n)
+	c.mu.Unlock()
+	if drop {
+		return len(b), nil
+	}
+	return c.PacketConn.WriteTo(b, addr)
+}
+
+// runDTLS runs a DTLS handshake over UDP loopback between a DTLSClient and a
+// connection accepted by a DTLS listener, and then has the client send msg
+// and the server echo it back. clientDrop and serverDrop, if not nil, select
+// the datagrams lost on the way.
+func runDTLS(t *testing.T, clientConfig, serverConfig *Config, clientDrop, serverDrop func(int) bool, msg []byte) (clientState, serverState ConnectionState, err error) {
+	t.Helper()
+	var pc net.PacketConn
+	pc, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP loopback not available: %v", err)
+	}
+	if serverDrop != nil {
+		pc = &lossyPacketConn{PacketConn: pc, drop: serverDrop}
+	}
+	ln := NewDTLSListener(pc, serverConfig)
+	defer ln.Close()
+
+	var netConn net.Conn
+	netConn, err = net.Dial("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clientDrop != nil {
+		netConn = &lossyConn{Conn: netConn, drop: clientDrop}
+	}
+	client := DTLSClient(netConn, clientConfig)
+	defer client.Close()
+	deadline := time.Now().Add(30 * time.Second)
+	client.SetDeadline(deadline)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		server := c.(*Conn)
+		defer server.Close()
+		server.SetDeadline(deadline)
+		if err := server.Handshake(); err != nil {
+			serverErr <- err
+			return
+		}
+		serverState = server.ConnectionState()
+		buf := make([]byte, dtlsMaxPayload)
+		n, err := server.Read(buf)
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		_, err = server.Write(buf[:n])
+		serverErr <- err
+	}()
+
+	if err = client.Handshake(); err != nil {
+		ln.Close()
+		<-serverErr
+		return
+	}
+	clientState = client.ConnectionState()
+
+	// Application data is not retransmitted, and is lost if it reaches the
+	// server before the handshake completes there, so retry like a
+	// request-response protocol over DTLS would.
+	buf := make([]byte, dtlsMaxPayload)
+	var n int
+	for {
+		if _, err = client.Write(msg); err != nil {
+			return
+		}
+		client.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, err = client.Read(buf)
+		if ne, ok := err.(net.Error); ok && ne.Timeout() && time.Now().Before(deadline) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		break
+	}
+	if !bytes.Equal(buf[:n], msg) {
+		t.Errorf("echoed message = %q, want %q", buf[:n], msg)
+	}
+	err = <-serverErr
+	return
+}
+
+func dtlsTestConfigs() (clientConfig, serverConfig *Config) {
+	clientConfig = testConfig.Clone()
+	clientConfig.Rand = nil
+	serverConfig = testConfig.Clone()
+	serverConfig.Rand = nil
+	return
+}
+
+func TestDTLSHandshake(t *testing.T) {
+	msg := bytes.Repeat([]byte("hello"), 100)
+	for _, tt := range []struct {
+		name  string
+		setup func(clientConfig, serverConfig *Config)
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "HelloRetryRequest",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.CurvePreferences = []CurveID{X25519, CurveP256}
+				serverConfig.CurvePreferences = []CurveID{CurveP256}
+			},
+		},
+		{
+			// The default key shares don't fit in a single datagram.
+			name: "fragmented ClientHello",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.CurvePreferences = nil
+				serverConfig.CurvePreferences = nil
+			},
+		},
+		{
+			name: "fragmented certificate",
+			setup: func(clientConfig, serverConfig *Config) {
+				cert := serverConfig.Certificates[0]
+				cert.Certificate = [][]byte{cert.Certificate[0], cert.Certificate[0], cert.Certificate[0], cert.Certificate[0]}
+				serverConfig.Certificates = []Certificate{cert}
+			},
+		},
+		{
+			name: "client certificate",
+			setup: func(clientConfig, serverConfig *Config) {
+				serverConfig.ClientAuth = RequireAnyClientCert
+			},
+		},
+		{
+			name: "ExternalPSK",
+			setup: func(clientConfig, serverConfig *Config) {
+				psk := ExternalPSK{Identity: []byte("device1"), Key: bytes.Repeat([]byte{0x42}, 32)}
+				clientConfig.InsecureSkipVerify = false
+				clientConfig.ExternalPSKs = []ExternalPSK{psk}
+				serverConfig.ExternalPSKs = []ExternalPSK{psk}
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig, serverConfig := dtlsTestConfigs()
+			if tt.setup != nil {
+				tt.setup(clientConfig, serverConfig)
+			}
+			clientState, serverState, err := runDTLS(t, clientConfig, serverConfig, nil, nil, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, state := range []ConnectionState{clientState, serverState} {
+				if state.Version != VersionDTLS13 {
+					t.Errorf("Version = %x, want %x", state.Version, VersionDTLS13)
+				}
+				if state.DidResume {
+					t.Error("DidResume = true, want false")
+				}
+			}
+			if !serverState.HelloRetryRequest {
+				t.Error("server did not send a HelloRetryRequest")
+			}
+		})
+	}
+}
+
+func TestDTLS12Handshake(t *testing.T) {
+	msg := bytes.Repeat([]byte("hello"), 100)
+	for _, tt := range []struct {
+		name  string
+		setup func(clientConfig, serverConfig *Config)
+	}{
+		{
+			name: "client max version",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.MaxVersion = VersionTLS12
+			},
+		},
+		{
+			name: "server max version",
+			setup: func(clientConfig, serverConfig *Config) {
+				serverConfig.MaxVersion = VersionTLS12
+			},
+		},
+		{
+			name: "CBC",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.MaxVersion = VersionTLS12
+				clientConfig.CipherSuites = []uint16{TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA}
+			},
+		},
+		{
+			name: "ChaCha20-Poly1305",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.MaxVersion = VersionTLS12
+				clientConfig.CipherSuites = []uint16{TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256}
+			},
+		},
+		{
+			name: "fragmented certificate",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.MaxVersion = VersionTLS12
+				cert := serverConfig.Certificates[0]
+				cert.Certificate = [][]byte{cert.Certificate[0], cert.Certificate[0], cert.Certificate[0], cert.Certificate[0]}
+				serverConfig.Certificates = []Certificate{cert}
+			},
+		},
+		{
+			name: "client certificate",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.MaxVersion = VersionTLS12
+				serverConfig.ClientAuth = RequireAnyClientCert
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig, serverConfig := dtlsTestConfigs()
+			tt.setup(clientConfig, serverConfig)
+			clientState, serverState, err := runDTLS(t, clientConfig, serverConfig, nil, nil, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, state := range []ConnectionState{clientState, serverState} {
+				if state.Version != VersionDTLS12 {
+					t.Errorf("Version = %x, want %x", state.Version, VersionDTLS12)
+				}
+				if clientConfig.CipherSuites != nil && state.CipherSuite != clientConfig.CipherSuites[0] {
+					t.Errorf("CipherSuite = %s, want %s", CipherSuiteName(state.CipherSuite), CipherSuiteName(clientConfig.CipherSuites[0]))
+				}
+			}
+			if serverConfig.ClientAuth == RequireAnyClientCert && len(serverState.PeerCertificates) == 0 {
+				t.Error("server did not receive the client certificate")
+			}
+		})
+	}
+}
+
+func TestDTLS12NoRC4(t *testing.T) {
+	clientConfig, serverConfig := dtlsTestConfigs()
+	clientConfig.MaxVersion = VersionTLS12
+	clientConfig.CipherSuites = []uint16{TLS_ECDHE_RSA_WITH_RC4_128_SHA, TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	serverConfig.CipherSuites = []uint16{TLS_ECDHE_RSA_WITH_RC4_128_SHA, TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	clientState, _, err := runDTLS(t, clientConfig, serverConfig, nil, nil, []byte("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clientState.CipherSuite != TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("CipherSuite = %s, want %s", CipherSuiteName(clientState.CipherSuite), CipherSuiteName(TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256))
+	}
+}
+
+func TestDTLSListenerStateless(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		vers uint16
+	}{
+		{"DTLS 1.2", VersionTLS12},
+		{"DTLS 1.3", VersionTLS13},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Skipf("UDP loopback not available: %v", err)
+			}
+			clientConfig, serverConfig := dtlsTestConfigs()
+			clientConfig.MaxVersion = tt.vers
+			ln := NewDTLSListener(pc, serverConfig)
+			defer ln.Close()
+			l := ln.(*dtlsListener)
+
+			// Clients that don't send back the cookie get an answer, but no
+			// connection.
+			var wg sync.WaitGroup
+			var conns []*lossyConn
+			for range 20 {
+				netConn, err := net.Dial("udp", pc.LocalAddr().String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				conn := &lossyConn{Conn: netConn, drop: func(n int) bool { return n > 1 }}
+				conns = append(conns, conn)
+				wg.Go(func() {
+					client := DTLSClient(conn, clientConfig)
+					defer client.Close()
+					client.SetDeadline(time.Now().Add(500 * time.Millisecond))
+					client.Handshake()
+				})
+			}
+			wg.Wait()
+			for _, conn := range conns {
+				if conn.n < 2 {
+					t.Errorf("client sent %d datagrams, want a second ClientHello", conn.n)
+				}
+			}
+			l.mu.Lock()
+			peers := len(l.peers)
+			l.mu.Unlock()
+			if peers != 0 || len(l.accept) != 0 {
+				t.Errorf("listener has %d peers and %d queued connections, want none", peers, len(l.accept))
+			}
+
+			netConn, err := net.Dial("udp", pc.LocalAddr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := DTLSClient(netConn, clientConfig)
+			defer client.Close()
+			deadline := time.Now().Add(30 * time.Second)
+			client.SetDeadline(deadline)
+			serverErr := make(chan error, 1)
+			go func() {
+				c, err := ln.Accept()
+				if err != nil {
+					serverErr <- err
+					return
+				}
+				c.SetDeadline(deadline)
+				serverErr <- c.(*Conn).Handshake()
+			}()
+			if err := client.Handshake(); err != nil {
+				t.Fatal(err)
+			}
+			if err := <-serverErr; err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDTLSCookie(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	random := bytes.Repeat([]byte{2}, 32)
+	addr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 4433}
+	state := []byte("state")
+	cookie := dtlsSealCookie(key, addr, random, state)
+
+	if got, ok := dtlsOpenCookie(key, addr, random, cookie); !ok || !bytes.Equal(got, state) {
+		t.Errorf("dtlsOpenCookie = %q, %v, want %q, true", got, ok, state)
+	}
+	if _, ok := dtlsOpenCookie(key, &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 4434}, random, cookie); ok {
+		t.Error("cookie accepted from another address")
+	}
+	if _, ok := dtlsOpenCookie(key, addr, bytes.Repeat([]byte{3}, 32), cookie); ok {
+		t.Error("cookie accepted for another ClientHello")
+	}
+	if _, ok := dtlsOpenCookie(bytes.Repeat([]byte{4}, 32), addr, random, cookie); ok {
+		t.Error("cookie accepted by another listener")
+	}
+	cookie[0] ^= 1
+	if _, ok := dtlsOpenCookie(key, addr, random, cookie); ok {
+		t.Error("modified cookie accepted")
+	}
+}
+
+func TestDTLSPacketLoss(t *testing.T) {
+	defer func(d time.Duration) { dtlsInitialTimeout = d }(dtlsInitialTimeout)
+	dtlsInitialTimeout = 50 * time.Millisecond
+
+	for _, tt := range []struct {
+		name                   string
+		clientDrop, serverDrop func(int) bool
+	}{
+		{"first ClientHello", func(n int) bool { return n == 1 }, nil},
+		{"HelloRetryRequest", nil, func(n int) bool { return n == 1 }},
+		{"server flight", nil, func(n int) bool { return n == 2 }},
+		{"client Finished", func(n int) bool { return n == 3 }, nil},
+		{"server ACK", nil, func(n int) bool { return n == 3 }},
+		{"every other datagram", func(n int) bool { return n%2 == 0 && n < 8 }, func(n int) bool { return n%2 == 1 && n < 8 }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig, serverConfig := dtlsTestConfigs()
+			cert := serverConfig.Certificates[0]
+			cert.Certificate = [][]byte{cert.Certificate[0], cert.Certificate[0]}
+			serverConfig.Certificates = []Certificate{cert}
+			if _, _, err := runDTLS(t, clientConfig, serverConfig, tt.clientDrop, tt.serverDrop, []byte("ping")); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDTLS12PacketLoss(t *testing.T) {
+	defer func(d time.Duration) { dtlsInitialTimeout = d }(dtlsInitialTimeout)
+	dtlsInitialTimeout = 50 * time.Millisecond
+
+	// Without loss, the client sends the first ClientHello, the second one,
+	// and its final flight, while the server sends the HelloVerifyRequest, its
+	// first flight, and its final flight, one datagram each.
+	for _, tt := range []struct {
+		name                   string
+		clientDrop, serverDrop func(int) bool
+	}{
+		{"first ClientHello", func(n int) bool { return n == 1 }, nil},
+		{"HelloVerifyRequest", nil, func(n int) bool { return n == 1 }},
+		{"second ClientHello", func(n int) bool { return n == 2 }, nil},
+		{"server flight", nil, func(n int) bool { return n == 2 }},
+		{"client Finished", func(n int) bool { return n == 3 }, nil},
+		{"server Finished", nil, func(n int) bool { return n == 3 }},
+		// Only lose handshake datagrams, since the echoed application data
+		// would be followed by the close_notify of the server.
+		{"every other datagram", func(n int) bool { return n%2 == 0 && n < 7 }, func(n int) bool { return n%2 == 1 && n < 7 }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig, serverConfig := dtlsTestConfigs()
+			clientConfig.MaxVersion = VersionTLS12
+			if _, _, err := runDTLS(t, clientConfig, serverConfig, tt.clientDrop, tt.serverDrop, []byte("ping")); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDTLSHandshakeTimeout(t *testing.T) {
+	defer func(d time.Duration) { dtlsInitialTimeout = d }(dtlsInitialTimeout)
+	dtlsInitialTimeout = time.Millisecond
+
+	clientConfig, serverConfig := dtlsTestConfigs()
+	drop := func(int) bool { return true }
+	_, _, err := runDTLS(t, clientConfig, serverConfig, drop, nil, []byte("ping"))
+	if !errors.Is(err, errDTLSTimeout) {
+		t.Errorf("handshake error = %v, want %v", err, errDTLSTimeout)
+	}
+}
+
+func TestDTLSWriteTooLarge(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP loopback not available: %v", err)
+	}
+	clientConfig, serverConfig := dtlsTestConfigs()
+	ln := NewDTLSListener(pc, serverConfig)
+	defer ln.Close()
+	netConn, err := net.Dial("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := DTLSClient(netConn, clientConfig)
+	defer client.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer c.Close()
+		buf := make([]byte, dtlsMaxPayload)
+		n, err := c.Read(buf)
+		if err == nil && n != dtlsMaxPayload {
+			err = fmt.Errorf("read %d bytes, want %d", n, dtlsMaxPayload)
+		}
+		errc <- err
+	}()
+
+	if _, err := client.Write(make([]byte, dtlsMaxPayload+1)); err != errDTLSMessageTooLarge {
+		t.Errorf("Write error = %v, want %v", err, errDTLSMessageTooLarge)
+	}
+	if _, err := client.Write(make([]byte, dtlsMaxPayload)); err != nil {
+		t.Errorf("Write error = %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDTLSRecordProtection(t *testing.T) {
+	for _, suite := range cipherSuitesTLS13 {
+		t.Run(CipherSuiteName(suite.id), func(t *testing.T) {
+			secret := bytes.Repeat([]byte{1}, suite.hash.Size())
+			out := newDTLSEpoch(suite, 3, secret)
+			d := newDTLSState()
+			d.in = append(d.in, newDTLSEpoch(suite, 3, secret))
+
+			var records [][]byte
+			for i := range 3 {
+				b, err := out.seal(nil, recordTypeApplicationData, []byte{byte(i)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				records = append(records, b)
+			}
+
+			// Deliver the records out of order.
+			for _, i := range []int{1, 0, 2} {
+				d.datagram = bytes.Clone(records[i])
+				_, seq, typ, data, ok := d.nextRecord()
+				if !ok {
+					t.Fatalf("record %d was rejected", i)
+				}
+				if seq != uint64(i) || typ != recordTypeApplicationData || !bytes.Equal(data, []byte{byte(i)}) {
+					t.Errorf("record %d: got seq %d, type %d, data %x", i, seq, typ, data)
+				}
+			}
+			d.datagram = bytes.Clone(records[1])
+			if _, _, _, _, ok := d.nextRecord(); ok {
+				t.Error("replayed record was accepted")
+			}
+
+			// A tampered record is dropped.
+			tampered := bytes.Clone(records[2])
+			tampered[len(tampered)-1] ^= 1
+			d.datagram = tampered
+			if _, _, _, _, ok := d.nextRecord(); ok {
+				t.Error("tampered record was accepted")
+			}
+		})
+	}
+}
+
+func TestDTLS12RecordProtection(t *testing.T) {
+	for _, id := range []uint16{
+		TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+		TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+	} {
+		t.Run(CipherSuiteName(id), func(t *testing.T) {
+			suite := cipherSuiteByID(id)
+			newEpoch := func(isRead bool) *dtlsEpoch {
+				key := bytes.Repeat([]byte{1}, suite.keyLen)
+				iv := bytes.Repeat([]byte{2}, suite.ivLen)
+				hc := new(halfConn)
+				if suite.aead != nil {
+					hc.prepareCipherSpec(VersionTLS12, suite.aead(key, iv), nil)
+				} else {
+					macKey := bytes.Repeat([]byte{3}, suite.macLen)
+					hc.prepareCipherSpec(VersionTLS12, suite.cipher(key, iv, isRead), suite.mac(macKey))
+				}
+				return newDTLS12Epoch(&dtlsEpoch{}, hc, rand.Reader)
+			}
+			out := newEpoch(false)
+			d := newDTLSState()
+			d.in = append(d.in, newEpoch(true))
+
+			var records [][]byte
+			for i := range 3 {
+				b, err := out.seal(nil, recordTypeApplicationData, bytes.Repeat([]byte{byte(i)}, 100))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(b) > 100+out.overhead() {
+					t.Errorf("record of %d bytes exceeds the overhead of %d bytes", len(b), out.overhead())
+				}
+				records = append(records, b)
+			}
+
+			// Deliver the records out of order.
+			for _, i := range []int{1, 0, 2} {
+				d.datagram = bytes.Clone(records[i])
+				e, seq, typ, data, ok := d.nextRecord()
+				if !ok {
+					t.Fatalf("record %d was rejected", i)
+				}
+				if e.epoch != 1 || seq != uint64(i) || typ != recordTypeApplicationData || !bytes.Equal(data, bytes.Repeat([]byte{byte(i)}, 100)) {
+					t.Errorf("record %d: got epoch %d, seq %d, type %d, data %x", i, e.epoch, seq, typ, data)
+				}
+			}
+			d.datagram = bytes.Clone(records[1])
+			if _, _, _, _, ok := d.nextRecord(); ok {
+				t.Error("replayed record was accepted")
+			}
+
+			// A tampered record is dropped, including if only its header is
+			// modified.
+			tampered := bytes.Clone(records[2])
+			tampered[len(tampered)-1] ^= 1
+			d.datagram = tampered
+			if _, _, _, _, ok := d.nextRecord(); ok {
+				t.Error("tampered record was accepted")
+			}
+			b, err := out.seal(nil, recordTypeApplicationData, []byte{3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			b[0] = byte(recordTypeAlert)
+			d.datagram = b
+			if _, _, _, _, ok := d.nextRecord(); ok {
+				t.Error("record with a tampered header was accepted")
+			}
+		})
+	}
+}
+
+func TestDTLS12Transcript(t *testing.T) {
+	d := newDTLSState()
+	d.msgSeq[typeServerHello] = 0x0102
+	h := newFinishedHash(VersionTLS12, cipherSuiteByID(TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256))
+	h.dtls = d
+	h.Write([]byte{typeServerHello, 0, 0, 3, 'a', 'b', 'c'})
+
+	// See RFC 6347, Section 4.2.6.
+	want := []byte{typeServerHello, 0, 0, 3, 0x01, 0x02, 0, 0, 0, 0, 0, 3, 'a', 'b', 'c'}
+	if !bytes.Equal(h.buffer, want) {
+		t.Errorf("transcript = %x, want %x", h.buffer, want)
+	}
+	if got, want := h.Sum(), sha256.Sum256(want); !bytes.Equal(got, want[:]) {
+		t.Errorf("transcript hash = %x, want %x", got, want)
+	}
+}
@@ -562,6 +562,7 @@ func (c *Conn) processECHClientHello(outer *clientHelloMsg, echKeys []EncryptedC
 	}
 
 	if len(echKeys) == 0 {
+		c.traceHandshake(HandshakeEvent{Kind: HandshakeECHRejected, Reason: "no ECH keys configured"})
 		return outer, nil, nil
 	}
 
@@ -620,6 +621,7 @@ func (c *Conn) processECHClientHello(outer *clientHelloMsg, echKeys []EncryptedC
 		}
 
 		c.echAccepted = true
+		c.traceHandshake(HandshakeEvent{Kind: HandshakeECHAccepted})
 
 		return echInner, &echServerContext{
 			hpkeContext: hpkeContext,
@@ -628,6 +630,7 @@ func (c *Conn) processECHClientHello(outer *clientHelloMsg, echKeys []EncryptedC
 		}, nil
 	}
 
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeECHRejected, Reason: "no ECH key could decrypt the ClientHello"})
 	return outer, nil, nil
 }
 
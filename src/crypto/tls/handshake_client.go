@@ -11,7 +11,6 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/hpke"
-	"crypto/internal/fips140/tls13"
 	"crypto/rsa"
 	"crypto/subtle"
 	"crypto/tls/internal/fips140tls"
@@ -42,7 +41,10 @@ type clientHandshakeState struct {
 
 func (c *Conn) makeClientHello() (*clientHelloMsg, *keySharePrivateKeys, *echClientContext, error) {
 	config := c.config
-	if len(config.ServerName) == 0 && !config.InsecureSkipVerify {
+	// Without a ServerName, certificates can't be verified, but an external
+	// PSK or a raw public key can still authenticate the server.
+	if len(config.ServerName) == 0 && !config.InsecureSkipVerify &&
+		len(config.ExternalPSKs) == 0 && config.acceptsPeerCertificateType(CertificateTypeX509) {
 		return nil, nil, nil, errors.New("tls: either ServerName or InsecureSkipVerify must be specified in the tls.Config")
 	}
 
@@ -59,6 +61,19 @@ func (c *Conn) makeClientHello() (*clientHelloMsg, *keySharePrivateKeys, *echCli
 	}
 
 	supportedVersions := config.supportedVersions(roleClient)
+	if c.dtls != nil {
+		// DTLS 1.2 and DTLS 1.3 are TLS 1.2 and TLS 1.3 for everything but
+		// the record layer. Earlier versions are not supported.
+		if fips140tls.Required() {
+			return nil, nil, nil, errDTLSFIPS
+		}
+		supportedVersions = slices.DeleteFunc(supportedVersions, func(v uint16) bool {
+			return v < VersionTLS12
+		})
+		if len(supportedVersions) == 0 {
+			return nil, nil, nil, errDTLSVersion
+		}
+	}
 	if len(supportedVersions) == 0 {
 		return nil, nil, nil, errors.New("tls: no supported versions satisfy MinVersion and MaxVersion")
 	}
@@ -110,8 +125,9 @@ func (c *Conn) makeClientHello() (*clientHelloMsg, *keySharePrivateKeys, *echCli
 	// and is resuming a session (see RFC 5077). In TLS 1.3, it's always set as
 	// a compatibility measure (see RFC 8446, Section 4.1.2).
 	//
-	// The session ID is not set for QUIC connections (see RFC 9001, Section 8.4).
-	if c.quic == nil {
+	// The session ID is not set for QUIC connections (see RFC 9001, Section 8.4)
+	// and for DTLS connections (see RFC 9147, Section 5.3).
+	if c.quic == nil && c.dtls == nil {
 		hello.sessionId = make([]byte, 32)
 		if _, err := io.ReadFull(config.rand(), hello.sessionId); err != nil {
 			return nil, nil, nil, errors.New("tls: short read from Rand: " + err.Error())
@@ -157,6 +173,21 @@ func (c *Conn) makeClientHello() (*clientHelloMsg, *keySharePrivateKeys, *echCli
 		if len(hello.keyShares) == 2 && !slices.Contains(hello.supportedCurves, hello.keyShares[1].group) {
 			hello.keyShares = hello.keyShares[:1]
 		}
+
+		// Raw public keys are negotiated with the extensions of RFC 7250,
+		// which are omitted when only X.509 certificates are supported.
+		if config.acceptsPeerCertificateType(CertificateTypeRawPublicKey) {
+			hello.serverCertTypes = config.PeerCertificateTypes
+		}
+		if slices.ContainsFunc(config.Certificates, func(cert Certificate) bool {
+			return cert.Type == CertificateTypeRawPublicKey
+		}) {
+			for _, cert := range config.Certificates {
+				if !slices.Contains(hello.clientCertTypes, cert.Type) {
+					hello.clientCertTypes = append(hello.clientCertTypes, cert.Type)
+				}
+			}
+		}
 	}
 
 	if c.quic != nil {
@@ -170,8 +201,30 @@ func (c *Conn) makeClientHello() (*clientHelloMsg, *keySharePrivateKeys, *echCli
 		hello.quicTransportParameters = p
 	}
 
+	if c.dtls != nil {
+		hello.dtls = true
+		hello.vers = VersionDTLS12
+		hello.supportedVersions = nil
+		if maxVersion >= VersionTLS13 {
+			for _, v := range supportedVersions {
+				hello.supportedVersions = append(hello.supportedVersions, dtlsVersion(v))
+			}
+		}
+		// Stream ciphers can't be used with DTLS. See RFC 6347, Section
+		// 4.1.2.2.
+		hello.cipherSuites = slices.DeleteFunc(hello.cipherSuites, func(id uint16) bool {
+			return !dtlsAllowedCipherSuite(id)
+		})
+	}
+
 	var ech *echClientContext
 	if c.config.EncryptedClientHelloConfigList != nil {
+		if c.dtls != nil {
+			return nil, nil, nil, errors.New("tls: EncryptedClientHelloConfigList is not supported with DTLS")
+		}
+		if len(c.config.ExternalPSKs) > 0 {
+			return nil, nil, nil, errors.New("tls: ExternalPSKs can't be used with EncryptedClientHelloConfigList")
+		}
 		if c.config.MinVersion != 0 && c.config.MinVersion < VersionTLS13 {
 			return nil, nil, nil, errors.New("tls: MinVersion must be >= VersionTLS13 if EncryptedClientHelloConfigList is populated")
 		}
@@ -237,6 +290,10 @@ func (c *Conn) clientHandshake(ctx context.Context) (err error) {
 	if err != nil {
 		return err
 	}
+	externalPSKs, err := c.loadExternalPSKs(hello)
+	if err != nil {
+		return err
+	}
 	if session != nil {
 		defer func() {
 			// If we got a handshake failure when resuming a session, throw away
@@ -302,6 +359,22 @@ func (c *Conn) clientHandshake(ctx context.Context) (err error) {
 		return err
 	}
 
+	// A DTLS 1.2 server can ask for the ClientHello to be sent again with a
+	// cookie. Neither is part of the transcript. See RFC 6347, Section 4.2.1.
+	if hvr, ok := msg.(*helloVerifyRequestMsg); ok {
+		if len(hvr.cookie) == 0 {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: server sent an empty DTLS cookie")
+		}
+		hello.dtlsCookie = hvr.cookie
+		if _, err := c.writeHandshakeRecord(hello, nil); err != nil {
+			return err
+		}
+		if msg, err = c.readHandshake(nil); err != nil {
+			return err
+		}
+	}
+
 	serverHello, ok := msg.(*serverHelloMsg)
 	if !ok {
 		c.sendAlert(alertUnexpectedMessage)
@@ -311,6 +384,10 @@ func (c *Conn) clientHandshake(ctx context.Context) (err error) {
 	if err := c.pickTLSVersion(serverHello); err != nil {
 		return err
 	}
+	if hello.dtlsCookie != nil && c.vers != VersionTLS12 {
+		c.sendAlert(alertIllegalParameter)
+		return errors.New("tls: server sent a HelloVerifyRequest and did not select DTLS 1.2")
+	}
 
 	// If we are negotiating a protocol version that's lower than what we
 	// support, check for the server downgrade canaries.
@@ -334,6 +411,7 @@ func (c *Conn) clientHandshake(ctx context.Context) (err error) {
 			session:      session,
 			earlySecret:  earlySecret,
 			binderKey:    binderKey,
+			externalPSKs: externalPSKs,
 			echContext:   ech,
 		}
 		return hs.handshake()
@@ -350,8 +428,14 @@ func (c *Conn) clientHandshake(ctx context.Context) (err error) {
 }
 
 func (c *Conn) loadSession(hello *clientHelloMsg) (
-	session *SessionState, earlySecret *tls13.EarlySecret, binderKey []byte, err error) {
-	if c.config.SessionTicketsDisabled || c.config.ClientSessionCache == nil {
+	session *SessionState, earlySecret *earlySecret, binderKey []byte, err error) {
+	if c.config.SessionTicketsDisabled || c.config.ClientSessionCache == nil || c.dtls != nil {
+		return nil, nil, nil, nil
+	}
+
+	// Resumption would replace the external PSKs as the authentication
+	// mechanism, so don't attempt it.
+	if len(c.config.ExternalPSKs) > 0 {
 		return nil, nil, nil, nil
 	}
 
@@ -491,23 +575,72 @@ func (c *Conn) loadSession(hello *clientHelloMsg) (
 	hello.pskBinders = [][]byte{make([]byte, cipherSuite.hash.Size())}
 
 	// Compute the PSK binders. See RFC 8446, Section 4.2.11.2.
-	earlySecret = tls13.NewEarlySecret(cipherSuite.hash.New, session.secret)
+	earlySecret = newEarlySecret(cipherSuite, false, session.secret)
 	binderKey = earlySecret.ResumptionBinderKey()
 	transcript := cipherSuite.hash.New()
-	if err := computeAndUpdatePSK(hello, binderKey, transcript, cipherSuite.finishedHash); err != nil {
+	if err := computeAndUpdatePSK(hello, binderKey, transcript, cipherSuite); err != nil {
 		return nil, nil, nil, err
 	}
 
 	return
 }
 
+// loadExternalPSKs sets the pre_shared_key extension of hello to offer the
+// external PSKs of c.config whose hash matches one of the offered TLS 1.3
+// cipher suites, and returns them in the order of hello.pskIdentities.
+func (c *Conn) loadExternalPSKs(hello *clientHelloMsg) ([]ExternalPSK, error) {
+	if len(c.config.ExternalPSKs) == 0 ||
+		hello.supportedVersions[0] != VersionTLS13 && hello.supportedVersions[0] != VersionDTLS13 {
+		return nil, nil
+	}
+
+	var psks []ExternalPSK
+	for _, psk := range c.config.ExternalPSKs {
+		if len(psk.Identity) == 0 || len(psk.Key) == 0 {
+			return nil, errors.New("tls: ExternalPSKs contains an entry with an empty Identity or Key")
+		}
+		suite := psk.suite()
+		if suite == nil {
+			return nil, errors.New("tls: ExternalPSKs contains an entry with an invalid TLS 1.3 CipherSuite")
+		}
+		if !slices.ContainsFunc(hello.cipherSuites, func(id uint16) bool {
+			offeredSuite := cipherSuiteTLS13ByID(id)
+			return offeredSuite != nil && offeredSuite.hash == suite.hash
+		}) {
+			continue
+		}
+		psks = append(psks, psk)
+		// Externally established identities use an obfuscated_ticket_age of
+		// zero. See RFC 8446, Section 4.2.11.
+		hello.pskIdentities = append(hello.pskIdentities, pskIdentity{label: psk.Identity})
+		hello.pskBinders = append(hello.pskBinders, make([]byte, suite.hash.Size()))
+	}
+	if len(psks) == 0 {
+		return nil, nil
+	}
+
+	// Require DHE, as for resumption, for forward secrecy against compromise
+	// of the PSKs. See RFC 8446, Section 4.2.9.
+	hello.pskModes = []uint8{pskModeDHE}
+
+	if err := computeAndUpdateExternalPSKBinders(hello, psks, nil, c.dtls != nil); err != nil {
+		return nil, err
+	}
+	return psks, nil
+}
+
 func (c *Conn) pickTLSVersion(serverHello *serverHelloMsg) error {
 	peerVersion := serverHello.vers
 	if serverHello.supportedVersion != 0 {
 		peerVersion = serverHello.supportedVersion
 	}
 
-	vers, ok := c.config.mutualVersion(roleClient, []uint16{peerVersion})
+	tlsVersion := peerVersion
+	if c.dtls != nil {
+		tlsVersion = tlsVersionForDTLS(peerVersion)
+	}
+
+	vers, ok := c.config.mutualVersion(roleClient, []uint16{tlsVersion})
 	if !ok {
 		c.sendAlert(alertProtocolVersion)
 		return fmt.Errorf("tls: server selected unsupported protocol version %x", peerVersion)
@@ -545,6 +678,7 @@ func (hs *clientHandshakeState) handshake() error {
 	}
 
 	hs.finishedHash = newFinishedHash(c.vers, hs.suite)
+	hs.finishedHash.dtls = c.dtls
 
 	// No signatures of the handshake are needed in a resumption.
 	// Otherwise, in a full handshake, if we don't have any certificates
@@ -563,6 +697,11 @@ func (hs *clientHandshakeState) handshake() error {
 
 	c.buffering = true
 	c.didResume = isResume
+	if isResume {
+		c.traceHandshake(HandshakeEvent{Kind: HandshakeSessionResumed})
+	} else if hs.session != nil {
+		c.traceSessionNotResumed("server did not resume the offered session")
+	}
 	if isResume {
 		if err := hs.establishKeys(); err != nil {
 			return err
@@ -615,6 +754,9 @@ func (hs *clientHandshakeState) handshake() error {
 	}
 
 	c.ekm = ekmFromMasterSecret(c.vers, hs.suite, hs.masterSecret, hs.hello.random, hs.serverHello.random)
+	if c.dtls != nil {
+		c.dtlsClientHandshakeComplete()
+	}
 	c.isHandshakeComplete.Store(true)
 
 	return nil
@@ -636,6 +778,7 @@ func (hs *clientHandshakeState) pickCipherSuite() error {
 	}
 
 	hs.c.cipherSuite = hs.suite.id
+	hs.c.traceCipherSuite()
 	return nil
 }
 
@@ -710,6 +853,10 @@ func (hs *clientHandshakeState) doFullHandshake() error {
 		if keyAgreement, ok := keyAgreement.(*ecdheKeyAgreement); ok {
 			c.curveID = keyAgreement.curveID
 			c.peerSigAlg = keyAgreement.signatureAlgorithm
+			c.traceHandshake(HandshakeEvent{Kind: HandshakeGroupSelected, Group: c.curveID})
+			if c.vers >= VersionTLS12 {
+				c.traceHandshake(HandshakeEvent{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: c.peerSigAlg, Peer: true})
+			}
 		}
 
 		msg, err = c.readHandshake(&hs.finishedHash)
@@ -802,6 +949,7 @@ func (hs *clientHandshakeState) doFullHandshake() error {
 			}
 			certVerify.hasSignatureAlgorithm = true
 			certVerify.signatureAlgorithm = signatureAlgorithm
+			c.traceHandshake(HandshakeEvent{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: signatureAlgorithm})
 			if sigHash == crypto.SHA1 {
 				tlssha1.Value() // ensure godebug is initialized
 				tlssha1.IncNonDefault()
@@ -1097,6 +1245,11 @@ func checkKeySize(n int) (max int, ok bool) {
 // verifyServerCertificate parses and verifies the provided chain, setting
 // c.verifiedChains and c.peerCertificates or sending the appropriate alert.
 func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
+	if !c.config.acceptsPeerCertificateType(CertificateTypeX509) {
+		c.sendAlert(alertUnsupportedCertificate)
+		return errors.New("tls: server sent an X.509 certificate, which is not in PeerCertificateTypes")
+	}
+
 	certs := make([]*x509.Certificate, len(certificates))
 	for i, asn1Data := range certificates {
 		cert, err := globalCertCache.newCert(asn1Data)
@@ -1145,6 +1298,13 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 			}
 		}
 	} else if !c.config.InsecureSkipVerify {
+		// An empty ServerName is only allowed when the server is expected
+		// to authenticate with an external PSK, in which case it must not
+		// fall back to a certificate that can't be matched to a name.
+		if c.config.ServerName == "" {
+			c.sendAlert(alertBadCertificate)
+			return errors.New("tls: server sent a certificate, but ServerName is not set")
+		}
 		opts := x509.VerifyOptions{
 			Roots:         c.config.RootCAs,
 			CurrentTime:   c.config.time(),
@@ -1195,6 +1355,52 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 	return nil
 }
 
+// verifyPeerRawPublicKey parses and verifies the raw public key presented by
+// the peer in place of a certificate chain (see RFC 7250), setting
+// c.peerRawPublicKey or sending the appropriate alert.
+func (c *Conn) verifyPeerRawPublicKey(certificates [][]byte) error {
+	if len(certificates) != 1 {
+		c.sendAlert(alertDecodeError)
+		return errors.New("tls: peer sent more than one raw public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(certificates[0])
+	if err != nil {
+		c.sendAlert(alertBadCertificate)
+		return errors.New("tls: failed to parse raw public key from peer: " + err.Error())
+	}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if max, ok := checkKeySize(pub.N.BitLen()); !ok {
+			c.sendAlert(alertBadCertificate)
+			return fmt.Errorf("tls: peer sent RSA raw public key larger than %d bits", max)
+		}
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		c.sendAlert(alertUnsupportedCertificate)
+		return fmt.Errorf("tls: peer sent an unsupported type of raw public key: %T", pub)
+	}
+
+	if c.config.VerifyRawPublicKey == nil {
+		c.sendAlert(alertBadCertificate)
+		return errors.New("tls: peer sent a raw public key, but VerifyRawPublicKey is not set")
+	}
+	if err := c.config.VerifyRawPublicKey(pub); err != nil {
+		c.sendAlert(alertBadCertificate)
+		return err
+	}
+
+	c.peerRawPublicKey = pub
+
+	if c.config.VerifyConnection != nil {
+		if err := c.config.VerifyConnection(c.connectionStateLocked()); err != nil {
+			c.sendAlert(alertBadCertificate)
+			return err
+		}
+	}
+
+	return nil
+}
+
 // certificateRequestInfoFromMsg generates a CertificateRequestInfo from a TLS
 // <= 1.2 CertificateRequest, making an effort to fill in missing information.
 func certificateRequestInfoFromMsg(ctx context.Context, vers uint16, certReq *certificateRequestMsg) *CertificateRequestInfo {
@@ -1309,12 +1515,44 @@ func hostnameInSNI(name string) string {
 	return name
 }
 
-func computeAndUpdatePSK(m *clientHelloMsg, binderKey []byte, transcript hash.Hash, finishedHash func([]byte, hash.Hash) []byte) error {
+// computeAndUpdatePSK computes the binder of the resumption PSK of m, which
+// uses the key schedule of suite, and updates m. Resumption is not supported
+// over DTLS.
+func computeAndUpdatePSK(m *clientHelloMsg, binderKey []byte, transcript hash.Hash, suite *cipherSuiteTLS13) error {
 	helloBytes, err := m.marshalWithoutBinders()
 	if err != nil {
 		return err
 	}
 	transcript.Write(helloBytes)
-	pskBinders := [][]byte{finishedHash(binderKey, transcript)}
+	pskBinders := [][]byte{suite.finishedHash(false, binderKey, transcript)}
+	return m.updateBinders(pskBinders)
+}
+
+// computeAndUpdateExternalPSKBinders computes the binders of the external
+// psks, which must match m.pskIdentities, and updates m. If transcript is not
+// nil, it holds the messages preceding m, and all psks must share its hash.
+// If dtls is true, the binders use the DTLS 1.3 key schedule.
+// See RFC 8446, Section 4.2.11.2.
+func computeAndUpdateExternalPSKBinders(m *clientHelloMsg, psks []ExternalPSK, transcript hash.Hash, dtls bool) error {
+	helloBytes, err := m.marshalWithoutBinders()
+	if err != nil {
+		return err
+	}
+	pskBinders := make([][]byte, 0, len(psks))
+	for _, psk := range psks {
+		suite := psk.suite()
+		var pskTranscript hash.Hash
+		if transcript != nil {
+			pskTranscript = cloneHash(transcript, suite.hash)
+			if pskTranscript == nil {
+				return errors.New("tls: internal error: failed to clone hash")
+			}
+		} else {
+			pskTranscript = suite.hash.New()
+		}
+		pskTranscript.Write(helloBytes)
+		binderKey := newEarlySecret(suite, dtls, psk.Key).ExternalBinderKey()
+		pskBinders = append(pskBinders, suite.finishedHash(dtls, binderKey, pskTranscript))
+	}
 	return m.updateBinders(pskBinders)
 }
@@ -27,22 +27,32 @@ type clientHandshakeStateTLS13 struct {
 	keyShareKeys *keySharePrivateKeys
 
 	session     *SessionState
-	earlySecret *tls13.EarlySecret
+	earlySecret *earlySecret
 	binderKey   []byte
 
+	// externalPSKs are the external PSKs offered in hello, in the order of
+	// hello.pskIdentities. They are mutually exclusive with session.
+	externalPSKs []ExternalPSK
+
+	// serverCertType and clientCertType are the negotiated kinds of
+	// credentials of the server and of the client. See RFC 7250.
+	serverCertType CertificateType
+	clientCertType CertificateType
+
 	certReq       *certificateRequestMsgTLS13
 	usingPSK      bool
 	sentDummyCCS  bool
 	suite         *cipherSuiteTLS13
 	transcript    hash.Hash
-	masterSecret  *tls13.MasterSecret
+	masterSecret  *masterSecret
 	trafficSecret []byte // client_application_traffic_secret_0
 
 	echContext *echClientContext
 }
 
 // handshake requires hs.c, hs.hello, hs.serverHello, hs.keyShareKeys, and,
-// optionally, hs.session, hs.earlySecret and hs.binderKey to be set.
+// optionally, hs.session, hs.earlySecret and hs.binderKey, or hs.externalPSKs,
+// to be set.
 func (hs *clientHandshakeStateTLS13) handshake() error {
 	c := hs.c
 
@@ -101,6 +111,7 @@ func (hs *clientHandshakeStateTLS13) handshake() error {
 			c.serverName = c.config.ServerName
 			hs.transcript = hs.echContext.innerTranscript
 			c.echAccepted = true
+			c.traceHandshake(HandshakeEvent{Kind: HandshakeECHAccepted})
 
 			if hs.serverHello.encryptedClientHello != nil {
 				c.sendAlert(alertUnsupportedExtension)
@@ -113,6 +124,7 @@ func (hs *clientHandshakeStateTLS13) handshake() error {
 			}
 		} else {
 			hs.echContext.echRejected = true
+			c.traceHandshake(HandshakeEvent{Kind: HandshakeECHRejected})
 		}
 	}
 
@@ -169,12 +181,17 @@ func (hs *clientHandshakeStateTLS13) checkServerHelloOrHRR() error {
 		return errors.New("tls: server selected TLS 1.3 using the legacy version field")
 	}
 
-	if hs.serverHello.supportedVersion != VersionTLS13 {
+	supportedVersion, legacyVersion := uint16(VersionTLS13), uint16(VersionTLS12)
+	if c.dtls != nil {
+		supportedVersion, legacyVersion = VersionDTLS13, VersionDTLS12
+	}
+
+	if hs.serverHello.supportedVersion != supportedVersion {
 		c.sendAlert(alertIllegalParameter)
 		return errors.New("tls: server selected an invalid version after a HelloRetryRequest")
 	}
 
-	if hs.serverHello.vers != VersionTLS12 {
+	if hs.serverHello.vers != legacyVersion {
 		c.sendAlert(alertIllegalParameter)
 		return errors.New("tls: server sent an incorrect legacy version")
 	}
@@ -211,6 +228,7 @@ func (hs *clientHandshakeStateTLS13) checkServerHelloOrHRR() error {
 	}
 	hs.suite = selectedSuite
 	c.cipherSuite = hs.suite.id
+	c.traceCipherSuite()
 
 	return nil
 }
@@ -218,7 +236,7 @@ func (hs *clientHandshakeStateTLS13) checkServerHelloOrHRR() error {
 // sendDummyChangeCipherSpec sends a ChangeCipherSpec record for compatibility
 // with middleboxes that didn't implement TLS correctly. See RFC 8446, Appendix D.4.
 func (hs *clientHandshakeStateTLS13) sendDummyChangeCipherSpec() error {
-	if hs.c.quic != nil {
+	if hs.c.quic != nil || hs.c.dtls != nil {
 		return nil
 	}
 	if hs.sentDummyCCS {
@@ -233,6 +251,7 @@ func (hs *clientHandshakeStateTLS13) sendDummyChangeCipherSpec() error {
 // resends hs.hello, and reads the new ServerHello into hs.serverHello.
 func (hs *clientHandshakeStateTLS13) processHelloRetryRequest() error {
 	c := hs.c
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeHelloRetryRequest, Group: hs.serverHello.selectedGroup})
 
 	// The first ClientHello gets double-hashed into the transcript upon a
 	// HelloRetryRequest. (The idea is that the server might offload transcript
@@ -333,7 +352,34 @@ func (hs *clientHandshakeStateTLS13) processHelloRetryRequest() error {
 		hello.keyShares = hello.keyShares[:1]
 	}
 
-	if len(hello.pskIdentities) > 0 {
+	if len(hs.externalPSKs) > 0 {
+		// Only keep offering the external PSKs compatible with the selected
+		// cipher suite, and recompute their binders over the new transcript.
+		var psks []ExternalPSK
+		hello.pskIdentities, hello.pskBinders = nil, nil
+		for _, psk := range hs.externalPSKs {
+			if psk.suite().hash != hs.suite.hash {
+				continue
+			}
+			psks = append(psks, psk)
+			hello.pskIdentities = append(hello.pskIdentities, pskIdentity{label: psk.Identity})
+			hello.pskBinders = append(hello.pskBinders, make([]byte, hs.suite.hash.Size()))
+		}
+		hs.externalPSKs = psks
+
+		if len(psks) > 0 {
+			transcript := hs.suite.hash.New()
+			transcript.Write([]byte{typeMessageHash, 0, 0, uint8(len(chHash))})
+			transcript.Write(chHash)
+			if err := transcriptMsg(hs.serverHello, transcript); err != nil {
+				return err
+			}
+
+			if err := computeAndUpdateExternalPSKBinders(hello, psks, transcript, c.dtls != nil); err != nil {
+				return err
+			}
+		}
+	} else if len(hello.pskIdentities) > 0 {
 		pskSuite := cipherSuiteTLS13ByID(hs.session.cipherSuite)
 		if pskSuite == nil {
 			return c.sendAlert(alertInternalError)
@@ -350,7 +396,7 @@ func (hs *clientHandshakeStateTLS13) processHelloRetryRequest() error {
 				return err
 			}
 
-			if err := computeAndUpdatePSK(hello, hs.binderKey, transcript, hs.suite.finishedHash); err != nil {
+			if err := computeAndUpdatePSK(hello, hs.binderKey, transcript, hs.suite); err != nil {
 				return err
 			}
 		} else {
@@ -438,6 +484,9 @@ func (hs *clientHandshakeStateTLS13) processServerHello() error {
 	}
 
 	if !hs.serverHello.selectedIdentityPresent {
+		if hs.session != nil {
+			c.traceSessionNotResumed("server did not select the offered session")
+		}
 		return nil
 	}
 
@@ -446,6 +495,20 @@ func (hs *clientHandshakeStateTLS13) processServerHello() error {
 		return errors.New("tls: server selected an invalid PSK")
 	}
 
+	if len(hs.externalPSKs) > 0 {
+		psk := hs.externalPSKs[hs.serverHello.selectedIdentity]
+		pskSuite := psk.suite()
+		if pskSuite.hash != hs.suite.hash {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: server selected an invalid PSK and cipher suite pair")
+		}
+
+		hs.usingPSK = true
+		hs.earlySecret = newEarlySecret(hs.suite, c.dtls != nil, psk.Key)
+		c.pskIdentity = psk.Identity
+		return nil
+	}
+
 	if len(hs.hello.pskIdentities) != 1 || hs.session == nil {
 		return c.sendAlert(alertInternalError)
 	}
@@ -460,6 +523,7 @@ func (hs *clientHandshakeStateTLS13) processServerHello() error {
 
 	hs.usingPSK = true
 	c.didResume = true
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeSessionResumed})
 	c.peerCertificates = hs.session.peerCertificates
 	c.verifiedChains = hs.session.verifiedChains
 	c.ocspResponse = hs.session.ocspResponse
@@ -481,10 +545,11 @@ func (hs *clientHandshakeStateTLS13) establishHandshakeKeys() error {
 		return errors.New("tls: invalid server key share")
 	}
 	c.curveID = hs.serverHello.serverShare.group
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeGroupSelected, Group: c.curveID})
 
 	earlySecret := hs.earlySecret
 	if !hs.usingPSK {
-		earlySecret = tls13.NewEarlySecret(hs.suite.hash.New, nil)
+		earlySecret = newEarlySecret(hs.suite, c.dtls != nil, nil)
 	}
 
 	handshakeSecret := earlySecret.HandshakeSecret(sharedKey)
@@ -583,6 +648,22 @@ func (hs *clientHandshakeStateTLS13) readServerParameters() error {
 		}
 	}
 
+	// See RFC 7250, Section 4.2.
+	if encryptedExtensions.serverCertTypePresent {
+		if !slices.Contains(hs.hello.serverCertTypes, encryptedExtensions.serverCertType) {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: server selected an unadvertised server certificate type")
+		}
+		hs.serverCertType = encryptedExtensions.serverCertType
+	}
+	if encryptedExtensions.clientCertTypePresent {
+		if !slices.Contains(hs.hello.clientCertTypes, encryptedExtensions.clientCertType) {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: server selected an unadvertised client certificate type")
+		}
+		hs.clientCertType = encryptedExtensions.clientCertType
+	}
+
 	return nil
 }
 
@@ -629,11 +710,17 @@ func (hs *clientHandshakeStateTLS13) readServerCertificate() error {
 		return errors.New("tls: received empty certificates message")
 	}
 
-	c.scts = certMsg.certificate.SignedCertificateTimestamps
-	c.ocspResponse = certMsg.certificate.OCSPStaple
+	if hs.serverCertType == CertificateTypeRawPublicKey {
+		if err := c.verifyPeerRawPublicKey(certMsg.certificate.Certificate); err != nil {
+			return err
+		}
+	} else {
+		c.scts = certMsg.certificate.SignedCertificateTimestamps
+		c.ocspResponse = certMsg.certificate.OCSPStaple
 
-	if err := c.verifyServerCertificate(certMsg.certificate.Certificate); err != nil {
-		return err
+		if err := c.verifyServerCertificate(certMsg.certificate.Certificate); err != nil {
+			return err
+		}
 	}
 
 	// certificateVerifyMsg is included in the transcript, but not until
@@ -654,7 +741,7 @@ func (hs *clientHandshakeStateTLS13) readServerCertificate() error {
 	// We don't use hs.hello.supportedSignatureAlgorithms because it might
 	// include PKCS#1 v1.5 and SHA-1 if the ClientHello also supported TLS 1.2.
 	if !isSupportedSignatureAlgorithm(certVerify.signatureAlgorithm, supportedSignatureAlgorithms(c.vers)) ||
-		!isSupportedSignatureAlgorithm(certVerify.signatureAlgorithm, signatureSchemesForPublicKey(c.vers, c.peerCertificates[0].PublicKey)) {
+		!isSupportedSignatureAlgorithm(certVerify.signatureAlgorithm, signatureSchemesForPublicKey(c.vers, c.peerPublicKey())) {
 		c.sendAlert(alertIllegalParameter)
 		return errors.New("tls: certificate used with invalid signature algorithm")
 	}
@@ -665,8 +752,9 @@ func (hs *clientHandshakeStateTLS13) readServerCertificate() error {
 	if sigType == signaturePKCS1v15 || sigHash == crypto.SHA1 {
 		return c.sendAlert(alertInternalError)
 	}
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: certVerify.signatureAlgorithm, Peer: true})
 	signed := signedMessage(serverSignatureContext, hs.transcript)
-	if err := verifyHandshakeSignature(sigType, c.peerCertificates[0].PublicKey,
+	if err := verifyHandshakeSignature(sigType, c.peerPublicKey(),
 		sigHash, signed, certVerify.signature); err != nil {
 		c.sendAlert(alertDecryptError)
 		return errors.New("tls: invalid signature by the server certificate: " + err.Error())
@@ -697,7 +785,7 @@ func (hs *clientHandshakeStateTLS13) readServerFinished() error {
 		return unexpectedMessageError(finished, msg)
 	}
 
-	expectedMAC := hs.suite.finishedHash(c.in.trafficSecret, hs.transcript)
+	expectedMAC := hs.suite.finishedHash(hs.c.dtls != nil, c.in.trafficSecret, hs.transcript)
 	if !hmac.Equal(expectedMAC, finished.verifyData) {
 		c.sendAlert(alertDecryptError)
 		return errors.New("tls: invalid server finished hash")
@@ -749,17 +837,23 @@ func (hs *clientHandshakeStateTLS13) sendClientCertificate() error {
 		AcceptableCAs:    hs.certReq.certificateAuthorities,
 		SignatureSchemes: hs.certReq.supportedSignatureAlgorithms,
 		Version:          c.vers,
+		CertificateType:  hs.clientCertType,
 		ctx:              hs.ctx,
 	})
 	if err != nil {
 		return err
 	}
+	if len(cert.Certificate) > 0 && cert.Type != hs.clientCertType {
+		c.sendAlert(alertInternalError)
+		return errors.New("tls: client certificate type does not match the type negotiated with the server")
+	}
 
 	certMsg := new(certificateMsgTLS13)
 
 	certMsg.certificate = *cert
-	certMsg.scts = hs.certReq.scts && len(cert.SignedCertificateTimestamps) > 0
-	certMsg.ocspStapling = hs.certReq.ocspStapling && len(cert.OCSPStaple) > 0
+	isX509 := cert.Type == CertificateTypeX509
+	certMsg.scts = isX509 && hs.certReq.scts && len(cert.SignedCertificateTimestamps) > 0
+	certMsg.ocspStapling = isX509 && hs.certReq.ocspStapling && len(cert.OCSPStaple) > 0
 
 	if _, err := hs.c.writeHandshakeRecord(certMsg, hs.transcript); err != nil {
 		return err
@@ -785,6 +879,7 @@ func (hs *clientHandshakeStateTLS13) sendClientCertificate() error {
 	if err != nil {
 		return c.sendAlert(alertInternalError)
 	}
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: certVerifyMsg.signatureAlgorithm})
 
 	signed := signedMessage(clientSignatureContext, hs.transcript)
 	signOpts := crypto.SignerOpts(sigHash)
@@ -809,7 +904,7 @@ func (hs *clientHandshakeStateTLS13) sendClientFinished() error {
 	c := hs.c
 
 	finished := &finishedMsg{
-		verifyData: hs.suite.finishedHash(c.out.trafficSecret, hs.transcript),
+		verifyData: hs.suite.finishedHash(hs.c.dtls != nil, c.out.trafficSecret, hs.transcript),
 	}
 
 	if _, err := hs.c.writeHandshakeRecord(finished, hs.transcript); err != nil {
@@ -839,6 +934,13 @@ func (c *Conn) handleNewSessionTicket(msg *newSessionTicketMsgTLS13) error {
 		return nil
 	}
 
+	// Sessions are only resumed on the basis of a verified certificate chain,
+	// so there is no use for tickets issued to connections authenticated
+	// otherwise.
+	if c.pskIdentity != nil || c.peerRawPublicKey != nil {
+		return nil
+	}
+
 	// See RFC 8446, Section 4.6.1.
 	if msg.lifetime == 0 {
 		return nil
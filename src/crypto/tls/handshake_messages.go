@@ -97,8 +97,16 @@ type clientHelloMsg struct {
 	pskBinders                       [][]byte
 	quicTransportParameters          []byte
 	encryptedClientHello             []byte
+	serverCertTypes                  []CertificateType
+	clientCertTypes                  []CertificateType
 	// extensions are only populated on the server-side of a handshake
 	extensions []uint16
+	// dtls is set for DTLS ClientHellos, which have a cookie field after the
+	// session ID. It carries the HelloVerifyRequest cookie in DTLS 1.2, and
+	// must be empty in DTLS 1.3. See RFC 6347, Section 4.2.1 and RFC 9147,
+	// Section 5.3.
+	dtls       bool
+	dtlsCookie []byte
 }
 
 func (m *clientHelloMsg) marshalMsg(echInner bool) ([]byte, error) {
@@ -168,6 +176,20 @@ func (m *clientHelloMsg) marshalMsg(echInner bool) ([]byte, error) {
 			exts.AddBytes(m.encryptedClientHello)
 		})
 	}
+	if len(m.clientCertTypes) > 0 {
+		// RFC 7250, Section 4.1
+		exts.AddUint16(extensionClientCertificateType)
+		exts.AddUint16LengthPrefixed(func(exts *cryptobyte.Builder) {
+			addCertificateTypes(exts, m.clientCertTypes)
+		})
+	}
+	if len(m.serverCertTypes) > 0 {
+		// RFC 7250, Section 4.1
+		exts.AddUint16(extensionServerCertificateType)
+		exts.AddUint16LengthPrefixed(func(exts *cryptobyte.Builder) {
+			addCertificateTypes(exts, m.serverCertTypes)
+		})
+	}
 	// Note that any extension that can be compressed during ECH must be
 	// contiguous. If any additional extensions are to be compressed they must
 	// be added to the following block, so that they can be properly
@@ -353,6 +375,11 @@ func (m *clientHelloMsg) marshalMsg(echInner bool) ([]byte, error) {
 				b.AddBytes(m.sessionId)
 			}
 		})
+		if m.dtls {
+			b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddBytes(m.dtlsCookie)
+			})
+		}
 		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
 			for _, suite := range m.cipherSuites {
 				b.AddUint16(suite)
@@ -416,7 +443,7 @@ func (m *clientHelloMsg) updateBinders(pskBinders [][]byte) error {
 }
 
 func (m *clientHelloMsg) unmarshal(data []byte) bool {
-	*m = clientHelloMsg{original: data}
+	*m = clientHelloMsg{original: data, dtls: m.dtls}
 	s := cryptobyte.String(data)
 
 	if !s.Skip(4) || // message type and uint24 length field
@@ -424,6 +451,9 @@ func (m *clientHelloMsg) unmarshal(data []byte) bool {
 		!readUint8LengthPrefixed(&s, &m.sessionId) {
 		return false
 	}
+	if m.dtls && !readUint8LengthPrefixed(&s, &m.dtlsCookie) {
+		return false
+	}
 
 	var cipherSuites cryptobyte.String
 	if !s.ReadUint16LengthPrefixed(&cipherSuites) {
@@ -666,6 +696,16 @@ func (m *clientHelloMsg) unmarshal(data []byte) bool {
 			if !extData.ReadBytes(&m.encryptedClientHello, len(extData)) {
 				return false
 			}
+		case extensionClientCertificateType:
+			// RFC 7250, Section 4.1
+			if !readCertificateTypes(&extData, &m.clientCertTypes) {
+				return false
+			}
+		case extensionServerCertificateType:
+			// RFC 7250, Section 4.1
+			if !readCertificateTypes(&extData, &m.serverCertTypes) {
+				return false
+			}
 		default:
 			// Ignore unknown extensions.
 			continue
@@ -679,6 +719,29 @@ func (m *clientHelloMsg) unmarshal(data []byte) bool {
 	return true
 }
 
+func addCertificateTypes(b *cryptobyte.Builder, types []CertificateType) {
+	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, t := range types {
+			b.AddUint8(uint8(t))
+		}
+	})
+}
+
+func readCertificateTypes(s *cryptobyte.String, out *[]CertificateType) bool {
+	var types cryptobyte.String
+	if !s.ReadUint8LengthPrefixed(&types) || types.Empty() {
+		return false
+	}
+	for !types.Empty() {
+		var t uint8
+		if !types.ReadUint8(&t) {
+			return false
+		}
+		*out = append(*out, CertificateType(t))
+	}
+	return true
+}
+
 func (m *clientHelloMsg) originalBytes() []byte {
 	return m.original
 }
@@ -713,6 +776,10 @@ func (m *clientHelloMsg) clone() *clientHelloMsg {
 		pskBinders:                       slices.Clone(m.pskBinders),
 		quicTransportParameters:          slices.Clone(m.quicTransportParameters),
 		encryptedClientHello:             slices.Clone(m.encryptedClientHello),
+		serverCertTypes:                  slices.Clone(m.serverCertTypes),
+		clientCertTypes:                  slices.Clone(m.clientCertTypes),
+		dtls:                             m.dtls,
+		dtlsCookie:                       slices.Clone(m.dtlsCookie),
 	}
 }
 
@@ -1006,6 +1073,10 @@ type encryptedExtensionsMsg struct {
 	earlyData               bool
 	echRetryConfigs         []byte
 	serverNameAck           bool
+	serverCertTypePresent   bool
+	serverCertType          CertificateType
+	clientCertTypePresent   bool
+	clientCertType          CertificateType
 }
 
 func (m *encryptedExtensionsMsg) marshal() ([]byte, error) {
@@ -1045,6 +1116,18 @@ func (m *encryptedExtensionsMsg) marshal() ([]byte, error) {
 				b.AddUint16(extensionServerName)
 				b.AddUint16(0) // empty extension_data
 			}
+			if m.clientCertTypePresent {
+				// RFC 7250, Section 4.2
+				b.AddUint16(extensionClientCertificateType)
+				b.AddUint16(1) // extension_data length
+				b.AddUint8(uint8(m.clientCertType))
+			}
+			if m.serverCertTypePresent {
+				// RFC 7250, Section 4.2
+				b.AddUint16(extensionServerCertificateType)
+				b.AddUint16(1) // extension_data length
+				b.AddUint8(uint8(m.serverCertType))
+			}
 		})
 	})
 
@@ -1105,6 +1188,20 @@ func (m *encryptedExtensionsMsg) unmarshal(data []byte) bool {
 				return false
 			}
 			m.serverNameAck = true
+		case extensionClientCertificateType:
+			var t uint8
+			if !extData.ReadUint8(&t) {
+				return false
+			}
+			m.clientCertTypePresent = true
+			m.clientCertType = CertificateType(t)
+		case extensionServerCertificateType:
+			var t uint8
+			if !extData.ReadUint8(&t) {
+				return false
+			}
+			m.serverCertTypePresent = true
+			m.serverCertType = CertificateType(t)
 		default:
 			// Ignore unknown extensions.
 			continue
@@ -1931,6 +2028,34 @@ func (*helloRequestMsg) unmarshal(data []byte) bool {
 	return len(data) == 4
 }
 
+// helloVerifyRequestMsg is the DTLS 1.2 HelloVerifyRequest message. See RFC
+// 6347, Section 4.2.1.
+type helloVerifyRequestMsg struct {
+	vers   uint16
+	cookie []byte
+}
+
+func (m *helloVerifyRequestMsg) marshal() ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddUint8(typeHelloVerifyRequest)
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16(m.vers)
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(m.cookie)
+		})
+	})
+	return b.Bytes()
+}
+
+func (m *helloVerifyRequestMsg) unmarshal(data []byte) bool {
+	*m = helloVerifyRequestMsg{}
+	s := cryptobyte.String(data)
+	return s.Skip(4) && // message type and uint24 length field
+		s.ReadUint16(&m.vers) &&
+		readUint8LengthPrefixed(&s, &m.cookie) &&
+		s.Empty()
+}
+
 type transcriptHash interface {
 	Write([]byte) (int, error)
 }
@@ -36,6 +36,7 @@ var tests = []handshakeMessage{
 	&newSessionTicketMsgTLS13{},
 	&certificateRequestMsgTLS13{},
 	&certificateMsgTLS13{},
+	&helloVerifyRequestMsg{},
 	&SessionState{},
 }
 
@@ -231,6 +232,16 @@ func (*clientHelloMsg) Generate(rand *rand.Rand, size int) reflect.Value {
 	if rand.Intn(10) > 5 {
 		m.encryptedClientHello = randomBytes(rand.Intn(50)+1, rand)
 	}
+	if rand.Intn(10) > 5 {
+		for i := 0; i < rand.Intn(3)+1; i++ {
+			m.serverCertTypes = append(m.serverCertTypes, CertificateType(rand.Intn(256)))
+		}
+	}
+	if rand.Intn(10) > 5 {
+		for i := 0; i < rand.Intn(3)+1; i++ {
+			m.clientCertTypes = append(m.clientCertTypes, CertificateType(rand.Intn(256)))
+		}
+	}
 
 	return reflect.ValueOf(m)
 }
@@ -302,6 +313,14 @@ func (*encryptedExtensionsMsg) Generate(rand *rand.Rand, size int) reflect.Value
 	if rand.Intn(10) > 5 {
 		m.earlyData = true
 	}
+	if rand.Intn(10) > 5 {
+		m.serverCertTypePresent = true
+		m.serverCertType = CertificateType(rand.Intn(256))
+	}
+	if rand.Intn(10) > 5 {
+		m.clientCertTypePresent = true
+		m.clientCertType = CertificateType(rand.Intn(256))
+	}
 
 	return reflect.ValueOf(m)
 }
@@ -351,6 +370,13 @@ func (*finishedMsg) Generate(rand *rand.Rand, size int) reflect.Value {
 	return reflect.ValueOf(m)
 }
 
+func (*helloVerifyRequestMsg) Generate(rand *rand.Rand, size int) reflect.Value {
+	m := &helloVerifyRequestMsg{}
+	m.vers = uint16(rand.Intn(65536))
+	m.cookie = randomBytes(rand.Intn(256), rand)
+	return reflect.ValueOf(m)
+}
+
 func (*newSessionTicketMsg) Generate(rand *rand.Rand, size int) reflect.Value {
 	m := &newSessionTicketMsg{}
 	m.ticket = randomBytes(rand.Intn(4), rand)
@@ -147,7 +147,7 @@ func (c *Conn) readClientHello(ctx context.Context) (*clientHelloMsg, *echServer
 	// ECH processing has to be done before we do any other negotiation based on
 	// the contents of the client hello, since we may swap it out completely.
 	var ech *echServerContext
-	if len(clientHello.encryptedClientHello) != 0 {
+	if len(clientHello.encryptedClientHello) != 0 && c.dtls == nil {
 		echKeys := c.config.EncryptedClientHelloKeys
 		if c.config.GetEncryptedClientHelloKeys != nil {
 			echKeys, err = c.config.GetEncryptedClientHelloKeys(clientHelloInfo(ctx, c, clientHello))
@@ -176,7 +176,26 @@ func (c *Conn) readClientHello(ctx context.Context) (*clientHelloMsg, *echServer
 	c.ticketKeys = originalConfig.ticketKeys(configForClient)
 
 	clientVersions := clientHello.supportedVersions
-	if clientHello.vers >= VersionTLS13 && len(clientVersions) == 0 {
+	if c.dtls != nil {
+		// DTLS 1.2 and DTLS 1.3 are negotiated as TLS 1.2 and TLS 1.3, which
+		// the config must allow.
+		if fips140tls.Required() {
+			c.sendAlert(alertProtocolVersion)
+			return nil, nil, errDTLSFIPS
+		}
+		// DTLS version numbers decrease, so like above, a legacy version of
+		// DTLS 1.2 or later without supported_versions means DTLS 1.2.
+		if len(clientVersions) == 0 && clientHello.vers <= VersionDTLS12 {
+			clientVersions = []uint16{VersionDTLS12}
+		}
+		var versions []uint16
+		for _, v := range clientVersions {
+			if v := tlsVersionForDTLS(v); v != 0 {
+				versions = append(versions, v)
+			}
+		}
+		clientVersions = versions
+	} else if clientHello.vers >= VersionTLS13 && len(clientVersions) == 0 {
 		// RFC 8446 4.2.1 indicates when the supported_versions extension is not sent,
 		// compatible servers MUST negotiate TLS 1.2 or earlier if supported, even
 		// if the client legacy version is TLS 1.3 or later.
@@ -188,7 +207,7 @@ func (c *Conn) readClientHello(ctx context.Context) (*clientHelloMsg, *echServer
 		clientVersions = supportedVersionsFromMax(clientHello.vers)
 	}
 	c.vers, ok = c.config.mutualVersion(roleServer, clientVersions)
-	if !ok {
+	if !ok || c.dtls != nil && c.vers < VersionTLS12 {
 		c.sendAlert(alertProtocolVersion)
 		return nil, nil, fmt.Errorf("tls: client offered only unsupported versions: %x", clientVersions)
 	}
@@ -196,6 +215,13 @@ func (c *Conn) readClientHello(ctx context.Context) (*clientHelloMsg, *echServer
 	c.in.version = c.vers
 	c.out.version = c.vers
 
+	if c.dtls != nil && c.vers == VersionTLS12 {
+		clientHello, err = c.dtlsVerifyClientHello(clientHello)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// This check reflects some odd specification implied behavior. Client-facing servers
 	// are supposed to reject hellos with outer ECH and inner ECH that offers 1.2, but
 	// backend servers are allowed to accept hellos with inner ECH that offer 1.2, since
@@ -216,11 +242,68 @@ func (c *Conn) readClientHello(ctx context.Context) (*clientHelloMsg, *echServer
 	return clientHello, ech, nil
 }
 
+// dtlsVerifyClientHello responds to the DTLS 1.2 ClientHello ch1 with a
+// HelloVerifyRequest, and returns the ClientHello that the client sends back
+// with the cookie, which proves that it can receive datagrams at its address.
+// Neither message is part of the transcript. See RFC 6347, Section 4.2.1.
+//
+// A stateless server instead returns ch1 if it carries a valid cookie, and
+// otherwise sends a HelloVerifyRequest and returns errDTLSCookieSent.
+func (c *Conn) dtlsVerifyClientHello(ch1 *clientHelloMsg) (*clientHelloMsg, error) {
+	if d := c.dtls; d.cookieKey != nil {
+		addr := c.conn.RemoteAddr()
+		if _, ok := dtlsOpenCookie(d.cookieKey, addr, ch1.random, ch1.dtlsCookie); ok {
+			if d.cookieProbe {
+				return nil, errDTLSCookieValid
+			}
+			return ch1, nil
+		}
+		hvr := &helloVerifyRequestMsg{vers: versionDTLS10, cookie: dtlsSealCookie(d.cookieKey, addr, ch1.random, nil)}
+		if _, err := c.writeHandshakeRecord(hvr, nil); err != nil {
+			return nil, err
+		}
+		return nil, errDTLSCookieSent
+	}
+
+	hvr := &helloVerifyRequestMsg{vers: versionDTLS10, cookie: make([]byte, 32)}
+	if _, err := io.ReadFull(c.config.rand(), hvr.cookie); err != nil {
+		c.sendAlert(alertInternalError)
+		return nil, err
+	}
+	if _, err := c.writeHandshakeRecord(hvr, nil); err != nil {
+		return nil, err
+	}
+
+	msg, err := c.readHandshake(nil)
+	if err != nil {
+		return nil, err
+	}
+	ch, ok := msg.(*clientHelloMsg)
+	if !ok {
+		c.sendAlert(alertUnexpectedMessage)
+		return nil, unexpectedMessageError(ch, msg)
+	}
+	if subtle.ConstantTimeCompare(ch.dtlsCookie, hvr.cookie) != 1 {
+		c.sendAlert(alertHandshakeFailure)
+		return nil, errors.New("tls: client sent an invalid DTLS cookie")
+	}
+	ch1 = ch1.clone()
+	ch1.dtlsCookie = hvr.cookie
+	if illegalClientHelloChange(ch, ch1) {
+		c.sendAlert(alertIllegalParameter)
+		return nil, errors.New("tls: client illegally modified second ClientHello")
+	}
+	return ch, nil
+}
+
 func (hs *serverHandshakeState) processClientHello() error {
 	c := hs.c
 
 	hs.hello = new(serverHelloMsg)
 	hs.hello.vers = c.vers
+	if c.dtls != nil {
+		hs.hello.vers = VersionDTLS12
+	}
 
 	foundCompression := false
 	// We only support null compression, so check that the client offered it.
@@ -283,6 +366,10 @@ func (hs *serverHandshakeState) processClientHello() error {
 		}
 		return err
 	}
+	if hs.cert.Type != CertificateTypeX509 {
+		c.sendAlert(alertHandshakeFailure)
+		return errors.New("tls: raw public keys are only supported in TLS 1.3")
+	}
 	if hs.clientHello.scts {
 		hs.hello.scts = hs.cert.SignedCertificateTimestamps
 	}
@@ -405,6 +492,7 @@ func (hs *serverHandshakeState) pickCipherSuite() error {
 			hs.clientHello.cipherSuites)
 	}
 	c.cipherSuite = hs.suite.id
+	c.traceCipherSuite()
 
 	if c.config.CipherSuites == nil && !fips140tls.Required() && rsaKexCiphers[hs.suite.id] {
 		tlsrsakex.Value() // ensure godebug is initialized
@@ -418,7 +506,11 @@ func (hs *serverHandshakeState) pickCipherSuite() error {
 	for _, id := range hs.clientHello.cipherSuites {
 		if id == TLS_FALLBACK_SCSV {
 			// The client is doing a fallback connection. See RFC 7507.
-			if hs.clientHello.vers < c.config.maxSupportedVersion(roleServer) {
+			vers := hs.clientHello.vers
+			if c.dtls != nil {
+				vers = tlsVersionForDTLS(vers)
+			}
+			if vers < c.config.maxSupportedVersion(roleServer) {
 				c.sendAlert(alertInappropriateFallback)
 				return errors.New("tls: client using inappropriate protocol fallback")
 			}
@@ -430,6 +522,9 @@ func (hs *serverHandshakeState) pickCipherSuite() error {
 }
 
 func (hs *serverHandshakeState) cipherSuiteOk(c *cipherSuite) bool {
+	if hs.c.dtls != nil && !dtlsAllowedCipherSuite(c.id) {
+		return false
+	}
 	if c.flags&suiteECDHE != 0 {
 		if !hs.ecdheOk {
 			return false
@@ -454,7 +549,15 @@ func (hs *serverHandshakeState) cipherSuiteOk(c *cipherSuite) bool {
 func (hs *serverHandshakeState) checkForResumption() error {
 	c := hs.c
 
-	if c.config.SessionTicketsDisabled {
+	if c.config.SessionTicketsDisabled || c.dtls != nil {
+		return nil
+	}
+
+	// Only report the sessions that the client actually offered.
+	notResumed := func(reason string) error {
+		if len(hs.clientHello.sessionTicket) != 0 {
+			c.traceSessionNotResumed(reason)
+		}
 		return nil
 	}
 
@@ -465,17 +568,17 @@ func (hs *serverHandshakeState) checkForResumption() error {
 			return err
 		}
 		if ss == nil {
-			return nil
+			return notResumed("UnwrapSession returned no session")
 		}
 		sessionState = ss
 	} else {
 		plaintext := c.config.decryptTicket(hs.clientHello.sessionTicket, c.ticketKeys)
 		if plaintext == nil {
-			return nil
+			return notResumed("ticket could not be decrypted")
 		}
 		ss, err := ParseSessionState(plaintext)
 		if err != nil {
-			return nil
+			return notResumed("ticket could not be parsed")
 		}
 		sessionState = ss
 	}
@@ -485,12 +588,12 @@ func (hs *serverHandshakeState) checkForResumption() error {
 	// too long, weakening forward secrecy.
 	createdAt := time.Unix(int64(sessionState.createdAt), 0)
 	if c.config.time().Sub(createdAt) > maxSessionTicketLifetime {
-		return nil
+		return notResumed("session is expired")
 	}
 
 	// Never resume a session for a different TLS version.
 	if c.vers != sessionState.version {
-		return nil
+		return notResumed("session is for a different version")
 	}
 
 	cipherSuiteOk := false
@@ -502,26 +605,26 @@ func (hs *serverHandshakeState) checkForResumption() error {
 		}
 	}
 	if !cipherSuiteOk {
-		return nil
+		return notResumed("session cipher suite is not offered by the client")
 	}
 
 	// Check that we also support the ciphersuite from the session.
 	suite := selectCipherSuite([]uint16{sessionState.cipherSuite},
 		c.config.supportedCipherSuites(), hs.cipherSuiteOk)
 	if suite == nil {
-		return nil
+		return notResumed("session cipher suite is not supported")
 	}
 
 	sessionHasClientCerts := len(sessionState.peerCertificates) != 0
 	needClientCerts := requiresClientCert(c.config.ClientAuth)
 	if needClientCerts && !sessionHasClientCerts {
-		return nil
+		return notResumed("session lacks a required client certificate")
 	}
 	if sessionHasClientCerts && c.config.ClientAuth == NoClientCert {
-		return nil
+		return notResumed("session has an unrequested client certificate")
 	}
 	if sessionHasClientCerts && c.config.time().After(sessionState.peerCertificates[0].NotAfter) {
-		return nil
+		return notResumed("session client certificate is expired")
 	}
 	opts := x509.VerifyOptions{
 		CurrentTime: c.config.time(),
@@ -530,12 +633,12 @@ func (hs *serverHandshakeState) checkForResumption() error {
 	}
 	if sessionHasClientCerts && c.config.ClientAuth >= VerifyClientCertIfGiven &&
 		!anyValidVerifiedChain(sessionState.verifiedChains, opts) {
-		return nil
+		return notResumed("session client certificate chain is no longer valid")
 	}
 
 	// RFC 7627, Section 5.3
 	if !sessionState.extMasterSecret && hs.clientHello.extendedMasterSecret {
-		return nil
+		return notResumed("session lacks Extended Master Secret")
 	}
 	if sessionState.extMasterSecret && !hs.clientHello.extendedMasterSecret {
 		// Aborting is somewhat harsh, but it's a MUST and it would indicate a
@@ -544,7 +647,7 @@ func (hs *serverHandshakeState) checkForResumption() error {
 	}
 	if !sessionState.extMasterSecret && fips140tls.Required() {
 		// FIPS 140-3 requires the use of Extended Master Secret.
-		return nil
+		return notResumed("session lacks Extended Master Secret, required in FIPS 140-3 mode")
 	}
 
 	c.peerCertificates = sessionState.peerCertificates
@@ -556,6 +659,7 @@ func (hs *serverHandshakeState) checkForResumption() error {
 	hs.suite = suite
 	c.curveID = sessionState.curveID
 	c.didResume = true
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeSessionResumed})
 	return nil
 }
 
@@ -564,6 +668,7 @@ func (hs *serverHandshakeState) doResumeHandshake() error {
 
 	hs.hello.cipherSuite = hs.suite.id
 	c.cipherSuite = hs.suite.id
+	c.traceCipherSuite()
 	// We echo the client's session ID in the ServerHello to let it know
 	// that we're doing a resumption.
 	hs.hello.sessionId = hs.clientHello.sessionId
@@ -603,10 +708,11 @@ func (hs *serverHandshakeState) doFullHandshake() error {
 		hs.hello.serverNameAck = true
 	}
 
-	hs.hello.ticketSupported = hs.clientHello.ticketSupported && !c.config.SessionTicketsDisabled
+	hs.hello.ticketSupported = hs.clientHello.ticketSupported && !c.config.SessionTicketsDisabled && c.dtls == nil
 	hs.hello.cipherSuite = hs.suite.id
 
 	hs.finishedHash = newFinishedHash(hs.c.vers, hs.suite)
+	hs.finishedHash.dtls = hs.c.dtls
 	if c.config.ClientAuth == NoClientCert {
 		// No need to keep a full record of the handshake if client
 		// certificates won't be used.
@@ -643,6 +749,10 @@ func (hs *serverHandshakeState) doFullHandshake() error {
 		if keyAgreement, ok := keyAgreement.(*ecdheKeyAgreement); ok {
 			c.curveID = keyAgreement.curveID
 			c.peerSigAlg = keyAgreement.signatureAlgorithm
+			c.traceHandshake(HandshakeEvent{Kind: HandshakeGroupSelected, Group: c.curveID})
+			if c.vers >= VersionTLS12 {
+				c.traceHandshake(HandshakeEvent{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: c.peerSigAlg})
+			}
 		}
 		if _, err := hs.c.writeHandshakeRecord(skx, &hs.finishedHash); err != nil {
 			return err
@@ -781,6 +891,7 @@ func (hs *serverHandshakeState) doFullHandshake() error {
 			if err != nil {
 				return c.sendAlert(alertInternalError)
 			}
+			c.traceHandshake(HandshakeEvent{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: certVerify.signatureAlgorithm, Peer: true})
 			if sigHash == crypto.SHA1 {
 				tlssha1.Value() // ensure godebug is initialized
 				tlssha1.IncNonDefault()
@@ -939,6 +1050,10 @@ func (hs *serverHandshakeState) sendFinished(out []byte) error {
 // certificateMsg message or a certificateMsgTLS13 message and verifies them.
 func (c *Conn) processCertsFromClient(certificate Certificate) error {
 	certificates := certificate.Certificate
+	if len(certificates) > 0 && !c.config.acceptsPeerCertificateType(CertificateTypeX509) {
+		c.sendAlert(alertUnsupportedCertificate)
+		return errors.New("tls: client sent an X.509 certificate, which is not in PeerCertificateTypes")
+	}
 	certs := make([]*x509.Certificate, len(certificates))
 	var err error
 	for i, asn1Data := range certificates {
@@ -1025,17 +1140,18 @@ func clientHelloInfo(ctx context.Context, c *Conn, clientHello *clientHelloMsg)
 	}
 
 	return &ClientHelloInfo{
-		CipherSuites:      clientHello.cipherSuites,
-		ServerName:        clientHello.serverName,
-		SupportedCurves:   clientHello.supportedCurves,
-		SupportedPoints:   clientHello.supportedPoints,
-		SignatureSchemes:  clientHello.supportedSignatureAlgorithms,
-		SupportedProtos:   clientHello.alpnProtocols,
-		SupportedVersions: supportedVersions,
-		Extensions:        clientHello.extensions,
-		Conn:              c.conn,
-		HelloRetryRequest: c.didHRR,
-		config:            c.config,
-		ctx:               ctx,
+		CipherSuites:           clientHello.cipherSuites,
+		ServerName:             clientHello.serverName,
+		SupportedCurves:        clientHello.supportedCurves,
+		SupportedPoints:        clientHello.supportedPoints,
+		SignatureSchemes:       clientHello.supportedSignatureAlgorithms,
+		SupportedProtos:        clientHello.alpnProtocols,
+		SupportedVersions:      supportedVersions,
+		Extensions:             clientHello.extensions,
+		ServerCertificateTypes: clientHello.serverCertTypes,
+		Conn:                   c.conn,
+		HelloRetryRequest:      c.didHRR,
+		config:                 c.config,
+		ctx:                    ctx,
 	}
 }
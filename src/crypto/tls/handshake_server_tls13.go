@@ -53,14 +53,18 @@ type serverHandshakeStateTLS13 struct {
 	suite           *cipherSuiteTLS13
 	cert            *Certificate
 	sigAlg          SignatureScheme
-	earlySecret     *tls13.EarlySecret
+	earlySecret     *earlySecret
 	sharedKey       []byte
-	handshakeSecret *tls13.HandshakeSecret
-	masterSecret    *tls13.MasterSecret
+	handshakeSecret *handshakeSecret
+	masterSecret    *masterSecret
 	trafficSecret   []byte // client_application_traffic_secret_0
 	transcript      hash.Hash
 	clientFinished  []byte
 	echContext      *echServerContext
+	// serverCertType and clientCertType are the negotiated kinds of
+	// credentials of the server and of the client. See RFC 7250.
+	serverCertType CertificateType
+	clientCertType CertificateType
 }
 
 func (hs *serverHandshakeStateTLS13) handshake() error {
@@ -99,6 +103,12 @@ func (hs *serverHandshakeStateTLS13) handshake() error {
 		return err
 	}
 
+	if c.dtls != nil {
+		if err := c.dtlsServerHandshakeComplete(); err != nil {
+			return err
+		}
+	}
+
 	c.isHandshakeComplete.Store(true)
 
 	return nil
@@ -113,6 +123,15 @@ func (hs *serverHandshakeStateTLS13) processClientHello() error {
 	// supported_versions instead. See RFC 8446, sections 4.1.3 and 4.2.1.
 	hs.hello.vers = VersionTLS12
 	hs.hello.supportedVersion = c.vers
+	if c.dtls != nil {
+		hs.hello.vers = VersionDTLS12
+		hs.hello.supportedVersion = VersionDTLS13
+		// See RFC 9147, Section 5.3.
+		if len(hs.clientHello.dtlsCookie) != 0 {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: DTLS 1.3 client sent a non-empty legacy_cookie")
+		}
+	}
 
 	if len(hs.clientHello.supportedVersions) == 0 {
 		c.sendAlert(alertIllegalParameter)
@@ -195,6 +214,7 @@ func (hs *serverHandshakeStateTLS13) processClientHello() error {
 			hs.clientHello.cipherSuites)
 	}
 	c.cipherSuite = hs.suite.id
+	c.traceCipherSuite()
 	hs.hello.cipherSuite = hs.suite.id
 	hs.transcript = hs.suite.hash.New()
 
@@ -237,14 +257,24 @@ func (hs *serverHandshakeStateTLS13) processClientHello() error {
 			break
 		}
 	}
-	if clientKeyShare == nil {
-		ks, err := hs.doHelloRetryRequest(selectedGroup)
+	// DTLS servers always send a HelloRetryRequest with a cookie, to verify
+	// the client's address before doing any expensive work or sending a large
+	// flight. See RFC 9147, Section 5.1.
+	if c.dtls != nil && c.dtls.cookieKey != nil {
+		group, ks, err := hs.dtlsStatelessHelloRetryRequest(selectedGroup, clientKeyShare == nil)
+		if err != nil {
+			return err
+		}
+		selectedGroup, clientKeyShare = group, ks
+	} else if clientKeyShare == nil || c.dtls != nil {
+		ks, err := hs.doHelloRetryRequest(selectedGroup, clientKeyShare == nil)
 		if err != nil {
 			return err
 		}
 		clientKeyShare = ks
 	}
 	c.curveID = selectedGroup
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeGroupSelected, Group: c.curveID})
 
 	ke, err := keyExchangeForCurveID(selectedGroup)
 	if err != nil {
@@ -292,7 +322,13 @@ func (hs *serverHandshakeStateTLS13) processClientHello() error {
 func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 	c := hs.c
 
-	if c.config.SessionTicketsDisabled {
+	// External PSKs authenticate only the client's knowledge of the key, so
+	// they can't satisfy a requirement for a client certificate.
+	externalPSKs := (len(c.config.ExternalPSKs) > 0 || c.config.GetExternalPSK != nil) &&
+		!requiresClientCert(c.config.ClientAuth)
+
+	// DTLS connections don't support resumption.
+	if (c.config.SessionTicketsDisabled || c.dtls != nil) && !externalPSKs {
 		return nil
 	}
 
@@ -320,6 +356,37 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 			break
 		}
 
+		if externalPSKs {
+			psk, err := c.config.externalPSK(identity.label)
+			if err != nil {
+				c.sendAlert(alertInternalError)
+				return err
+			}
+			if psk != nil {
+				pskSuite := psk.suite()
+				if pskSuite == nil || pskSuite.hash != hs.suite.hash || len(psk.Key) == 0 {
+					c.traceSessionNotResumed("external PSK is unusable with the selected cipher suite")
+					continue
+				}
+
+				hs.earlySecret = newEarlySecret(hs.suite, c.dtls != nil, psk.Key)
+				if err := hs.verifyPSKBinder(i, hs.earlySecret.ExternalBinderKey()); err != nil {
+					return err
+				}
+
+				c.pskIdentity = identity.label
+				hs.hello.selectedIdentityPresent = true
+				hs.hello.selectedIdentity = uint16(i)
+				hs.usingPSK = true
+				return nil
+			}
+		}
+
+		if c.config.SessionTicketsDisabled || c.dtls != nil {
+			c.traceSessionNotResumed("identity matched no external PSK")
+			continue
+		}
+
 		var sessionState *SessionState
 		if c.config.UnwrapSession != nil {
 			var err error
@@ -328,31 +395,41 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 				return err
 			}
 			if sessionState == nil {
+				c.traceSessionNotResumed("UnwrapSession returned no session")
 				continue
 			}
 		} else {
 			plaintext := c.config.decryptTicket(identity.label, c.ticketKeys)
+			if plaintext == nil && externalPSKs {
+				c.traceSessionNotResumed("identity matched no external PSK or session ticket")
+				continue
+			}
 			if plaintext == nil {
+				c.traceSessionNotResumed("ticket could not be decrypted")
 				continue
 			}
 			var err error
 			sessionState, err = ParseSessionState(plaintext)
 			if err != nil {
+				c.traceSessionNotResumed("ticket could not be parsed")
 				continue
 			}
 		}
 
 		if sessionState.version != VersionTLS13 {
+			c.traceSessionNotResumed("session is for a different version")
 			continue
 		}
 
 		createdAt := time.Unix(int64(sessionState.createdAt), 0)
 		if c.config.time().Sub(createdAt) > maxSessionTicketLifetime {
+			c.traceSessionNotResumed("session is expired")
 			continue
 		}
 
 		pskSuite := cipherSuiteTLS13ByID(sessionState.cipherSuite)
 		if pskSuite == nil || pskSuite.hash != hs.suite.hash {
+			c.traceSessionNotResumed("session cipher suite hash mismatch")
 			continue
 		}
 
@@ -362,12 +439,15 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 		sessionHasClientCerts := len(sessionState.peerCertificates) != 0
 		needClientCerts := requiresClientCert(c.config.ClientAuth)
 		if needClientCerts && !sessionHasClientCerts {
+			c.traceSessionNotResumed("session lacks a required client certificate")
 			continue
 		}
 		if sessionHasClientCerts && c.config.ClientAuth == NoClientCert {
+			c.traceSessionNotResumed("session has an unrequested client certificate")
 			continue
 		}
 		if sessionHasClientCerts && c.config.time().After(sessionState.peerCertificates[0].NotAfter) {
+			c.traceSessionNotResumed("session client certificate is expired")
 			continue
 		}
 		opts := x509.VerifyOptions{
@@ -377,6 +457,7 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 		}
 		if sessionHasClientCerts && c.config.ClientAuth >= VerifyClientCertIfGiven &&
 			!anyValidVerifiedChain(sessionState.verifiedChains, opts) {
+			c.traceSessionNotResumed("session client certificate chain is no longer valid")
 			continue
 		}
 
@@ -386,25 +467,10 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 			}
 		}
 
-		hs.earlySecret = tls13.NewEarlySecret(hs.suite.hash.New, sessionState.secret)
-		binderKey := hs.earlySecret.ResumptionBinderKey()
-		// Clone the transcript in case a HelloRetryRequest was recorded.
-		transcript := cloneHash(hs.transcript, hs.suite.hash)
-		if transcript == nil {
-			c.sendAlert(alertInternalError)
-			return errors.New("tls: internal error: failed to clone hash")
-		}
-		clientHelloBytes, err := hs.clientHello.marshalWithoutBinders()
-		if err != nil {
-			c.sendAlert(alertInternalError)
+		hs.earlySecret = newEarlySecret(hs.suite, false, sessionState.secret)
+		if err := hs.verifyPSKBinder(i, hs.earlySecret.ResumptionBinderKey()); err != nil {
 			return err
 		}
-		transcript.Write(clientHelloBytes)
-		pskBinder := hs.suite.finishedHash(binderKey, transcript)
-		if !hmac.Equal(hs.clientHello.pskBinders[i], pskBinder) {
-			c.sendAlert(alertDecryptError)
-			return errors.New("tls: invalid PSK binder")
-		}
 
 		if c.quic != nil && hs.clientHello.earlyData && i == 0 &&
 			sessionState.EarlyData && sessionState.cipherSuite == hs.suite.id &&
@@ -422,6 +488,7 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 		}
 
 		c.didResume = true
+		c.traceHandshake(HandshakeEvent{Kind: HandshakeSessionResumed})
 		c.peerCertificates = sessionState.peerCertificates
 		c.ocspResponse = sessionState.ocspResponse
 		c.scts = sessionState.scts
@@ -436,6 +503,31 @@ func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 	return nil
 }
 
+// verifyPSKBinder checks the binder of the i-th PSK identity offered in the
+// ClientHello against binderKey. See RFC 8446, Section 4.2.11.2.
+func (hs *serverHandshakeStateTLS13) verifyPSKBinder(i int, binderKey []byte) error {
+	c := hs.c
+
+	// Clone the transcript in case a HelloRetryRequest was recorded.
+	transcript := cloneHash(hs.transcript, hs.suite.hash)
+	if transcript == nil {
+		c.sendAlert(alertInternalError)
+		return errors.New("tls: internal error: failed to clone hash")
+	}
+	clientHelloBytes, err := hs.clientHello.marshalWithoutBinders()
+	if err != nil {
+		c.sendAlert(alertInternalError)
+		return err
+	}
+	transcript.Write(clientHelloBytes)
+	pskBinder := hs.suite.finishedHash(hs.c.dtls != nil, binderKey, transcript)
+	if !hmac.Equal(hs.clientHello.pskBinders[i], pskBinder) {
+		c.sendAlert(alertDecryptError)
+		return errors.New("tls: invalid PSK binder")
+	}
+	return nil
+}
+
 // cloneHash uses [hash.Cloner] to clone in. If [hash.Cloner]
 // is not implemented or not supported, then it falls back to the
 // [encoding.BinaryMarshaler] and [encoding.BinaryUnmarshaler]
@@ -484,7 +576,8 @@ func (hs *serverHandshakeStateTLS13) pickCertificate() error {
 		return c.sendAlert(alertMissingExtension)
 	}
 
-	certificate, err := c.config.getCertificate(clientHelloInfo(hs.ctx, c, hs.clientHello))
+	chi := clientHelloInfo(hs.ctx, c, hs.clientHello)
+	certificate, err := c.config.getCertificate(chi)
 	if err != nil {
 		if err == errNoCertificates {
 			c.sendAlert(alertUnrecognizedName)
@@ -493,6 +586,11 @@ func (hs *serverHandshakeStateTLS13) pickCertificate() error {
 		}
 		return err
 	}
+	if !chi.supportsCertificateType(c.vers, certificate.Type) {
+		c.sendAlert(alertUnsupportedCertificate)
+		return errors.New("tls: client does not accept the type of the selected certificate")
+	}
+	hs.serverCertType = certificate.Type
 	hs.sigAlg, err = selectSignatureScheme(c.vers, certificate, hs.clientHello.supportedSignatureAlgorithms)
 	if err != nil {
 		// getCertificate returned a certificate that is unsupported or
@@ -508,7 +606,7 @@ func (hs *serverHandshakeStateTLS13) pickCertificate() error {
 // sendDummyChangeCipherSpec sends a ChangeCipherSpec record for compatibility
 // with middleboxes that didn't implement TLS correctly. See RFC 8446, Appendix D.4.
 func (hs *serverHandshakeStateTLS13) sendDummyChangeCipherSpec() error {
-	if hs.c.quic != nil {
+	if hs.c.quic != nil || hs.c.dtls != nil {
 		return nil
 	}
 	if hs.sentDummyCCS {
@@ -519,7 +617,10 @@ func (hs *serverHandshakeStateTLS13) sendDummyChangeCipherSpec() error {
 	return hs.c.writeChangeCipherRecord()
 }
 
-func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID) (*keyShare, error) {
+// doHelloRetryRequest sends a HelloRetryRequest and reads the second
+// ClientHello. If requestKeyShare is false, the HelloRetryRequest only carries
+// a cookie, and the client must send the same key shares again.
+func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID, requestKeyShare bool) (*keyShare, error) {
 	c := hs.c
 
 	// Make sure the client didn't send extra handshake messages alongside
@@ -547,7 +648,19 @@ func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID)
 		cipherSuite:       hs.hello.cipherSuite,
 		compressionMethod: hs.hello.compressionMethod,
 		supportedVersion:  hs.hello.supportedVersion,
-		selectedGroup:     selectedGroup,
+	}
+	if requestKeyShare {
+		helloRetryRequest.selectedGroup = selectedGroup
+	}
+	if c.dtls != nil {
+		// The cookie is kept in the Conn rather than being a stateless
+		// encoding of the transcript, since each peer address gets its own
+		// Conn anyway.
+		helloRetryRequest.cookie = make([]byte, 32)
+		if _, err := io.ReadFull(c.config.rand(), helloRetryRequest.cookie); err != nil {
+			c.sendAlert(alertInternalError)
+			return nil, err
+		}
 	}
 
 	if hs.echContext != nil {
@@ -567,6 +680,7 @@ func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID)
 		helloRetryRequest.encryptedClientHello = acceptConfirmation
 	}
 
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeHelloRetryRequest, Group: helloRetryRequest.selectedGroup})
 	if _, err := hs.c.writeHandshakeRecord(helloRetryRequest, hs.transcript); err != nil {
 		return nil, err
 	}
@@ -626,15 +740,35 @@ func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID)
 		}
 	}
 
-	if len(clientHello.keyShares) != 1 {
+	if !bytes.Equal(clientHello.cookie, helloRetryRequest.cookie) {
 		c.sendAlert(alertIllegalParameter)
-		return nil, errors.New("tls: client didn't send one key share in second ClientHello")
+		return nil, errors.New("tls: client sent an invalid cookie in second ClientHello")
 	}
-	ks := &clientHello.keyShares[0]
 
-	if ks.group != selectedGroup {
-		c.sendAlert(alertIllegalParameter)
-		return nil, errors.New("tls: client sent unexpected key share in second ClientHello")
+	var ks *keyShare
+	if requestKeyShare {
+		if len(clientHello.keyShares) != 1 {
+			c.sendAlert(alertIllegalParameter)
+			return nil, errors.New("tls: client didn't send one key share in second ClientHello")
+		}
+		ks = &clientHello.keyShares[0]
+
+		if ks.group != selectedGroup {
+			c.sendAlert(alertIllegalParameter)
+			return nil, errors.New("tls: client sent unexpected key share in second ClientHello")
+		}
+	} else {
+		if !slices.EqualFunc(clientHello.keyShares, hs.clientHello.keyShares, func(a, b keyShare) bool {
+			return a.group == b.group && bytes.Equal(a.data, b.data)
+		}) {
+			c.sendAlert(alertIllegalParameter)
+			return nil, errors.New("tls: client changed its key shares in second ClientHello")
+		}
+		for i := range clientHello.keyShares {
+			if clientHello.keyShares[i].group == selectedGroup {
+				ks = &clientHello.keyShares[i]
+			}
+		}
 	}
 
 	if clientHello.earlyData {
@@ -642,7 +776,13 @@ func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID)
 		return nil, errors.New("tls: client indicated early data in second ClientHello")
 	}
 
-	if illegalClientHelloChange(clientHello, hs.clientHello) {
+	ch1 := hs.clientHello
+	if helloRetryRequest.cookie != nil {
+		// The cookie is the one change we requested, beside the key share.
+		ch1 = ch1.clone()
+		ch1.cookie = helloRetryRequest.cookie
+	}
+	if illegalClientHelloChange(clientHello, ch1) {
 		c.sendAlert(alertIllegalParameter)
 		return nil, errors.New("tls: client illegally modified second ClientHello")
 	}
@@ -652,6 +792,97 @@ func (hs *serverHandshakeStateTLS13) doHelloRetryRequest(selectedGroup CurveID)
 	return ks, nil
 }
 
+// dtlsStatelessHelloRetryRequest is the version of doHelloRetryRequest for
+// servers that keep no state until the client proves that it can receive
+// datagrams at its address, see dtlsListener. If the ClientHello doesn't carry
+// a valid cookie, it sends a HelloRetryRequest and returns errDTLSCookieSent.
+// Otherwise, the ClientHello is the second one, and the transcript is rebuilt
+// from the cookie, which carries the cipher suite, the requested and selected
+// groups, and the hash of the first ClientHello.
+func (hs *serverHandshakeStateTLS13) dtlsStatelessHelloRetryRequest(selectedGroup CurveID, requestKeyShare bool) (CurveID, *keyShare, error) {
+	c := hs.c
+	d := c.dtls
+	addr := c.conn.RemoteAddr()
+
+	helloRetryRequest := &serverHelloMsg{
+		vers:              hs.hello.vers,
+		random:            helloRetryRequestRandom,
+		sessionId:         hs.hello.sessionId,
+		cipherSuite:       hs.hello.cipherSuite,
+		compressionMethod: hs.hello.compressionMethod,
+		supportedVersion:  hs.hello.supportedVersion,
+	}
+
+	state, ok := dtlsOpenCookie(d.cookieKey, addr, hs.clientHello.random, hs.clientHello.cookie)
+	if !ok {
+		if requestKeyShare {
+			helloRetryRequest.selectedGroup = selectedGroup
+		}
+		if err := transcriptMsg(hs.clientHello, hs.transcript); err != nil {
+			return 0, nil, err
+		}
+		state = byteorder.BEAppendUint16(nil, hs.suite.id)
+		state = byteorder.BEAppendUint16(state, uint16(helloRetryRequest.selectedGroup))
+		state = byteorder.BEAppendUint16(state, uint16(selectedGroup))
+		state = hs.transcript.Sum(state)
+		helloRetryRequest.cookie = dtlsSealCookie(d.cookieKey, addr, hs.clientHello.random, state)
+
+		c.traceHandshake(HandshakeEvent{Kind: HandshakeHelloRetryRequest, Group: helloRetryRequest.selectedGroup})
+		if _, err := c.writeHandshakeRecord(helloRetryRequest, nil); err != nil {
+			return 0, nil, err
+		}
+		return 0, nil, errDTLSCookieSent
+	}
+	if d.cookieProbe {
+		return 0, nil, errDTLSCookieValid
+	}
+
+	if len(state) != 6+hs.suite.hash.Size() || byteorder.BEUint16(state) != hs.suite.id {
+		c.sendAlert(alertIllegalParameter)
+		return 0, nil, errors.New("tls: client changed its cipher suites in second ClientHello")
+	}
+	requestedGroup := CurveID(byteorder.BEUint16(state[2:]))
+	selectedGroup = CurveID(byteorder.BEUint16(state[4:]))
+	chHash := state[6:]
+	if !slices.Contains(hs.clientHello.supportedCurves, selectedGroup) {
+		c.sendAlert(alertIllegalParameter)
+		return 0, nil, errors.New("tls: client changed its supported groups in second ClientHello")
+	}
+
+	// The first ClientHello gets double-hashed into the transcript upon a
+	// HelloRetryRequest. See RFC 8446, Section 4.4.1.
+	hs.transcript.Write([]byte{typeMessageHash, 0, 0, uint8(len(chHash))})
+	hs.transcript.Write(chHash)
+	helloRetryRequest.selectedGroup = requestedGroup
+	helloRetryRequest.cookie = hs.clientHello.cookie
+	if err := transcriptMsg(helloRetryRequest, hs.transcript); err != nil {
+		return 0, nil, err
+	}
+
+	if requestedGroup != 0 && len(hs.clientHello.keyShares) != 1 {
+		c.sendAlert(alertIllegalParameter)
+		return 0, nil, errors.New("tls: client didn't send one key share in second ClientHello")
+	}
+	var ks *keyShare
+	for i := range hs.clientHello.keyShares {
+		if hs.clientHello.keyShares[i].group == selectedGroup {
+			ks = &hs.clientHello.keyShares[i]
+		}
+	}
+	if ks == nil {
+		c.sendAlert(alertIllegalParameter)
+		return 0, nil, errors.New("tls: client sent unexpected key share in second ClientHello")
+	}
+
+	if hs.clientHello.earlyData {
+		c.sendAlert(alertIllegalParameter)
+		return 0, nil, errors.New("tls: client indicated early data in second ClientHello")
+	}
+
+	c.didHRR = true
+	return selectedGroup, ks, nil
+}
+
 // illegalClientHelloChange reports whether the two ClientHello messages are
 // different, with the exception of the changes allowed before and after a
 // HelloRetryRequest. See RFC 8446, Section 4.1.2.
@@ -707,7 +938,8 @@ func illegalClientHelloChange(ch, ch1 *clientHelloMsg) bool {
 		!bytes.Equal(ch.secureRenegotiation, ch1.secureRenegotiation) ||
 		ch.scts != ch1.scts ||
 		!bytes.Equal(ch.cookie, ch1.cookie) ||
-		!bytes.Equal(ch.pskModes, ch1.pskModes)
+		!bytes.Equal(ch.pskModes, ch1.pskModes) ||
+		!bytes.Equal(ch.dtlsCookie, ch1.dtlsCookie)
 }
 
 func (hs *serverHandshakeStateTLS13) sendServerParameters() error {
@@ -745,7 +977,7 @@ func (hs *serverHandshakeStateTLS13) sendServerParameters() error {
 
 	earlySecret := hs.earlySecret
 	if earlySecret == nil {
-		earlySecret = tls13.NewEarlySecret(hs.suite.hash.New, nil)
+		earlySecret = newEarlySecret(hs.suite, c.dtls != nil, nil)
 	}
 	hs.handshakeSecret = earlySecret.HandshakeSecret(hs.sharedKey)
 
@@ -808,6 +1040,29 @@ func (hs *serverHandshakeStateTLS13) sendServerParameters() error {
 		}
 	}
 
+	// The certificate type extensions are only relevant, and so only echoed,
+	// when the corresponding certificate is sent. See RFC 7250, Section 4.2.
+	if !hs.usingPSK && len(hs.clientHello.serverCertTypes) > 0 {
+		encryptedExtensions.serverCertTypePresent = true
+		encryptedExtensions.serverCertType = hs.serverCertType
+	}
+	if hs.requestClientCert() && len(hs.clientHello.clientCertTypes) > 0 {
+		accepted := c.config.PeerCertificateTypes
+		if len(accepted) == 0 {
+			accepted = []CertificateType{CertificateTypeX509}
+		}
+		i := slices.IndexFunc(accepted, func(t CertificateType) bool {
+			return slices.Contains(hs.clientHello.clientCertTypes, t)
+		})
+		if i < 0 {
+			c.sendAlert(alertUnsupportedCertificate)
+			return errors.New("tls: no client certificate type supported by both client and server")
+		}
+		hs.clientCertType = accepted[i]
+		encryptedExtensions.clientCertTypePresent = true
+		encryptedExtensions.clientCertType = hs.clientCertType
+	}
+
 	if _, err := hs.c.writeHandshakeRecord(encryptedExtensions, hs.transcript); err != nil {
 		return err
 	}
@@ -846,8 +1101,9 @@ func (hs *serverHandshakeStateTLS13) sendServerCertificate() error {
 	certMsg := new(certificateMsgTLS13)
 
 	certMsg.certificate = *hs.cert
-	certMsg.scts = hs.clientHello.scts && len(hs.cert.SignedCertificateTimestamps) > 0
-	certMsg.ocspStapling = hs.clientHello.ocspStapling && len(hs.cert.OCSPStaple) > 0
+	isX509 := hs.cert.Type == CertificateTypeX509
+	certMsg.scts = isX509 && hs.clientHello.scts && len(hs.cert.SignedCertificateTimestamps) > 0
+	certMsg.ocspStapling = isX509 && hs.clientHello.ocspStapling && len(hs.cert.OCSPStaple) > 0
 
 	if _, err := hs.c.writeHandshakeRecord(certMsg, hs.transcript); err != nil {
 		return err
@@ -856,6 +1112,7 @@ func (hs *serverHandshakeStateTLS13) sendServerCertificate() error {
 	certVerifyMsg := new(certificateVerifyMsg)
 	certVerifyMsg.hasSignatureAlgorithm = true
 	certVerifyMsg.signatureAlgorithm = hs.sigAlg
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: hs.sigAlg})
 
 	sigType, sigHash, err := typeAndHashFromSignatureScheme(hs.sigAlg)
 	if err != nil {
@@ -891,7 +1148,7 @@ func (hs *serverHandshakeStateTLS13) sendServerFinished() error {
 	c := hs.c
 
 	finished := &finishedMsg{
-		verifyData: hs.suite.finishedHash(c.out.trafficSecret, hs.transcript),
+		verifyData: hs.suite.finishedHash(hs.c.dtls != nil, c.out.trafficSecret, hs.transcript),
 	}
 
 	if _, err := hs.c.writeHandshakeRecord(finished, hs.transcript); err != nil {
@@ -945,6 +1202,18 @@ func (hs *serverHandshakeStateTLS13) shouldSendSessionTickets() bool {
 		return false
 	}
 
+	// DTLS connections don't support resumption.
+	if hs.c.dtls != nil {
+		return false
+	}
+
+	// Sessions can only be resumed on the basis of certificates, so don't
+	// issue tickets for connections authenticated otherwise.
+	if hs.c.pskIdentity != nil || hs.c.peerRawPublicKey != nil ||
+		hs.serverCertType == CertificateTypeRawPublicKey {
+		return false
+	}
+
 	// Don't send tickets the client wouldn't use. See RFC 8446, Section 4.2.9.
 	return slices.Contains(hs.clientHello.pskModes, pskModeDHE)
 }
@@ -952,7 +1221,7 @@ func (hs *serverHandshakeStateTLS13) shouldSendSessionTickets() bool {
 func (hs *serverHandshakeStateTLS13) sendSessionTickets() error {
 	c := hs.c
 
-	hs.clientFinished = hs.suite.finishedHash(c.in.trafficSecret, hs.transcript)
+	hs.clientFinished = hs.suite.finishedHash(hs.c.dtls != nil, c.in.trafficSecret, hs.transcript)
 	finishedMsg := &finishedMsg{
 		verifyData: hs.clientFinished,
 	}
@@ -1053,15 +1322,21 @@ func (hs *serverHandshakeStateTLS13) readClientCertificate() error {
 		return unexpectedMessageError(certMsg, msg)
 	}
 
-	if err := c.processCertsFromClient(certMsg.certificate); err != nil {
-		return err
-	}
-
-	if c.config.VerifyConnection != nil {
-		if err := c.config.VerifyConnection(c.connectionStateLocked()); err != nil {
-			c.sendAlert(alertBadCertificate)
+	if hs.clientCertType == CertificateTypeRawPublicKey && len(certMsg.certificate.Certificate) != 0 {
+		if err := c.verifyPeerRawPublicKey(certMsg.certificate.Certificate); err != nil {
+			return err
+		}
+	} else {
+		if err := c.processCertsFromClient(certMsg.certificate); err != nil {
 			return err
 		}
+
+		if c.config.VerifyConnection != nil {
+			if err := c.config.VerifyConnection(c.connectionStateLocked()); err != nil {
+				c.sendAlert(alertBadCertificate)
+				return err
+			}
+		}
 	}
 
 	if len(certMsg.certificate.Certificate) != 0 {
@@ -1083,7 +1358,7 @@ func (hs *serverHandshakeStateTLS13) readClientCertificate() error {
 		// We don't use certReq.supportedSignatureAlgorithms because it would
 		// require keeping the certificateRequestMsgTLS13 around in the hs.
 		if !isSupportedSignatureAlgorithm(certVerify.signatureAlgorithm, supportedSignatureAlgorithms(c.vers)) ||
-			!isSupportedSignatureAlgorithm(certVerify.signatureAlgorithm, signatureSchemesForPublicKey(c.vers, c.peerCertificates[0].PublicKey)) {
+			!isSupportedSignatureAlgorithm(certVerify.signatureAlgorithm, signatureSchemesForPublicKey(c.vers, c.peerPublicKey())) {
 			c.sendAlert(alertIllegalParameter)
 			return errors.New("tls: client certificate used with invalid signature algorithm")
 		}
@@ -1094,8 +1369,9 @@ func (hs *serverHandshakeStateTLS13) readClientCertificate() error {
 		if sigType == signaturePKCS1v15 || sigHash == crypto.SHA1 {
 			return c.sendAlert(alertInternalError)
 		}
+		c.traceHandshake(HandshakeEvent{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: certVerify.signatureAlgorithm, Peer: true})
 		signed := signedMessage(clientSignatureContext, hs.transcript)
-		if err := verifyHandshakeSignature(sigType, c.peerCertificates[0].PublicKey,
+		if err := verifyHandshakeSignature(sigType, c.peerPublicKey(),
 			sigHash, signed, certVerify.signature); err != nil {
 			c.sendAlert(alertDecryptError)
 			return errors.New("tls: invalid signature by the client certificate: " + err.Error())
@@ -8,10 +8,12 @@ import (
 	"crypto"
 	"crypto/ecdh"
 	"crypto/hmac"
+	fipshkdf "crypto/internal/fips140/hkdf"
 	"crypto/internal/fips140/tls13"
 	"crypto/mlkem"
 	"errors"
 	"hash"
+	"internal/byteorder"
 	"io"
 )
 
@@ -33,9 +35,9 @@ func (c *cipherSuiteTLS13) trafficKey(trafficSecret []byte) (key, iv []byte) {
 
 // finishedHash generates the Finished verify_data or PskBinderEntry according
 // to RFC 8446, Section 4.4.4. See sections 4.4 and 4.2.11.2 for the baseKey
-// selection.
-func (c *cipherSuiteTLS13) finishedHash(baseKey []byte, transcript hash.Hash) []byte {
-	finishedKey := tls13.ExpandLabel(c.hash.New, baseKey, "finished", nil, c.hash.Size())
+// selection. If dtls is true, it uses the DTLS 1.3 key schedule.
+func (c *cipherSuiteTLS13) finishedHash(dtls bool, baseKey []byte, transcript hash.Hash) []byte {
+	finishedKey := c.expandLabel(dtls, baseKey, "finished", nil, c.hash.Size())
 	verifyData := hmac.New(c.hash.New, finishedKey)
 	verifyData.Write(transcript.Sum(nil))
 	return verifyData.Sum(nil)
@@ -43,13 +45,192 @@ func (c *cipherSuiteTLS13) finishedHash(baseKey []byte, transcript hash.Hash) []
 
 // exportKeyingMaterial implements RFC5705 exporters for TLS 1.3 according to
 // RFC 8446, Section 7.5.
-func (c *cipherSuiteTLS13) exportKeyingMaterial(s *tls13.MasterSecret, transcript hash.Hash) func(string, []byte, int) ([]byte, error) {
-	expMasterSecret := s.ExporterMasterSecret(transcript)
+func (c *cipherSuiteTLS13) exportKeyingMaterial(s *masterSecret, transcript hash.Hash) func(string, []byte, int) ([]byte, error) {
+	if s.dtls != nil {
+		expMasterSecret := &dtlsSecret{c, s.dtls.deriveSecret("exp master", transcript)}
+		return func(label string, context []byte, length int) ([]byte, error) {
+			secret := expMasterSecret.deriveSecret(label, nil)
+			h := c.hash.New()
+			h.Write(context)
+			return c.expandLabel(true, secret, "exporter", h.Sum(nil), length), nil
+		}
+	}
+	expMasterSecret := s.tls.ExporterMasterSecret(transcript)
 	return func(label string, context []byte, length int) ([]byte, error) {
 		return expMasterSecret.Exporter(label, context, length), nil
 	}
 }
 
+// The DTLS 1.3 key schedule is the TLS 1.3 one with "dtls13" replacing the
+// "tls13 " prefix of the HKDF-Expand-Label labels. See RFC 9147, Section 5.9.
+//
+// The TLS 1.3 key schedule is implemented by the FIPS 140-3 module, and the
+// DTLS 1.3 one is implemented here on top of its HKDF. The earlySecret,
+// handshakeSecret and masterSecret types wrap the secrets of either, and have
+// the methods of the tls13 types used by the handshake.
+
+const dtls13LabelPrefix = "dtls13"
+
+// expandLabel implements HKDF-Expand-Label from RFC 8446, Section 7.1, with the
+// DTLS 1.3 label prefix if dtls is true.
+func (c *cipherSuiteTLS13) expandLabel(dtls bool, secret []byte, label string, context []byte, length int) []byte {
+	if !dtls {
+		return tls13.ExpandLabel(c.hash.New, secret, label, context, length)
+	}
+	if len(dtls13LabelPrefix)+len(label) > 255 || len(context) > 255 {
+		// Labels are fixed strings, and contexts are hashes.
+		panic("tls: internal error: label or context too long")
+	}
+	hkdfLabel := make([]byte, 0, 2+1+len(dtls13LabelPrefix)+len(label)+1+len(context))
+	hkdfLabel = byteorder.BEAppendUint16(hkdfLabel, uint16(length))
+	hkdfLabel = append(hkdfLabel, byte(len(dtls13LabelPrefix)+len(label)))
+	hkdfLabel = append(hkdfLabel, dtls13LabelPrefix...)
+	hkdfLabel = append(hkdfLabel, label...)
+	hkdfLabel = append(hkdfLabel, byte(len(context)))
+	hkdfLabel = append(hkdfLabel, context...)
+	return fipshkdf.Expand(c.hash.New, secret, string(hkdfLabel), length)
+}
+
+// A dtlsSecret is a secret of the DTLS 1.3 key schedule.
+type dtlsSecret struct {
+	suite  *cipherSuiteTLS13
+	secret []byte
+}
+
+// newDTLSSecret extracts a secret from ikm and salt, either of which may be
+// nil. ikm defaults to a string of zeros.
+func newDTLSSecret(suite *cipherSuiteTLS13, ikm, salt []byte) *dtlsSecret {
+	if ikm == nil {
+		ikm = make([]byte, suite.hash.Size())
+	}
+	return &dtlsSecret{suite, fipshkdf.Extract(suite.hash.New, ikm, salt)}
+}
+
+// deriveSecret implements Derive-Secret from RFC 8446, Section 7.1. A nil
+// transcript is the transcript of no messages.
+func (s *dtlsSecret) deriveSecret(label string, transcript hash.Hash) []byte {
+	if transcript == nil {
+		transcript = s.suite.hash.New()
+	}
+	return s.suite.expandLabel(true, s.secret, label, transcript.Sum(nil), transcript.Size())
+}
+
+// next extracts the secret of the next stage of the key schedule from ikm.
+func (s *dtlsSecret) next(ikm []byte) *dtlsSecret {
+	return newDTLSSecret(s.suite, ikm, s.deriveSecret("derived", nil))
+}
+
+// An earlySecret is the early secret of the TLS 1.3 key schedule, or of the
+// DTLS 1.3 one if dtls is set.
+type earlySecret struct {
+	tls  *tls13.EarlySecret
+	dtls *dtlsSecret
+
+	// suite and psk are kept for ExternalBinderKey, which the FIPS 140-3
+	// module doesn't implement.
+	suite *cipherSuiteTLS13
+	psk   []byte
+}
+
+// newEarlySecret starts the key schedule of suite from psk, which may be nil.
+func newEarlySecret(suite *cipherSuiteTLS13, dtls bool, psk []byte) *earlySecret {
+	if dtls {
+		return &earlySecret{dtls: newDTLSSecret(suite, psk, nil)}
+	}
+	return &earlySecret{tls: tls13.NewEarlySecret(suite.hash.New, psk), suite: suite, psk: psk}
+}
+
+func (s *earlySecret) ResumptionBinderKey() []byte {
+	if s.dtls != nil {
+		return s.dtls.deriveSecret("res binder", nil)
+	}
+	return s.tls.ResumptionBinderKey()
+}
+
+func (s *earlySecret) ExternalBinderKey() []byte {
+	if s.dtls != nil {
+		return s.dtls.deriveSecret("ext binder", nil)
+	}
+	// Derive-Secret(Early Secret, "ext binder", ""), with the early secret
+	// extracted again from the PSK, as tls13.NewEarlySecret does.
+	ikm := s.psk
+	if ikm == nil {
+		ikm = make([]byte, s.suite.hash.Size())
+	}
+	early := fipshkdf.Extract(s.suite.hash.New, ikm, nil)
+	return s.suite.expandLabel(false, early, "ext binder", s.suite.hash.New().Sum(nil), s.suite.hash.Size())
+}
+
+func (s *earlySecret) ClientEarlyTrafficSecret(transcript hash.Hash) []byte {
+	if s.dtls != nil {
+		return s.dtls.deriveSecret("c e traffic", transcript)
+	}
+	return s.tls.ClientEarlyTrafficSecret(transcript)
+}
+
+func (s *earlySecret) HandshakeSecret(sharedSecret []byte) *handshakeSecret {
+	if s.dtls != nil {
+		return &handshakeSecret{dtls: s.dtls.next(sharedSecret)}
+	}
+	return &handshakeSecret{tls: s.tls.HandshakeSecret(sharedSecret)}
+}
+
+// A handshakeSecret is the handshake secret of the TLS 1.3 key schedule, or
+// of the DTLS 1.3 one if dtls is set.
+type handshakeSecret struct {
+	tls  *tls13.HandshakeSecret
+	dtls *dtlsSecret
+}
+
+func (s *handshakeSecret) ClientHandshakeTrafficSecret(transcript hash.Hash) []byte {
+	if s.dtls != nil {
+		return s.dtls.deriveSecret("c hs traffic", transcript)
+	}
+	return s.tls.ClientHandshakeTrafficSecret(transcript)
+}
+
+func (s *handshakeSecret) ServerHandshakeTrafficSecret(transcript hash.Hash) []byte {
+	if s.dtls != nil {
+		return s.dtls.deriveSecret("s hs traffic", transcript)
+	}
+	return s.tls.ServerHandshakeTrafficSecret(transcript)
+}
+
+func (s *handshakeSecret) MasterSecret() *masterSecret {
+	if s.dtls != nil {
+		return &masterSecret{dtls: s.dtls.next(nil)}
+	}
+	return &masterSecret{tls: s.tls.MasterSecret()}
+}
+
+// A masterSecret is the master secret of the TLS 1.3 key schedule, or of the
+// DTLS 1.3 one if dtls is set.
+type masterSecret struct {
+	tls  *tls13.MasterSecret
+	dtls *dtlsSecret
+}
+
+func (s *masterSecret) ClientApplicationTrafficSecret(transcript hash.Hash) []byte {
+	if s.dtls != nil {
+		return s.dtls.deriveSecret("c ap traffic", transcript)
+	}
+	return s.tls.ClientApplicationTrafficSecret(transcript)
+}
+
+func (s *masterSecret) ServerApplicationTrafficSecret(transcript hash.Hash) []byte {
+	if s.dtls != nil {
+		return s.dtls.deriveSecret("s ap traffic", transcript)
+	}
+	return s.tls.ServerApplicationTrafficSecret(transcript)
+}
+
+func (s *masterSecret) ResumptionMasterSecret(transcript hash.Hash) []byte {
+	if s.dtls != nil {
+		return s.dtls.deriveSecret("res master", transcript)
+	}
+	return s.tls.ResumptionMasterSecret(transcript)
+}
+
 type keySharePrivateKeys struct {
 	ecdhe *ecdh.PrivateKey
 	mlkem crypto.Decapsulator
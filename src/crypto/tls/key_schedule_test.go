@@ -6,12 +6,15 @@ package tls
 
 import (
 	"bytes"
+	"crypto/hkdf"
 	"crypto/internal/fips140/tls13"
 	"crypto/sha256"
 	"encoding/hex"
 	"strings"
 	"testing"
 	"unicode"
+
+	"golang.org/x/crypto/cryptobyte"
 )
 
 func TestACVPVectors(t *testing.T) {
@@ -80,6 +83,81 @@ func TestACVPVectors(t *testing.T) {
 	}
 }
 
+// TestDTLSKeySchedule checks the DTLS 1.3 key schedule against a direct
+// implementation of RFC 8446, Section 7.1 with the label prefix of RFC 9147,
+// Section 5.9.
+func TestDTLSKeySchedule(t *testing.T) {
+	expandLabel := func(secret []byte, label string, context []byte, length int) []byte {
+		var b cryptobyte.Builder
+		b.AddUint16(uint16(length))
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes([]byte("dtls13" + label))
+		})
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(context)
+		})
+		out, err := hkdf.Expand(sha256.New, secret, string(b.BytesOrPanic()), length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	extract := func(ikm, salt []byte) []byte {
+		out, err := hkdf.Extract(sha256.New, ikm, salt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	emptyHash := sha256.Sum256(nil)
+
+	psk := bytes.Repeat([]byte{1}, 32)
+	dhe := bytes.Repeat([]byte{2}, 32)
+	transcript := sha256.New()
+	transcript.Write([]byte("ClientHello"))
+
+	suite := cipherSuiteTLS13ByID(TLS_AES_128_GCM_SHA256)
+	es := newEarlySecret(suite, true, psk)
+	early := extract(psk, nil)
+	if got, want := es.ExternalBinderKey(), expandLabel(early, "ext binder", emptyHash[:], 32); !bytes.Equal(got, want) {
+		t.Errorf("ExternalBinderKey = %x, want %x", got, want)
+	}
+	tlsKey := newEarlySecret(suite, false, psk).ExternalBinderKey()
+	if want := tls13.ExpandLabel(sha256.New, early, "ext binder", emptyHash[:], 32); !bytes.Equal(tlsKey, want) {
+		t.Errorf("TLS ExternalBinderKey = %x, want %x", tlsKey, want)
+	}
+	if bytes.Equal(es.ExternalBinderKey(), tlsKey) {
+		t.Errorf("DTLS and TLS binder keys are equal")
+	}
+
+	hs := es.HandshakeSecret(dhe)
+	handshake := extract(dhe, expandLabel(early, "derived", emptyHash[:], 32))
+	transcript.Write([]byte("ServerHello"))
+	if got, want := hs.ClientHandshakeTrafficSecret(transcript), expandLabel(handshake, "c hs traffic", transcript.Sum(nil), 32); !bytes.Equal(got, want) {
+		t.Errorf("ClientHandshakeTrafficSecret = %x, want %x", got, want)
+	}
+	if got, want := hs.ServerHandshakeTrafficSecret(transcript), expandLabel(handshake, "s hs traffic", transcript.Sum(nil), 32); !bytes.Equal(got, want) {
+		t.Errorf("ServerHandshakeTrafficSecret = %x, want %x", got, want)
+	}
+
+	ms := hs.MasterSecret()
+	master := extract(make([]byte, 32), expandLabel(handshake, "derived", emptyHash[:], 32))
+	transcript.Write([]byte("Finished"))
+	if got, want := ms.ClientApplicationTrafficSecret(transcript), expandLabel(master, "c ap traffic", transcript.Sum(nil), 32); !bytes.Equal(got, want) {
+		t.Errorf("ClientApplicationTrafficSecret = %x, want %x", got, want)
+	}
+	if got, want := ms.ServerApplicationTrafficSecret(transcript), expandLabel(master, "s ap traffic", transcript.Sum(nil), 32); !bytes.Equal(got, want) {
+		t.Errorf("ServerApplicationTrafficSecret = %x, want %x", got, want)
+	}
+
+	exporter := expandLabel(master, "exp master", transcript.Sum(nil), 32)
+	contextHash := sha256.Sum256([]byte("context"))
+	want := expandLabel(expandLabel(exporter, "label", emptyHash[:], 32), "exporter", contextHash[:], 20)
+	if got, _ := suite.exportKeyingMaterial(ms, transcript)("label", []byte("context"), 20); !bytes.Equal(got, want) {
+		t.Errorf("exporter = %x, want %x", got, want)
+	}
+}
+
 // This file contains tests derived from draft-ietf-tls-tls13-vectors-07.
 
 func parseVector(v string) []byte {
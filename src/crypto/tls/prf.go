@@ -160,10 +160,10 @@ func newFinishedHash(version uint16, cipherSuite *cipherSuite) finishedHash {
 
 	prf, hash := prfAndHashForVersion(version, cipherSuite)
 	if hash != 0 {
-		return finishedHash{hash.New(), hash.New(), nil, nil, buffer, version, prf}
+		return finishedHash{hash.New(), hash.New(), nil, nil, buffer, version, prf, nil}
 	}
 
-	return finishedHash{sha1.New(), sha1.New(), md5.New(), md5.New(), buffer, version, prf}
+	return finishedHash{sha1.New(), sha1.New(), md5.New(), md5.New(), buffer, version, prf, nil}
 }
 
 // A finishedHash calculates the hash of a set of handshake messages suitable
@@ -181,9 +181,30 @@ type finishedHash struct {
 
 	version uint16
 	prf     prfFunc
+
+	// dtls is set for DTLS 1.2 connections, whose transcript includes the
+	// message_seq, fragment_offset, and fragment_length fields of the DTLS
+	// handshake headers, as if messages were not fragmented. See RFC 6347,
+	// Section 4.2.6.
+	dtls *dtlsState
 }
 
 func (h *finishedHash) Write(msg []byte) (n int, err error) {
+	if h.dtls != nil && len(msg) >= 4 {
+		var hdr [dtlsHandshakeHeaderLen]byte
+		copy(hdr[:], msg[:4])
+		seq := h.dtls.msgSeq[msg[0]]
+		hdr[4], hdr[5] = byte(seq>>8), byte(seq)
+		copy(hdr[9:], msg[1:4])
+		h.write(hdr[:])
+		h.write(msg[4:])
+		return len(msg), nil
+	}
+	h.write(msg)
+	return len(msg), nil
+}
+
+func (h *finishedHash) write(msg []byte) {
 	h.client.Write(msg)
 	h.server.Write(msg)
 
@@ -195,8 +216,6 @@ func (h *finishedHash) Write(msg []byte) (n int, err error) {
 	if h.buffer != nil {
 		h.buffer = append(h.buffer, msg...)
 	}
-
-	return len(msg), nil
 }
 
 func (h finishedHash) Sum() []byte {
@@ -0,0 +1,312 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExternalPSK(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	psk := ExternalPSK{Identity: []byte("client1"), Key: key}
+
+	for _, tt := range []struct {
+		name         string
+		clientPSKs   []ExternalPSK
+		serverPSKs   []ExternalPSK
+		getPSK       func([]byte) (*ExternalPSK, error)
+		clientAuth   ClientAuthType
+		clientCurves []CurveID
+		err          string
+	}{
+		{
+			name:       "matching",
+			clientPSKs: []ExternalPSK{psk},
+			serverPSKs: []ExternalPSK{psk},
+		},
+		{
+			name:       "second identity",
+			clientPSKs: []ExternalPSK{{Identity: []byte("unknown"), Key: key}, psk},
+			serverPSKs: []ExternalPSK{psk},
+		},
+		{
+			name:       "GetExternalPSK",
+			clientPSKs: []ExternalPSK{psk},
+			getPSK: func(identity []byte) (*ExternalPSK, error) {
+				if string(identity) == "client1" {
+					return &ExternalPSK{Identity: identity, Key: key}, nil
+				}
+				return nil, nil
+			},
+		},
+		{
+			name:         "HelloRetryRequest",
+			clientPSKs:   []ExternalPSK{psk},
+			serverPSKs:   []ExternalPSK{psk},
+			clientCurves: []CurveID{X25519, CurveP256},
+		},
+		{
+			name:       "wrong key",
+			clientPSKs: []ExternalPSK{{Identity: psk.Identity, Key: bytes.Repeat([]byte{0x43}, 32)}},
+			serverPSKs: []ExternalPSK{psk},
+			err:        "invalid PSK binder",
+		},
+		{
+			name:       "unknown identity",
+			clientPSKs: []ExternalPSK{{Identity: []byte("unknown"), Key: key}},
+			serverPSKs: []ExternalPSK{psk},
+			err:        "ServerName is not set",
+		},
+		{
+			name:       "client certificate required",
+			clientPSKs: []ExternalPSK{psk},
+			serverPSKs: []ExternalPSK{psk},
+			clientAuth: RequireAnyClientCert,
+			err:        "ServerName is not set",
+		},
+		{
+			name:       "GetExternalPSK error",
+			clientPSKs: []ExternalPSK{psk},
+			getPSK: func([]byte) (*ExternalPSK, error) {
+				return nil, errors.New("lookup failed")
+			},
+			err: "lookup failed",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig := testConfig.Clone()
+			clientConfig.MinVersion = VersionTLS13
+			clientConfig.InsecureSkipVerify = false
+			clientConfig.ExternalPSKs = tt.clientPSKs
+
+			serverConfig := testConfig.Clone()
+			serverConfig.MinVersion = VersionTLS13
+			serverConfig.ExternalPSKs = tt.serverPSKs
+			serverConfig.GetExternalPSK = tt.getPSK
+			serverConfig.ClientAuth = tt.clientAuth
+			if tt.clientCurves != nil {
+				// Force a HelloRetryRequest by only accepting the client's
+				// second preference.
+				clientConfig.CurvePreferences = tt.clientCurves
+				serverConfig.CurvePreferences = tt.clientCurves[1:]
+			}
+
+			serverState, clientState, err := testHandshake(t, clientConfig, serverConfig)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("handshake error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+			for _, state := range []ConnectionState{serverState, clientState} {
+				if !bytes.Equal(state.PSKIdentity, psk.Identity) {
+					t.Errorf("PSKIdentity = %q, want %q", state.PSKIdentity, psk.Identity)
+				}
+				if state.DidResume {
+					t.Error("DidResume = true, want false")
+				}
+				if len(state.PeerCertificates) != 0 {
+					t.Error("PeerCertificates is not empty")
+				}
+			}
+			if want := tt.clientCurves != nil; clientState.HelloRetryRequest != want {
+				t.Errorf("HelloRetryRequest = %v, want %v", clientState.HelloRetryRequest, want)
+			}
+		})
+	}
+}
+
+func TestExternalPSKConfigErrors(t *testing.T) {
+	config := testConfig.Clone()
+	config.MinVersion = VersionTLS13
+	config.EncryptedClientHelloConfigList = []byte{0}
+	config.ExternalPSKs = []ExternalPSK{{Identity: []byte("a"), Key: []byte("b")}}
+	c := &Conn{config: config}
+	if _, _, _, err := c.makeClientHello(); err == nil {
+		t.Error("makeClientHello succeeded with both ExternalPSKs and EncryptedClientHelloConfigList")
+	}
+
+	config = testConfig.Clone()
+	config.MinVersion = VersionTLS13
+	config.ExternalPSKs = []ExternalPSK{{Identity: []byte("a"), Key: []byte("b"), CipherSuite: TLS_RSA_WITH_AES_128_CBC_SHA}}
+	c = &Conn{config: config}
+	hello, _, _, err := c.makeClientHello()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.loadExternalPSKs(hello); err == nil {
+		t.Error("loadExternalPSKs succeeded with a TLS 1.2 cipher suite")
+	}
+}
+
+func rawPublicKeyCertificate(t *testing.T, priv crypto.Signer) Certificate {
+	t.Helper()
+	spki, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Certificate{
+		Certificate: [][]byte{spki},
+		PrivateKey:  priv,
+		Type:        CertificateTypeRawPublicKey,
+	}
+}
+
+func verifyRawPublicKeyIs(want crypto.PublicKey) func(crypto.PublicKey) error {
+	return func(pub crypto.PublicKey) error {
+		if !pub.(interface{ Equal(crypto.PublicKey) bool }).Equal(want) {
+			return errors.New("unexpected raw public key")
+		}
+		return nil
+	}
+}
+
+func TestRawPublicKey(t *testing.T) {
+	serverCert := rawPublicKeyCertificate(t, testEd25519PrivateKey)
+	clientCert := rawPublicKeyCertificate(t, testECDSAPrivateKey)
+
+	for _, tt := range []struct {
+		name   string
+		client func(*Config)
+		server func(*Config)
+		// wantServerKey and wantClientKey are the raw public keys expected
+		// to be reported by the client and by the server, respectively.
+		wantServerKey crypto.PublicKey
+		wantClientKey crypto.PublicKey
+		err           string
+	}{
+		{
+			name: "server",
+			client: func(c *Config) {
+				c.InsecureSkipVerify = false
+				c.PeerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = verifyRawPublicKeyIs(testEd25519PrivateKey.Public())
+			},
+			server: func(c *Config) {
+				c.Certificates = []Certificate{serverCert}
+			},
+			wantServerKey: testEd25519PrivateKey.Public(),
+		},
+		{
+			name: "mutual",
+			client: func(c *Config) {
+				c.PeerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = verifyRawPublicKeyIs(testEd25519PrivateKey.Public())
+				c.Certificates = []Certificate{clientCert}
+			},
+			server: func(c *Config) {
+				c.Certificates = []Certificate{serverCert}
+				c.ClientAuth = RequireAnyClientCert
+				c.PeerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = verifyRawPublicKeyIs(testECDSAPrivateKey.Public())
+			},
+			wantServerKey: testEd25519PrivateKey.Public(),
+			wantClientKey: testECDSAPrivateKey.Public(),
+		},
+		{
+			name: "X.509 fallback",
+			client: func(c *Config) {
+				c.PeerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey, CertificateTypeX509}
+				c.VerifyRawPublicKey = verifyRawPublicKeyIs(testEd25519PrivateKey.Public())
+			},
+		},
+		{
+			name: "not accepted by client",
+			server: func(c *Config) {
+				c.Certificates = []Certificate{serverCert}
+			},
+			err: "does not accept the type",
+		},
+		{
+			name: "not offered by server",
+			client: func(c *Config) {
+				c.PeerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = verifyRawPublicKeyIs(testEd25519PrivateKey.Public())
+			},
+			err: "does not accept the type",
+		},
+		{
+			name: "rejected",
+			client: func(c *Config) {
+				c.PeerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = verifyRawPublicKeyIs(testECDSAPrivateKey.Public())
+			},
+			server: func(c *Config) {
+				c.Certificates = []Certificate{serverCert}
+			},
+			err: "unexpected raw public key",
+		},
+		{
+			name: "no VerifyRawPublicKey",
+			client: func(c *Config) {
+				c.PeerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+			},
+			server: func(c *Config) {
+				c.Certificates = []Certificate{serverCert}
+			},
+			err: "VerifyRawPublicKey is not set",
+		},
+		{
+			name: "no client type in common",
+			client: func(c *Config) {
+				c.Certificates = []Certificate{clientCert}
+			},
+			server: func(c *Config) {
+				c.ClientAuth = RequireAnyClientCert
+				c.PeerCertificateTypes = []CertificateType{CertificateTypeX509}
+			},
+			err: "no client certificate type supported",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig := testConfig.Clone()
+			clientConfig.MinVersion = VersionTLS13
+			if tt.client != nil {
+				tt.client(clientConfig)
+			}
+			serverConfig := testConfig.Clone()
+			serverConfig.MinVersion = VersionTLS13
+			if tt.server != nil {
+				tt.server(serverConfig)
+			}
+
+			serverState, clientState, err := testHandshake(t, clientConfig, serverConfig)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("handshake error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+			checkKey := func(side string, got, want crypto.PublicKey) {
+				t.Helper()
+				if want == nil {
+					if got != nil {
+						t.Errorf("%s: PeerRawPublicKey = %v, want nil", side, got)
+					}
+					return
+				}
+				if got == nil || !got.(interface{ Equal(crypto.PublicKey) bool }).Equal(want) {
+					t.Errorf("%s: PeerRawPublicKey = %v, want %v", side, got, want)
+				}
+			}
+			checkKey("client", clientState.PeerRawPublicKey, tt.wantServerKey)
+			checkKey("server", serverState.PeerRawPublicKey, tt.wantClientKey)
+			if tt.wantServerKey != nil && len(clientState.PeerCertificates) != 0 {
+				t.Error("client: PeerCertificates is not empty")
+			}
+		})
+	}
+}
@@ -3,7 +3,8 @@
 // license that can be found in the LICENSE file.
 
 // Package tls partially implements TLS 1.2, as specified in RFC 5246,
-// and TLS 1.3, as specified in RFC 8446.
+// TLS 1.3, as specified in RFC 8446, DTLS 1.2, as specified in RFC 6347, and
+// DTLS 1.3, as specified in RFC 9147.
 //
 // # FIPS 140-3 mode
 //
@@ -28,6 +29,7 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/keystore"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -240,6 +242,10 @@ func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Con
 // contain intermediate certificates following the leaf certificate to form a
 // certificate chain. On successful return, Certificate.Leaf will be populated.
 //
+// If keyFile is a PKCS #11 URI (starting with "pkcs11:"), the private key is
+// instead looked up among the keys of the providers registered with
+// [keystore.Register], using [keystore.FindKey].
+//
 // Before Go 1.23 Certificate.Leaf was left nil, and the parsed certificate was
 // discarded. This behavior can be re-enabled by setting "x509keypairleaf=0"
 // in the GODEBUG environment variable.
@@ -248,6 +254,9 @@ func LoadX509KeyPair(certFile, keyFile string) (Certificate, error) {
 	if err != nil {
 		return Certificate{}, err
 	}
+	if strings.HasPrefix(keyFile, "pkcs11:") {
+		return keystoreKeyPair(certPEMBlock, keyFile)
+	}
 	keyPEMBlock, err := os.ReadFile(keyFile)
 	if err != nil {
 		return Certificate{}, err
@@ -266,32 +275,12 @@ var x509keypairleaf = godebug.New("x509keypairleaf")
 func X509KeyPair(certPEMBlock, keyPEMBlock []byte) (Certificate, error) {
 	fail := func(err error) (Certificate, error) { return Certificate{}, err }
 
-	var cert Certificate
-	var skippedBlockTypes []string
-	for {
-		var certDERBlock *pem.Block
-		certDERBlock, certPEMBlock = pem.Decode(certPEMBlock)
-		if certDERBlock == nil {
-			break
-		}
-		if certDERBlock.Type == "CERTIFICATE" {
-			cert.Certificate = append(cert.Certificate, certDERBlock.Bytes)
-		} else {
-			skippedBlockTypes = append(skippedBlockTypes, certDERBlock.Type)
-		}
-	}
-
-	if len(cert.Certificate) == 0 {
-		if len(skippedBlockTypes) == 0 {
-			return fail(errors.New("tls: failed to find any PEM data in certificate input"))
-		}
-		if len(skippedBlockTypes) == 1 && strings.HasSuffix(skippedBlockTypes[0], "PRIVATE KEY") {
-			return fail(errors.New("tls: failed to find certificate PEM data in certificate input, but did find a private key; PEM inputs may have been switched"))
-		}
-		return fail(fmt.Errorf("tls: failed to find \"CERTIFICATE\" PEM block in certificate input after skipping PEM blocks of the following types: %v", skippedBlockTypes))
+	cert, x509Cert, err := parseCertificatePEM(certPEMBlock)
+	if err != nil {
+		return fail(err)
 	}
 
-	skippedBlockTypes = skippedBlockTypes[:0]
+	var skippedBlockTypes []string
 	var keyDERBlock *pem.Block
 	for {
 		keyDERBlock, keyPEMBlock = pem.Decode(keyPEMBlock)
@@ -310,19 +299,6 @@ func X509KeyPair(certPEMBlock, keyPEMBlock []byte) (Certificate, error) {
 		skippedBlockTypes = append(skippedBlockTypes, keyDERBlock.Type)
 	}
 
-	// We don't need to parse the public key for TLS, but we so do anyway
-	// to check that it looks sane and matches the private key.
-	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
-	if err != nil {
-		return fail(err)
-	}
-
-	if x509keypairleaf.Value() != "0" {
-		cert.Leaf = x509Cert
-	} else {
-		x509keypairleaf.IncNonDefault()
-	}
-
 	cert.PrivateKey, err = parsePrivateKey(keyDERBlock.Bytes)
 	if err != nil {
 		return fail(err)
@@ -360,6 +336,71 @@ func X509KeyPair(certPEMBlock, keyPEMBlock []byte) (Certificate, error) {
 	return cert, nil
 }
 
+// parseCertificatePEM parses the certificate chain in certPEMBlock. It sets Certificate.Leaf according to the
+// x509keypairleaf GODEBUG setting, and always returns the parsed leaf.
+func parseCertificatePEM(certPEMBlock []byte) (Certificate, *x509.Certificate, error) {
+	fail := func(err error) (Certificate, *x509.Certificate, error) { return Certificate{}, nil, err }
+
+	var cert Certificate
+	var skippedBlockTypes []string
+	for {
+		var certDERBlock *pem.Block
+		certDERBlock, certPEMBlock = pem.Decode(certPEMBlock)
+		if certDERBlock == nil {
+			break
+		}
+		if certDERBlock.Type == "CERTIFICATE" {
+			cert.Certificate = append(cert.Certificate, certDERBlock.Bytes)
+		} else {
+			skippedBlockTypes = append(skippedBlockTypes, certDERBlock.Type)
+		}
+	}
+
+	if len(cert.Certificate) == 0 {
+		if len(skippedBlockTypes) == 0 {
+			return fail(errors.New("tls: failed to find any PEM data in certificate input"))
+		}
+		if len(skippedBlockTypes) == 1 && strings.HasSuffix(skippedBlockTypes[0], "PRIVATE KEY") {
+			return fail(errors.New("tls: failed to find certificate PEM data in certificate input, but did find a private key; PEM inputs may have been switched"))
+		}
+		return fail(fmt.Errorf("tls: failed to find \"CERTIFICATE\" PEM block in certificate input after skipping PEM blocks of the following types: %v", skippedBlockTypes))
+	}
+
+	// We don't need to parse the public key for TLS, but we so do anyway
+	// to check that it looks sane and matches the private key.
+	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fail(err)
+	}
+
+	if x509keypairleaf.Value() != "0" {
+		cert.Leaf = x509Cert
+	} else {
+		x509keypairleaf.IncNonDefault()
+	}
+
+	return cert, x509Cert, nil
+}
+
+// keystoreKeyPair returns the certificate chain in certPEMBlock, with the
+// private key identified by the PKCS #11 URI keyURI.
+func keystoreKeyPair(certPEMBlock []byte, keyURI string) (Certificate, error) {
+	cert, x509Cert, err := parseCertificatePEM(certPEMBlock)
+	if err != nil {
+		return Certificate{}, err
+	}
+	key, err := keystore.FindKey(keyURI)
+	if err != nil {
+		return Certificate{}, err
+	}
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(x509Cert.PublicKey) {
+		return Certificate{}, errors.New("tls: private key does not match public key")
+	}
+	cert.PrivateKey = key
+	return cert, nil
+}
+
 // Attempt to parse the given private key DER block. OpenSSL 0.9.8 generates
 // PKCS #1 private keys by default, while OpenSSL 1.0.0 generates PKCS #8 keys.
 // OpenSSL ecparam generates SEC1 EC private keys for ECDSA. We try all three.
@@ -12,6 +12,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/internal/boring"
+	"crypto/keystore"
 	"crypto/rand"
 	"crypto/tls/internal/fips140tls"
 	"crypto/x509"
@@ -27,6 +28,7 @@ import (
 	"math/big"
 	"net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"slices"
 	"strings"
@@ -167,6 +169,57 @@ func TestX509MixedKeyPair(t *testing.T) {
 	}
 }
 
+func TestLoadX509KeyPairKeystore(t *testing.T) {
+	dir := t.TempDir()
+	token, err := keystore.OpenSoftToken(filepath.Join(dir, "token.pem"), "tls-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keystore.Register(token)
+	defer keystore.Unregister(token)
+
+	for _, test := range keyPairTests {
+		pair, err := X509KeyPair([]byte(test.cert), []byte(test.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := token.ImportKey(test.algo, nil, pair.PrivateKey.(crypto.Signer)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ecdsaCertFile := filepath.Join(dir, "ecdsa.pem")
+	rsaCertFile := filepath.Join(dir, "rsa.pem")
+	if err := os.WriteFile(ecdsaCertFile, []byte(ecdsaCertPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rsaCertFile, []byte(rsaCertPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := LoadX509KeyPair(ecdsaCertFile, "pkcs11:token=tls-test;object=ECDSA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cert.PrivateKey.(keystore.Key); !ok {
+		t.Fatalf("PrivateKey is %T, want a keystore.Key", cert.PrivateKey)
+	}
+	if cert.Leaf == nil {
+		t.Error("Leaf is nil")
+	}
+	serverConfig := testConfig.Clone()
+	serverConfig.Certificates = []Certificate{cert}
+	if _, _, err := testHandshake(t, testConfig.Clone(), serverConfig); err != nil {
+		t.Errorf("handshake with keystore key failed: %v", err)
+	}
+
+	if _, err := LoadX509KeyPair(rsaCertFile, "pkcs11:token=tls-test;object=ECDSA"); err == nil {
+		t.Error("Load of RSA certificate succeeded with ECDSA keystore key")
+	}
+	if _, err := LoadX509KeyPair(rsaCertFile, "pkcs11:token=tls-test;object=missing"); err == nil {
+		t.Error("Load succeeded with a missing keystore key")
+	}
+}
+
 func newLocalListener(t testing.TB) net.Listener {
 	t.Helper()
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
@@ -846,7 +899,7 @@ func TestWarningAlertFlood(t *testing.T) {
 }
 
 func TestCloneFuncFields(t *testing.T) {
-	const expectedCount = 10
+	const expectedCount = 13
 	called := 0
 
 	c1 := Config{
@@ -890,6 +943,17 @@ func TestCloneFuncFields(t *testing.T) {
 			called |= 1 << 9
 			return nil, nil
 		},
+		GetExternalPSK: func([]byte) (*ExternalPSK, error) {
+			called |= 1 << 10
+			return nil, nil
+		},
+		VerifyRawPublicKey: func(crypto.PublicKey) error {
+			called |= 1 << 11
+			return nil
+		},
+		HandshakeTrace: func(HandshakeEvent) {
+			called |= 1 << 12
+		},
 	}
 
 	c2 := c1.Clone()
@@ -904,6 +968,9 @@ func TestCloneFuncFields(t *testing.T) {
 	c2.WrapSession(ConnectionState{}, nil)
 	c2.EncryptedClientHelloRejectionVerify(ConnectionState{})
 	c2.GetEncryptedClientHelloKeys(nil)
+	c2.GetExternalPSK(nil)
+	c2.VerifyRawPublicKey(nil)
+	c2.HandshakeTrace(HandshakeEvent{})
 
 	if called != (1<<expectedCount)-1 {
 		t.Fatalf("expected %d calls but saw calls %b", expectedCount, called)
@@ -922,7 +989,7 @@ func TestCloneNonFuncFields(t *testing.T) {
 		switch fn := typ.Field(i).Name; fn {
 		case "Rand":
 			f.Set(reflect.ValueOf(io.Reader(os.Stdin)))
-		case "Time", "GetCertificate", "GetConfigForClient", "VerifyPeerCertificate", "VerifyConnection", "GetClientCertificate", "WrapSession", "UnwrapSession", "EncryptedClientHelloRejectionVerify", "GetEncryptedClientHelloKeys":
+		case "Time", "GetCertificate", "GetConfigForClient", "VerifyPeerCertificate", "VerifyConnection", "GetClientCertificate", "WrapSession", "UnwrapSession", "EncryptedClientHelloRejectionVerify", "GetEncryptedClientHelloKeys", "GetExternalPSK", "VerifyRawPublicKey", "HandshakeTrace":
 			// DeepEqual can't compare functions. If you add a
 			// function field to this list, you must also change
 			// TestCloneFuncFields to ensure that the func field is
@@ -963,6 +1030,12 @@ func TestCloneNonFuncFields(t *testing.T) {
 			f.Set(reflect.ValueOf([]EncryptedClientHelloKey{
 				{Config: []byte{1}, PrivateKey: []byte{1}},
 			}))
+		case "ExternalPSKs":
+			f.Set(reflect.ValueOf([]ExternalPSK{
+				{Identity: []byte{1}, Key: []byte{2}},
+			}))
+		case "PeerCertificateTypes":
+			f.Set(reflect.ValueOf([]CertificateType{CertificateTypeRawPublicKey}))
 		case "mutex", "autoSessionTicketKeys", "sessionTicketKeys":
 			continue // these are unexported fields that are handled separately
 		default:
@@ -0,0 +1,117 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import "net"
+
+// A HandshakeEventKind is a type of event reported to [Config.HandshakeTrace].
+type HandshakeEventKind int
+
+const (
+	// HandshakeMessageSent and HandshakeMessageReceived indicate that a
+	// handshake message was sent to or received from the peer.
+	// HandshakeEvent.MessageType is set.
+	//
+	// Received messages are reported once they are parsed, but before they
+	// are processed, and sent messages before they are written.
+	HandshakeMessageSent HandshakeEventKind = iota + 1
+	HandshakeMessageReceived
+
+	// HandshakeCipherSuiteSelected indicates that the protocol version and
+	// the cipher suite were negotiated.
+	// HandshakeEvent.Version and HandshakeEvent.CipherSuite are set.
+	HandshakeCipherSuiteSelected
+
+	// HandshakeGroupSelected indicates that the key exchange group was
+	// negotiated. It doesn't occur when resuming a TLS 1.2 session.
+	// HandshakeEvent.Group is set.
+	HandshakeGroupSelected
+
+	// HandshakeSignatureSchemeSelected indicates that a signature was made or
+	// verified as part of the handshake. HandshakeEvent.Peer reports whether
+	// the signature was made by the peer.
+	// HandshakeEvent.SignatureScheme and HandshakeEvent.Peer are set.
+	HandshakeSignatureSchemeSelected
+
+	// HandshakeHelloRetryRequest indicates that the server sent, or the
+	// client received, a TLS 1.3 HelloRetryRequest. HandshakeEvent.Group is
+	// the group the server requested a key share for, or zero if the
+	// HelloRetryRequest didn't request one.
+	// HandshakeEvent.Group is set.
+	HandshakeHelloRetryRequest
+
+	// HandshakeECHAccepted and HandshakeECHRejected indicate whether the
+	// server accepted the Encrypted Client Hello offered by the client.
+	// For HandshakeECHRejected, HandshakeEvent.Reason is set on servers.
+	HandshakeECHAccepted
+	HandshakeECHRejected
+
+	// HandshakeSessionResumed indicates that a previous session is being
+	// resumed.
+	HandshakeSessionResumed
+
+	// HandshakeSessionNotResumed indicates that a session offered by the
+	// client was not resumed. It occurs for each session ticket rejected by a
+	// server, and on clients if the server didn't resume the offered session.
+	// HandshakeEvent.Reason is set.
+	HandshakeSessionNotResumed
+
+	// HandshakeAlertSent and HandshakeAlertReceived indicate that a TLS alert
+	// was sent to or received from the peer.
+	// HandshakeEvent.Alert is set.
+	HandshakeAlertSent
+	HandshakeAlertReceived
+)
+
+// A HandshakeEvent describes a step of a TLS handshake. See
+// [Config.HandshakeTrace].
+//
+// Which fields are set depends on the Kind of the event; the others are zero.
+type HandshakeEvent struct {
+	Kind HandshakeEventKind
+
+	// Conn is the underlying net.Conn of the connection, or nil for QUIC
+	// connections.
+	Conn net.Conn
+
+	// MessageType is the HandshakeType of the message, as assigned by
+	// https://www.iana.org/assignments/tls-parameters/#tls-parameters-7.
+	MessageType uint8
+
+	Version         uint16
+	CipherSuite     uint16
+	Group           CurveID
+	SignatureScheme SignatureScheme
+	Peer            bool
+	Alert           AlertError
+
+	// Reason is a human-readable explanation of a negative decision. Its
+	// contents are not stable, and are only meant for logging.
+	Reason string
+}
+
+// traceHandshake reports e to Config.HandshakeTrace, if set.
+func (c *Conn) traceHandshake(e HandshakeEvent) {
+	if c.config == nil || c.config.HandshakeTrace == nil {
+		return
+	}
+	e.Conn = c.conn
+	c.config.HandshakeTrace(e)
+}
+
+// traceCipherSuite reports a HandshakeCipherSuiteSelected event for the
+// negotiated version and c.cipherSuite.
+func (c *Conn) traceCipherSuite() {
+	vers := c.vers
+	if c.dtls != nil {
+		vers = dtlsVersion(vers)
+	}
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeCipherSuiteSelected, Version: vers, CipherSuite: c.cipherSuite})
+}
+
+// traceSessionNotResumed reports a HandshakeSessionNotResumed event.
+func (c *Conn) traceSessionNotResumed(reason string) {
+	c.traceHandshake(HandshakeEvent{Kind: HandshakeSessionNotResumed, Reason: reason})
+}
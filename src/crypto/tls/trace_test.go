@@ -0,0 +1,235 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// traceRecorder collects the events reported to Config.HandshakeTrace.
+type traceRecorder struct {
+	mu     sync.Mutex
+	events []HandshakeEvent
+}
+
+func (r *traceRecorder) trace(e HandshakeEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e.Conn == nil {
+		panic("HandshakeEvent.Conn is nil")
+	}
+	e.Conn = nil
+	r.events = append(r.events, e)
+}
+
+func (r *traceRecorder) has(want HandshakeEvent) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Contains(r.events, want)
+}
+
+func (r *traceRecorder) kinds(kind HandshakeEventKind) []HandshakeEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []HandshakeEvent
+	for _, e := range r.events {
+		if e.Kind == kind {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func TestHandshakeTrace(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		setup      func(clientConfig, serverConfig *Config)
+		wantErr    bool
+		wantClient []HandshakeEvent
+		wantServer []HandshakeEvent
+	}{
+		{
+			name: "TLS 1.3",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.CurvePreferences = []CurveID{X25519}
+			},
+			wantClient: []HandshakeEvent{
+				{Kind: HandshakeMessageSent, MessageType: typeClientHello},
+				{Kind: HandshakeMessageReceived, MessageType: typeServerHello},
+				{Kind: HandshakeMessageReceived, MessageType: typeCertificateVerify},
+				{Kind: HandshakeMessageSent, MessageType: typeFinished},
+				{Kind: HandshakeGroupSelected, Group: X25519},
+				{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: PSSWithSHA256, Peer: true},
+			},
+			wantServer: []HandshakeEvent{
+				{Kind: HandshakeMessageReceived, MessageType: typeClientHello},
+				{Kind: HandshakeMessageSent, MessageType: typeServerHello},
+				{Kind: HandshakeMessageReceived, MessageType: typeFinished},
+				{Kind: HandshakeGroupSelected, Group: X25519},
+				{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: PSSWithSHA256},
+			},
+		},
+		{
+			name: "TLS 1.2",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.MaxVersion = VersionTLS12
+				clientConfig.CipherSuites = []uint16{TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+				clientConfig.CurvePreferences = []CurveID{CurveP256}
+			},
+			wantClient: []HandshakeEvent{
+				{Kind: HandshakeMessageReceived, MessageType: typeServerKeyExchange},
+				{Kind: HandshakeCipherSuiteSelected, Version: VersionTLS12, CipherSuite: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+				{Kind: HandshakeGroupSelected, Group: CurveP256},
+				{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: PSSWithSHA256, Peer: true},
+			},
+			wantServer: []HandshakeEvent{
+				{Kind: HandshakeMessageSent, MessageType: typeServerHelloDone},
+				{Kind: HandshakeCipherSuiteSelected, Version: VersionTLS12, CipherSuite: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+				{Kind: HandshakeGroupSelected, Group: CurveP256},
+				{Kind: HandshakeSignatureSchemeSelected, SignatureScheme: PSSWithSHA256},
+			},
+		},
+		{
+			name: "HelloRetryRequest",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.MinVersion = VersionTLS13
+				clientConfig.CurvePreferences = []CurveID{X25519, CurveP256}
+				serverConfig.CurvePreferences = []CurveID{CurveP256}
+			},
+			wantClient: []HandshakeEvent{
+				{Kind: HandshakeHelloRetryRequest, Group: CurveP256},
+				{Kind: HandshakeGroupSelected, Group: CurveP256},
+			},
+			wantServer: []HandshakeEvent{
+				{Kind: HandshakeHelloRetryRequest, Group: CurveP256},
+				{Kind: HandshakeGroupSelected, Group: CurveP256},
+			},
+		},
+		{
+			name: "certificate verification failure",
+			setup: func(clientConfig, serverConfig *Config) {
+				clientConfig.InsecureSkipVerify = false
+				clientConfig.ServerName = "example.golang"
+			},
+			wantErr: true,
+			wantClient: []HandshakeEvent{
+				{Kind: HandshakeAlertSent, Alert: AlertError(alertBadCertificate)},
+			},
+			wantServer: []HandshakeEvent{
+				{Kind: HandshakeAlertReceived, Alert: AlertError(alertBadCertificate)},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var clientTrace, serverTrace traceRecorder
+			clientConfig := testConfig.Clone()
+			clientConfig.HandshakeTrace = clientTrace.trace
+			serverConfig := testConfig.Clone()
+			serverConfig.HandshakeTrace = serverTrace.trace
+			tt.setup(clientConfig, serverConfig)
+
+			serverState, clientState, err := testHandshake(t, clientConfig, serverConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handshake error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil {
+				tt.wantClient = append(tt.wantClient, HandshakeEvent{Kind: HandshakeCipherSuiteSelected,
+					Version: clientState.Version, CipherSuite: clientState.CipherSuite})
+				tt.wantServer = append(tt.wantServer, HandshakeEvent{Kind: HandshakeCipherSuiteSelected,
+					Version: serverState.Version, CipherSuite: serverState.CipherSuite})
+			}
+			for _, e := range tt.wantClient {
+				if !clientTrace.has(e) {
+					t.Errorf("client events %+v don't include %+v", clientTrace.events, e)
+				}
+			}
+			for _, e := range tt.wantServer {
+				if !serverTrace.has(e) {
+					t.Errorf("server events %+v don't include %+v", serverTrace.events, e)
+				}
+			}
+		})
+	}
+}
+
+func TestHandshakeTraceResumption(t *testing.T) {
+	for _, version := range []uint16{VersionTLS12, VersionTLS13} {
+		t.Run(VersionName(version), func(t *testing.T) {
+			var clientTrace, serverTrace traceRecorder
+			clientConfig := testConfig.Clone()
+			clientConfig.MaxVersion = version
+			clientConfig.ClientSessionCache = NewLRUClientSessionCache(1)
+			clientConfig.HandshakeTrace = clientTrace.trace
+			serverConfig := testConfig.Clone()
+			serverConfig.MaxVersion = version
+			serverConfig.HandshakeTrace = serverTrace.trace
+
+			if _, _, err := testHandshake(t, clientConfig, serverConfig); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := testHandshake(t, clientConfig, serverConfig); err != nil {
+				t.Fatal(err)
+			}
+			if len(clientTrace.kinds(HandshakeSessionResumed)) != 1 {
+				t.Errorf("client reported %d resumptions, want 1", len(clientTrace.kinds(HandshakeSessionResumed)))
+			}
+			if len(serverTrace.kinds(HandshakeSessionResumed)) != 1 {
+				t.Errorf("server reported %d resumptions, want 1", len(serverTrace.kinds(HandshakeSessionResumed)))
+			}
+
+			// Rotate the ticket keys, so that the next ticket is rejected.
+			serverConfig.SetSessionTicketKeys([][32]byte{{1}})
+			if _, _, err := testHandshake(t, clientConfig, serverConfig); err != nil {
+				t.Fatal(err)
+			}
+			if !serverTrace.has(HandshakeEvent{Kind: HandshakeSessionNotResumed, Reason: "ticket could not be decrypted"}) {
+				t.Errorf("server events %+v don't include the rejected ticket", serverTrace.kinds(HandshakeSessionNotResumed))
+			}
+			if len(clientTrace.kinds(HandshakeSessionNotResumed)) != 1 {
+				t.Errorf("client events %+v don't include the rejected session", clientTrace.events)
+			}
+		})
+	}
+}
+
+func TestHandshakeTraceExternalPSK(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	for _, tt := range []struct {
+		name           string
+		ticketsEnabled bool
+		reason         string
+	}{
+		{"SessionTicketsDisabled", false, "identity matched no external PSK"},
+		{"SessionTicketsEnabled", true, "identity matched no external PSK or session ticket"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverTrace traceRecorder
+			clientConfig := testConfig.Clone()
+			clientConfig.MinVersion = VersionTLS13
+			clientConfig.ExternalPSKs = []ExternalPSK{
+				{Identity: []byte("unknown"), Key: key},
+				{Identity: []byte("client1"), Key: key},
+			}
+			serverConfig := testConfig.Clone()
+			serverConfig.MinVersion = VersionTLS13
+			serverConfig.ExternalPSKs = []ExternalPSK{{Identity: []byte("client1"), Key: key}}
+			serverConfig.SessionTicketsDisabled = !tt.ticketsEnabled
+			serverConfig.HandshakeTrace = serverTrace.trace
+
+			if _, _, err := testHandshake(t, clientConfig, serverConfig); err != nil {
+				t.Fatal(err)
+			}
+			if !serverTrace.has(HandshakeEvent{Kind: HandshakeSessionNotResumed, Reason: tt.reason}) {
+				t.Errorf("server events %+v don't include %q", serverTrace.kinds(HandshakeSessionNotResumed), tt.reason)
+			}
+			if serverTrace.has(HandshakeEvent{Kind: HandshakeSessionNotResumed, Reason: "ticket could not be decrypted"}) {
+				t.Error("unmatched external PSK reported as an undecryptable ticket")
+			}
+		})
+	}
+}
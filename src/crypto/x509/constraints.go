@@ -498,6 +498,19 @@ func (cc *chainConstraints) check(dns []string, uris []parsedURI, emails []parse
 	return nil
 }
 
+// newChainConstraints returns the name constraints described by the
+// permitted and excluded subtree fields of c, which is at position index in
+// the chain being checked.
+func newChainConstraints(c *Certificate, index int) *chainConstraints {
+	return &chainConstraints{
+		ip:    constraints[*net.IPNet, net.IP]{"IP address", newIPNetConstraints(c.PermittedIPRanges), newIPNetConstraints(c.ExcludedIPRanges)},
+		dns:   constraints[string, string]{"DNS name", newDNSConstraints(c.PermittedDNSDomains, true), newDNSConstraints(c.ExcludedDNSDomains, false)},
+		uri:   constraints[string, string]{"URI", newDNSConstraints(c.PermittedURIDomains, true), newDNSConstraints(c.ExcludedURIDomains, false)},
+		email: constraints[string, parsedEmail]{"email address", newEmailConstraints(c.PermittedEmailAddresses, true), newEmailConstraints(c.ExcludedEmailAddresses, false)},
+		index: index,
+	}
+}
+
 func checkChainConstraints(chain []*Certificate) error {
 	var currentConstraints *chainConstraints
 	var last *chainConstraints
@@ -505,13 +518,7 @@ func checkChainConstraints(chain []*Certificate) error {
 		if !c.hasNameConstraints() {
 			continue
 		}
-		cc := &chainConstraints{
-			ip:    constraints[*net.IPNet, net.IP]{"IP address", newIPNetConstraints(c.PermittedIPRanges), newIPNetConstraints(c.ExcludedIPRanges)},
-			dns:   constraints[string, string]{"DNS name", newDNSConstraints(c.PermittedDNSDomains, true), newDNSConstraints(c.ExcludedDNSDomains, false)},
-			uri:   constraints[string, string]{"URI", newDNSConstraints(c.PermittedURIDomains, true), newDNSConstraints(c.ExcludedURIDomains, false)},
-			email: constraints[string, parsedEmail]{"email address", newEmailConstraints(c.PermittedEmailAddresses, true), newEmailConstraints(c.ExcludedEmailAddresses, false)},
-			index: i,
-		}
+		cc := newChainConstraints(c, i)
 		if currentConstraints == nil {
 			currentConstraints = cc
 			last = cc
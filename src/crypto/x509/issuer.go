@@ -0,0 +1,360 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// IssuancePolicy describes the certificates an [Issuer] is willing to sign.
+//
+// The zero value permits any subject alternative names, key usages and
+// validity period that the issuing certificate itself allows, but never the
+// issuance of CA certificates.
+type IssuancePolicy struct {
+	// MaxValidity, if non-zero, is the longest validity period, measured
+	// from NotBefore to NotAfter, that an issued certificate may have.
+	MaxValidity time.Duration
+
+	// KeyUsage, if non-zero, is the set of key usages that issued
+	// certificates may assert. Issued certificates must then assert at
+	// least one of them, since a certificate without a key usage extension
+	// is not restricted to any.
+	KeyUsage KeyUsage
+
+	// ExtKeyUsage, if non-empty, lists the extended key usages that issued
+	// certificates may assert. Issued certificates must then assert at
+	// least one of them, for the same reason. If it contains
+	// ExtKeyUsageAny, any extended key usage, including unknown ones, is
+	// permitted, and issued certificates need not assert any.
+	ExtKeyUsage []ExtKeyUsage
+
+	// AllowCA permits the issuance of certificates with IsCA set. If the
+	// issuing certificate has a path length constraint, issued CA
+	// certificates must have a smaller MaxPathLen.
+	AllowCA bool
+
+	// The following fields constrain the subject alternative names of
+	// issued certificates. They have the same semantics as the
+	// corresponding name constraint fields of [Certificate], and are
+	// enforced in addition to any name constraints of the issuing
+	// certificate.
+	PermittedDNSDomains     []string
+	ExcludedDNSDomains      []string
+	PermittedIPRanges       []*net.IPNet
+	ExcludedIPRanges        []*net.IPNet
+	PermittedEmailAddresses []string
+	ExcludedEmailAddresses  []string
+	PermittedURIDomains     []string
+	ExcludedURIDomains      []string
+}
+
+// hasNameConstraintFields reports whether any of the name constraint fields
+// of c are populated. Unlike hasNameConstraints, it does not require c to
+// have been parsed.
+func hasNameConstraintFields(c *Certificate) bool {
+	return len(c.PermittedDNSDomains) > 0 || len(c.ExcludedDNSDomains) > 0 ||
+		len(c.PermittedIPRanges) > 0 || len(c.ExcludedIPRanges) > 0 ||
+		len(c.PermittedEmailAddresses) > 0 || len(c.ExcludedEmailAddresses) > 0 ||
+		len(c.PermittedURIDomains) > 0 || len(c.ExcludedURIDomains) > 0
+}
+
+// An Issuer signs certificates and certificate revocation lists on behalf of
+// a certificate authority, enforcing an [IssuancePolicy] on every certificate
+// it creates.
+//
+// An Issuer is safe for concurrent use by multiple goroutines.
+type Issuer struct {
+	cert        *Certificate
+	priv        crypto.Signer
+	policy      IssuancePolicy
+	constraints []*chainConstraints
+
+	mu        sync.Mutex
+	revoked   []RevocationListEntry
+	crlNumber *big.Int
+}
+
+// NewIssuer returns an Issuer that signs with priv on behalf of cert.
+//
+// cert must be a CA certificate permitted to sign certificates, and priv must
+// correspond to its public key. If policy is nil, the zero IssuancePolicy is
+// used. policy is copied and may be reused after NewIssuer returns.
+func NewIssuer(cert *Certificate, priv crypto.Signer, policy *IssuancePolicy) (*Issuer, error) {
+	if cert == nil {
+		return nil, errors.New("x509: issuer certificate can not be nil")
+	}
+	if priv == nil {
+		return nil, errors.New("x509: issuer private key can not be nil")
+	}
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return nil, errors.New("x509: issuer certificate is not a CA")
+	}
+	if cert.KeyUsage != 0 && cert.KeyUsage&KeyUsageCertSign == 0 {
+		return nil, errors.New("x509: issuer certificate must have the keyCertSign key usage bit set")
+	}
+	if pub, ok := priv.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(cert.PublicKey) {
+		return nil, errors.New("x509: issuer private key does not match the certificate public key")
+	}
+
+	ca := &Issuer{cert: cert, priv: priv}
+	if policy != nil {
+		ca.policy = *policy
+		ca.policy.ExtKeyUsage = slices.Clone(policy.ExtKeyUsage)
+		ca.policy.PermittedDNSDomains = slices.Clone(policy.PermittedDNSDomains)
+		ca.policy.ExcludedDNSDomains = slices.Clone(policy.ExcludedDNSDomains)
+		ca.policy.PermittedIPRanges = cloneIPNets(policy.PermittedIPRanges)
+		ca.policy.ExcludedIPRanges = cloneIPNets(policy.ExcludedIPRanges)
+		ca.policy.PermittedEmailAddresses = slices.Clone(policy.PermittedEmailAddresses)
+		ca.policy.ExcludedEmailAddresses = slices.Clone(policy.ExcludedEmailAddresses)
+		ca.policy.PermittedURIDomains = slices.Clone(policy.PermittedURIDomains)
+		ca.policy.ExcludedURIDomains = slices.Clone(policy.ExcludedURIDomains)
+	}
+	p := &ca.policy
+	for _, c := range []*Certificate{cert, {
+		PermittedDNSDomains:     p.PermittedDNSDomains,
+		ExcludedDNSDomains:      p.ExcludedDNSDomains,
+		PermittedIPRanges:       p.PermittedIPRanges,
+		ExcludedIPRanges:        p.ExcludedIPRanges,
+		PermittedEmailAddresses: p.PermittedEmailAddresses,
+		ExcludedEmailAddresses:  p.ExcludedEmailAddresses,
+		PermittedURIDomains:     p.PermittedURIDomains,
+		ExcludedURIDomains:      p.ExcludedURIDomains,
+	}} {
+		if hasNameConstraintFields(c) {
+			ca.constraints = append(ca.constraints, newChainConstraints(c, 0))
+		}
+	}
+	return ca, nil
+}
+
+// cloneIPNets returns a deep copy of nets.
+func cloneIPNets(nets []*net.IPNet) []*net.IPNet {
+	if nets == nil {
+		return nil
+	}
+	c := make([]*net.IPNet, len(nets))
+	for i, n := range nets {
+		c[i] = &net.IPNet{IP: slices.Clone(n.IP), Mask: slices.Clone(n.Mask)}
+	}
+	return c
+}
+
+// Certificate returns the certificate of the issuing CA.
+func (ca *Issuer) Certificate() *Certificate {
+	return ca.cert
+}
+
+// Issue creates a certificate for the subject and public key of csr, signed
+// by the issuer, and returns it in DER encoding.
+//
+// The signature on csr is verified before anything else. The subject and
+// subject alternative names (DNSNames, EmailAddresses, IPAddresses and URIs)
+// of the new certificate are taken from csr; the corresponding fields of
+// template are ignored. template may not carry subject alternative name, key
+// usage, extended key usage or basic constraints extensions in
+// ExtraExtensions, since those would bypass the policy. All other fields are
+// used as described in [CreateCertificate]. If template.NotBefore is zero,
+// the current time is used, and if template.NotAfter is zero, NotBefore plus
+// the policy's MaxValidity is used.
+//
+// Issue returns an error if the resulting certificate would violate the
+// issuer's [IssuancePolicy], the name constraints of the issuing certificate,
+// or its validity period.
+func (ca *Issuer) Issue(rand io.Reader, csr *CertificateRequest, template *Certificate) ([]byte, error) {
+	if csr == nil {
+		return nil, errors.New("x509: certificate request can not be nil")
+	}
+	if template == nil {
+		return nil, errors.New("x509: template can not be nil")
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("x509: invalid certificate request signature: %w", err)
+	}
+
+	tmpl := *template
+	tmpl.RawSubject = csr.RawSubject
+	tmpl.Subject = csr.Subject
+	tmpl.DNSNames = csr.DNSNames
+	tmpl.EmailAddresses = csr.EmailAddresses
+	tmpl.IPAddresses = csr.IPAddresses
+	tmpl.URIs = csr.URIs
+	for _, ext := range policyExtensions {
+		if oidInExtensions(ext.oid, tmpl.ExtraExtensions) {
+			return nil, fmt.Errorf("x509: template contains a %s ExtraExtension", ext.name)
+		}
+	}
+
+	if tmpl.NotBefore.IsZero() {
+		tmpl.NotBefore = time.Now()
+	}
+	if tmpl.NotAfter.IsZero() {
+		if ca.policy.MaxValidity == 0 {
+			return nil, errors.New("x509: template.NotAfter is zero and the policy has no MaxValidity")
+		}
+		tmpl.NotAfter = tmpl.NotBefore.Add(ca.policy.MaxValidity)
+	}
+	if err := ca.checkPolicy(&tmpl); err != nil {
+		return nil, err
+	}
+
+	return CreateCertificate(rand, &tmpl, ca.cert, csr.PublicKey, ca.priv)
+}
+
+// policyExtensions are the extensions that encode fields which Issue takes
+// from the request or checks against the policy. CreateCertificate would let
+// an ExtraExtensions entry with the same OID replace them, so they are
+// rejected instead.
+var policyExtensions = []struct {
+	oid  asn1.ObjectIdentifier
+	name string
+}{
+	{oidExtensionSubjectAltName, "subject alternative name"},
+	{oidExtensionKeyUsage, "key usage"},
+	{oidExtensionExtendedKeyUsage, "extended key usage"},
+	{oidExtensionBasicConstraints, "basic constraints"},
+}
+
+// checkPolicy reports whether the certificate described by tmpl may be issued.
+func (ca *Issuer) checkPolicy(tmpl *Certificate) error {
+	if !tmpl.NotAfter.After(tmpl.NotBefore) {
+		return errors.New("x509: template.NotAfter is not after template.NotBefore")
+	}
+	if maxValidity := ca.policy.MaxValidity; maxValidity > 0 && tmpl.NotAfter.Sub(tmpl.NotBefore) > maxValidity {
+		return fmt.Errorf("x509: requested validity period exceeds the maximum of %v", maxValidity)
+	}
+	if tmpl.NotBefore.Before(ca.cert.NotBefore) || tmpl.NotAfter.After(ca.cert.NotAfter) {
+		return errors.New("x509: requested validity period is outside the validity period of the issuer")
+	}
+
+	if tmpl.IsCA {
+		if !ca.policy.AllowCA {
+			return errors.New("x509: policy does not permit issuing CA certificates")
+		}
+		if limit := ca.cert.MaxPathLen; limit > 0 || limit == 0 && ca.cert.MaxPathLenZero {
+			if limit == 0 {
+				return errors.New("x509: issuer path length constraint does not permit issuing CA certificates")
+			}
+			// Don't issue CA certificates that claim a longer path than
+			// the issuer's constraint leaves them.
+			if n := tmpl.MaxPathLen; n < 0 || n == 0 && !tmpl.MaxPathLenZero || n >= limit {
+				return fmt.Errorf("x509: issued CA certificates must have a MaxPathLen below the issuer's %d", limit)
+			}
+		}
+	}
+
+	if ku := ca.policy.KeyUsage; ku != 0 {
+		if tmpl.KeyUsage == 0 {
+			return errors.New("x509: policy requires issued certificates to assert a key usage")
+		}
+		if tmpl.KeyUsage&^ku != 0 {
+			return fmt.Errorf("x509: policy does not permit key usage %#x", int(tmpl.KeyUsage&^ku))
+		}
+	}
+	if allowed := ca.policy.ExtKeyUsage; len(allowed) > 0 && !slices.Contains(allowed, ExtKeyUsageAny) {
+		if len(tmpl.ExtKeyUsage) == 0 && len(tmpl.UnknownExtKeyUsage) == 0 {
+			return errors.New("x509: policy requires issued certificates to assert an extended key usage")
+		}
+		for _, eku := range tmpl.ExtKeyUsage {
+			if !slices.Contains(allowed, eku) {
+				return fmt.Errorf("x509: policy does not permit extended key usage %v", eku)
+			}
+		}
+		if len(tmpl.UnknownExtKeyUsage) > 0 {
+			return fmt.Errorf("x509: policy does not permit extended key usage %v", tmpl.UnknownExtKeyUsage[0])
+		}
+	}
+
+	if len(ca.constraints) == 0 {
+		return nil
+	}
+	uris, err := parseURIs(tmpl.URIs)
+	if err != nil {
+		return fmt.Errorf("x509: %w", err)
+	}
+	emails, err := parseMailboxes(tmpl.EmailAddresses)
+	if err != nil {
+		return fmt.Errorf("x509: %w", err)
+	}
+	for _, cc := range ca.constraints {
+		if err := cc.check(tmpl.DNSNames, uris, emails, tmpl.IPAddresses); err != nil {
+			return fmt.Errorf("x509: name constraint violation: %w", err)
+		}
+	}
+	return nil
+}
+
+// Revoke records the revocation of the certificate described by entry, which
+// will be included in every revocation list subsequently created by
+// [Issuer.CreateRevocationList]. If entry.RevocationTime is zero, the current
+// time is used.
+//
+// Revoke returns an error if a certificate with the same serial number has
+// already been revoked.
+func (ca *Issuer) Revoke(entry RevocationListEntry) error {
+	if entry.SerialNumber == nil {
+		return errors.New("x509: revocation entry contains nil SerialNumber field")
+	}
+	if entry.RevocationTime.IsZero() {
+		entry.RevocationTime = time.Now()
+	}
+	entry.Raw = nil
+	entry.SerialNumber = new(big.Int).Set(entry.SerialNumber)
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	for _, e := range ca.revoked {
+		if e.SerialNumber.Cmp(entry.SerialNumber) == 0 {
+			return fmt.Errorf("x509: certificate with serial number %v is already revoked", entry.SerialNumber)
+		}
+	}
+	ca.revoked = append(ca.revoked, entry)
+	return nil
+}
+
+// CreateRevocationList creates a new X.509 v2 [Certificate] Revocation List
+// signed by the issuer, as described in [CreateRevocationList], listing every
+// certificate previously passed to [Issuer.Revoke] in addition to the entries
+// in template.RevokedCertificateEntries.
+//
+// If template.Number is nil, the issuer assigns the successor of the number of
+// the last revocation list it created, starting from 1. If template.ThisUpdate
+// is zero, the current time is used.
+func (ca *Issuer) CreateRevocationList(rand io.Reader, template *RevocationList) ([]byte, error) {
+	if template == nil {
+		return nil, errors.New("x509: template can not be nil")
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	tmpl := *template
+	tmpl.RevokedCertificateEntries = append(slices.Clip(template.RevokedCertificateEntries), ca.revoked...)
+	if tmpl.ThisUpdate.IsZero() {
+		tmpl.ThisUpdate = time.Now()
+	}
+	if tmpl.Number == nil {
+		tmpl.Number = big.NewInt(1)
+		if ca.crlNumber != nil {
+			tmpl.Number.Add(ca.crlNumber, tmpl.Number)
+		}
+	}
+
+	der, err := CreateRevocationList(rand, &tmpl, ca.cert, ca.priv)
+	if err != nil {
+		return nil, err
+	}
+	ca.crlNumber = new(big.Int).Set(tmpl.Number)
+	return der, nil
+}
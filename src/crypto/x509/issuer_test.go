@@ -0,0 +1,379 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestIssuer(t *testing.T, policy *IssuancePolicy, caTemplate *Certificate) *Issuer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Issuer Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              KeyUsageCertSign | KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if caTemplate != nil {
+		tmpl.PermittedDNSDomains = caTemplate.PermittedDNSDomains
+		tmpl.ExcludedDNSDomains = caTemplate.ExcludedDNSDomains
+		tmpl.MaxPathLen = caTemplate.MaxPathLen
+		tmpl.MaxPathLenZero = caTemplate.MaxPathLenZero
+	}
+	der, err := CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := NewIssuer(cert, key, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca
+}
+
+func newTestCSR(t *testing.T, template *CertificateRequest) *CertificateRequest {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csr
+}
+
+func TestIssuerIssue(t *testing.T) {
+	ca := newTestIssuer(t, &IssuancePolicy{
+		MaxValidity:         90 * 24 * time.Hour,
+		KeyUsage:            KeyUsageDigitalSignature,
+		ExtKeyUsage:         []ExtKeyUsage{ExtKeyUsageServerAuth},
+		PermittedDNSDomains: []string{"example.com"},
+		PermittedIPRanges:   []*net.IPNet{{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)}},
+	}, nil)
+	csr := newTestCSR(t, &CertificateRequest{
+		Subject:     pkix.Name{CommonName: "www.example.com"},
+		DNSNames:    []string{"www.example.com"},
+		IPAddresses: []net.IP{net.IPv4(10, 1, 2, 3)},
+	})
+
+	der, err := ca.Issue(rand.Reader, csr, &Certificate{
+		KeyUsage:    KeyUsageDigitalSignature,
+		ExtKeyUsage: []ExtKeyUsage{ExtKeyUsageServerAuth},
+		// Ignored in favor of the names in the request.
+		DNSNames: []string{"evil.test"},
+	})
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Subject.CommonName != "www.example.com" {
+		t.Errorf("Subject.CommonName = %q, want %q", cert.Subject.CommonName, "www.example.com")
+	}
+	if len(cert.DNSNames) != 1 || cert.DNSNames[0] != "www.example.com" {
+		t.Errorf("DNSNames = %q, want [www.example.com]", cert.DNSNames)
+	}
+	if got := cert.NotAfter.Sub(cert.NotBefore); got > 90*24*time.Hour {
+		t.Errorf("validity period = %v, want at most %v", got, 90*24*time.Hour)
+	}
+
+	roots := NewCertPool()
+	roots.AddCert(ca.Certificate())
+	if _, err := cert.Verify(VerifyOptions{Roots: roots, DNSName: "www.example.com"}); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+}
+
+func TestIssuerPolicyViolations(t *testing.T) {
+	ca := newTestIssuer(t, &IssuancePolicy{
+		MaxValidity:         24 * time.Hour,
+		KeyUsage:            KeyUsageDigitalSignature,
+		ExtKeyUsage:         []ExtKeyUsage{ExtKeyUsageClientAuth},
+		PermittedDNSDomains: []string{"example.com"},
+		ExcludedDNSDomains:  []string{"secret.example.com"},
+	}, &Certificate{ExcludedDNSDomains: []string{"internal.example.com"}})
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		csr      *CertificateRequest
+		template *Certificate
+		err      string
+	}{
+		{
+			name:     "validity too long",
+			csr:      &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{NotBefore: now, NotAfter: now.Add(48 * time.Hour)},
+			err:      "exceeds the maximum",
+		},
+		{
+			name:     "key usage",
+			csr:      &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{KeyUsage: KeyUsageDigitalSignature | KeyUsageKeyEncipherment},
+			err:      "key usage",
+		},
+		{
+			name:     "extended key usage",
+			csr:      &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{KeyUsage: KeyUsageDigitalSignature, ExtKeyUsage: []ExtKeyUsage{ExtKeyUsageServerAuth}},
+			err:      "extended key usage",
+		},
+		{
+			name:     "no key usage",
+			csr:      &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{ExtKeyUsage: []ExtKeyUsage{ExtKeyUsageClientAuth}},
+			err:      "assert a key usage",
+		},
+		{
+			name:     "no extended key usage",
+			csr:      &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{KeyUsage: KeyUsageDigitalSignature},
+			err:      "assert an extended key usage",
+		},
+		{
+			name:     "CA",
+			csr:      &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{BasicConstraintsValid: true, IsCA: true},
+			err:      "CA certificates",
+		},
+		{
+			name:     "not permitted",
+			csr:      &CertificateRequest{DNSNames: []string{"a.example.org"}},
+			template: &Certificate{KeyUsage: KeyUsageDigitalSignature, ExtKeyUsage: []ExtKeyUsage{ExtKeyUsageClientAuth}},
+			err:      "not permitted",
+		},
+		{
+			name:     "excluded by policy",
+			csr:      &CertificateRequest{DNSNames: []string{"x.secret.example.com"}},
+			template: &Certificate{KeyUsage: KeyUsageDigitalSignature, ExtKeyUsage: []ExtKeyUsage{ExtKeyUsageClientAuth}},
+			err:      "excluded",
+		},
+		{
+			name:     "excluded by issuer",
+			csr:      &CertificateRequest{DNSNames: []string{"x.internal.example.com"}},
+			template: &Certificate{KeyUsage: KeyUsageDigitalSignature, ExtKeyUsage: []ExtKeyUsage{ExtKeyUsageClientAuth}},
+			err:      "excluded",
+		},
+		{
+			name: "SAN extra extension",
+			csr:  &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{ExtraExtensions: []pkix.Extension{
+				{Id: oidExtensionSubjectAltName, Value: []byte{0x30, 0x00}},
+			}},
+			err: "subject alternative name",
+		},
+		{
+			name: "key usage extra extension",
+			csr:  &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{ExtraExtensions: []pkix.Extension{
+				// keyCertSign and cRLSign.
+				{Id: oidExtensionKeyUsage, Critical: true, Value: []byte{0x03, 0x02, 0x01, 0x06}},
+			}},
+			err: "key usage ExtraExtension",
+		},
+		{
+			name: "extended key usage extra extension",
+			csr:  &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{ExtraExtensions: []pkix.Extension{
+				// serverAuth.
+				{Id: oidExtensionExtendedKeyUsage, Value: []byte{0x30, 0x0a, 0x06, 0x08, 0x2b, 0x06, 0x01, 0x05, 0x05, 0x07, 0x03, 0x01}},
+			}},
+			err: "extended key usage ExtraExtension",
+		},
+		{
+			name: "basic constraints extra extension",
+			csr:  &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{ExtraExtensions: []pkix.Extension{
+				// cA TRUE.
+				{Id: oidExtensionBasicConstraints, Critical: true, Value: []byte{0x30, 0x03, 0x01, 0x01, 0xff}},
+			}},
+			err: "basic constraints ExtraExtension",
+		},
+		{
+			name:     "outlives issuer",
+			csr:      &CertificateRequest{DNSNames: []string{"a.example.com"}},
+			template: &Certificate{NotBefore: now.Add(400 * 24 * time.Hour), NotAfter: now.Add(400*24*time.Hour + time.Hour)},
+			err:      "validity period of the issuer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ca.Issue(rand.Reader, newTestCSR(t, tt.csr), tt.template)
+			if err == nil {
+				t.Fatal("Issue succeeded, want error")
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Issue error = %q, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestIssuerPolicyIsCopied(t *testing.T) {
+	policy := &IssuancePolicy{
+		MaxValidity:         time.Hour,
+		ExtKeyUsage:         []ExtKeyUsage{ExtKeyUsageClientAuth},
+		PermittedDNSDomains: []string{"example.com"},
+		PermittedIPRanges:   []*net.IPNet{{IP: net.IPv4(192, 0, 2, 0).To4(), Mask: net.CIDRMask(24, 32)}},
+	}
+	ca := newTestIssuer(t, policy, nil)
+	policy.ExtKeyUsage[0] = ExtKeyUsageServerAuth
+	policy.PermittedDNSDomains[0] = "example.org"
+	policy.PermittedIPRanges[0].IP[0] = 198
+
+	for _, tt := range []struct {
+		name string
+		csr  *CertificateRequest
+		eku  ExtKeyUsage
+	}{
+		{"extended key usage", &CertificateRequest{DNSNames: []string{"a.example.com"}}, ExtKeyUsageServerAuth},
+		{"DNS name", &CertificateRequest{DNSNames: []string{"a.example.org"}}, ExtKeyUsageClientAuth},
+		{"IP address", &CertificateRequest{IPAddresses: []net.IP{net.IPv4(198, 0, 2, 1)}}, ExtKeyUsageClientAuth},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ca.Issue(rand.Reader, newTestCSR(t, tt.csr), &Certificate{ExtKeyUsage: []ExtKeyUsage{tt.eku}}); err == nil {
+				t.Error("Issue succeeded after the policy was modified, want error")
+			}
+		})
+	}
+}
+
+func TestIssuerMaxPathLen(t *testing.T) {
+	ca := newTestIssuer(t, &IssuancePolicy{AllowCA: true}, &Certificate{MaxPathLen: 1})
+	csr := newTestCSR(t, &CertificateRequest{Subject: pkix.Name{CommonName: "Intermediate CA"}})
+
+	for _, tt := range []struct {
+		name     string
+		template *Certificate
+		ok       bool
+	}{
+		{"unconstrained", &Certificate{MaxPathLen: -1}, false},
+		{"unset", &Certificate{}, false},
+		{"equal", &Certificate{MaxPathLen: 1}, false},
+		{"longer", &Certificate{MaxPathLen: 2}, false},
+		{"zero", &Certificate{MaxPathLenZero: true}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := *tt.template
+			tmpl.BasicConstraintsValid = true
+			tmpl.IsCA = true
+			tmpl.KeyUsage = KeyUsageCertSign
+			tmpl.NotAfter = ca.Certificate().NotAfter
+			der, err := ca.Issue(rand.Reader, csr, &tmpl)
+			if !tt.ok {
+				if err == nil || !strings.Contains(err.Error(), "MaxPathLen") {
+					t.Fatalf("Issue error = %v, want a MaxPathLen error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Issue failed: %v", err)
+			}
+			cert, err := ParseCertificate(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cert.IsCA || cert.MaxPathLen != 0 || !cert.MaxPathLenZero {
+				t.Errorf("IsCA, MaxPathLen, MaxPathLenZero = %v, %d, %v; want true, 0, true", cert.IsCA, cert.MaxPathLen, cert.MaxPathLenZero)
+			}
+		})
+	}
+}
+
+func TestIssuerRejectsBadCSRSignature(t *testing.T) {
+	ca := newTestIssuer(t, &IssuancePolicy{MaxValidity: time.Hour}, nil)
+	csr := newTestCSR(t, &CertificateRequest{DNSNames: []string{"example.com"}})
+	csr.Signature = append([]byte(nil), csr.Signature...)
+	csr.Signature[len(csr.Signature)-1] ^= 0xff
+	if _, err := ca.Issue(rand.Reader, csr, &Certificate{}); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("Issue with corrupted CSR signature = %v, want signature error", err)
+	}
+}
+
+func TestNewIssuerErrors(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := newTestIssuer(t, nil, nil)
+	if _, err := NewIssuer(ca.Certificate(), key, nil); err == nil {
+		t.Error("NewIssuer with mismatched key succeeded")
+	}
+	leaf := &Certificate{PublicKey: key.Public()}
+	if _, err := NewIssuer(leaf, key, nil); err == nil {
+		t.Error("NewIssuer with non-CA certificate succeeded")
+	}
+}
+
+func TestIssuerRevocationList(t *testing.T) {
+	ca := newTestIssuer(t, nil, nil)
+	if err := ca.Revoke(RevocationListEntry{SerialNumber: big.NewInt(42), ReasonCode: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ca.Revoke(RevocationListEntry{SerialNumber: big.NewInt(42)}); err == nil {
+		t.Error("revoking the same serial number twice succeeded")
+	}
+
+	now := time.Now()
+	for i, serials := range [][]int64{{42}, {42, 7}} {
+		if i == 1 {
+			if err := ca.Revoke(RevocationListEntry{SerialNumber: big.NewInt(7), RevocationTime: now}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		der, err := ca.CreateRevocationList(rand.Reader, &RevocationList{NextUpdate: now.Add(time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		crl, err := ParseRevocationList(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crl.CheckSignatureFrom(ca.Certificate()); err != nil {
+			t.Errorf("CheckSignatureFrom failed: %v", err)
+		}
+		if want := int64(i + 1); crl.Number.Int64() != want {
+			t.Errorf("CRL number = %v, want %d", crl.Number, want)
+		}
+		if len(crl.RevokedCertificateEntries) != len(serials) {
+			t.Fatalf("CRL has %d entries, want %d", len(crl.RevokedCertificateEntries), len(serials))
+		}
+		for j, s := range serials {
+			if got := crl.RevokedCertificateEntries[j].SerialNumber.Int64(); got != s {
+				t.Errorf("entry %d serial number = %d, want %d", j, got, s)
+			}
+		}
+		if got := crl.RevokedCertificateEntries[0].ReasonCode; got != 1 {
+			t.Errorf("entry 0 reason code = %d, want 1", got)
+		}
+	}
+}
@@ -122,7 +122,10 @@ var depsRules = `
 
 	cmp, runtime, math/bits
 	< iter
-	< maps, slices;
+	< iter/xiter, maps, slices;
+
+	slices, sync/atomic
+	< container/btree;
 
 	internal/oserror, maps, slices
 	< RUNTIME;
@@ -135,7 +138,7 @@ var depsRules = `
 	RUNTIME
 	< io;
 
-	RUNTIME
+	RUNTIME, unicode/utf8
 	< arena;
 
 	syscall !< io;
@@ -268,6 +271,9 @@ var depsRules = `
 	< encoding/ascii85, encoding/csv, encoding/gob, encoding/hex,
 	  encoding/pem, encoding/xml, mime;
 
+	FMT, encoding/binary, internal/saferio
+	< runtime/debug/heapsnapshot;
+
 	STR, errors
 	< encoding/json/internal
 	< encoding/json/internal/jsonflags
@@ -613,8 +619,12 @@ var depsRules = `
 	< crypto/x509/internal/macos
 	< crypto/x509/pkix
 	< crypto/x509
+	< crypto/keystore
 	< crypto/tls;
 
+	CRYPTO-MATH, NET, encoding/base32, encoding/hex
+	< net/dnssec;
+
 	# crypto-aware packages
 
 	DEBUG, go/build, go/types, text/scanner, crypto/sha256
@@ -684,7 +694,7 @@ var depsRules = `
 	OS, compress/gzip, internal/lazyregexp
 	< internal/profile;
 
-	html, internal/profile, net/http, runtime/pprof, runtime/trace
+	encoding/json, html, internal/profile, net/http, runtime/pprof, runtime/trace
 	< net/http/pprof;
 
 	# RPC
@@ -83,8 +83,10 @@ type Flags struct {
 	// by default.
 	HeapMinimum512KiB bool
 
-	// Arenas causes the "arena" standard library package to be visible
-	// to the outside world.
+	// Arenas has no effect. It used to make the "arena" standard
+	// library package visible to the outside world, which it now
+	// always is, and is kept so that GOEXPERIMENT=arenas is still
+	// accepted.
 	Arenas bool
 
 	// CgoCheck2 enables an expensive cgo rule checker.
@@ -20,3 +20,29 @@ func GetPipeFds(p *SplicePipe) (int, int) {
 }
 
 type SplicePipe = splicePipe
+
+// EnableRing makes the reads and writes of files use io_uring, as with
+// GODEBUG=iouring=1. It reports whether the kernel provides io_uring.
+func EnableRing() bool {
+	ringOnce.Do(func() {})
+	if theRing.Load() == nil {
+		theRing.Store(newRing())
+	}
+	return theRing.Load() != nil
+}
+
+func (fd *FD) RingRead(p []byte, off int64) (int, bool, error) {
+	return fd.ringRead(p, off)
+}
+
+func (fd *FD) RingWrite(p []byte, off int64) (int, bool, error) {
+	return fd.ringWrite(p, off)
+}
+
+// RingBufSize returns the size of the bounce buffer used to read or
+// write n bytes with io_uring.
+func RingBufSize(n int) int {
+	bp := getRingBuf(make([]byte, n))
+	defer putRingBuf(bp)
+	return len(*bp)
+}
@@ -160,6 +160,7 @@ func setDeadlineImpl(fd *FD, t time.Time, mode int) error {
 		return ErrNoDeadline
 	}
 	runtime_pollSetDeadline(fd.pd.runtimeCtx, d, mode)
+	fd.ringSetDeadline(t, mode)
 	return nil
 }
 
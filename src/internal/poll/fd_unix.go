@@ -45,6 +45,15 @@ type FD struct {
 
 	// Whether this is a file rather than a network socket.
 	isFile bool
+
+	// Whether reads and writes use io_uring, on Linux.
+	// Accessed atomically.
+	ringState uint32
+
+	// The user data of the read and the write in flight on the
+	// io_uring instance, and their deadlines, on Linux.
+	ringOps       [2]atomic.Uint64
+	ringDeadlines [2]atomic.Int64
 }
 
 // Init initializes the FD. The Sysfd field should already be set.
@@ -99,6 +108,7 @@ func (fd *FD) Close() error {
 	// fairly quickly, since all the I/O is non-blocking, and any
 	// attempts to block in the pollDesc will return errClosing(fd.isFile).
 	fd.pd.evict()
+	fd.ringCancel()
 
 	// The call to decref will call destroy if there are no other
 	// references.
@@ -157,6 +167,9 @@ func (fd *FD) Read(p []byte) (int, error) {
 	if fd.IsStream && len(p) > maxRW {
 		p = p[:maxRW]
 	}
+	if n, handled, err := fd.ringRead(p, -1); handled {
+		return n, fd.eofError(n, err)
+	}
 	for {
 		n, err := ignoringEINTRIO(syscall.Read, fd.Sysfd, p)
 		if err != nil {
@@ -183,9 +196,17 @@ func (fd *FD) Pread(p []byte, off int64) (int, error) {
 	if fd.IsStream && len(p) > maxRW {
 		p = p[:maxRW]
 	}
-	n, err := ignoringEINTR2(func() (int, error) {
-		return syscall.Pread(fd.Sysfd, p, off)
-	})
+	var n int
+	var handled bool
+	var err error
+	if off >= 0 {
+		n, handled, err = fd.ringRead(p, off)
+	}
+	if !handled {
+		n, err = ignoringEINTR2(func() (int, error) {
+			return syscall.Pread(fd.Sysfd, p, off)
+		})
+	}
 	if err != nil {
 		n = 0
 	}
@@ -371,7 +392,10 @@ func (fd *FD) Write(p []byte) (int, error) {
 		if fd.IsStream && max-nn > maxRW {
 			max = nn + maxRW
 		}
-		n, err := ignoringEINTRIO(syscall.Write, fd.Sysfd, p[nn:max])
+		n, handled, err := fd.ringWrite(p[nn:max], -1)
+		if !handled {
+			n, err = ignoringEINTRIO(syscall.Write, fd.Sysfd, p[nn:max])
+		}
 		if n > 0 {
 			if n > max-nn {
 				// This can reportedly happen when using
@@ -415,9 +439,17 @@ func (fd *FD) Pwrite(p []byte, off int64) (int, error) {
 		if fd.IsStream && max-nn > maxRW {
 			max = nn + maxRW
 		}
-		n, err := syscall.Pwrite(fd.Sysfd, p[nn:max], off+int64(nn))
-		if err == syscall.EINTR {
-			continue
+		var n int
+		var handled bool
+		var err error
+		if off >= 0 {
+			n, handled, err = fd.ringWrite(p[nn:max], off+int64(nn))
+		}
+		if !handled {
+			n, err = syscall.Pwrite(fd.Sysfd, p[nn:max], off+int64(nn))
+			if err == syscall.EINTR {
+				continue
+			}
 		}
 		if n > 0 {
 			nn += n
@@ -0,0 +1,541 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package poll
+
+import (
+	"internal/godebug"
+	"internal/syscall/unix"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// With GODEBUG=iouring=1, the reads and writes of regular files and of
+// stream sockets go through an io_uring(7) instance shared by the
+// process: instead of blocking a thread in a system call, or waiting
+// for the network poller to report a socket ready, a goroutine submits
+// its operation to the ring and sleeps until a goroutine reaping the
+// completions of the ring wakes it up. The reaping goroutine waits for
+// completions with the network poller, through an eventfd registered
+// with the ring.
+//
+// The kernel reads and writes bounce buffers allocated on the heap
+// rather than the caller's buffer, which may be on a goroutine stack
+// that moves while the operation is in flight.
+//
+// The operations on sockets may block for as long as no data arrives.
+// A linked timeout ends them at the deadline of the socket, and they
+// are canceled when the socket is closed or its deadline changes.
+
+var iouring = godebug.New("iouring")
+
+const (
+	// ringEntries is the size of the submission queue of the ring,
+	// which is also the maximum number of file operations in flight.
+	ringEntries = 256
+
+	// ringBufSize is the size of the largest bounce buffers, and thus
+	// the maximum number of bytes read or written by an operation.
+	ringBufSize = 256 << 10
+
+	// ringMinBufSize is the size of the smallest bounce buffers. The
+	// sizes of the ringBufClasses size classes double up to ringBufSize.
+	ringMinBufSize = 4 << 10
+	ringBufClasses = 7
+)
+
+var (
+	ringOnce sync.Once
+	theRing  atomic.Pointer[ring] // nil if files and sockets don't use the ring
+)
+
+// getRing returns the ring, or nil if the reads and writes of files and
+// sockets don't use it.
+func getRing() *ring {
+	ringOnce.Do(func() {
+		if iouring.Value() == "1" {
+			theRing.Store(newRing())
+		}
+	})
+	return theRing.Load()
+}
+
+// A ring is an io_uring instance.
+type ring struct {
+	fd      int
+	ringMem []byte // the submission and completion queue rings
+	sqeMem  []byte // the submission queue entries
+
+	sqTail  *uint32
+	sqFlags *uint32
+	sqMask  uint32
+	sqArray []uint32
+	sqes    []unix.IoUringSqe
+
+	cqHead *uint32
+	cqTail *uint32
+	cqMask uint32
+	cqes   []unix.IoUringCqe
+
+	// event is an eventfd that the kernel signals when it posts
+	// completions.
+	event FD
+
+	mu sync.Mutex // serializes submissions, and protects ops and lastID
+
+	// ops are the operations in flight, by the user data of their
+	// queue entries. User data 0 marks the entries whose completions
+	// are ignored, such as cancellations and timeouts.
+	ops    map[uint64]*ringOp
+	lastID uint64
+
+	// files limits the number of file operations in flight, and thus
+	// the memory used by their bounce buffers. The operations on
+	// sockets are not limited, since they may wait for data forever.
+	// The completion queue doesn't overflow, thanks to
+	// IORING_FEAT_NODROP.
+	files chan struct{}
+}
+
+type ringOp struct {
+	sema    uint32
+	res     atomic.Int32        // result of the completed operation, set by reap
+	timeout unix.KernelTimespec // read by the kernel on submission
+}
+
+var ringOpPool = sync.Pool{
+	New: func() any { return new(ringOp) },
+}
+
+// newRing returns a new ring, or nil if the kernel doesn't provide
+// io_uring with the needed features.
+func newRing() *ring {
+	var p unix.IoUringParams
+	fd, err := unix.IoUringSetup(ringEntries, &p)
+	if err != nil {
+		return nil
+	}
+	r := &ring{fd: fd, event: FD{Sysfd: -1}}
+	if !r.init(&p) {
+		r.destroy()
+		return nil
+	}
+	go r.reap()
+	return r
+}
+
+func (r *ring) init(p *unix.IoUringParams) bool {
+	// IORING_FEAT_RW_CUR_POS makes an offset of -1 mean the file
+	// offset, as for read and write. It also comes with the send and
+	// receive operations, in Linux 5.6.
+	const features = unix.IORING_FEAT_SINGLE_MMAP | unix.IORING_FEAT_NODROP | unix.IORING_FEAT_RW_CUR_POS
+	if p.Features&features != features {
+		return false
+	}
+
+	const prot = syscall.PROT_READ | syscall.PROT_WRITE
+	const flags = syscall.MAP_SHARED | syscall.MAP_POPULATE
+	sqSize := p.SqOff.Array + p.SqEntries*4
+	cqSize := p.CqOff.Cqes + p.CqEntries*uint32(unsafe.Sizeof(unix.IoUringCqe{}))
+	var err error
+	r.ringMem, err = syscall.Mmap(r.fd, unix.IORING_OFF_SQ_RING, int(max(sqSize, cqSize)), prot, flags)
+	if err != nil {
+		return false
+	}
+	r.sqeMem, err = syscall.Mmap(r.fd, unix.IORING_OFF_SQES, int(p.SqEntries)*int(unsafe.Sizeof(unix.IoUringSqe{})), prot, flags)
+	if err != nil {
+		return false
+	}
+	base := unsafe.Pointer(&r.ringMem[0])
+	r.sqTail = (*uint32)(unsafe.Add(base, p.SqOff.Tail))
+	r.sqFlags = (*uint32)(unsafe.Add(base, p.SqOff.Flags))
+	r.sqMask = *(*uint32)(unsafe.Add(base, p.SqOff.RingMask))
+	r.sqArray = unsafe.Slice((*uint32)(unsafe.Add(base, p.SqOff.Array)), p.SqEntries)
+	r.sqes = unsafe.Slice((*unix.IoUringSqe)(unsafe.Pointer(&r.sqeMem[0])), p.SqEntries)
+	r.cqHead = (*uint32)(unsafe.Add(base, p.CqOff.Head))
+	r.cqTail = (*uint32)(unsafe.Add(base, p.CqOff.Tail))
+	r.cqMask = *(*uint32)(unsafe.Add(base, p.CqOff.RingMask))
+	r.cqes = unsafe.Slice((*unix.IoUringCqe)(unsafe.Add(base, p.CqOff.Cqes)), p.CqEntries)
+
+	efd, err := unix.Eventfd(0, syscall.O_CLOEXEC|syscall.O_NONBLOCK)
+	if err != nil {
+		return false
+	}
+	r.event.Sysfd = efd
+	if err := r.event.Init("file", true); err != nil {
+		return false
+	}
+	arg := int32(efd)
+	if err := unix.IoUringRegister(r.fd, unix.IORING_REGISTER_EVENTFD, unsafe.Pointer(&arg), 1); err != nil {
+		return false
+	}
+
+	r.ops = make(map[uint64]*ringOp)
+	r.files = make(chan struct{}, p.SqEntries)
+	return true
+}
+
+// destroy releases the resources of a ring that failed to initialize.
+func (r *ring) destroy() {
+	if r.event.Sysfd >= 0 {
+		r.event.Close()
+	}
+	if r.sqeMem != nil {
+		syscall.Munmap(r.sqeMem)
+	}
+	if r.ringMem != nil {
+		syscall.Munmap(r.ringMem)
+	}
+	syscall.Close(r.fd)
+}
+
+// reap wakes up the goroutines whose operations completed.
+func (r *ring) reap() {
+	var buf [8]byte
+	for {
+		// Reading the eventfd resets its counter. The completions
+		// posted after the completion queue is drained signal it
+		// again.
+		if _, err := r.event.Read(buf[:]); err != nil {
+			panic("poll: read of io_uring eventfd failed: " + err.Error())
+		}
+		for r.drain() {
+			// The kernel kept completions that didn't fit in the
+			// completion queue, and only posts them when asked to.
+			unix.IoUringEnter(r.fd, 0, 0, unix.IORING_ENTER_GETEVENTS)
+		}
+	}
+}
+
+// drain wakes up the goroutines whose operations are in the completion
+// queue, and empties it. It reports whether completions overflowed it.
+func (r *ring) drain() bool {
+	head := *r.cqHead
+	tail := atomic.LoadUint32(r.cqTail)
+	r.mu.Lock()
+	for ; head != tail; head++ {
+		cqe := &r.cqes[head&r.cqMask]
+		op := r.ops[cqe.UserData]
+		if cqe.UserData == 0 || op == nil {
+			continue
+		}
+		delete(r.ops, cqe.UserData)
+		op.res.Store(cqe.Res)
+		runtime_Semrelease(&op.sema)
+	}
+	r.mu.Unlock()
+	atomic.StoreUint32(r.cqHead, head)
+	return atomic.LoadUint32(r.sqFlags)&unix.IORING_SQ_CQ_OVERFLOW != 0
+}
+
+// start submits the operation opcode on fd with the buffer buf, at
+// offset off. If timeout is positive, the operation is canceled after
+// that time. start returns the operation and its user data, or a nil
+// operation if the submission failed.
+func (r *ring) start(opcode uint8, fd int, buf []byte, off int64, flags uint32, timeout time.Duration) (*ringOp, uint64) {
+	op := ringOpPool.Get().(*ringOp)
+	sqes := []unix.IoUringSqe{{
+		Opcode:  opcode,
+		Fd:      int32(fd),
+		Off:     uint64(off),
+		Addr:    uint64(uintptr(unsafe.Pointer(&buf[0]))),
+		Len:     uint32(len(buf)),
+		RwFlags: flags,
+	}}
+	if timeout > 0 {
+		op.timeout = unix.KernelTimespec{Sec: int64(timeout / time.Second), Nsec: int64(timeout % time.Second)}
+		sqes[0].Flags = unix.IOSQE_IO_LINK
+		sqes = append(sqes, unix.IoUringSqe{
+			Opcode: unix.IORING_OP_LINK_TIMEOUT,
+			Addr:   uint64(uintptr(unsafe.Pointer(&op.timeout))),
+			Len:    1,
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastID++
+	id := r.lastID
+	sqes[0].UserData = id
+	r.ops[id] = op
+	switch r.submit(sqes) {
+	case 0:
+		delete(r.ops, id)
+		ringOpPool.Put(op)
+		return nil, 0
+	case len(sqes):
+	default:
+		// The operation is in flight without its timeout: end it,
+		// and let the caller retry.
+		r.submit([]unix.IoUringSqe{{Opcode: unix.IORING_OP_ASYNC_CANCEL, Addr: id}})
+	}
+	return op, id
+}
+
+// wait waits for op to complete, and returns its result.
+func (r *ring) wait(op *ringOp) int32 {
+	runtime_Semacquire(&op.sema)
+	res := op.res.Load()
+	ringOpPool.Put(op)
+	return res
+}
+
+// cancel cancels the operation with the user data id, if it is still
+// in flight.
+func (r *ring) cancel(id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ops[id] != nil {
+		r.submit([]unix.IoUringSqe{{Opcode: unix.IORING_OP_ASYNC_CANCEL, Addr: id}})
+	}
+}
+
+// do performs the operation opcode on the file fd with the buffer buf,
+// at offset off or, if off is -1, at the file offset, and returns its
+// result. The operation is not handled if it failed in a way the system
+// call might not, and then nothing was read or written.
+func (r *ring) do(opcode uint8, fd int, buf []byte, off int64) (n int, handled bool, err error) {
+	r.files <- struct{}{}
+	defer func() { <-r.files }()
+	op, _ := r.start(opcode, fd, buf, off, 0, 0)
+	if op == nil {
+		return 0, false, nil
+	}
+	res := r.wait(op)
+	if res >= 0 {
+		return int(res), true, nil
+	}
+	switch errno := syscall.Errno(-res); errno {
+	case syscall.EINTR, syscall.EAGAIN, syscall.EINVAL, syscall.EOPNOTSUPP, syscall.ECANCELED:
+		return 0, false, nil
+	default:
+		return 0, true, errno
+	}
+}
+
+// submit submits sqes to the kernel, and returns the number of entries
+// it consumed. r.mu must be held.
+func (r *ring) submit(sqes []unix.IoUringSqe) int {
+	tail := *r.sqTail
+	for j := range sqes {
+		i := (tail + uint32(j)) & r.sqMask
+		r.sqes[i] = sqes[j]
+		r.sqArray[i] = i
+	}
+	atomic.StoreUint32(r.sqTail, tail+uint32(len(sqes)))
+	for {
+		n, err := unix.IoUringEnter(r.fd, uint32(len(sqes)), 0, 0)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			n = 0
+		}
+		// Take back the entries the kernel didn't consume.
+		atomic.StoreUint32(r.sqTail, tail+uint32(n))
+		return n
+	}
+}
+
+// ringBufPools hold the bounce buffers by size class. An operation
+// takes the smallest buffer that fits the buffer of its caller, so that
+// the operations on sockets, which may be pending for a long time,
+// don't hold more memory than their callers asked to read or write.
+var ringBufPools [ringBufClasses]sync.Pool
+
+// ringBufClass returns the size class of the bounce buffer for an
+// operation on n bytes.
+func ringBufClass(n int) int {
+	class := 0
+	for class < ringBufClasses-1 && ringMinBufSize<<class < n {
+		class++
+	}
+	return class
+}
+
+// getRingBuf returns a bounce buffer of len(p) bytes, or ringBufSize
+// bytes if p is larger. It must be returned with putRingBuf.
+func getRingBuf(p []byte) *[]byte {
+	class := ringBufClass(len(p))
+	bp, _ := ringBufPools[class].Get().(*[]byte)
+	if bp == nil {
+		b := make([]byte, ringMinBufSize<<class)
+		bp = &b
+	}
+	return bp
+}
+
+func putRingBuf(bp *[]byte) {
+	ringBufPools[ringBufClass(cap(*bp))].Put(bp)
+}
+
+// Values of FD.ringState.
+const (
+	ringUnknown = iota
+	ringUsed
+	ringUnused
+)
+
+// ring returns the ring to read and write fd with, or nil if fd uses
+// system calls.
+func (fd *FD) ring() *ring {
+	if fd.isFile == fd.pd.pollable() {
+		// A file registered with the network poller, such as a
+		// pipe, or a socket that is not.
+		return nil
+	}
+	if !fd.isFile && (!fd.IsStream || atomic.LoadUint32(&fd.isBlocking) != 0) {
+		return nil
+	}
+	r := getRing()
+	if r == nil {
+		return nil
+	}
+	if !fd.isFile {
+		return r
+	}
+	switch atomic.LoadUint32(&fd.ringState) {
+	case ringUsed:
+		return r
+	case ringUnused:
+		return nil
+	}
+	// Only regular files use the ring. Reads and writes of other
+	// files, such as pipes and terminals passed to os.NewFile, may
+	// block forever, and the ring couldn't interrupt them when the
+	// file is closed.
+	var st syscall.Stat_t
+	state := uint32(ringUnused)
+	err := ignoringEINTR(func() error {
+		return syscall.Fstat(fd.Sysfd, &st)
+	})
+	if err == nil && st.Mode&syscall.S_IFMT == syscall.S_IFREG {
+		state = ringUsed
+	}
+	atomic.StoreUint32(&fd.ringState, state)
+	if state == ringUnused {
+		return nil
+	}
+	return r
+}
+
+// ringRead reads into p with the ring, at offset off or, if off is -1,
+// at the file offset. If the read is not handled, nothing was read and
+// the caller uses a system call.
+func (fd *FD) ringRead(p []byte, off int64) (n int, handled bool, err error) {
+	r := fd.ring()
+	if r == nil || len(p) == 0 {
+		return 0, false, nil
+	}
+	bp := getRingBuf(p)
+	defer putRingBuf(bp)
+	buf := (*bp)[:min(len(p), len(*bp))]
+	if fd.isFile {
+		n, handled, err = r.do(unix.IORING_OP_READ, fd.Sysfd, buf, off)
+	} else {
+		n, handled, err = fd.ringSocket(r, unix.IORING_OP_RECV, buf)
+	}
+	copy(p, buf[:n])
+	return n, handled, err
+}
+
+// ringWrite writes p with the ring, at offset off or, if off is -1, at
+// the file offset. It may write less than p even if it doesn't fail.
+// If the write is not handled, nothing was written and the caller uses
+// a system call.
+func (fd *FD) ringWrite(p []byte, off int64) (n int, handled bool, err error) {
+	r := fd.ring()
+	if r == nil || len(p) == 0 {
+		return 0, false, nil
+	}
+	bp := getRingBuf(p)
+	defer putRingBuf(bp)
+	buf := (*bp)[:min(len(p), len(*bp))]
+	copy(buf, p)
+	if fd.isFile {
+		return r.do(unix.IORING_OP_WRITE, fd.Sysfd, buf, off)
+	}
+	return fd.ringSocket(r, unix.IORING_OP_SEND, buf)
+}
+
+// ringSocket performs the receive or send operation opcode on the
+// socket fd with buf. The operation ends at the deadline of fd, or
+// when fd is closed. If it is not handled, nothing was read or written
+// and the caller waits for fd with the network poller.
+func (fd *FD) ringSocket(r *ring, opcode uint8, buf []byte) (n int, handled bool, err error) {
+	i, mode, flags := 0, 'r', uint32(0)
+	if opcode == unix.IORING_OP_SEND {
+		// Report EPIPE rather than raising SIGPIPE, as the
+		// network poller does for sockets.
+		i, mode, flags = 1, 'w', syscall.MSG_NOSIGNAL
+	}
+	for {
+		deadline := fd.ringDeadlines[i].Load()
+		var timeout time.Duration
+		if deadline != 0 {
+			if timeout = time.Until(time.Unix(0, deadline)); timeout <= 0 {
+				return 0, true, ErrDeadlineExceeded
+			}
+		}
+		op, id := r.start(opcode, fd.Sysfd, buf, 0, flags, timeout)
+		if op == nil {
+			return 0, false, nil
+		}
+		// Close and SetDeadline cancel the operation recorded in
+		// ringOps after they change fd. Check for changes made
+		// before it was recorded.
+		fd.ringOps[i].Store(id)
+		if fd.pd.prepare(int(mode), fd.isFile) != nil || fd.ringDeadlines[i].Load() != deadline {
+			r.cancel(id)
+		}
+		res := r.wait(op)
+		fd.ringOps[i].Store(0)
+		if res >= 0 {
+			return int(res), true, nil
+		}
+		switch errno := syscall.Errno(-res); errno {
+		case syscall.ECANCELED, syscall.EINTR:
+			// Closed, timed out, or with a new deadline.
+			if err := fd.pd.prepare(int(mode), fd.isFile); err != nil {
+				return 0, true, err
+			}
+		case syscall.EAGAIN, syscall.EINVAL, syscall.EOPNOTSUPP:
+			return 0, false, nil
+		default:
+			return 0, true, errno
+		}
+	}
+}
+
+// ringSetDeadline sets the deadline of the operations of fd on the
+// ring, and cancels the ones in flight so that they use it.
+func (fd *FD) ringSetDeadline(t time.Time, mode int) {
+	var d int64
+	if !t.IsZero() {
+		d = t.UnixNano()
+	}
+	if mode == 'r' || mode == 'r'+'w' {
+		fd.ringDeadlines[0].Store(d)
+	}
+	if mode == 'w' || mode == 'r'+'w' {
+		fd.ringDeadlines[1].Store(d)
+	}
+	fd.ringCancel()
+}
+
+// ringCancel cancels the operations of fd in flight on the ring.
+func (fd *FD) ringCancel() {
+	r := theRing.Load()
+	if r == nil {
+		return
+	}
+	for i := range fd.ringOps {
+		if id := fd.ringOps[i].Load(); id != 0 {
+			r.cancel(id)
+		}
+	}
+}
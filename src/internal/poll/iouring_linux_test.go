@@ -0,0 +1,318 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package poll_test
+
+import (
+	"bytes"
+	"fmt"
+	"internal/poll"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newRingFile returns an FD for a new file, whose reads and writes use
+// io_uring.
+func newRingFile(t *testing.T) *poll.FD {
+	t.Helper()
+	if !poll.EnableRing() {
+		t.Skip("io_uring not available")
+	}
+	name := filepath.Join(t.TempDir(), "file")
+	sysfd, err := syscall.Open(name, syscall.O_RDWR|syscall.O_CREAT|syscall.O_CLOEXEC, 0o666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd := &poll.FD{Sysfd: sysfd, IsStream: true, ZeroReadIsEOF: true}
+	if err := fd.Init("file", false); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fd.Close() })
+	return fd
+}
+
+func TestRing(t *testing.T) {
+	fd := newRingFile(t)
+
+	n, handled, err := fd.RingWrite([]byte("hello, world"), -1)
+	if n != 12 || !handled || err != nil {
+		t.Fatalf("RingWrite = %d, %v, %v; want 12, true, nil", n, handled, err)
+	}
+	if _, err := fd.Pwrite([]byte("W"), 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fd.Write([]byte("!")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 100)
+	n, handled, err = fd.RingRead(buf, 0)
+	if got := string(buf[:n]); got != "hello, World!" || !handled || err != nil {
+		t.Fatalf("RingRead = %q, %v, %v; want %q, true, nil", got, handled, err, "hello, World!")
+	}
+	n, err = fd.Pread(buf, 7)
+	if got := string(buf[:n]); got != "World!" || err != nil {
+		t.Fatalf("Pread = %q, %v; want %q, nil", got, err, "World!")
+	}
+	if _, err := fd.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	n, err = fd.Read(buf[:5])
+	if got := string(buf[:n]); got != "hello" || err != nil {
+		t.Fatalf("Read = %q, %v; want %q, nil", got, err, "hello")
+	}
+	n, err = fd.Read(buf)
+	if got := string(buf[:n]); got != ", World!" || err != nil {
+		t.Fatalf("Read = %q, %v; want %q, nil", got, err, ", World!")
+	}
+	n, err = fd.Read(buf)
+	if n != 0 || err != io.EOF {
+		t.Fatalf("Read at end of file = %d, %v; want 0, EOF", n, err)
+	}
+	n, err = fd.Pread(buf, 100)
+	if n != 0 || err != io.EOF {
+		t.Fatalf("Pread after end of file = %d, %v; want 0, EOF", n, err)
+	}
+}
+
+func TestRingLarge(t *testing.T) {
+	fd := newRingFile(t)
+
+	// Larger than a bounce buffer.
+	data := bytes.Repeat([]byte("0123456789abcdef"), 100000)
+	if n, err := fd.Write(data); n != len(data) || err != nil {
+		t.Fatalf("Write = %d, %v; want %d, nil", n, err, len(data))
+	}
+	got := make([]byte, 0, len(data))
+	buf := make([]byte, 1<<20)
+	for off := int64(0); ; {
+		n, err := fd.Pread(buf, off)
+		got = append(got, buf[:n]...)
+		off += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes, not the %d bytes written", len(got), len(data))
+	}
+}
+
+func TestRingBufSize(t *testing.T) {
+	for _, tt := range []struct{ n, want int }{
+		{1, 4 << 10},
+		{4 << 10, 4 << 10},
+		{4<<10 + 1, 8 << 10},
+		{32 << 10, 32 << 10},
+		{100 << 10, 128 << 10},
+		{256 << 10, 256 << 10},
+		{1 << 20, 256 << 10},
+	} {
+		if got := poll.RingBufSize(tt.n); got != tt.want {
+			t.Errorf("RingBufSize(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRingConcurrent(t *testing.T) {
+	fd := newRingFile(t)
+
+	// More goroutines than operations in flight.
+	const goroutines, size = 600, 100
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		wg.Go(func() {
+			off := int64(i * size)
+			want := bytes.Repeat([]byte{byte(i)}, size)
+			if _, err := fd.Pwrite(want, off); err != nil {
+				t.Error(err)
+				return
+			}
+			got := make([]byte, size)
+			if _, err := fd.Pread(got, off); err != nil {
+				t.Error(err)
+				return
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("read at %d: got %v, want %v", off, got[:4], want[:4])
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestRingOSFile(t *testing.T) {
+	if !poll.EnableRing() {
+		t.Skip("io_uring not available")
+	}
+	name := filepath.Join(t.TempDir(), "file")
+	data := bytes.Repeat([]byte("data"), 1000)
+	if err := os.WriteFile(name, data, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("ReadFile returned %d bytes, want the %d bytes written", len(got), len(data))
+	}
+}
+
+func TestRingPipe(t *testing.T) {
+	if !poll.EnableRing() {
+		t.Skip("io_uring not available")
+	}
+	var p [2]int
+	if err := syscall.Pipe2(p[:], syscall.O_CLOEXEC); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(p[1])
+	// A blocking pipe, as passed to os.NewFile, is not pollable, but
+	// doesn't use io_uring either.
+	fd := &poll.FD{Sysfd: p[0], IsStream: true, ZeroReadIsEOF: true}
+	if err := fd.Init("file", false); err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	if _, handled, err := fd.RingRead(make([]byte, 1), -1); handled {
+		t.Fatalf("RingRead of a pipe was handled, with error %v", err)
+	}
+}
+
+// newRingSockets returns the FDs of a new pair of connected stream
+// sockets, whose reads and writes use io_uring.
+func newRingSockets(t *testing.T) (*poll.FD, *poll.FD) {
+	t.Helper()
+	if !poll.EnableRing() {
+		t.Skip("io_uring not available")
+	}
+	p, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_NONBLOCK|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fds [2]*poll.FD
+	for i, sysfd := range p {
+		fd := &poll.FD{Sysfd: sysfd, IsStream: true, ZeroReadIsEOF: true}
+		if err := fd.Init("unix", true); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { fd.Close() })
+		fds[i] = fd
+	}
+	return fds[0], fds[1]
+}
+
+func TestRingSocket(t *testing.T) {
+	a, b := newRingSockets(t)
+
+	// A read waits for data on the ring.
+	done := make(chan error)
+	go func() {
+		buf := make([]byte, 100)
+		n, handled, err := a.RingRead(buf, -1)
+		if got := string(buf[:n]); got != "hello" || !handled || err != nil {
+			err = fmt.Errorf("RingRead = %q, %v, %v; want %q, true, nil", got, handled, err, "hello")
+		}
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	n, handled, err := b.RingWrite([]byte("hello"), -1)
+	if n != 5 || !handled || err != nil {
+		t.Fatalf("RingWrite = %d, %v, %v; want 5, true, nil", n, handled, err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// Larger than a bounce buffer, through Read and Write.
+	data := bytes.Repeat([]byte("0123456789abcdef"), 100000)
+	go func() {
+		_, err := b.Write(data)
+		done <- err
+	}()
+	got := make([]byte, len(data))
+	if _, err := io.ReadFull(readerFunc(a.Read), got); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes, not the %d bytes written", len(got), len(data))
+	}
+
+	b.Close()
+	if n, err := a.Read(got); n != 0 || err != io.EOF {
+		t.Fatalf("Read after Close of the peer = %d, %v; want 0, EOF", n, err)
+	}
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
+
+func TestRingSocketDeadline(t *testing.T) {
+	a, _ := newRingSockets(t)
+	buf := make([]byte, 100)
+
+	// The deadline ends a read in flight.
+	if err := a.SetReadDeadline(time.Now().Add(20 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, handled, err := a.RingRead(buf, -1)
+	if !handled || err != poll.ErrDeadlineExceeded {
+		t.Fatalf("RingRead = %v, %v; want true, ErrDeadlineExceeded", handled, err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("RingRead returned after %v, before the deadline", d)
+	}
+
+	// A new deadline applies to a read in flight.
+	if err := a.SetReadDeadline(time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		_, _, err := a.RingRead(buf, -1)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := a.SetReadDeadline(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != poll.ErrDeadlineExceeded {
+		t.Fatalf("RingRead = %v; want ErrDeadlineExceeded", err)
+	}
+}
+
+func TestRingSocketClose(t *testing.T) {
+	a, _ := newRingSockets(t)
+	done := make(chan error)
+	go func() {
+		_, err := a.Read(make([]byte, 100))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != poll.ErrNetClosing {
+			t.Fatalf("Read = %v; want ErrNetClosing", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Close didn't end a read in flight")
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (unix && !linux) || (js && wasm) || wasip1 || windows
+
+package poll
+
+import "time"
+
+// ringRead is not handled: only Linux provides io_uring.
+func (fd *FD) ringRead(p []byte, off int64) (n int, handled bool, err error) {
+	return 0, false, nil
+}
+
+// ringWrite is not handled: only Linux provides io_uring.
+func (fd *FD) ringWrite(p []byte, off int64) (n int, handled bool, err error) {
+	return 0, false, nil
+}
+
+// ringSetDeadline does nothing: only Linux provides io_uring.
+func (fd *FD) ringSetDeadline(t time.Time, mode int) {}
+
+// ringCancel does nothing: only Linux provides io_uring.
+func (fd *FD) ringCancel() {}
@@ -8,6 +8,8 @@
 // TODO: Consider moving this to internal/runtime, see golang.org/issue/65355.
 package profilerecord
 
+import "unsafe"
+
 type StackRecord struct {
 	Stack []uintptr
 }
@@ -26,3 +28,27 @@ type BlockProfileRecord struct {
 	Cycles int64
 	Stack  []uintptr
 }
+
+// GoroutineRecord is a structured traceback of a goroutine.
+type GoroutineRecord struct {
+	ID               uint64
+	Status           string
+	WaitReason       string
+	WaitDuration     int64 // nanoseconds
+	LockedToThread   bool
+	CreatorID        uint64
+	CreatedBy        FrameRecord // zero if not shown in tracebacks
+	Labels           unsafe.Pointer
+	Frames           []FrameRecord
+	FramesTruncated  bool
+	StackUnavailable bool
+}
+
+// FrameRecord is a logical frame of a GoroutineRecord.
+type FrameRecord struct {
+	Function string
+	File     string
+	Line     int
+	Args     string
+	Inlined  bool
+}
@@ -23,3 +23,18 @@ type Set struct {
 func NewSet(list []Label) Set {
 	return Set{List: list}
 }
+
+// Usage accumulates the resources consumed by the goroutines having a given
+// label, for [runtime/pprof.TrackLabel]. The fields are updated atomically by
+// the runtime.
+type Usage struct {
+	CPUTime    int64 // nanoseconds
+	AllocBytes int64
+	BlockTime  int64 // nanoseconds
+}
+
+// UsageSet is the list of Usages that a goroutine's resource consumption is
+// charged to, one for each tracked label of the goroutine.
+type UsageSet struct {
+	List []*Usage
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unix
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// Definitions of io_uring(7), from linux/io_uring.h.
+const (
+	IORING_FEAT_SINGLE_MMAP = 1 << 0
+	IORING_FEAT_NODROP      = 1 << 1
+	IORING_FEAT_RW_CUR_POS  = 1 << 3
+
+	IORING_OFF_SQ_RING = 0
+	IORING_OFF_CQ_RING = 0x8000000
+	IORING_OFF_SQES    = 0x10000000
+
+	IORING_OP_ASYNC_CANCEL = 14
+	IORING_OP_LINK_TIMEOUT = 15
+	IORING_OP_READ         = 22
+	IORING_OP_WRITE        = 23
+	IORING_OP_SEND         = 26
+	IORING_OP_RECV         = 27
+
+	IOSQE_IO_LINK = 1 << 2
+
+	IORING_SQ_CQ_OVERFLOW = 1 << 1
+
+	IORING_ENTER_GETEVENTS = 1 << 0
+
+	IORING_REGISTER_EVENTFD = 4
+)
+
+type IoUringSqringOffsets struct {
+	Head        uint32
+	Tail        uint32
+	RingMask    uint32
+	RingEntries uint32
+	Flags       uint32
+	Dropped     uint32
+	Array       uint32
+	Resv1       uint32
+	UserAddr    uint64
+}
+
+type IoUringCqringOffsets struct {
+	Head        uint32
+	Tail        uint32
+	RingMask    uint32
+	RingEntries uint32
+	Overflow    uint32
+	Cqes        uint32
+	Flags       uint32
+	Resv1       uint32
+	UserAddr    uint64
+}
+
+type IoUringParams struct {
+	SqEntries    uint32
+	CqEntries    uint32
+	Flags        uint32
+	SqThreadCPU  uint32
+	SqThreadIdle uint32
+	Features     uint32
+	WqFd         uint32
+	Resv         [3]uint32
+	SqOff        IoUringSqringOffsets
+	CqOff        IoUringCqringOffsets
+}
+
+// An IoUringSqe is a submission queue entry.
+type IoUringSqe struct {
+	Opcode      uint8
+	Flags       uint8
+	Ioprio      uint16
+	Fd          int32
+	Off         uint64
+	Addr        uint64
+	Len         uint32
+	RwFlags     uint32
+	UserData    uint64
+	BufIndex    uint16
+	Personality uint16
+	SpliceFdIn  int32
+	Addr3       uint64
+	_           uint64
+}
+
+// A KernelTimespec is a struct __kernel_timespec, as read by the
+// timeout operations of io_uring on all architectures.
+type KernelTimespec struct {
+	Sec  int64
+	Nsec int64
+}
+
+// An IoUringCqe is a completion queue entry.
+type IoUringCqe struct {
+	UserData uint64
+	Res      int32
+	Flags    uint32
+}
+
+func IoUringSetup(entries uint32, params *IoUringParams) (int, error) {
+	fd, _, errno := syscall.Syscall(ioUringSetupTrap, uintptr(entries), uintptr(unsafe.Pointer(params)), 0)
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(fd), nil
+}
+
+func IoUringEnter(fd int, toSubmit, minComplete, flags uint32) (int, error) {
+	n, _, errno := syscall.Syscall6(ioUringEnterTrap, uintptr(fd), uintptr(toSubmit), uintptr(minComplete), uintptr(flags), 0, 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(n), nil
+}
+
+func IoUringRegister(fd int, opcode uint32, arg unsafe.Pointer, nrArgs uint32) error {
+	_, _, errno := syscall.Syscall6(ioUringRegisterTrap, uintptr(fd), uintptr(opcode), uintptr(arg), uintptr(nrArgs), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Eventfd creates an eventfd(2) descriptor. The flags EFD_CLOEXEC and
+// EFD_NONBLOCK have the values of syscall.O_CLOEXEC and
+// syscall.O_NONBLOCK.
+func Eventfd(initval uint, flags int) (int, error) {
+	fd, _, errno := syscall.RawSyscall(syscall.SYS_EVENTFD2, uintptr(initval), uintptr(flags), 0)
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(fd), nil
+}
@@ -8,6 +8,9 @@ const (
 	getrandomTrap       uintptr = 384
 	copyFileRangeTrap   uintptr = 391
 	pidfdSendSignalTrap uintptr = 424
+	ioUringSetupTrap    uintptr = 425
+	ioUringEnterTrap    uintptr = 426
+	ioUringRegisterTrap uintptr = 427
 	pidfdOpenTrap       uintptr = 434
 	openat2Trap         uintptr = 437
 )
@@ -10,6 +10,9 @@ const (
 	getrandomTrap       uintptr = 5313
 	copyFileRangeTrap   uintptr = 5320
 	pidfdSendSignalTrap uintptr = 5424
+	ioUringSetupTrap    uintptr = 5425
+	ioUringEnterTrap    uintptr = 5426
+	ioUringRegisterTrap uintptr = 5427
 	pidfdOpenTrap       uintptr = 5434
 	openat2Trap         uintptr = 5437
 )
@@ -10,6 +10,9 @@ const (
 	getrandomTrap       uintptr = 4353
 	copyFileRangeTrap   uintptr = 4360
 	pidfdSendSignalTrap uintptr = 4424
+	ioUringSetupTrap    uintptr = 4425
+	ioUringEnterTrap    uintptr = 4426
+	ioUringRegisterTrap uintptr = 4427
 	pidfdOpenTrap       uintptr = 4434
 	openat2Trap         uintptr = 4437
 )
@@ -0,0 +1,214 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fs
+
+import (
+	"errors"
+	"io"
+	"path"
+	"syscall"
+)
+
+// The flags of OpenFile used by the helpers of this file: the same
+// values as the flags of package os.
+const (
+	oRDONLY = syscall.O_RDONLY
+	oWRONLY = syscall.O_WRONLY
+	oRDWR   = syscall.O_RDWR
+	oAPPEND = syscall.O_APPEND
+	oCREATE = syscall.O_CREAT
+	oEXCL   = syscall.O_EXCL
+	oTRUNC  = syscall.O_TRUNC
+)
+
+// OpenFileFS is the interface implemented by a file system
+// that can open files for writing.
+type OpenFileFS interface {
+	FS
+
+	// OpenFile opens the named file with the specified flag and, if the
+	// file is created, mode perm (before umask). The flag and perm
+	// arguments are interpreted as for os.OpenFile: the flag is a
+	// combination of os.O_RDONLY, os.O_WRONLY, os.O_CREATE, and so on.
+	// A file opened for writing implements [io.Writer].
+	OpenFile(name string, flag int, perm FileMode) (File, error)
+}
+
+// OpenFile opens the named file from the file system fs with the
+// specified flag and perm, as described for [OpenFileFS].
+//
+// If fs implements [OpenFileFS], OpenFile calls fs.OpenFile.
+// Otherwise, if flag only opens the file for reading, OpenFile calls
+// fs.Open, and else it returns an error wrapping
+// [errors.ErrUnsupported].
+func OpenFile(fsys FS, name string, flag int, perm FileMode) (File, error) {
+	if fsys, ok := fsys.(OpenFileFS); ok {
+		return fsys.OpenFile(name, flag, perm)
+	}
+	if flag&(oWRONLY|oRDWR|oAPPEND|oCREATE|oEXCL|oTRUNC) == oRDONLY {
+		return fsys.Open(name)
+	}
+	return nil, &PathError{Op: "open", Path: name, Err: errors.ErrUnsupported}
+}
+
+// CreateFS is the interface implemented by a file system
+// that can create files.
+type CreateFS interface {
+	FS
+
+	// Create creates or truncates the named file, as described for
+	// [Create]. The returned file implements [io.Writer].
+	Create(name string) (File, error)
+}
+
+// Create creates or truncates the named file in the file system fs. If
+// the file already exists, it is truncated. If the file does not exist,
+// it is created with mode 0o666 (before umask). The file is opened for
+// reading and writing, and the returned [File] implements [io.Writer].
+//
+// If fs implements [CreateFS], Create calls fs.Create. Otherwise Create
+// calls [OpenFile] with the flag os.O_RDWR|os.O_CREATE|os.O_TRUNC.
+func Create(fsys FS, name string) (File, error) {
+	if fsys, ok := fsys.(CreateFS); ok {
+		return fsys.Create(name)
+	}
+	return OpenFile(fsys, name, oRDWR|oCREATE|oTRUNC, 0o666)
+}
+
+// WriteFileFS is the interface implemented by a file system
+// that provides an optimized implementation of [WriteFile].
+type WriteFileFS interface {
+	FS
+
+	// WriteFile writes data to the named file, creating it if
+	// necessary, as described for [WriteFile].
+	WriteFile(name string, data []byte, perm FileMode) error
+}
+
+// WriteFile writes data to the named file in the file system fs,
+// creating it if necessary. If the file does not exist, WriteFile
+// creates it with permissions perm (before umask); otherwise WriteFile
+// truncates it before writing, without changing permissions.
+//
+// If fs implements [WriteFileFS], WriteFile calls fs.WriteFile.
+// Otherwise WriteFile calls [OpenFile] with the flag
+// os.O_WRONLY|os.O_CREATE|os.O_TRUNC and uses Write and Close on the
+// returned [File].
+func WriteFile(fsys FS, name string, data []byte, perm FileMode) error {
+	if fsys, ok := fsys.(WriteFileFS); ok {
+		return fsys.WriteFile(name, data, perm)
+	}
+	file, err := OpenFile(fsys, name, oWRONLY|oCREATE|oTRUNC, perm)
+	if err != nil {
+		return err
+	}
+	w, ok := file.(io.Writer)
+	if !ok {
+		file.Close()
+		return &PathError{Op: "write", Path: name, Err: errors.ErrUnsupported}
+	}
+	_, err = w.Write(data)
+	if err1 := file.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	return err
+}
+
+// MkdirFS is the interface implemented by a file system
+// that can create directories.
+type MkdirFS interface {
+	FS
+
+	// Mkdir creates a new directory with the specified name and
+	// permission bits (before umask). If the directory already exists,
+	// Mkdir returns an error wrapping ErrExist.
+	Mkdir(name string, perm FileMode) error
+}
+
+// Mkdir creates a new directory in the file system fs with the
+// specified name and permission bits (before umask).
+//
+// If fs implements [MkdirFS], Mkdir calls fs.Mkdir. Otherwise Mkdir
+// returns an error wrapping [errors.ErrUnsupported].
+func Mkdir(fsys FS, name string, perm FileMode) error {
+	if fsys, ok := fsys.(MkdirFS); ok {
+		return fsys.Mkdir(name, perm)
+	}
+	return &PathError{Op: "mkdir", Path: name, Err: errors.ErrUnsupported}
+}
+
+// MkdirAll creates a directory named name in the file system fs, along
+// with any necessary parents, and returns nil, or else returns an error.
+// The permission bits perm (before umask) are used for all directories
+// that MkdirAll creates. If name is already a directory, MkdirAll does
+// nothing and returns nil.
+//
+// MkdirAll calls [Stat] and [Mkdir].
+func MkdirAll(fsys FS, name string, perm FileMode) error {
+	if !ValidPath(name) {
+		return &PathError{Op: "mkdir", Path: name, Err: ErrInvalid}
+	}
+	if info, err := Stat(fsys, name); err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return &PathError{Op: "mkdir", Path: name, Err: ErrExist}
+	}
+	if dir := path.Dir(name); dir != "." {
+		if err := MkdirAll(fsys, dir, perm); err != nil {
+			return err
+		}
+	}
+	if err := Mkdir(fsys, name, perm); err != nil {
+		// The directory may have been created concurrently.
+		if info, err1 := Stat(fsys, name); err1 == nil && info.IsDir() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveFS is the interface implemented by a file system
+// that can remove files.
+type RemoveFS interface {
+	FS
+
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+}
+
+// Remove removes the named file or empty directory from the file system
+// fs.
+//
+// If fs implements [RemoveFS], Remove calls fs.Remove. Otherwise Remove
+// returns an error wrapping [errors.ErrUnsupported].
+func Remove(fsys FS, name string) error {
+	if fsys, ok := fsys.(RemoveFS); ok {
+		return fsys.Remove(name)
+	}
+	return &PathError{Op: "remove", Path: name, Err: errors.ErrUnsupported}
+}
+
+// RenameFS is the interface implemented by a file system
+// that can rename files.
+type RenameFS interface {
+	FS
+
+	// Rename renames (moves) oldname to newname. If newname already
+	// exists and is not a directory, Rename replaces it.
+	Rename(oldname, newname string) error
+}
+
+// Rename renames (moves) oldname to newname in the file system fs.
+//
+// If fs implements [RenameFS], Rename calls fs.Rename. Otherwise Rename
+// returns an error wrapping [errors.ErrUnsupported].
+func Rename(fsys FS, oldname, newname string) error {
+	if fsys, ok := fsys.(RenameFS); ok {
+		return fsys.Rename(oldname, newname)
+	}
+	return &PathError{Op: "rename", Path: oldname, Err: errors.ErrUnsupported}
+}
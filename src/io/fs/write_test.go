@@ -0,0 +1,126 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fs_test
+
+import (
+	"errors"
+	"io"
+	. "io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+// openFileOnly hides the methods of a MapFS other than Open and
+// OpenFile, to test the fallbacks of the helpers.
+type openFileOnly struct{ m fstest.MapFS }
+
+func (fsys openFileOnly) Open(name string) (File, error) {
+	return fsys.m.Open(name)
+}
+
+func (fsys openFileOnly) OpenFile(name string, flag int, perm FileMode) (File, error) {
+	return fsys.m.OpenFile(name, flag, perm)
+}
+
+// mkdirOnly hides the methods of a MapFS other than Open and Mkdir.
+type mkdirOnly struct{ m fstest.MapFS }
+
+func (fsys mkdirOnly) Open(name string) (File, error) {
+	return fsys.m.Open(name)
+}
+
+func (fsys mkdirOnly) Mkdir(name string, perm FileMode) error {
+	return fsys.m.Mkdir(name, perm)
+}
+
+func TestWriteFile(t *testing.T) {
+	m := fstest.MapFS{"f": {Data: []byte("old contents"), Mode: 0o600}}
+	for _, fsys := range []FS{m, openFileOnly{m}} {
+		if err := WriteFile(fsys, "f", []byte("new"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFile(fsys, "g", []byte("created"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(m["f"].Data); got != "new" {
+			t.Errorf("WriteFile(%T, f) wrote %q, want %q", fsys, got, "new")
+		}
+		if mode := m["f"].Mode; mode != 0o600 {
+			t.Errorf("WriteFile(%T, f) changed mode to %v", fsys, mode)
+		}
+		if got := string(m["g"].Data); got != "created" || m["g"].Mode != 0o644 {
+			t.Errorf("WriteFile(%T, g) created %q with mode %v, want %q with mode 0644", fsys, got, m["g"].Mode, "created")
+		}
+		delete(m, "g")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	m := fstest.MapFS{"f": {Data: []byte("old contents")}}
+	for _, fsys := range []FS{m, openFileOnly{m}} {
+		f, err := Create(fsys, "f")
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(f.(io.Writer), "new")
+		f.Close()
+		if got := string(m["f"].Data); got != "new" {
+			t.Errorf("Create(%T, f) then Write: file has %q, want %q", fsys, got, "new")
+		}
+	}
+}
+
+func TestOpenFileReadOnly(t *testing.T) {
+	fsys := struct{ FS }{testFsys}
+	f, err := OpenFile(fsys, "hello.txt", os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if _, err := OpenFile(fsys, "hello.txt", os.O_RDWR, 0); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("OpenFile for writing on a read-only FS: got %v, want ErrUnsupported", err)
+	}
+}
+
+func TestMkdirAll(t *testing.T) {
+	m := fstest.MapFS{"a/f": {}}
+	fsys := mkdirOnly{m}
+	if err := MkdirAll(fsys, "a/b/c", 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a/b", "a/b/c"} {
+		if f := m[name]; f == nil || f.Mode != ModeDir|0o750 {
+			t.Errorf("MkdirAll didn't create %s", name)
+		}
+	}
+	if m["a"] != nil {
+		t.Errorf("MkdirAll created existing directory a")
+	}
+	if err := MkdirAll(fsys, "a/b", 0o750); err != nil {
+		t.Errorf("MkdirAll of existing directory: %v", err)
+	}
+	if err := MkdirAll(fsys, "a/f/g", 0o750); err == nil {
+		t.Errorf("MkdirAll below a file succeeded")
+	}
+	if err := MkdirAll(fsys, "../a", 0o750); !errors.Is(err, ErrInvalid) {
+		t.Errorf("MkdirAll of invalid path: got %v, want ErrInvalid", err)
+	}
+}
+
+func TestWriteUnsupported(t *testing.T) {
+	fsys := struct{ FS }{testFsys}
+	for name, err := range map[string]error{
+		"WriteFile": WriteFile(fsys, "x", nil, 0o666),
+		"Mkdir":     Mkdir(fsys, "x", 0o777),
+		"MkdirAll":  MkdirAll(fsys, "x/y", 0o777),
+		"Remove":    Remove(fsys, "hello.txt"),
+		"Rename":    Rename(fsys, "hello.txt", "x"),
+	} {
+		if !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("%s on a read-only FS: got %v, want ErrUnsupported", name, err)
+		}
+	}
+}
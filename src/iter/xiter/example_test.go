@@ -0,0 +1,64 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xiter_test
+
+import (
+	"fmt"
+	"iter/xiter"
+	"maps"
+	"slices"
+	"strings"
+)
+
+func ExampleMap() {
+	words := []string{"apple", "Banana", "cherry", "Date"}
+	isUpper := func(s string) bool { return s[0] >= 'A' && s[0] <= 'Z' }
+	for w := range xiter.Map(strings.ToLower, xiter.Filter(isUpper, slices.Values(words))) {
+		fmt.Println(w)
+	}
+	// Output:
+	// banana
+	// date
+}
+
+func ExampleChunk() {
+	lines := slices.Values([]string{"a", "b", "c", "d", "e"})
+	for batch := range xiter.Chunk(lines, 2) {
+		fmt.Println(batch)
+	}
+	// Output:
+	// [a b]
+	// [c d]
+	// [e]
+}
+
+func ExampleZip() {
+	names := slices.Values([]string{"x", "y", "z"})
+	values := slices.Values([]float64{1.5, 2.5})
+	fmt.Println(maps.Collect(xiter.Zip(names, values)))
+	// Output:
+	// map[x:1.5 y:2.5]
+}
+
+func ExampleReduce() {
+	prices := map[string]int{"tea": 3, "cake": 5, "jam": 4}
+	total := xiter.Reduce(func(sum, p int) int { return sum + p }, 0, maps.Values(prices))
+	fmt.Println(total)
+	// Output:
+	// 12
+}
+
+func ExampleTake() {
+	naturals := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	fmt.Println(slices.Collect(xiter.Take(xiter.Skip(naturals, 3), 4)))
+	// Output:
+	// [3 4 5 6]
+}
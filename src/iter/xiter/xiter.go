@@ -0,0 +1,254 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xiter implements adapters that combine and transform
+// iterators of type [iter.Seq] and [iter.Seq2].
+//
+// Most adapters come in two forms: one for [iter.Seq], and one whose
+// name ends in 2 for [iter.Seq2]. Adapters taking a function take it as
+// their first argument, so that chains read from the outermost operation
+// to the source:
+//
+//	squares := xiter.Map(square, xiter.Filter(isEven, slices.Values(s)))
+//
+// The adapters are lazy: they return an iterator that does nothing until
+// it is ranged over, and they consume only as many values of their
+// sources as needed to produce the values that their caller asks for.
+// Ranging over an adapted iterator again ranges over its sources again.
+package xiter
+
+import "iter"
+
+// Concat returns an iterator over the concatenation of the sequences.
+func Concat[V any](seqs ...iter.Seq[V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, seq := range seqs {
+			for v := range seq {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Concat2 returns an iterator over the concatenation of the sequences.
+func Concat2[K, V any](seqs ...iter.Seq2[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, seq := range seqs {
+			for k, v := range seq {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Filter returns an iterator over the values of seq for which f returns
+// true.
+func Filter[V any](f func(V) bool, seq iter.Seq[V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for v := range seq {
+			if f(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Filter2 returns an iterator over the pairs of seq for which f returns
+// true.
+func Filter2[K, V any](f func(K, V) bool, seq iter.Seq2[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if f(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns an iterator over f applied to the values of seq.
+func Map[In, Out any](f func(In) Out, seq iter.Seq[In]) iter.Seq[Out] {
+	return func(yield func(Out) bool) {
+		for in := range seq {
+			if !yield(f(in)) {
+				return
+			}
+		}
+	}
+}
+
+// Map2 returns an iterator over f applied to the pairs of seq.
+func Map2[KIn, VIn, KOut, VOut any](f func(KIn, VIn) (KOut, VOut), seq iter.Seq2[KIn, VIn]) iter.Seq2[KOut, VOut] {
+	return func(yield func(KOut, VOut) bool) {
+		for k, v := range seq {
+			if !yield(f(k, v)) {
+				return
+			}
+		}
+	}
+}
+
+// Take returns an iterator over the first n values of seq, or over all of
+// them if seq has fewer than n values. Take stops ranging over seq once
+// it has yielded n values, without asking seq for one more.
+func Take[V any](seq iter.Seq[V], n int) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		if n <= 0 {
+			return
+		}
+		left := n
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			if left--; left == 0 {
+				return
+			}
+		}
+	}
+}
+
+// Take2 returns an iterator over the first n pairs of seq, or over all of
+// them if seq has fewer than n pairs. Like [Take], it stops ranging over
+// seq once it has yielded n pairs.
+func Take2[K, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if n <= 0 {
+			return
+		}
+		left := n
+		for k, v := range seq {
+			if !yield(k, v) {
+				return
+			}
+			if left--; left == 0 {
+				return
+			}
+		}
+	}
+}
+
+// Skip returns an iterator over the values of seq after the first n.
+func Skip[V any](seq iter.Seq[V], n int) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		left := n
+		for v := range seq {
+			if left > 0 {
+				left--
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Skip2 returns an iterator over the pairs of seq after the first n.
+func Skip2[K, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		left := n
+		for k, v := range seq {
+			if left > 0 {
+				left--
+				continue
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk returns an iterator over consecutive slices of up to n values of
+// seq. All but the last slice have length n. If seq is empty, the
+// sequence is empty: there is no empty slice in the sequence. Each slice
+// is newly allocated, so the caller may retain or modify it.
+// Chunk panics if n is less than 1.
+func Chunk[V any](seq iter.Seq[V], n int) iter.Seq[[]V] {
+	if n < 1 {
+		panic("cannot be less than 1")
+	}
+	return func(yield func([]V) bool) {
+		var chunk []V
+		for v := range seq {
+			if chunk == nil {
+				chunk = make([]V, 0, n)
+			}
+			chunk = append(chunk, v)
+			if len(chunk) == n {
+				if !yield(chunk) {
+					return
+				}
+				chunk = nil
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Zip returns an iterator over the pairs of values of x and y at the same
+// position: the first value of x with the first value of y, and so on.
+// The sequence stops at the end of the shorter of x and y.
+//
+// Zip ranges over x, and pulls the values of y with [iter.Pull].
+func Zip[V1, V2 any](x iter.Seq[V1], y iter.Seq[V2]) iter.Seq2[V1, V2] {
+	return func(yield func(V1, V2) bool) {
+		next, stop := iter.Pull(y)
+		defer stop()
+		for v1 := range x {
+			v2, ok := next()
+			if !ok || !yield(v1, v2) {
+				return
+			}
+		}
+	}
+}
+
+// Reduce combines the values of seq with f, in order, starting from sum:
+// it returns f(...f(f(sum, v0), v1)..., vn). If seq is empty, Reduce
+// returns sum.
+func Reduce[Sum, V any](f func(Sum, V) Sum, sum Sum, seq iter.Seq[V]) Sum {
+	for v := range seq {
+		sum = f(sum, v)
+	}
+	return sum
+}
+
+// Reduce2 combines the pairs of seq with f, in order, starting from sum:
+// it returns f(...f(f(sum, k0, v0), k1, v1)..., kn, vn). If seq is empty,
+// Reduce2 returns sum.
+func Reduce2[Sum, K, V any](f func(Sum, K, V) Sum, sum Sum, seq iter.Seq2[K, V]) Sum {
+	for k, v := range seq {
+		sum = f(sum, k, v)
+	}
+	return sum
+}
+
+// Keys returns an iterator over the first values of the pairs of seq.
+func Keys[K, V any](seq iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the second values of the pairs of seq.
+func Values[K, V any](seq iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range seq {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
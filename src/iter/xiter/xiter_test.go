@@ -0,0 +1,264 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xiter_test
+
+import (
+	"internal/testenv"
+	"iter"
+	. "iter/xiter"
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// count returns an iterator over 0, 1, ..., n-1 that records in *pulled
+// how many values it yielded.
+func count(n int, pulled *int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := range n {
+			*pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// pairs returns an iterator over the pairs (i, strconv.Itoa(i)) for i in
+// 0, 1, ..., n-1.
+func pairs(n int) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for i := range n {
+			if !yield(i, strconv.Itoa(i)) {
+				return
+			}
+		}
+	}
+}
+
+// collect2 collects the pairs of seq into two slices.
+func collect2[K, V any](seq iter.Seq2[K, V]) (ks []K, vs []V) {
+	for k, v := range seq {
+		ks = append(ks, k)
+		vs = append(vs, v)
+	}
+	return ks, vs
+}
+
+func TestConcat(t *testing.T) {
+	var n int
+	got := slices.Collect(Concat(count(3, &n), Concat[int](), count(2, &n)))
+	if want := []int{0, 1, 2, 0, 1}; !slices.Equal(got, want) {
+		t.Errorf("Concat yields %v, want %v", got, want)
+	}
+	got = slices.Collect(Take(Concat(count(3, &n), count(100, &n)), 4))
+	if want := []int{0, 1, 2, 0}; !slices.Equal(got, want) {
+		t.Errorf("Take(Concat, 4) yields %v, want %v", got, want)
+	}
+
+	ks, vs := collect2(Concat2(pairs(2), pairs(1)))
+	if want := []int{0, 1, 0}; !slices.Equal(ks, want) {
+		t.Errorf("Concat2 yields keys %v, want %v", ks, want)
+	}
+	if want := []string{"0", "1", "0"}; !slices.Equal(vs, want) {
+		t.Errorf("Concat2 yields values %v, want %v", vs, want)
+	}
+}
+
+func TestFilterMap(t *testing.T) {
+	var n int
+	isOdd := func(v int) bool { return v%2 == 1 }
+	square := func(v int) int { return v * v }
+	got := slices.Collect(Map(square, Filter(isOdd, count(10, &n))))
+	if want := []int{1, 9, 25, 49, 81}; !slices.Equal(got, want) {
+		t.Errorf("Map(square, Filter(isOdd)) yields %v, want %v", got, want)
+	}
+
+	swap := func(k int, v string) (string, int) { return v + v, k }
+	even := func(k int, _ string) bool { return k%2 == 0 }
+	ks, vs := collect2(Map2(swap, Filter2(even, pairs(5))))
+	if want := []string{"00", "22", "44"}; !slices.Equal(ks, want) {
+		t.Errorf("Map2(swap, Filter2(even)) yields keys %v, want %v", ks, want)
+	}
+	if want := []int{0, 2, 4}; !slices.Equal(vs, want) {
+		t.Errorf("Map2(swap, Filter2(even)) yields values %v, want %v", vs, want)
+	}
+
+	if got, want := slices.Collect(Keys(pairs(3))), []int{0, 1, 2}; !slices.Equal(got, want) {
+		t.Errorf("Keys yields %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(Values(pairs(3))), []string{"0", "1", "2"}; !slices.Equal(got, want) {
+		t.Errorf("Values yields %v, want %v", got, want)
+	}
+}
+
+func TestTakeSkip(t *testing.T) {
+	for _, size := range []int{0, 1, 5} {
+		for _, n := range []int{-1, 0, 1, 4, 5, 6} {
+			var pulled int
+			got := slices.Collect(Take(count(size, &pulled), n))
+			want := slices.Collect(count(max(0, min(n, size)), new(int)))
+			if !slices.Equal(got, want) {
+				t.Errorf("Take(count(%d), %d) yields %v, want %v", size, n, got, want)
+			}
+			if pulled != len(want) {
+				t.Errorf("Take(count(%d), %d) pulled %d values, want %d", size, n, pulled, len(want))
+			}
+			ks, _ := collect2(Take2(pairs(size), n))
+			if !slices.Equal(ks, want) {
+				t.Errorf("Take2(pairs(%d), %d) yields %v, want %v", size, n, ks, want)
+			}
+
+			got = slices.Collect(Skip(count(size, &pulled), n))
+			want = want[:0]
+			for i := max(n, 0); i < size; i++ {
+				want = append(want, i)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("Skip(count(%d), %d) yields %v, want %v", size, n, got, want)
+			}
+			ks, _ = collect2(Skip2(pairs(size), n))
+			if !slices.Equal(ks, want) {
+				t.Errorf("Skip2(pairs(%d), %d) yields %v, want %v", size, n, ks, want)
+			}
+		}
+	}
+
+	// The adapted iterators can be ranged over more than once.
+	var pulled int
+	seq := Take(count(10, &pulled), 3)
+	for range 2 {
+		if got, want := slices.Collect(seq), []int{0, 1, 2}; !slices.Equal(got, want) {
+			t.Errorf("Take(count(10), 3) yields %v, want %v", got, want)
+		}
+	}
+}
+
+func TestChunk(t *testing.T) {
+	for _, size := range []int{0, 1, 5, 6} {
+		var pulled int
+		var got [][]int
+		for c := range Chunk(count(size, &pulled), 3) {
+			got = append(got, c)
+		}
+		want := slices.Collect(slices.Chunk(slices.Collect(count(size, new(int))), 3))
+		if !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("Chunk(count(%d), 3) yields %v, want %v", size, got, want)
+		}
+	}
+
+	// The chunks are independent slices.
+	chunks := slices.Collect(Chunk(count(4, new(int)), 2))
+	chunks[0] = append(chunks[0], 9)
+	if chunks[1][0] != 2 {
+		t.Errorf("appending to a chunk changed the next one")
+	}
+
+	// Stopping early doesn't pull more values than needed.
+	var pulled int
+	for range Chunk(count(10, &pulled), 3) {
+		break
+	}
+	if pulled != 3 {
+		t.Errorf("first chunk of 3 pulled %d values", pulled)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Chunk(seq, 0) didn't panic")
+		}
+	}()
+	Chunk(count(1, new(int)), 0)
+}
+
+func TestZip(t *testing.T) {
+	for _, tt := range []struct{ nx, ny int }{{0, 0}, {0, 3}, {3, 0}, {3, 3}, {2, 5}, {5, 2}} {
+		var pulledX, pulledY int
+		ks, vs := collect2(Zip(count(tt.nx, &pulledX), Map(strconv.Itoa, count(tt.ny, &pulledY))))
+		n := min(tt.nx, tt.ny)
+		if want := slices.Collect(count(n, new(int))); !slices.Equal(ks, want) {
+			t.Errorf("Zip(count(%d), count(%d)) yields keys %v, want %v", tt.nx, tt.ny, ks, want)
+		}
+		if want := slices.Collect(Map(strconv.Itoa, count(n, new(int)))); !slices.Equal(vs, want) {
+			t.Errorf("Zip(count(%d), count(%d)) yields values %v, want %v", tt.nx, tt.ny, vs, want)
+		}
+	}
+
+	// Breaking out of the loop stops both sequences.
+	var pulled int
+	for x, y := range Zip(count(10, &pulled), count(10, &pulled)) {
+		if x != y {
+			t.Errorf("Zip yields %d, %d", x, y)
+		}
+		if x == 1 {
+			break
+		}
+	}
+	if pulled != 4 {
+		t.Errorf("zipping 2 pairs pulled %d values, want 4", pulled)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	add := func(sum, v int) int { return sum + v }
+	if got := Reduce(add, 100, count(5, new(int))); got != 110 {
+		t.Errorf("Reduce(add, 100, count(5)) = %d, want 110", got)
+	}
+	if got := Reduce(add, 7, count(0, new(int))); got != 7 {
+		t.Errorf("Reduce of empty sequence = %d, want 7", got)
+	}
+	join := func(s string, k int, v string) string { return s + strconv.Itoa(k) + "=" + v + ";" }
+	if got, want := Reduce2(join, ">", pairs(3)), ">0=0;1=1;2=2;"; got != want {
+		t.Errorf("Reduce2(join, >, pairs(3)) = %q, want %q", got, want)
+	}
+	m := map[string]int{"a": 1, "b": 2}
+	sum := func(s int, _ string, v int) int { return s + v }
+	if got := Reduce2(sum, 0, maps.All(m)); got != 3 {
+		t.Errorf("Reduce2(sum, 0, maps.All) = %d, want 3", got)
+	}
+}
+
+func TestChainAllocs(t *testing.T) {
+	testenv.SkipIfOptimizationOff(t)
+	s := make([]int, 100)
+	for i := range s {
+		s[i] = i
+	}
+	isEven := func(v int) bool { return v%2 == 0 }
+	square := func(v int) int { return v * v }
+	var total int
+	allocs := testing.AllocsPerRun(10, func() {
+		total = 0
+		for v := range Take(Map(square, Filter(isEven, slices.Values(s))), 10) {
+			total += v
+		}
+	})
+	if total != 1140 {
+		t.Errorf("sum of the first 10 even squares = %d, want 1140", total)
+	}
+	// The adapters are inlined, and their closures don't escape.
+	if allocs != 0 {
+		t.Errorf("chain of adapters allocates %v times per run, want 0", allocs)
+	}
+}
+
+func BenchmarkChain(b *testing.B) {
+	s := make([]int, 1000)
+	for i := range s {
+		s[i] = i
+	}
+	isEven := func(v int) bool { return v%2 == 0 }
+	square := func(v int) int { return v * v }
+	for b.Loop() {
+		total := 0
+		for v := range Map(square, Filter(isEven, slices.Values(s))) {
+			total += v
+		}
+		if total == 0 {
+			b.Fatal("empty sum")
+		}
+	}
+}
@@ -211,7 +211,7 @@ func (c *conf) mustUseGoResolver(r *Resolver) bool {
 		}
 	}
 
-	return c.netGo || r.preferGo()
+	return c.netGo || r.preferGo() || r.validatesDNSSEC()
 }
 
 // addrLookupOrder determines which strategy to use to resolve addresses.
@@ -284,6 +284,12 @@ func (c *conf) lookupOrder(r *Resolver, hostname string) (ret hostLookupOrder, d
 
 	dnsConf = getSystemDNSConfig()
 
+	if dnsConf.encrypted(r) {
+		// The cgo resolver would send the queries in cleartext.
+		fallbackOrder = hostLookupFilesDNS
+		canUseCgo = false
+	}
+
 	if canUseCgo && dnsConf.err != nil && !errors.Is(dnsConf.err, fs.ErrNotExist) && !errors.Is(dnsConf.err, fs.ErrPermission) {
 		// We can't read the resolv.conf file, so use cgo if we can.
 		return hostLookupCgo, dnsConf
@@ -407,10 +413,10 @@ func (c *conf) lookupOrder(r *Resolver, hostname string) (ret hostLookupOrder, d
 				continue
 			case hostname != "" && stringslite.HasPrefix(src.source, "mdns"):
 				if stringsHasSuffixFold(hostname, ".local") {
-					// Per RFC 6762, the ".local" TLD is special. And
-					// because Go's native resolver doesn't do mDNS or
-					// similar local resolution mechanisms, assume that
-					// libc might (via Avahi, etc) and use cgo.
+					// Per RFC 6762, the ".local" TLD is special. Go's
+					// native resolver only sends one-shot mDNS queries,
+					// so given a choice, let libc resolve it (via Avahi,
+					// etc), which also caches the answers.
 					return hostLookupCgo, dnsConf
 				}
 
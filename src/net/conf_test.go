@@ -7,6 +7,7 @@
 package net
 
 import (
+	"context"
 	"io/fs"
 	"os"
 	"testing"
@@ -340,6 +341,24 @@ func TestConfHostLookupOrder(t *testing.T) {
 			nss:       nssStr(t, "foo: bar"),
 			hostTests: []nssHostTest{{"google.com", "myhostname", hostLookupCgo}},
 		},
+		{
+			name: "resolv.conf-encrypted",
+			resolver: &Resolver{Exchange: func(context.Context, string, []byte) ([]byte, error) {
+				return nil, nil
+			}},
+			c:         &conf{},
+			resolv:    &dnsConfig{servers: []string{"8.8.8.8:53"}, encryptedServers: []string{"tls://8.8.8.8:853"}, ndots: 1, timeout: 5, attempts: 2, unknownOpt: true, transport: "tls"},
+			nss:       nssStr(t, "hosts: files mdns4_minimal [NOTFOUND=return] dns"),
+			hostTests: []nssHostTest{{"google.com", "myhostname", hostLookupFilesDNS}},
+		},
+		// Resolvers without Exchange ignore the encrypted transport.
+		{
+			name:      "resolv.conf-encrypted-no-exchange",
+			c:         &conf{},
+			resolv:    &dnsConfig{servers: []string{"8.8.8.8:53"}, encryptedServers: []string{"tls://8.8.8.8:853"}, ndots: 1, timeout: 5, attempts: 2, unknownOpt: true, transport: "tls"},
+			nss:       nssStr(t, "hosts: files mdns4_minimal [NOTFOUND=return] dns"),
+			hostTests: []nssHostTest{{"google.com", "myhostname", hostLookupCgo}},
+		},
 		// Issue 24393: make sure "Resolver.PreferGo = true" acts like netgo.
 		{
 			name:     "resolver-prefergo",
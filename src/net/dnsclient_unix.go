@@ -56,7 +56,10 @@ var (
 // netedns0 controls whether we send an EDNS0 additional header.
 var netedns0 = godebug.New("netedns0")
 
-func newRequest(q dnsmessage.Question, ad bool) (id uint16, udpReq, tcpReq []byte, err error) {
+// netmdns controls whether we look up .local names with multicast DNS.
+var netmdns = godebug.New("netmdns")
+
+func newRequest(q dnsmessage.Question, ad, do bool) (id uint16, udpReq, tcpReq []byte, err error) {
 	id = uint16(randInt())
 	b := dnsmessage.NewBuilder(make([]byte, 2, 514), dnsmessage.Header{ID: id, RecursionDesired: true, AuthenticData: ad})
 	if err := b.StartQuestions(); err != nil {
@@ -66,15 +69,16 @@ func newRequest(q dnsmessage.Question, ad bool) (id uint16, udpReq, tcpReq []byt
 		return 0, nil, nil, err
 	}
 
-	if netedns0.Value() == "0" {
+	if netedns0.Value() == "0" && !do {
 		netedns0.IncNonDefault()
 	} else {
 		// Accept packets up to maxDNSPacketSize.  RFC 6891.
+		// The DNSSEC OK bit asks for the DNSSEC records.  RFC 3225.
 		if err := b.StartAdditionals(); err != nil {
 			return 0, nil, nil, err
 		}
 		var rh dnsmessage.ResourceHeader
-		if err := rh.SetEDNS0(maxDNSPacketSize, dnsmessage.RCodeSuccess, false); err != nil {
+		if err := rh.SetEDNS0(maxDNSPacketSize, dnsmessage.RCodeSuccess, do); err != nil {
 			return 0, nil, nil, err
 		}
 		if err := b.OPTResource(rh, dnsmessage.OPTResource{}); err != nil {
@@ -168,7 +172,7 @@ func dnsStreamRoundTrip(c Conn, id uint16, query dnsmessage.Question, b []byte)
 // exchange sends a query on the connection and hopes for a response.
 func (r *Resolver) exchange(ctx context.Context, server string, q dnsmessage.Question, timeout time.Duration, useTCP, ad bool) (dnsmessage.Parser, dnsmessage.Header, error) {
 	q.Class = dnsmessage.ClassINET
-	id, udpReq, tcpReq, err := newRequest(q, ad)
+	id, udpReq, tcpReq, err := newRequest(q, ad, r.validatesDNSSEC())
 	if err != nil {
 		return dnsmessage.Parser{}, dnsmessage.Header{}, errCannotMarshalDNSMessage
 	}
@@ -218,6 +222,34 @@ func (r *Resolver) exchange(ctx context.Context, server string, q dnsmessage.Que
 	return dnsmessage.Parser{}, dnsmessage.Header{}, errNoAnswerFromDNSServer
 }
 
+// exchangeEncrypted sends a query to server with r.Exchange.
+func (r *Resolver) exchangeEncrypted(ctx context.Context, server string, q dnsmessage.Question, timeout time.Duration, ad bool) (dnsmessage.Parser, dnsmessage.Header, error) {
+	q.Class = dnsmessage.ClassINET
+	id, req, _, err := newRequest(q, ad, r.validatesDNSSEC())
+	if err != nil {
+		return dnsmessage.Parser{}, dnsmessage.Header{}, errCannotMarshalDNSMessage
+	}
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(timeout))
+	defer cancel()
+	resp, err := r.Exchange(ctx, server, req)
+	if err != nil {
+		return dnsmessage.Parser{}, dnsmessage.Header{}, mapErr(err)
+	}
+	var p dnsmessage.Parser
+	h, err := p.Start(resp)
+	if err != nil {
+		return dnsmessage.Parser{}, dnsmessage.Header{}, errCannotUnmarshalDNSMessage
+	}
+	rq, err := p.Question()
+	if err != nil || !checkResponse(id, q, h, rq) {
+		return dnsmessage.Parser{}, dnsmessage.Header{}, errInvalidDNSResponse
+	}
+	if err := p.SkipQuestion(); err != dnsmessage.ErrSectionDone {
+		return dnsmessage.Parser{}, dnsmessage.Header{}, errInvalidDNSResponse
+	}
+	return p, h, nil
+}
+
 // checkHeader performs basic sanity checks on the header.
 func checkHeader(p *dnsmessage.Parser, h dnsmessage.Header) error {
 	rcode, hasAdd := extractExtendedRCode(*p, h)
@@ -296,8 +328,9 @@ func extractExtendedRCode(p dnsmessage.Parser, hdr dnsmessage.Header) (dnsmessag
 // (otherwise answer will not find the answers).
 func (r *Resolver) tryOneName(ctx context.Context, cfg *dnsConfig, name string, qtype dnsmessage.Type) (dnsmessage.Parser, string, error) {
 	var lastErr error
+	servers := cfg.serversFor(r)
 	serverOffset := cfg.serverOffset()
-	sLen := uint32(len(cfg.servers))
+	sLen := uint32(len(servers))
 
 	n, err := dnsmessage.NewName(name)
 	if err != nil {
@@ -311,9 +344,9 @@ func (r *Resolver) tryOneName(ctx context.Context, cfg *dnsConfig, name string,
 
 	for i := 0; i < cfg.attempts; i++ {
 		for j := uint32(0); j < sLen; j++ {
-			server := cfg.servers[(serverOffset+j)%sLen]
+			server := servers[(serverOffset+j)%sLen]
 
-			p, h, err := r.exchange(ctx, server, q, cfg.timeout, cfg.useTCP, cfg.trustAD)
+			p, h, err := r.exchangeConfig(ctx, cfg, server, q)
 			if err != nil {
 				dnsErr := newDNSError(err, name, server)
 				// Set IsTemporary for socket-level errors. Note that this flag
@@ -325,6 +358,17 @@ func (r *Resolver) tryOneName(ctx context.Context, cfg *dnsConfig, name string,
 				continue
 			}
 
+			// Validate the response before trusting it, including
+			// its denial of the existence of the name.
+			status, err := r.dnssecStatus(ctx, cfg, p, h, q)
+			if err != nil {
+				if status == DNSSECBogus {
+					addDNSSECStatus(ctx, DNSSECBogus)
+				}
+				lastErr = newDNSError(err, name, server)
+				continue
+			}
+
 			if err := checkHeader(&p, h); err != nil {
 				if err == errNoSuchHost {
 					// The name does not exist, so trying
@@ -345,12 +389,22 @@ func (r *Resolver) tryOneName(ctx context.Context, cfg *dnsConfig, name string,
 				continue
 			}
 
+			addDNSSECStatus(ctx, status)
 			return p, server, nil
 		}
 	}
 	return dnsmessage.Parser{}, "", lastErr
 }
 
+// exchangeConfig sends a query to server, one of cfg.serversFor(r), with
+// the transport of cfg.
+func (r *Resolver) exchangeConfig(ctx context.Context, cfg *dnsConfig, server string, q dnsmessage.Question) (dnsmessage.Parser, dnsmessage.Header, error) {
+	if cfg.encrypted(r) {
+		return r.exchangeEncrypted(ctx, server, q, cfg.timeout, cfg.trustAD)
+	}
+	return r.exchange(ctx, server, q, cfg.timeout, cfg.useTCP, cfg.trustAD)
+}
+
 // A resolverConfig represents a DNS stub resolver configuration.
 type resolverConfig struct {
 	initOnce sync.Once // guards init of resolverConfig
@@ -633,16 +687,80 @@ func (r *Resolver) goLookupIPCNAMEOrder(ctx context.Context, network, name strin
 		// See comment in func lookup above about use of errNoSuchHost.
 		return nil, dnsmessage.Name{}, newDNSError(errNoSuchHost, name, "")
 	}
+	if conf == nil {
+		conf = getSystemDNSConfig()
+	}
+	// Multicast queries can't be encrypted, so they are not sent if
+	// the queries to the name servers are.
+	if isMDNSName(name) && !conf.encrypted(r) {
+		if netmdns.Value() != "0" {
+			return r.goLookupIPCNAMEMDNS(ctx, network, name, order, conf)
+		}
+		netmdns.IncNonDefault()
+	}
+	return r.goLookupIPCNAMEDNS(ctx, network, name, order, conf)
+}
+
+// goLookupIPCNAMEMDNS looks up name, in the .local domain, with
+// multicast DNS and with the name servers at the same time, since some
+// networks serve .local names with unicast DNS (golang.org/issue/16739).
+// It returns the first answer with addresses, or else the error of the
+// name servers.
+func (r *Resolver) goLookupIPCNAMEMDNS(ctx context.Context, network, name string, order hostLookupOrder, conf *dnsConfig) ([]IPAddr, dnsmessage.Name, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		addrs []IPAddr
+		cname dnsmessage.Name
+		err   error
+	}
+	var ifi *Interface
+	if r != nil {
+		ifi = r.MulticastInterface
+	}
+	mdns := make(chan result, 1)
+	dns := make(chan result, 1)
+	dnsWaitGroup.Add(2)
+	go func() {
+		defer dnsWaitGroup.Done()
+		addrs, cname, err := mdnsLookupIP(ctx, ifi, network, name)
+		mdns <- result{addrs, cname, err}
+	}()
+	go func() {
+		defer dnsWaitGroup.Done()
+		addrs, cname, err := r.goLookupIPCNAMEDNS(ctx, network, name, order, conf)
+		dns <- result{addrs, cname, err}
+	}()
+
+	var dnsResult *result
+	for mdns != nil || dnsResult == nil {
+		select {
+		case res := <-mdns:
+			if res.err == nil {
+				return res.addrs, res.cname, nil
+			}
+			mdns = nil
+		case res := <-dns:
+			if res.err == nil && len(res.addrs) > 0 {
+				return res.addrs, res.cname, nil
+			}
+			dnsResult = &res
+			dns = nil
+		}
+	}
+	return dnsResult.addrs, dnsResult.cname, dnsResult.err
+}
+
+// goLookupIPCNAMEDNS looks up name with the name servers of conf, and
+// then in /etc/hosts if order is hostLookupDNSFiles.
+func (r *Resolver) goLookupIPCNAMEDNS(ctx context.Context, network, name string, order hostLookupOrder, conf *dnsConfig) (addrs []IPAddr, cname dnsmessage.Name, err error) {
 	type result struct {
 		p      dnsmessage.Parser
 		server string
 		error
 	}
 
-	if conf == nil {
-		conf = getSystemDNSConfig()
-	}
-
 	lane := make(chan result, 1)
 	qtypes := []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA}
 	if network == "CNAME" {
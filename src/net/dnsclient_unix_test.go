@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"net/netip"
 	"os"
 	"path"
 	"path/filepath"
@@ -2855,3 +2856,471 @@ func TestExtendedRCode(t *testing.T) {
 		t.Fatalf("r.tryOneName(): unexpected error: %v", err)
 	}
 }
+
+func TestEncryptedTransport(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+
+	noDial := func(ctx context.Context, network, address string) (Conn, error) {
+		t.Errorf("Dial(%q, %q) called with an encrypted transport", network, address)
+		return nil, errors.New("no dial")
+	}
+	var mu sync.Mutex
+	var servers []string
+	exchange := func(ctx context.Context, server string, query []byte) ([]byte, error) {
+		mu.Lock()
+		servers = append(servers, server)
+		mu.Unlock()
+		var q dnsmessage.Message
+		if err := q.Unpack(query); err != nil {
+			return nil, err
+		}
+		r := dnsmessage.Message{
+			Header: dnsmessage.Header{
+				ID:                 q.ID,
+				Response:           true,
+				RecursionAvailable: true,
+			},
+			Questions: q.Questions,
+		}
+		if q.Questions[0].Type == dnsmessage.TypeA {
+			r.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{
+					Name:   q.Questions[0].Name,
+					Type:   dnsmessage.TypeA,
+					Class:  dnsmessage.ClassINET,
+					Length: 4,
+				},
+				Body: &dnsmessage.AResource{A: TestAddr},
+			}}
+		}
+		return r.Pack()
+	}
+
+	for _, tt := range []struct {
+		option string
+		server string
+	}{
+		{"dns-over-tls", "tls://192.0.2.1:853"},
+		{"dns-over-https", "https://192.0.2.1/dns-query"},
+		{"dns-over-https:/resolve", "https://192.0.2.1/resolve"},
+	} {
+		if err := conf.writeAndUpdate([]string{"nameserver 192.0.2.1", "options " + tt.option}); err != nil {
+			t.Fatal(err)
+		}
+		servers = nil
+		r := Resolver{PreferGo: true, Dial: noDial, Exchange: exchange}
+		addrs, err := r.LookupHost(context.Background(), "www.golang.org")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.option, err)
+		}
+		if want := []string{IP(TestAddr[:]).String()}; !slices.Equal(addrs, want) {
+			t.Errorf("%s: got %v, want %v", tt.option, addrs, want)
+		}
+		if len(servers) == 0 {
+			t.Errorf("%s: Exchange not called", tt.option)
+		}
+		for _, s := range servers {
+			if s != tt.server {
+				t.Errorf("%s: Exchange called with server %q, want %q", tt.option, s, tt.server)
+			}
+		}
+
+		// Without Exchange, the option is ignored, and the queries are
+		// sent in cleartext with Dial.
+		var dialed []string
+		fake := fakeDNSServer{rh: func(n, s string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+			mu.Lock()
+			dialed = append(dialed, s)
+			mu.Unlock()
+			return dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: q.ID, Response: true, RecursionAvailable: true, RCode: dnsmessage.RCodeNameError},
+				Questions: q.Questions,
+			}, nil
+		}}
+		servers = nil
+		r = Resolver{PreferGo: true, Dial: fake.DialContext}
+		_, err = r.LookupHost(context.Background(), "www.golang.org")
+		if dnsErr, ok := errors.AsType[*DNSError](err); !ok || !dnsErr.IsNotFound {
+			t.Errorf("%s: lookup without Exchange: got %v, want not found error", tt.option, err)
+		}
+		if len(servers) != 0 {
+			t.Errorf("%s: Exchange called with servers %q", tt.option, servers)
+		}
+		if len(dialed) == 0 || !slices.Equal(slices.Compact(dialed), []string{"192.0.2.1:53"}) {
+			t.Errorf("%s: Dial called with %q, want 192.0.2.1:53", tt.option, dialed)
+		}
+	}
+}
+
+func TestDNSSECStatus(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+
+	var validating atomic.Bool
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		do := false
+		for _, rr := range q.Additionals {
+			if rr.Header.Type == dnsmessage.TypeOPT {
+				do = rr.Header.DNSSECAllowed()
+			}
+		}
+		if do != validating.Load() {
+			t.Errorf("query for %v has DNSSEC OK bit %v, want %v", q.Questions[0].Name, do, validating.Load())
+		}
+		qname := q.Questions[0].Name
+		r := dnsmessage.Message{
+			Header: dnsmessage.Header{
+				ID:                 q.ID,
+				Response:           true,
+				RecursionAvailable: true,
+				AuthenticData:      strings.HasPrefix(qname.String(), "secure."),
+			},
+			Questions: q.Questions,
+		}
+		hdr := dnsmessage.ResourceHeader{Name: qname, Type: q.Questions[0].Type, Class: dnsmessage.ClassINET}
+		switch q.Questions[0].Type {
+		case dnsmessage.TypeA:
+			r.Answers = []dnsmessage.Resource{{Header: hdr, Body: &dnsmessage.AResource{A: TestAddr}}}
+		case dnsmessage.TypeAAAA:
+			if strings.HasPrefix(qname.String(), "mixed.") {
+				r.Answers = []dnsmessage.Resource{{Header: hdr, Body: &dnsmessage.AAAAResource{AAAA: TestAddr6}}}
+			}
+		case dnsmessage.TypeTXT:
+			r.Answers = []dnsmessage.Resource{{Header: hdr, Body: &dnsmessage.TXTResource{TXT: []string{"txt"}}}}
+		}
+		return r, nil
+	}}
+
+	// Without a validator, the status comes from the AD bit, with trust-ad.
+	r := &Resolver{PreferGo: true, Dial: fake.DialContext}
+	for _, tt := range []struct {
+		options string
+		name    string
+		want    DNSSECStatus
+	}{
+		{"", "secure.go.dev", DNSSECUnvalidated},
+		{"options trust-ad", "secure.go.dev", DNSSECSecure},
+		{"options trust-ad", "other.go.dev", DNSSECUnvalidated},
+	} {
+		if err := conf.writeAndUpdate([]string{"nameserver 127.0.0.1", tt.options}); err != nil {
+			t.Fatal(err)
+		}
+		_, status, err := r.LookupHostDNSSEC(context.Background(), tt.name)
+		if err != nil || status != tt.want {
+			t.Errorf("%q: LookupHostDNSSEC(%q) = %v, %v; want %v, nil", tt.options, tt.name, status, err, tt.want)
+		}
+	}
+
+	// With a validator, the status is the weakest of the answers.
+	validating.Store(true)
+	var queried atomic.Bool
+	r.ValidateDNSSEC = func(ctx context.Context, response []byte, query func(context.Context, string, uint16) ([]byte, error)) (DNSSECStatus, error) {
+		var m dnsmessage.Message
+		if err := m.Unpack(response); err != nil {
+			t.Errorf("invalid response: %v", err)
+			return DNSSECBogus, err
+		}
+		key, err := query(ctx, "go.dev", uint16(dnsmessage.TypeTXT))
+		if err != nil {
+			return DNSSECUnvalidated, err
+		}
+		if err := m.Unpack(key); err != nil || m.Questions[0].Name.String() != "go.dev." || len(m.Answers) != 1 {
+			t.Errorf("invalid query response %v: %v", m, err)
+		}
+		queried.Store(true)
+		if err := m.Unpack(response); err != nil {
+			return DNSSECBogus, err
+		}
+		name := m.Questions[0].Name.String()
+		switch {
+		case strings.HasPrefix(name, "secure."):
+			return DNSSECSecure, nil
+		case strings.HasPrefix(name, "mixed."):
+			if m.Questions[0].Type == dnsmessage.TypeAAAA {
+				return DNSSECInsecure, nil
+			}
+			return DNSSECSecure, nil
+		case strings.HasPrefix(name, "bogus."):
+			return DNSSECBogus, nil
+		}
+		return DNSSECInsecure, nil
+	}
+	if err := conf.writeAndUpdate([]string{"nameserver 127.0.0.1", "options trust-ad"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		want DNSSECStatus
+	}{
+		{"secure.go.dev", DNSSECSecure},
+		{"mixed.go.dev", DNSSECInsecure},
+		{"other.go.dev", DNSSECInsecure},
+	} {
+		addrs, status, err := r.LookupHostDNSSEC(context.Background(), tt.name)
+		if err != nil || status != tt.want || len(addrs) == 0 {
+			t.Errorf("LookupHostDNSSEC(%q) = %v, %v, %v; want addresses, %v, nil", tt.name, addrs, status, err, tt.want)
+		}
+	}
+	if !queried.Load() {
+		t.Errorf("ValidateDNSSEC didn't send a query")
+	}
+
+	addrs, status, err := r.LookupHostDNSSEC(context.Background(), "bogus.go.dev")
+	if dnsErr, ok := errors.AsType[*DNSError](err); !ok || dnsErr.Err != errDNSSECBogus.Error() || status != DNSSECBogus {
+		t.Errorf("LookupHostDNSSEC(bogus.go.dev) = %v, %v, %v; want %v, %v", addrs, status, err, DNSSECBogus, errDNSSECBogus)
+	}
+
+	txts, status, err := r.LookupTXTDNSSEC(context.Background(), "secure.go.dev")
+	if err != nil || status != DNSSECSecure || !slices.Equal(txts, []string{"txt"}) {
+		t.Errorf("LookupTXTDNSSEC(secure.go.dev) = %v, %v, %v; want [txt], %v, nil", txts, status, err, DNSSECSecure)
+	}
+
+	// IP addresses are not looked up.
+	if _, status, err := r.LookupHostDNSSEC(context.Background(), "192.0.2.1"); err != nil || status != DNSSECUnvalidated {
+		t.Errorf("LookupHostDNSSEC(192.0.2.1) = %v, %v; want %v, nil", status, err, DNSSECUnvalidated)
+	}
+}
+
+func TestMDNSFallbackToDNS(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+	defer func(d time.Duration) { mdnsTimeout = d }(mdnsTimeout)
+	mdnsTimeout = 50 * time.Millisecond
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+	if err := conf.writeAndUpdate([]string{"nameserver 127.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Names in .local that no multicast DNS responder answers for
+	// are looked up with the name servers (golang.org/issue/16739).
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		r := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: q.ID, Response: true, RecursionAvailable: true},
+			Questions: q.Questions,
+		}
+		if q.Questions[0].Type == dnsmessage.TypeA {
+			r.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+				Body:   &dnsmessage.AResource{A: TestAddr},
+			}}
+		}
+		return r, nil
+	}}
+	r := &Resolver{PreferGo: true, Dial: fake.DialContext, MulticastInterface: loopbackInterface()}
+	addrs, _, err := r.goLookupIPCNAMEOrder(context.Background(), "ip", "gotest-unicast.local", hostLookupDNS, nil)
+	if err != nil || len(addrs) != 1 || !addrs[0].IP.Equal(IP(TestAddr[:])) {
+		t.Errorf("looking up gotest-unicast.local = %v, %v; want [%v]", addrs, err, IP(TestAddr[:]))
+	}
+}
+
+func TestMDNSMultiLabelMiss(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+	defer func(d time.Duration) { mdnsTimeout = d }(mdnsTimeout)
+	mdnsTimeout = 50 * time.Millisecond
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+	if err := conf.writeAndUpdate([]string{"nameserver 127.0.0.1", "search svc.cluster.local cluster.local", "options ndots:5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Names of several labels in .local, such as those of Kubernetes
+	// services, are usually served by the name servers, which also
+	// report the ones that don't exist.
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		r := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: q.ID, Response: true, RecursionAvailable: true},
+			Questions: q.Questions,
+		}
+		switch {
+		case q.Questions[0].Name.String() != "api.default.svc.cluster.local.":
+			r.RCode = dnsmessage.RCodeNameError
+		case q.Questions[0].Type == dnsmessage.TypeA:
+			r.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+				Body:   &dnsmessage.AResource{A: TestAddr},
+			}}
+		}
+		return r, nil
+	}}
+	r := &Resolver{PreferGo: true, Dial: fake.DialContext, MulticastInterface: loopbackInterface()}
+
+	lookup := func(name string) {
+		t.Helper()
+		addrs, _, err := r.goLookupIPCNAMEOrder(context.Background(), "ip", name, hostLookupDNS, nil)
+		if dnsErr, ok := errors.AsType[*DNSError](err); !ok || !dnsErr.IsNotFound {
+			t.Errorf("looking up %s = %v, %v; want not found error", name, addrs, err)
+		}
+	}
+	for _, name := range []string{"api.default.svc.cluster.local", "api.default.svc.cluster.local."} {
+		addrs, _, err := r.goLookupIPCNAMEOrder(context.Background(), "ip", name, hostLookupDNS, nil)
+		if err != nil || len(addrs) != 1 || !addrs[0].IP.Equal(IP(TestAddr[:])) {
+			t.Errorf("looking up %s = %v, %v; want [%v]", name, addrs, err, IP(TestAddr[:]))
+		}
+	}
+	lookup("missing.default.svc.cluster.local")
+	lookup("missing.default.svc.cluster.local.")
+	lookup("api.missing.cluster.local")
+
+	// With GODEBUG=netmdns=0, .local names are only looked up with the
+	// name servers, so misses don't wait for multicast answers.
+	t.Setenv("GODEBUG", "netmdns=0")
+	mdnsTimeout = time.Minute
+	start := time.Now()
+	lookup("missing.default.svc.cluster.local")
+	if d := time.Since(start); d > mdnsTimeout/2 {
+		t.Errorf("lookup with netmdns=0 took %v", d)
+	}
+}
+
+func TestMDNSAndDNSInParallel(t *testing.T) {
+	ifi := mdnsLoopback(t)
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+	if err := conf.writeAndUpdate([]string{"nameserver 127.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	host := fmt.Sprintf("gotest-parallel-%d.local", os.Getpid())
+	reg, err := RegisterService(ifi, &ServiceInstance{
+		Instance: fmt.Sprintf("Go Test Parallel %d", os.Getpid()),
+		Service:  "_gotest._tcp",
+		Host:     host,
+		Port:     8080,
+		Addrs:    []netip.Addr{netip.MustParseAddr("127.0.0.1")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Close()
+
+	// The multicast DNS answer is used without waiting for the name
+	// servers, which don't answer until the end of the test.
+	release := make(chan struct{})
+	defer close(release)
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		<-release
+		return dnsmessage.Message{}, errors.New("released")
+	}}
+	r := &Resolver{PreferGo: true, Dial: fake.DialContext, MulticastInterface: ifi}
+	addrs, _, err := r.goLookupIPCNAMEOrder(context.Background(), "ip", host, hostLookupDNS, nil)
+	if err != nil || len(addrs) != 1 || !addrs[0].IP.Equal(IPv4(127, 0, 0, 1)) {
+		t.Errorf("looking up %s = %v, %v; want [127.0.0.1]", host, addrs, err)
+	}
+
+	// With encrypted DNS, the name is only looked up with the name
+	// servers.
+	if err := conf.writeAndUpdate([]string{"nameserver 192.0.2.1", "options dns-over-tls"}); err != nil {
+		t.Fatal(err)
+	}
+	r.Exchange = func(_ context.Context, _ string, query []byte) ([]byte, error) {
+		var q dnsmessage.Message
+		if err := q.Unpack(query); err != nil {
+			return nil, err
+		}
+		m := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: q.ID, Response: true, RecursionAvailable: true, RCode: dnsmessage.RCodeNameError},
+			Questions: q.Questions,
+		}
+		return m.Pack()
+	}
+	addrs, _, err = r.goLookupIPCNAMEOrder(context.Background(), "ip", host, hostLookupDNS, nil)
+	if dnsErr, ok := errors.AsType[*DNSError](err); !ok || !dnsErr.IsNotFound {
+		t.Errorf("looking up %s with encrypted DNS = %v, %v; want not found error", host, addrs, err)
+	}
+}
+
+func TestDNSSECStatusConcurrent(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+	if err := conf.writeAndUpdate([]string{"nameserver 127.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server is slow, so that the concurrent lookups are merged.
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		time.Sleep(50 * time.Millisecond)
+		r := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: q.ID, Response: true, RecursionAvailable: true},
+			Questions: q.Questions,
+		}
+		if q.Questions[0].Type == dnsmessage.TypeA {
+			r.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: q.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+				Body:   &dnsmessage.AResource{A: TestAddr},
+			}}
+		}
+		return r, nil
+	}}
+	r := &Resolver{
+		PreferGo: true,
+		Dial:     fake.DialContext,
+		ValidateDNSSEC: func(_ context.Context, response []byte, _ func(context.Context, string, uint16) ([]byte, error)) (DNSSECStatus, error) {
+			var m dnsmessage.Message
+			if err := m.Unpack(response); err != nil {
+				return DNSSECBogus, err
+			}
+			if strings.HasPrefix(m.Questions[0].Name.String(), "bogus.") {
+				return DNSSECBogus, nil
+			}
+			return DNSSECSecure, nil
+		},
+	}
+
+	for _, tt := range []struct {
+		name   string
+		status DNSSECStatus
+	}{
+		{"secure.go.dev", DNSSECSecure},
+		{"bogus.go.dev", DNSSECBogus},
+	} {
+		lookups := map[string]func(ctx context.Context) (any, error){
+			"LookupHost": func(ctx context.Context) (any, error) {
+				return r.LookupHost(ctx, tt.name)
+			},
+			"LookupIPAddr": func(ctx context.Context) (any, error) {
+				return r.LookupIPAddr(ctx, tt.name)
+			},
+		}
+		for lookupName, lookup := range lookups {
+			var wg sync.WaitGroup
+			for range 4 {
+				wg.Go(func() {
+					_, status, err := lookupDNSSEC(context.Background(), lookup)
+					if status != tt.status || (err == nil) != (tt.status != DNSSECBogus) {
+						t.Errorf("%s(%q) with DNSSEC = %v, %v; want %v", lookupName, tt.name, status, err, tt.status)
+					}
+				})
+			}
+			wg.Wait()
+		}
+	}
+}
@@ -5,6 +5,8 @@
 package net
 
 import (
+	"internal/bytealg"
+	"internal/stringslite"
 	"os"
 	"sync/atomic"
 	"time"
@@ -43,6 +45,10 @@ type dnsConfig struct {
 	useTCP        bool          // force usage of TCP for DNS resolutions
 	trustAD       bool          // add AD flag to queries
 	noReload      bool          // do not check for config file updates
+	transport     string        // "tls" or "https" to use encryptedServers with Resolver.Exchange
+	// encryptedServers are the URLs of the servers for Resolver.Exchange,
+	// if transport is set.
+	encryptedServers []string
 }
 
 // serverOffset returns an offset that can be used to determine
@@ -55,3 +61,48 @@ func (c *dnsConfig) serverOffset() uint32 {
 	}
 	return 0
 }
+
+// encrypted reports whether r sends the queries to the servers with an
+// encrypted transport, which it does if c asks for one and r has an
+// Exchange function.
+func (c *dnsConfig) encrypted(r *Resolver) bool {
+	return c.transport != "" && r != nil && r.Exchange != nil
+}
+
+// serversFor returns the servers that r sends the queries to: the URLs
+// of c.encryptedServers if r uses an encrypted transport, and the
+// addresses of c.servers otherwise.
+func (c *dnsConfig) serversFor(r *Resolver) []string {
+	if c.encrypted(r) {
+		return c.encryptedServers
+	}
+	return c.servers
+}
+
+// encryptServers sets c.encryptedServers to the URLs that
+// Resolver.Exchange takes for c.transport and the addresses of
+// c.servers: tls://host:853 for DNS over TLS, and https://host followed
+// by path for DNS over HTTPS.
+func (c *dnsConfig) encryptServers(path string) {
+	servers := make([]string, 0, len(c.servers))
+	for _, server := range c.servers {
+		host, _, err := SplitHostPort(server)
+		if err != nil {
+			continue
+		}
+		switch c.transport {
+		case "tls":
+			servers = append(servers, "tls://"+JoinHostPort(host, "853"))
+		case "https":
+			if bytealg.IndexByteString(host, ':') >= 0 {
+				// An IPv6 address, whose zone is escaped as in RFC 6874.
+				if addr, zone, ok := stringslite.Cut(host, "%"); ok {
+					host = addr + "%25" + zone
+				}
+				host = "[" + host + "]"
+			}
+			servers = append(servers, "https://"+host+path)
+		}
+	}
+	c.encryptedServers = servers
+}
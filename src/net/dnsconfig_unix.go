@@ -30,6 +30,7 @@ func dnsReadConfig(filename string) *dnsConfig {
 		return conf
 	}
 	defer file.close()
+	httpsPath := "/dns-query"
 	if fi, err := file.file.Stat(); err == nil {
 		conf.mtime = fi.ModTime()
 	} else {
@@ -120,6 +121,18 @@ func dnsReadConfig(filename string) *dnsConfig {
 					// Ignore this option.
 				case s == "no-reload":
 					conf.noReload = true
+				case s == "dns-over-tls":
+					// Go option: send the queries to the name
+					// servers with DNS over TLS (RFC 7858).
+					conf.transport = "tls"
+				case s == "dns-over-https":
+					// Go option: send the queries to the name
+					// servers with DNS over HTTPS (RFC 8484).
+					conf.transport = "https"
+				case stringslite.HasPrefix(s, "dns-over-https:") && len(s) > 15 && s[15] == '/':
+					// Likewise, at the given URL path.
+					conf.transport = "https"
+					httpsPath = s[15:]
 				default:
 					conf.unknownOpt = true
 				}
@@ -138,6 +151,9 @@ func dnsReadConfig(filename string) *dnsConfig {
 	if len(conf.servers) == 0 {
 		conf.servers = defaultNS
 	}
+	if conf.transport != "" {
+		conf.encryptServers(httpsPath)
+	}
 	if len(conf.search) == 0 {
 		conf.search = dnsDefaultSearch()
 	}
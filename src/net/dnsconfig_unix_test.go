@@ -158,6 +158,30 @@ var dnsReadConfigTests = []struct {
 			search:   []string{"domain.local."},
 		},
 	},
+	{
+		name: "testdata/dns-over-tls-resolv.conf",
+		want: &dnsConfig{
+			servers:          []string{"8.8.8.8:53", "[2001:4860:4860::8888]:53"},
+			encryptedServers: []string{"tls://8.8.8.8:853", "tls://[2001:4860:4860::8888]:853"},
+			ndots:            1,
+			timeout:          5 * time.Second,
+			attempts:         2,
+			search:           []string{"domain.local."},
+			transport:        "tls",
+		},
+	},
+	{
+		name: "testdata/dns-over-https-resolv.conf",
+		want: &dnsConfig{
+			servers:          []string{"8.8.8.8:53", "[fe80::1%lo0]:53"},
+			encryptedServers: []string{"https://8.8.8.8/resolve", "https://[fe80::1%25lo0]/resolve"},
+			ndots:            1,
+			timeout:          5 * time.Second,
+			attempts:         2,
+			search:           []string{"domain.local."},
+			transport:        "https",
+		},
+	},
 	{
 		name: "testdata/openbsd-tcp-resolv.conf",
 		want: &dnsConfig{
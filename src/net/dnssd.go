@@ -0,0 +1,684 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// DNS-Based Service Discovery (RFC 6763) over multicast DNS.
+
+package net
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"internal/stringslite"
+	"net/netip"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// A ServiceInstance is an instance of a service advertised with
+// DNS-Based Service Discovery (RFC 6763) on the local link.
+type ServiceInstance struct {
+	// Instance is the user-visible name of the instance, such as
+	// "Living Room Printer". It must not contain dots.
+	Instance string
+
+	// Service is the type of the service, an underscore-prefixed
+	// service name and protocol, such as "_ipp._tcp".
+	Service string
+
+	// Host is the name of the host providing the service, such as
+	// "printer.local.".
+	Host string
+
+	// Port is the port on which the service is provided.
+	Port int
+
+	// Text holds the key/value pairs of the instance, such as
+	// "paper=A4" (RFC 6763, Section 6).
+	Text []string
+
+	// Addrs holds the addresses of Host.
+	Addrs []netip.Addr
+}
+
+var (
+	errInvalidService     = errors.New("invalid service type")
+	errInvalidInstance    = errors.New("invalid service instance name")
+	errInvalidServicePort = errors.New("invalid service port")
+	errServiceConflict    = errors.New("service instance name already in use")
+)
+
+// serviceTypeName returns the domain name of the service type service,
+// such as "_ipp._tcp.local.".
+func serviceTypeName(service string) (dnsmessage.Name, error) {
+	name, proto, ok := stringslite.Cut(stringslite.TrimSuffix(service, "."), ".")
+	if !ok || len(name) < 2 || len(name) > 16 || name[0] != '_' || proto != "_tcp" && proto != "_udp" {
+		return dnsmessage.Name{}, errInvalidService
+	}
+	n, err := dnsmessage.NewName(name + "." + proto + ".local.")
+	if err != nil {
+		return dnsmessage.Name{}, errInvalidService
+	}
+	return n, nil
+}
+
+// servicesName is the name whose PTR records list the service types
+// advertised on the link (RFC 6763, Section 9).
+var servicesName = dnsmessage.MustNewName("_services._dns-sd._udp.local.")
+
+// BrowseServices uses multicast DNS to find the instances of the
+// service type service, such as "_ipp._tcp", on the local link. It
+// waits one second for the responses, or until the deadline of ctx if
+// that is earlier, and returns the instances that responded, sorted
+// by their names.
+func (r *Resolver) BrowseServices(ctx context.Context, service string) ([]*ServiceInstance, error) {
+	typeName, err := serviceTypeName(service)
+	if err != nil {
+		return nil, &DNSError{Err: err.Error(), Name: service}
+	}
+	var ifi *Interface
+	if r != nil {
+		ifi = r.MulticastInterface
+	}
+
+	var c mdnsCache
+	q := dnsmessage.Question{Name: typeName, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET}
+	err = mdnsQuery(ctx, ifi, []dnsmessage.Question{q}, func(m *dnsmessage.Message) bool {
+		c.add(m)
+		return false
+	})
+	if err != nil {
+		return nil, newDNSError(err, service, "")
+	}
+
+	// Responders usually send the SRV, TXT and address records of
+	// the instances along with their PTR records (RFC 6763, Section
+	// 12). Ask for those that are missing.
+	if qs := c.missing(typeName); len(qs) > 0 {
+		err := mdnsQuery(ctx, ifi, qs, func(m *dnsmessage.Message) bool {
+			c.add(m)
+			return len(c.missing(typeName)) == 0
+		})
+		if err != nil {
+			return nil, newDNSError(err, service, "")
+		}
+	}
+
+	var insts []*ServiceInstance
+	for _, name := range c.instances(typeName) {
+		srv, ok := c.lookup(name, dnsmessage.TypeSRV).(*dnsmessage.SRVResource)
+		if !ok {
+			// The instance has gone away.
+			continue
+		}
+		inst := &ServiceInstance{
+			Instance: name.String()[:len(name.String())-len(typeName.String())-1],
+			Service:  service,
+			Host:     srv.Target.String(),
+			Port:     int(srv.Port),
+		}
+		if txt, ok := c.lookup(name, dnsmessage.TypeTXT).(*dnsmessage.TXTResource); ok {
+			for _, s := range txt.TXT {
+				if s != "" {
+					inst.Text = append(inst.Text, s)
+				}
+			}
+		}
+		inst.Addrs = c.addrs(srv.Target)
+		insts = append(insts, inst)
+	}
+	slices.SortFunc(insts, func(a, b *ServiceInstance) int {
+		return cmp.Compare(a.Instance, b.Instance)
+	})
+	return insts, nil
+}
+
+// An mdnsCache holds the records of multicast DNS responses.
+type mdnsCache struct {
+	records []dnsmessage.Resource
+}
+
+// add adds the records of the response m to c. Records with a TTL of
+// zero remove the records they match (RFC 6762, Section 10.1).
+func (c *mdnsCache) add(m *dnsmessage.Message) {
+	for _, sec := range [][]dnsmessage.Resource{m.Answers, m.Additionals} {
+		for _, rr := range sec {
+			rr.Header.Class &^= mdnsClassBit
+			if rr.Header.Class != dnsmessage.ClassINET {
+				continue
+			}
+			c.records = slices.DeleteFunc(c.records, func(old dnsmessage.Resource) bool {
+				return sameRecord(old, rr)
+			})
+			if rr.Header.TTL > 0 {
+				c.records = append(c.records, rr)
+			}
+		}
+	}
+}
+
+// sameRecord reports whether a and b have the same name, type and data.
+func sameRecord(a, b dnsmessage.Resource) bool {
+	if a.Header.Type != b.Header.Type || !stringsEqualFold(a.Header.Name.String(), b.Header.Name.String()) {
+		return false
+	}
+	switch a := a.Body.(type) {
+	case *dnsmessage.PTRResource:
+		b, ok := b.Body.(*dnsmessage.PTRResource)
+		return ok && stringsEqualFold(a.PTR.String(), b.PTR.String())
+	case *dnsmessage.AResource:
+		b, ok := b.Body.(*dnsmessage.AResource)
+		return ok && a.A == b.A
+	case *dnsmessage.AAAAResource:
+		b, ok := b.Body.(*dnsmessage.AAAAResource)
+		return ok && a.AAAA == b.AAAA
+	}
+	// Other records are unique: a new one replaces the old ones.
+	return true
+}
+
+// lookup returns the data of the first record of c with the given name
+// and type, or nil.
+func (c *mdnsCache) lookup(name dnsmessage.Name, typ dnsmessage.Type) dnsmessage.ResourceBody {
+	for _, rr := range c.records {
+		if rr.Header.Type == typ && stringsEqualFold(rr.Header.Name.String(), name.String()) {
+			return rr.Body
+		}
+	}
+	return nil
+}
+
+// instances returns the names of the instances of the service type
+// typeName in c.
+func (c *mdnsCache) instances(typeName dnsmessage.Name) []dnsmessage.Name {
+	var names []dnsmessage.Name
+	for _, rr := range c.records {
+		ptr, ok := rr.Body.(*dnsmessage.PTRResource)
+		if !ok || !stringsEqualFold(rr.Header.Name.String(), typeName.String()) {
+			continue
+		}
+		if !stringsHasSuffixFold(ptr.PTR.String(), "."+typeName.String()) {
+			continue
+		}
+		if !slices.ContainsFunc(names, func(n dnsmessage.Name) bool {
+			return stringsEqualFold(n.String(), ptr.PTR.String())
+		}) {
+			names = append(names, ptr.PTR)
+		}
+	}
+	return names
+}
+
+// addrs returns the addresses of host in c.
+func (c *mdnsCache) addrs(host dnsmessage.Name) []netip.Addr {
+	var addrs []netip.Addr
+	for _, rr := range c.records {
+		if !stringsEqualFold(rr.Header.Name.String(), host.String()) {
+			continue
+		}
+		switch b := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			addrs = append(addrs, netip.AddrFrom4(b.A))
+		case *dnsmessage.AAAAResource:
+			addrs = append(addrs, netip.AddrFrom16(b.AAAA))
+		}
+	}
+	return addrs
+}
+
+// missing returns the questions for the records of the instances of
+// typeName that are missing from c.
+func (c *mdnsCache) missing(typeName dnsmessage.Name) []dnsmessage.Question {
+	var qs []dnsmessage.Question
+	for _, name := range c.instances(typeName) {
+		srv, ok := c.lookup(name, dnsmessage.TypeSRV).(*dnsmessage.SRVResource)
+		if !ok {
+			qs = append(qs,
+				dnsmessage.Question{Name: name, Type: dnsmessage.TypeSRV, Class: dnsmessage.ClassINET},
+				dnsmessage.Question{Name: name, Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassINET})
+			continue
+		}
+		if len(c.addrs(srv.Target)) == 0 {
+			qs = append(qs,
+				dnsmessage.Question{Name: srv.Target, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+				dnsmessage.Question{Name: srv.Target, Type: dnsmessage.TypeAAAA, Class: dnsmessage.ClassINET})
+		}
+	}
+	return qs
+}
+
+// Timing of the multicast DNS responder, as variables for testing.
+var (
+	mdnsProbeInterval    = 250 * time.Millisecond // RFC 6762, Section 8.1
+	mdnsAnnounceInterval = 1 * time.Second        // RFC 6762, Section 8.3
+)
+
+// TTLs of the records of service instances (RFC 6762, Section 10).
+const (
+	mdnsHostTTL  = 120
+	mdnsOtherTTL = 75 * 60
+)
+
+// A ServiceRegistration advertises a service instance on the local
+// link with multicast DNS, answering the queries for its records until
+// it is closed.
+type ServiceRegistration struct {
+	ifi      *Interface
+	conns    []*UDPConn
+	groups   []*UDPAddr // the group of each of conns
+	instName dnsmessage.Name
+	records  []mdnsRecord
+
+	mu        sync.Mutex
+	probing   bool
+	announced bool
+	conflict  chan struct{} // closed when another responder owns instName
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+	wg        sync.WaitGroup
+}
+
+// An mdnsRecord is a record of a [ServiceRegistration].
+type mdnsRecord struct {
+	dnsmessage.Resource
+
+	// unique is set for the records that only the registration
+	// may have, as opposed to the PTR records that list it.
+	unique bool
+}
+
+// RegisterService advertises the service instance s on the local link
+// with multicast DNS, listening on the interface ifi, or on the
+// system-assigned multicast interface if ifi is nil. If s.Host is
+// empty, the host name of the system in the .local domain is used. If
+// s.Addrs is empty, the addresses of ifi are used, or those of all
+// interfaces except loopback interfaces if ifi is nil.
+//
+// RegisterService first checks that no other responder on the link
+// uses the name of the instance (RFC 6762, Section 8), which takes
+// about a second, and returns an error if one does. The caller must
+// close the returned registration when the service stops.
+func RegisterService(ifi *Interface, s *ServiceInstance) (*ServiceRegistration, error) {
+	reg := &ServiceRegistration{
+		ifi:      ifi,
+		probing:  true,
+		conflict: make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+	opError := func(err error) error {
+		return &OpError{Op: "register", Net: "udp", Addr: mdnsGroups(ifi)[0], Err: err}
+	}
+	if err := reg.init(s); err != nil {
+		return nil, opError(err)
+	}
+
+	var firstErr error
+	for _, g := range mdnsGroups(ifi) {
+		c, err := ListenMulticastUDP(mdnsNetwork(g), ifi, &UDPAddr{IP: g.IP, Port: mdnsPort})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		reg.conns = append(reg.conns, c)
+		reg.groups = append(reg.groups, g)
+	}
+	if len(reg.conns) == 0 {
+		return nil, firstErr
+	}
+	for i, c := range reg.conns {
+		reg.wg.Add(1)
+		go func() {
+			defer reg.wg.Done()
+			mdnsReceive(c, func(m *dnsmessage.Message, from netip.AddrPort) {
+				if m.Response {
+					reg.checkConflict(m)
+				} else {
+					reg.answer(c, reg.groups[i], m, from)
+				}
+			})
+		}()
+	}
+
+	if err := reg.probe(); err != nil {
+		reg.Close()
+		return nil, opError(err)
+	}
+	reg.mu.Lock()
+	reg.probing = false
+	reg.announced = true
+	reg.mu.Unlock()
+
+	// Announce the records twice (RFC 6762, Section 8.3).
+	announcement := reg.response(nil, false, false)
+	reg.send(announcement)
+	reg.wg.Add(1)
+	go func() {
+		defer reg.wg.Done()
+		t := time.NewTimer(mdnsAnnounceInterval)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			reg.send(announcement)
+		case <-reg.closed:
+		}
+	}()
+	return reg, nil
+}
+
+// init sets the records of reg for the instance s.
+func (reg *ServiceRegistration) init(s *ServiceInstance) error {
+	typeName, err := serviceTypeName(s.Service)
+	if err != nil {
+		return err
+	}
+	if s.Instance == "" || len(s.Instance) > 63 || stringslite.IndexByte(s.Instance, '.') >= 0 {
+		return errInvalidInstance
+	}
+	reg.instName, err = dnsmessage.NewName(s.Instance + "." + typeName.String())
+	if err != nil {
+		return errInvalidInstance
+	}
+	if s.Port < 0 || s.Port > 0xffff {
+		return errInvalidServicePort
+	}
+
+	host := s.Host
+	if host == "" {
+		hn, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		if i := stringslite.IndexByte(hn, '.'); i >= 0 {
+			hn = hn[:i]
+		}
+		host = hn + ".local."
+	} else if !stringslite.HasSuffix(host, ".") {
+		host += "."
+	}
+	hostName, err := dnsmessage.NewName(host)
+	if err != nil {
+		return &DNSError{Err: errNoSuchHost.Error(), Name: host}
+	}
+	addrs := s.Addrs
+	if len(addrs) == 0 {
+		if addrs, err = interfaceAddrs(reg.ifi); err != nil {
+			return err
+		}
+	}
+
+	add := func(name dnsmessage.Name, typ dnsmessage.Type, ttl uint32, unique bool, body dnsmessage.ResourceBody) {
+		reg.records = append(reg.records, mdnsRecord{
+			Resource: dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: name, Type: typ, Class: dnsmessage.ClassINET, TTL: ttl},
+				Body:   body,
+			},
+			unique: unique,
+		})
+	}
+	add(servicesName, dnsmessage.TypePTR, mdnsOtherTTL, false, &dnsmessage.PTRResource{PTR: typeName})
+	add(typeName, dnsmessage.TypePTR, mdnsOtherTTL, false, &dnsmessage.PTRResource{PTR: reg.instName})
+	add(reg.instName, dnsmessage.TypeSRV, mdnsHostTTL, true, &dnsmessage.SRVResource{Port: uint16(s.Port), Target: hostName})
+	txt := s.Text
+	if len(txt) == 0 {
+		// A TXT record must have a string (RFC 6763, Section 6.1).
+		txt = []string{""}
+	}
+	add(reg.instName, dnsmessage.TypeTXT, mdnsOtherTTL, true, &dnsmessage.TXTResource{TXT: txt})
+	for _, a := range addrs {
+		if a.Is4() || a.Is4In6() {
+			add(hostName, dnsmessage.TypeA, mdnsHostTTL, true, &dnsmessage.AResource{A: a.Unmap().As4()})
+		} else {
+			add(hostName, dnsmessage.TypeAAAA, mdnsHostTTL, true, &dnsmessage.AAAAResource{AAAA: a.As16()})
+		}
+	}
+	return nil
+}
+
+// interfaceAddrs returns the addresses of ifi, or those of all
+// interfaces except loopback interfaces if ifi is nil.
+func interfaceAddrs(ifi *Interface) ([]netip.Addr, error) {
+	var ifat []Addr
+	var err error
+	if ifi != nil {
+		ifat, err = ifi.Addrs()
+	} else {
+		ifat, err = InterfaceAddrs()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var addrs []netip.Addr
+	for _, ifa := range ifat {
+		ipn, ok := ifa.(*IPNet)
+		if !ok {
+			continue
+		}
+		a, ok := netip.AddrFromSlice(ipn.IP)
+		if !ok || ifi == nil && a.IsLoopback() {
+			continue
+		}
+		addrs = append(addrs, a.Unmap())
+	}
+	return addrs, nil
+}
+
+// probe sends the probes for the name of reg's instance and waits for
+// conflicting responses (RFC 6762, Section 8.1).
+func (reg *ServiceRegistration) probe() error {
+	// The probes don't ask for unicast responses: responders on the
+	// same host share the port, so only one of them would get those.
+	q := dnsmessage.Message{
+		Questions: []dnsmessage.Question{{
+			Name:  reg.instName,
+			Type:  dnsmessage.TypeALL,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	for _, rec := range reg.records {
+		if rec.unique && rec.Header.Name == reg.instName {
+			q.Authorities = append(q.Authorities, rec.Resource)
+		}
+	}
+	for range 3 {
+		reg.send(&q)
+		select {
+		case <-reg.conflict:
+			return errServiceConflict
+		case <-time.After(mdnsProbeInterval):
+		}
+	}
+	return nil
+}
+
+// checkConflict checks whether the response m, received while probing,
+// has records for the name of reg's instance.
+func (reg *ServiceRegistration) checkConflict(m *dnsmessage.Message) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if !reg.probing {
+		return
+	}
+	for _, sec := range [][]dnsmessage.Resource{m.Answers, m.Additionals} {
+		for _, rr := range sec {
+			if stringsEqualFold(rr.Header.Name.String(), reg.instName.String()) {
+				close(reg.conflict)
+				reg.probing = false
+				return
+			}
+		}
+	}
+}
+
+// answer responds to the query m, received on c, which listens to the
+// group, from the address from.
+func (reg *ServiceRegistration) answer(c *UDPConn, group *UDPAddr, m *dnsmessage.Message, from netip.AddrPort) {
+	reg.mu.Lock()
+	announced := reg.announced
+	reg.mu.Unlock()
+	if !announced || m.OpCode != 0 {
+		return
+	}
+
+	// Queries from other ports than the multicast DNS port come from
+	// simple resolvers that expect a conventional unicast response
+	// (RFC 6762, Section 6.7).
+	legacy := from.Port() != mdnsPort
+	unicast := legacy
+	var answers []*mdnsRecord
+	for _, q := range m.Questions {
+		if q.Class&mdnsClassBit != 0 {
+			unicast = true
+		}
+		if class := q.Class &^ mdnsClassBit; class != dnsmessage.ClassINET && class != dnsmessage.ClassANY {
+			continue
+		}
+		for i := range reg.records {
+			rec := &reg.records[i]
+			if q.Type != dnsmessage.TypeALL && q.Type != rec.Header.Type ||
+				!stringsEqualFold(q.Name.String(), rec.Header.Name.String()) ||
+				slices.Contains(answers, rec) || knownAnswer(m, rec) {
+				continue
+			}
+			answers = append(answers, rec)
+		}
+	}
+	if len(answers) == 0 {
+		return
+	}
+
+	resp := reg.response(answers, legacy, true)
+	if legacy {
+		resp.ID = m.ID
+		for _, q := range m.Questions {
+			q.Class &^= mdnsClassBit
+			resp.Questions = append(resp.Questions, q)
+		}
+	}
+	b, err := resp.Pack()
+	if err != nil {
+		return
+	}
+	if unicast {
+		c.WriteToUDPAddrPort(b, from)
+		return
+	}
+	if slices.ContainsFunc(answers, func(rec *mdnsRecord) bool { return !rec.unique }) {
+		// Delay multicast responses with shared records to avoid
+		// collisions with other responders (RFC 6762, Section 6).
+		time.AfterFunc(time.Duration(20+randIntn(100))*time.Millisecond, func() {
+			c.WriteTo(b, group)
+		})
+		return
+	}
+	c.WriteTo(b, group)
+}
+
+// knownAnswer reports whether the query m lists rec among the answers
+// it already knows, with at least half of its TTL left (RFC 6762,
+// Section 7.1).
+func knownAnswer(m *dnsmessage.Message, rec *mdnsRecord) bool {
+	for _, rr := range m.Answers {
+		if rr.Header.TTL >= rec.Header.TTL/2 && sameRecord(rr, rec.Resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// response returns a response with the records answers, or with all the
+// records of reg if answers is nil. If legacy is set, the response is
+// for a simple resolver (RFC 6762, Section 6.7). If additional is set,
+// the records related to the answers are added as additional records
+// (RFC 6763, Section 12).
+func (reg *ServiceRegistration) response(answers []*mdnsRecord, legacy, additional bool) *dnsmessage.Message {
+	if answers == nil {
+		for i := range reg.records {
+			answers = append(answers, &reg.records[i])
+		}
+	}
+	resource := func(rec *mdnsRecord) dnsmessage.Resource {
+		rr := rec.Resource
+		switch {
+		case legacy:
+			rr.Header.TTL = min(rr.Header.TTL, 10)
+		case rec.unique:
+			rr.Header.Class |= mdnsClassBit
+		}
+		return rr
+	}
+	m := &dnsmessage.Message{Header: dnsmessage.Header{Response: true, Authoritative: true}}
+	var names []string // names whose records are additional
+	for _, rec := range answers {
+		m.Answers = append(m.Answers, resource(rec))
+		switch b := rec.Body.(type) {
+		case *dnsmessage.PTRResource:
+			names = append(names, b.PTR.String())
+		case *dnsmessage.SRVResource:
+			names = append(names, b.Target.String())
+		}
+	}
+	if !additional {
+		return m
+	}
+	for len(names) > 0 {
+		name := names[0]
+		names = names[1:]
+		for i := range reg.records {
+			rec := &reg.records[i]
+			if !rec.unique || slices.Contains(answers, rec) || !stringsEqualFold(rec.Header.Name.String(), name) {
+				continue
+			}
+			answers = append(answers, rec)
+			m.Additionals = append(m.Additionals, resource(rec))
+			if srv, ok := rec.Body.(*dnsmessage.SRVResource); ok {
+				names = append(names, srv.Target.String())
+			}
+		}
+	}
+	return m
+}
+
+// send sends the message m to the multicast DNS groups.
+func (reg *ServiceRegistration) send(m *dnsmessage.Message) {
+	b, err := m.Pack()
+	if err != nil {
+		return
+	}
+	for i, c := range reg.conns {
+		c.WriteTo(b, reg.groups[i])
+	}
+}
+
+// Close stops advertising the service instance, telling the other
+// hosts on the link that it has gone away (RFC 6762, Section 10.1).
+func (reg *ServiceRegistration) Close() error {
+	reg.closeOnce.Do(func() {
+		close(reg.closed)
+		reg.mu.Lock()
+		announced := reg.announced
+		reg.mu.Unlock()
+		if announced {
+			goodbye := reg.response(nil, false, false)
+			for i := range goodbye.Answers {
+				goodbye.Answers[i].Header.TTL = 0
+			}
+			reg.send(goodbye)
+		}
+		for _, c := range reg.conns {
+			if err := c.Close(); err != nil && reg.closeErr == nil {
+				reg.closeErr = err
+			}
+		}
+	})
+	reg.wg.Wait()
+	return reg.closeErr
+}
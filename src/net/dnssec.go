@@ -0,0 +1,225 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"context"
+	"errors"
+	"internal/strconv"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// A DNSSECStatus is the result of the DNSSEC validation of DNS records
+// (RFC 4033).
+type DNSSECStatus int
+
+const (
+	// DNSSECUnvalidated means that the records were not validated:
+	// the Resolver has no ValidateDNSSEC function, or they didn't
+	// come from DNS.
+	DNSSECUnvalidated DNSSECStatus = iota
+
+	// DNSSECInsecure means that the records come from a zone that is
+	// provably not signed.
+	DNSSECInsecure
+
+	// DNSSECSecure means that the records were validated from a
+	// trust anchor, either by the Resolver's ValidateDNSSEC function
+	// or, with the trust-ad option of resolv.conf and no
+	// ValidateDNSSEC function, by the DNS server.
+	DNSSECSecure
+
+	// DNSSECBogus means that the records failed validation. Lookups
+	// don't return such records.
+	DNSSECBogus
+)
+
+var dnssecStatusNames = [...]string{"unvalidated", "insecure", "secure", "bogus"}
+
+func (s DNSSECStatus) String() string {
+	if uint(s) < uint(len(dnssecStatusNames)) {
+		return dnssecStatusNames[s]
+	}
+	return "DNSSECStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
+// errDNSSECBogus is returned for responses that fail validation.
+var errDNSSECBogus = errors.New("DNSSEC validation failed")
+
+func (r *Resolver) validatesDNSSEC() bool { return r != nil && r.ValidateDNSSEC != nil }
+
+// dnssecStatus returns the DNSSEC status of the response p, whose
+// header is h, to the query q. It returns an error if the response
+// must not be used.
+func (r *Resolver) dnssecStatus(ctx context.Context, cfg *dnsConfig, p dnsmessage.Parser, h dnsmessage.Header, q dnsmessage.Question) (DNSSECStatus, error) {
+	if !r.validatesDNSSEC() {
+		// The AD bit is only meaningful if the server is trusted
+		// to validate, and the path to it is secure.
+		if cfg.trustAD && h.AuthenticData {
+			return DNSSECSecure, nil
+		}
+		return DNSSECUnvalidated, nil
+	}
+	msg, err := packResponse(p, h, q)
+	if err != nil {
+		return DNSSECBogus, errCannotUnmarshalDNSMessage
+	}
+	status, err := r.ValidateDNSSEC(ctx, msg, r.dnssecQuery(cfg))
+	if status == DNSSECBogus && err == nil {
+		err = errDNSSECBogus
+	}
+	return status, err
+}
+
+// dnssecQuery returns a function that sends a query for name and
+// qtype to the servers of cfg and returns the first response, for
+// the Resolver's ValidateDNSSEC function.
+func (r *Resolver) dnssecQuery(cfg *dnsConfig) func(ctx context.Context, name string, qtype uint16) ([]byte, error) {
+	return func(ctx context.Context, name string, qtype uint16) ([]byte, error) {
+		if len(name) == 0 || name[len(name)-1] != '.' {
+			name += "."
+		}
+		n, err := dnsmessage.NewName(name)
+		if err != nil {
+			return nil, newDNSError(errCannotMarshalDNSMessage, name, "")
+		}
+		q := dnsmessage.Question{
+			Name:  n,
+			Type:  dnsmessage.Type(qtype),
+			Class: dnsmessage.ClassINET,
+		}
+		var lastErr error = newDNSError(errNoAnswerFromDNSServer, name, "")
+		servers := cfg.serversFor(r)
+		serverOffset := cfg.serverOffset()
+		sLen := uint32(len(servers))
+		for i := 0; i < cfg.attempts; i++ {
+			for j := uint32(0); j < sLen; j++ {
+				server := servers[(serverOffset+j)%sLen]
+				p, h, err := r.exchangeConfig(ctx, cfg, server, q)
+				if err != nil {
+					lastErr = newDNSError(err, name, server)
+					continue
+				}
+				msg, err := packResponse(p, h, q)
+				if err != nil {
+					return nil, newDNSError(errCannotUnmarshalDNSMessage, name, server)
+				}
+				return msg, nil
+			}
+		}
+		return nil, lastErr
+	}
+}
+
+// packResponse returns the message of the response p, whose header is
+// h and whose question section, already parsed, is q.
+func packResponse(p dnsmessage.Parser, h dnsmessage.Header, q dnsmessage.Question) ([]byte, error) {
+	m := dnsmessage.Message{
+		Header:    h,
+		Questions: []dnsmessage.Question{q},
+	}
+	var err error
+	if m.Answers, err = p.AllAnswers(); err != nil {
+		return nil, err
+	}
+	if m.Authorities, err = p.AllAuthorities(); err != nil {
+		return nil, err
+	}
+	if m.Additionals, err = p.AllAdditionals(); err != nil {
+		return nil, err
+	}
+	return m.Pack()
+}
+
+// A dnssecResult collects the DNSSEC status of the records returned
+// by a lookup. It is passed to the resolver in the lookup's context.
+type dnssecResult struct {
+	mu     sync.Mutex
+	status DNSSECStatus
+	set    bool // status was set by a response
+	bogus  bool // a response failed validation
+}
+
+type dnssecResultKey struct{}
+
+// addDNSSECStatus records the status of a response in the dnssecResult
+// of ctx, if any. The status of a lookup is the weakest status of the
+// responses it uses.
+func addDNSSECStatus(ctx context.Context, status DNSSECStatus) {
+	res, _ := ctx.Value(dnssecResultKey{}).(*dnssecResult)
+	if res == nil {
+		return
+	}
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	if status == DNSSECBogus {
+		res.bogus = true
+		return
+	}
+	if !res.set || status < res.status {
+		res.status = status
+		res.set = true
+	}
+}
+
+// merge records the status collected in o in res.
+func (res *dnssecResult) merge(o *dnssecResult) {
+	o.mu.Lock()
+	status, set, bogus := o.status, o.set, o.bogus
+	o.mu.Unlock()
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	if bogus {
+		res.bogus = true
+	}
+	if set && (!res.set || status < res.status) {
+		res.status = status
+		res.set = true
+	}
+}
+
+// dnssecAddrs is the result of merged address lookups that collect
+// their DNSSEC status.
+type dnssecAddrs struct {
+	addrs []IPAddr
+	res   *dnssecResult
+}
+
+// lookupDNSSEC calls lookup with a context that collects the DNSSEC
+// status of the records it returns.
+func lookupDNSSEC[T any](ctx context.Context, lookup func(context.Context) (T, error)) (T, DNSSECStatus, error) {
+	res := new(dnssecResult)
+	v, err := lookup(context.WithValue(ctx, dnssecResultKey{}, res))
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	if err != nil {
+		if res.bogus {
+			return v, DNSSECBogus, err
+		}
+		return v, DNSSECUnvalidated, err
+	}
+	return v, res.status, nil
+}
+
+// LookupHostDNSSEC is like [Resolver.LookupHost], and also returns the
+// DNSSEC status of the addresses: the weakest status of the DNS
+// records that they come from. If the lookup fails because a response
+// failed validation, the status is [DNSSECBogus].
+func (r *Resolver) LookupHostDNSSEC(ctx context.Context, host string) ([]string, DNSSECStatus, error) {
+	return lookupDNSSEC(ctx, func(ctx context.Context) ([]string, error) {
+		return r.LookupHost(ctx, host)
+	})
+}
+
+// LookupTXTDNSSEC is like [Resolver.LookupTXT], and also returns the
+// DNSSEC status of the TXT records, as described for
+// [Resolver.LookupHostDNSSEC].
+func (r *Resolver) LookupTXTDNSSEC(ctx context.Context, name string) ([]string, DNSSECStatus, error) {
+	return lookupDNSSEC(ctx, func(ctx context.Context) ([]string, error) {
+		return r.LookupTXT(ctx, name)
+	})
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dnssec
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base32"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var base32Hex = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// maxIterations is the largest number of additional NSEC3 hash
+// iterations that is honored. Zones using more are treated as
+// insecure, as RFC 9276, Section 3.2, allows.
+const maxIterations = 150
+
+// A denial holds the validated NSEC and NSEC3 records of a response,
+// which prove that names or records don't exist.
+type denial struct {
+	nsecs  []*nsec
+	nsec3s []*nsec3
+
+	// insecure is set if NSEC3 records were ignored because of their
+	// number of iterations.
+	insecure bool
+}
+
+// add adds the records of set, a validated NSEC or NSEC3 rrset of zone,
+// to d. Records that don't parse are ignored.
+func (d *denial) add(set *rrset, zone string) {
+	for _, r := range set.records {
+		switch set.typ {
+		case typeNSEC:
+			if n, err := parseNSEC(r); err == nil {
+				d.nsecs = append(d.nsecs, n)
+			}
+		case typeNSEC3:
+			n, err := parseNSEC3(r, zone)
+			switch {
+			case err != nil || n.algorithm != 1: // SHA-1 is the only hash algorithm
+			case n.iterations > maxIterations:
+				d.insecure = true
+			default:
+				d.nsec3s = append(d.nsec3s, n)
+			}
+		}
+	}
+}
+
+func (d *denial) empty() bool {
+	return len(d.nsecs) == 0 && len(d.nsec3s) == 0
+}
+
+// nsecMatch returns the NSEC record owned by name, or nil.
+func (d *denial) nsecMatch(name string) *nsec {
+	for _, n := range d.nsecs {
+		if n.name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// nsecCover returns an NSEC record whose owner comes before name and
+// whose next name comes after it in the canonical order, or nil.
+func (d *denial) nsecCover(name string) *nsec {
+	for _, n := range d.nsecs {
+		if compareNames(n.name, name) >= 0 {
+			continue
+		}
+		// The next name of the last NSEC record of a zone is the
+		// apex of the zone.
+		if compareNames(n.name, n.next) < 0 && compareNames(name, n.next) < 0 ||
+			compareNames(n.name, n.next) >= 0 && isSubdomain(name, n.next) {
+			return n
+		}
+	}
+	return nil
+}
+
+// hashName returns the NSEC3 hash of name with the parameters of n
+// (RFC 5155, Section 5).
+func (n *nsec3) hashName(name string) []byte {
+	h := sha1.New()
+	h.Write(appendName(nil, name))
+	h.Write(n.salt)
+	sum := h.Sum(nil)
+	for range n.iterations {
+		h.Reset()
+		h.Write(sum)
+		h.Write(n.salt)
+		sum = h.Sum(sum[:0])
+	}
+	return sum
+}
+
+// nsec3Match returns the NSEC3 record for name, or nil.
+func (d *denial) nsec3Match(name string) *nsec3 {
+	for _, n := range d.nsec3s {
+		if isSubdomain(name, n.zone) && bytes.Equal(n.hashName(name), n.hash) {
+			return n
+		}
+	}
+	return nil
+}
+
+// nsec3Cover returns an NSEC3 record whose hash comes before the hash of
+// name and whose next hash comes after it, or nil.
+func (d *denial) nsec3Cover(name string) *nsec3 {
+	for _, n := range d.nsec3s {
+		if !isSubdomain(name, n.zone) {
+			continue
+		}
+		h := n.hashName(name)
+		after := bytes.Compare(n.hash, h) < 0
+		before := bytes.Compare(h, n.next) < 0
+		// The next hash of the last record is the first hash.
+		if bytes.Compare(n.hash, n.next) < 0 && after && before ||
+			bytes.Compare(n.hash, n.next) >= 0 && (after || before) {
+			return n
+		}
+	}
+	return nil
+}
+
+// closestEncloser proves with NSEC3 records that name doesn't exist, as
+// described in RFC 5155, Section 8.3. It returns the closest existing
+// ancestor of name and the record covering the next closer name.
+func (d *denial) closestEncloser(name string) (string, *nsec3, bool) {
+	nc := name
+	for ce := name; ce != "."; ce = parent(ce) {
+		p := parent(ce)
+		if d.nsec3Match(p) != nil {
+			nc = ce
+			ce = p
+			if n := d.nsec3Cover(nc); n != nil {
+				return ce, n, true
+			}
+			return "", nil, false
+		}
+	}
+	return "", nil, false
+}
+
+// nxdomain reports whether d proves that name doesn't exist. optOut
+// reports whether the proof relies on an NSEC3 record with the opt-out
+// flag, in which case name may be an unsigned delegation.
+func (d *denial) nxdomain(name string) (ok, optOut bool) {
+	if n := d.nsecCover(name); n != nil && !isSubdomain(n.next, name) {
+		// The closest encloser is the longest common ancestor of
+		// name and the names of n, and its wildcard must not exist.
+		ce := commonAncestor(name, n.name)
+		if a := commonAncestor(name, n.next); len(a) > len(ce) {
+			ce = a
+		}
+		wildcard := "*." + ce
+		if ce == "." {
+			wildcard = "*."
+		}
+		return d.nsecMatch(wildcard) == nil && d.nsecCover(wildcard) != nil, false
+	}
+	ce, n, ok := d.closestEncloser(name)
+	if !ok {
+		return false, false
+	}
+	wildcard := "*." + ce
+	if ce == "." {
+		wildcard = "*."
+	}
+	return d.nsec3Cover(wildcard) != nil, n.flags&flagOptOut != 0
+}
+
+// nodata reports whether d proves that name exists but has no records
+// of type typ, nor a CNAME record. optOut is as for nxdomain.
+func (d *denial) nodata(name string, typ dnsmessage.Type) (ok, optOut bool) {
+	if n := d.nsecMatch(name); n != nil {
+		return !hasType(n.types, typ) && !hasType(n.types, dnsmessage.TypeCNAME), false
+	}
+	if n := d.nsecCover(name); n != nil && n.next != name && isSubdomain(n.next, name) {
+		// An empty non-terminal: a name that only has names below it.
+		return true, false
+	}
+	if n := d.nsec3Match(name); n != nil {
+		return !hasType(n.types, typ) && !hasType(n.types, dnsmessage.TypeCNAME), false
+	}
+	if typ == typeDS {
+		// There may be an unsigned delegation in an opt-out span
+		// (RFC 5155, Section 8.6).
+		if _, n, ok := d.closestEncloser(name); ok && n.flags&flagOptOut != 0 {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// wildcard reports whether d proves that name, whose records were
+// expanded from the wildcard of its ancestor with the given number of
+// labels, doesn't exist (RFC 4035, Section 5.3.4, and RFC 5155,
+// Section 8.8).
+func (d *denial) wildcard(name string, n int) bool {
+	if c := d.nsecCover(name); c != nil && !isSubdomain(c.next, name) {
+		return true
+	}
+	l := labels(name)
+	if n+1 > len(l) {
+		return false
+	}
+	nc := strings.Join(l[len(l)-n-1:], ".") + "."
+	return d.nsec3Cover(nc) != nil
+}
+
+// Zone cuts proved by cut.
+const (
+	cutUnknown  = iota // there is no proof
+	cutNone            // name is not a delegation
+	cutInsecure        // name is a delegation without DS records
+)
+
+// cut returns what d proves about name, in the response to a query for
+// its DS records that has no such records.
+func (d *denial) cut(name string, nxdomain bool) int {
+	types := func(types []byte) int {
+		switch {
+		case hasType(types, typeDS), hasType(types, dnsmessage.TypeSOA):
+			// The DS records exist, or the record is from
+			// the zone of name rather than from its parent.
+			return cutUnknown
+		case hasType(types, dnsmessage.TypeNS):
+			return cutInsecure
+		}
+		return cutNone
+	}
+	if n := d.nsecMatch(name); n != nil {
+		return types(n.types)
+	}
+	if n := d.nsec3Match(name); n != nil {
+		return types(n.types)
+	}
+	if _, n, ok := d.closestEncloser(name); ok {
+		if n.flags&flagOptOut != 0 {
+			return cutInsecure
+		}
+		return cutNone
+	}
+	if ok, _ := d.nxdomain(name); ok && nxdomain {
+		return cutNone
+	}
+	if ok, _ := d.nodata(name, typeDS); ok {
+		// An empty non-terminal.
+		return cutNone
+	}
+	return cutUnknown
+}
+
+// commonAncestor returns the longest common ancestor of the names a and
+// b.
+func commonAncestor(a, b string) string {
+	la, lb := labels(a), labels(b)
+	n := 0
+	for n < len(la) && n < len(lb) && la[len(la)-1-n] == lb[len(lb)-1-n] {
+		n++
+	}
+	if n == 0 {
+		return "."
+	}
+	return strings.Join(la[len(la)-n:], ".") + "."
+}
@@ -0,0 +1,583 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dnssec implements the validation of DNS responses with the
+// DNS Security Extensions (DNSSEC), as specified in RFC 4033, RFC 4034,
+// RFC 4035, and RFC 5155, for Go's DNS resolver.
+//
+// A [Validator] checks the signatures of the records of a response and
+// the chain of trust that leads to them from a trust anchor: by default,
+// the key signing keys of the root zone. Its Validate method is meant
+// to be the ValidateDNSSEC function of a [net.Resolver]:
+//
+//	r := &net.Resolver{ValidateDNSSEC: new(dnssec.Validator).Validate}
+//	addrs, status, err := r.LookupHostDNSSEC(ctx, "go.dev")
+//
+// The validator verifies signatures made with the RSA/SHA-256,
+// RSA/SHA-512, ECDSA P-256/SHA-256, ECDSA P-384/SHA-384, and Ed25519
+// algorithms. Zones whose delegations only use other algorithms are
+// treated as unsigned. The nonexistence of names and records is proved
+// with NSEC and NSEC3 records; wildcard expansions in negative answers
+// are not supported, and such answers fail validation.
+//
+// The validator relies on its DNS server for recursion: its queries for
+// DNSKEY and DS records go to the same server as the lookups.
+package dnssec
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// A DS is a delegation signer record (RFC 4034, Section 5), which
+// identifies a key of a zone by its digest.
+type DS struct {
+	Zone       string // the name of the zone, such as "." for the root zone
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	Digest     []byte
+}
+
+// RootTrustAnchors returns the DS records of the key signing keys of
+// the root zone, KSK-2017 and KSK-2024, as published by IANA at
+// https://data.iana.org/root-anchors/.
+func RootTrustAnchors() []DS {
+	return []DS{
+		{
+			Zone:       ".",
+			KeyTag:     20326,
+			Algorithm:  algRSASHA256,
+			DigestType: digestSHA256,
+			Digest:     mustDecodeHex("E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D"),
+		},
+		{
+			Zone:       ".",
+			KeyTag:     38696,
+			Algorithm:  algRSASHA256,
+			DigestType: digestSHA256,
+			Digest:     mustDecodeHex("683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16"),
+		},
+	}
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// A Validator validates DNS responses with DNSSEC. Its zero value is a
+// Validator that trusts the root zone's keys.
+//
+// A Validator caches the keys of the zones it validates, and the zone
+// cuts it finds, for at most the time to live of their records. Its
+// fields must not be changed after its first use. A Validator is safe
+// for concurrent use by multiple goroutines.
+type Validator struct {
+	// TrustAnchors are the DS records of the keys that the Validator
+	// trusts. Each zone with a trust anchor is validated from its own
+	// anchors rather than from its parent. If TrustAnchors is empty,
+	// the Validator uses RootTrustAnchors.
+	TrustAnchors []DS
+
+	// Time returns the current time, against which the validity
+	// periods of signatures are checked. If nil, time.Now is used.
+	Time func() time.Time
+
+	mu   sync.Mutex
+	cuts map[string]cutEntry // by name
+}
+
+// A zone is a zone whose status is known.
+type zone struct {
+	name     string
+	insecure bool
+	keys     []*dnskey // the validated keys of a secure zone
+}
+
+type cutEntry struct {
+	zone    *zone // nil if the name is not a zone cut
+	expires time.Time
+}
+
+const (
+	maxCacheTTL    = time.Hour
+	maxCacheSize   = 1024
+	maxQueries     = 64 // per response
+	anchorCacheTTL = uint32(maxCacheTTL / time.Second)
+)
+
+// A queryError is an error of the query function passed to Validate.
+type queryError struct {
+	err error
+}
+
+func (e *queryError) Error() string { return "dnssec: " + e.err.Error() }
+func (e *queryError) Unwrap() error { return e.err }
+
+// Validate validates the DNS response message response. It uses query
+// to send queries for the DNSKEY and DS records that it needs. It
+// returns [net.DNSSECSecure] if all the records of the answer of
+// response are signed by a chain of keys starting from a trust anchor,
+// and [net.DNSSECInsecure] if some are in a zone that is proved to be
+// unsigned. A negative response must similarly prove that the name or
+// records don't exist.
+//
+// Validate returns [net.DNSSECBogus] and an error describing the
+// failure if the response fails validation. It returns
+// [net.DNSSECUnvalidated] and an error if query fails. Responses with
+// errors other than name errors, such as server failures, are not
+// validated.
+//
+// Validate has the type of the ValidateDNSSEC field of [net.Resolver].
+func (v *Validator) Validate(ctx context.Context, response []byte, query func(ctx context.Context, name string, qtype uint16) ([]byte, error)) (net.DNSSECStatus, error) {
+	now := time.Now()
+	if v.Time != nil {
+		now = v.Time()
+	}
+	m, err := parseMessage(response)
+	if err != nil {
+		return net.DNSSECBogus, fmt.Errorf("dnssec: cannot parse response: %w", err)
+	}
+	c := &checker{v: v, ctx: ctx, query: query, now: now}
+	status, err := c.message(m)
+	if err != nil {
+		if _, ok := errors.AsType[*queryError](err); ok {
+			return net.DNSSECUnvalidated, err
+		}
+		return net.DNSSECBogus, err
+	}
+	return status, nil
+}
+
+// A checker validates a response.
+type checker struct {
+	v       *Validator
+	ctx     context.Context
+	query   func(ctx context.Context, name string, qtype uint16) ([]byte, error)
+	now     time.Time
+	queries int
+}
+
+// message returns the status of the response m.
+func (c *checker) message(m *message) (net.DNSSECStatus, error) {
+	if m.rcode != dnsmessage.RCodeSuccess && m.rcode != dnsmessage.RCodeNameError {
+		return net.DNSSECUnvalidated, nil
+	}
+	status := net.DNSSECSecure
+	answers := rrsets(m.answers)
+	type expansion struct {
+		name   string
+		labels int
+	}
+	var wildcards []expansion
+	for _, set := range answers {
+		st, sig, err := c.rrset(set)
+		if err != nil {
+			return net.DNSSECBogus, err
+		}
+		status = min(status, st)
+		if st == net.DNSSECSecure && sig.wildcard(set.name) {
+			wildcards = append(wildcards, expansion{set.name, int(sig.labels)})
+		}
+	}
+
+	// Follow the aliases of the answer to the name whose records were
+	// asked for.
+	target := m.qname
+	for range answers {
+		cname := findRRset(answers, target, dnsmessage.TypeCNAME)
+		if cname == nil || m.qtype == dnsmessage.TypeCNAME || len(cname.records) != 1 {
+			break
+		}
+		next, _, err := readName(cname.records[0].data)
+		if err != nil {
+			return net.DNSSECBogus, err
+		}
+		target = next
+	}
+	found := false
+	for _, set := range answers {
+		if set.name == target && (set.typ == m.qtype || m.qtype == dnsmessage.TypeALL) ||
+			set.name == m.qname && set.typ == dnsmessage.TypeCNAME && m.qtype == dnsmessage.TypeCNAME {
+			found = true
+		}
+	}
+	if found && len(wildcards) == 0 {
+		return status, nil
+	}
+
+	// The response must prove that the records it doesn't have don't
+	// exist.
+	d := new(denial)
+	for _, set := range rrsets(m.authorities) {
+		if set.typ != typeNSEC && set.typ != typeNSEC3 {
+			continue
+		}
+		st, sig, err := c.rrset(set)
+		if err != nil {
+			return net.DNSSECBogus, err
+		}
+		if st != net.DNSSECSecure {
+			// An unsigned zone can't prove anything, but then
+			// the records don't need proofs either.
+			return net.DNSSECInsecure, nil
+		}
+		d.add(set, sig.signer)
+	}
+	for _, w := range wildcards {
+		if !d.wildcard(w.name, w.labels) {
+			return net.DNSSECBogus, fmt.Errorf("dnssec: no proof that %s does not exist for its wildcard records", w.name)
+		}
+	}
+	if found {
+		return status, nil
+	}
+
+	if d.empty() {
+		z, err := c.enclosing(target)
+		if err != nil {
+			return net.DNSSECBogus, err
+		}
+		if z.insecure {
+			return net.DNSSECInsecure, nil
+		}
+	}
+	var ok, optOut bool
+	if m.rcode == dnsmessage.RCodeNameError {
+		ok, optOut = d.nxdomain(target)
+	} else {
+		ok, optOut = d.nodata(target, m.qtype)
+	}
+	switch {
+	case !ok && d.insecure:
+		return net.DNSSECInsecure, nil
+	case !ok && m.rcode == dnsmessage.RCodeNameError:
+		return net.DNSSECBogus, fmt.Errorf("dnssec: no proof that %s does not exist", target)
+	case !ok:
+		return net.DNSSECBogus, fmt.Errorf("dnssec: no proof that %s has no %s records", target, typeName(m.qtype))
+	case optOut:
+		return net.DNSSECInsecure, nil
+	}
+	return status, nil
+}
+
+// rrset validates set. It returns its status and, if set is signed,
+// the signature that validates it.
+func (c *checker) rrset(set *rrset) (net.DNSSECStatus, *rrsig, error) {
+	if len(set.sigs) == 0 {
+		name := set.name
+		if set.typ == typeDS && name != "." {
+			// DS records belong to the zone above name.
+			name = parent(name)
+		}
+		z, err := c.enclosing(name)
+		if err != nil {
+			return net.DNSSECBogus, nil, err
+		}
+		if z.insecure {
+			return net.DNSSECInsecure, nil, nil
+		}
+		return net.DNSSECBogus, nil, fmt.Errorf("dnssec: %s %s records are not signed", set.name, typeName(set.typ))
+	}
+
+	err := errBadSignature
+	for _, sig := range set.sigs {
+		if !isSubdomain(set.name, sig.signer) || set.typ == typeDS && set.name == sig.signer {
+			continue
+		}
+		z, zerr := c.enclosing(sig.signer)
+		if zerr != nil {
+			return net.DNSSECBogus, nil, zerr
+		}
+		if z.insecure {
+			return net.DNSSECInsecure, sig, nil
+		}
+		if z.name != sig.signer {
+			continue
+		}
+		for _, key := range z.keys {
+			verr := verify(set, sig, key, c.now)
+			if verr == nil {
+				return net.DNSSECSecure, sig, nil
+			}
+			if verr != errBadSignature || err == errBadSignature {
+				err = verr
+			}
+		}
+	}
+	return net.DNSSECBogus, nil, fmt.Errorf("%w for %s %s records", err, set.name, typeName(set.typ))
+}
+
+// verifyWith checks that set is signed by the keys of the secure zone z.
+func (c *checker) verifyWith(set *rrset, z *zone) error {
+	err := errBadSignature
+	for _, sig := range set.sigs {
+		if sig.signer != z.name {
+			continue
+		}
+		for _, key := range z.keys {
+			verr := verify(set, sig, key, c.now)
+			if verr == nil {
+				return nil
+			}
+			if verr != errBadSignature {
+				err = verr
+			}
+		}
+	}
+	return fmt.Errorf("%w for %s %s records", err, set.name, typeName(set.typ))
+}
+
+// enclosing returns the secure zone that contains name, or the insecure
+// zone that contains name or one of its ancestors. It follows the zone
+// cuts down from the closest trust anchor above name.
+func (c *checker) enclosing(name string) (*zone, error) {
+	anchor := ""
+	for _, ds := range c.v.anchors() {
+		if a := canonicalName(ds.Zone); isSubdomain(name, a) && len(a) > len(anchor) {
+			anchor = a
+		}
+	}
+	if anchor == "" {
+		return nil, fmt.Errorf("dnssec: no trust anchor for %s", name)
+	}
+	z, err := c.cut(nil, anchor)
+	if err != nil {
+		return nil, err
+	}
+	l := labels(name)
+	for i := len(l) - len(labels(anchor)) - 1; i >= 0 && !z.insecure; i-- {
+		child, err := c.cut(z, strings.Join(l[i:], ".")+".")
+		if err != nil {
+			return nil, err
+		}
+		if child != nil {
+			z = child
+		}
+	}
+	return z, nil
+}
+
+// cut returns the zone whose apex is name, whose parent is the secure
+// zone p, or nil if name is not a zone cut. If p is nil, name is the
+// zone of a trust anchor.
+func (c *checker) cut(p *zone, name string) (*zone, error) {
+	if z, ok := c.v.cached(name, c.now); ok {
+		return z, nil
+	}
+	z, ttl, err := c.findCut(p, name)
+	if err != nil {
+		return nil, err
+	}
+	c.v.cache(name, z, ttl, c.now)
+	return z, nil
+}
+
+func (c *checker) findCut(p *zone, name string) (*zone, uint32, error) {
+	if p == nil {
+		var anchors []DS
+		for _, ds := range c.v.anchors() {
+			if canonicalName(ds.Zone) == name {
+				anchors = append(anchors, ds)
+			}
+		}
+		z, ttl, err := c.keys(name, anchors)
+		return z, min(ttl, anchorCacheTTL), err
+	}
+
+	m, err := c.fetch(name, typeDS)
+	if err != nil {
+		return nil, 0, err
+	}
+	answers := rrsets(m.answers)
+	if set := findRRset(answers, name, typeDS); set != nil {
+		if err := c.verifyWith(set, p); err != nil {
+			return nil, 0, err
+		}
+		var ds []DS
+		for _, r := range set.records {
+			if d, err := parseDS(r); err == nil {
+				ds = append(ds, d)
+			}
+		}
+		z, ttl, err := c.keys(name, ds)
+		return z, min(ttl, set.ttl), err
+	}
+	if set := findRRset(answers, name, dnsmessage.TypeCNAME); set != nil {
+		// An alias is not a zone cut.
+		if err := c.verifyWith(set, p); err != nil {
+			return nil, 0, err
+		}
+		return nil, set.ttl, nil
+	}
+
+	d := new(denial)
+	ttl := anchorCacheTTL
+	for _, set := range rrsets(m.authorities) {
+		if set.typ != typeNSEC && set.typ != typeNSEC3 {
+			continue
+		}
+		if err := c.verifyWith(set, p); err != nil {
+			return nil, 0, err
+		}
+		d.add(set, p.name)
+		ttl = min(ttl, set.ttl)
+	}
+	switch d.cut(name, m.rcode == dnsmessage.RCodeNameError) {
+	case cutNone:
+		return nil, ttl, nil
+	case cutInsecure:
+		return &zone{name: name, insecure: true}, ttl, nil
+	}
+	if d.insecure {
+		return &zone{name: name, insecure: true}, ttl, nil
+	}
+	return nil, 0, fmt.Errorf("dnssec: no proof that %s has no DS records", name)
+}
+
+// keys returns the zone name, secured by the keys identified by ds.
+func (c *checker) keys(name string, ds []DS) (*zone, uint32, error) {
+	supported := false
+	for _, d := range ds {
+		if supportedAlgorithm(d.Algorithm) && newDigest(d.DigestType) != nil {
+			supported = true
+		}
+	}
+	if !supported {
+		// The zone is treated as unsigned (RFC 4035, Section 5.2).
+		return &zone{name: name, insecure: true}, anchorCacheTTL, nil
+	}
+
+	m, err := c.fetch(name, typeDNSKEY)
+	if err != nil {
+		return nil, 0, err
+	}
+	set := findRRset(rrsets(m.answers), name, typeDNSKEY)
+	if set == nil {
+		return nil, 0, fmt.Errorf("dnssec: no DNSKEY records for %s", name)
+	}
+	var keys []*dnskey
+	for _, r := range set.records {
+		k, err := parseDNSKEY(r)
+		if err == nil && k.flags&flagZone != 0 && k.flags&flagRevoke == 0 {
+			keys = append(keys, k)
+		}
+	}
+	err = errBadSignature
+	for _, k := range keys {
+		matched := false
+		for _, d := range ds {
+			matched = matched || matchDS(d, name, k)
+		}
+		if !matched {
+			continue
+		}
+		for _, sig := range set.sigs {
+			if sig.signer != name {
+				continue
+			}
+			verr := verify(set, sig, k, c.now)
+			if verr == nil {
+				return &zone{name: name, keys: keys}, set.ttl, nil
+			}
+			if verr != errBadSignature {
+				err = verr
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("%w by a key matching the DS records of %s", err, name)
+}
+
+// fetch sends a query for the records of name and type typ.
+func (c *checker) fetch(name string, typ dnsmessage.Type) (*message, error) {
+	c.queries++
+	if c.queries > maxQueries {
+		return nil, errors.New("dnssec: too many queries")
+	}
+	b, err := c.query(c.ctx, name, uint16(typ))
+	if err != nil {
+		return nil, &queryError{err}
+	}
+	m, err := parseMessage(b)
+	if err != nil {
+		return nil, fmt.Errorf("dnssec: cannot parse response for %s %s records: %w", name, typeName(typ), err)
+	}
+	if m.rcode != dnsmessage.RCodeSuccess && m.rcode != dnsmessage.RCodeNameError {
+		return nil, &queryError{fmt.Errorf("query for %s %s records failed: %v", name, typeName(typ), m.rcode)}
+	}
+	if m.qname != name || m.qtype != typ {
+		return nil, fmt.Errorf("dnssec: response for %s %s records has another question", name, typeName(typ))
+	}
+	return m, nil
+}
+
+func (v *Validator) anchors() []DS {
+	if len(v.TrustAnchors) > 0 {
+		return v.TrustAnchors
+	}
+	return rootTrustAnchors
+}
+
+var rootTrustAnchors = RootTrustAnchors()
+
+// cached returns the cached zone cut of name, if any.
+func (v *Validator) cached(name string, now time.Time) (*zone, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	e, ok := v.cuts[name]
+	if !ok || !now.Before(e.expires) {
+		return nil, false
+	}
+	return e.zone, true
+}
+
+// cache caches the zone cut z of name for ttl seconds.
+func (v *Validator) cache(name string, z *zone, ttl uint32, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.cuts == nil {
+		v.cuts = make(map[string]cutEntry)
+	}
+	if len(v.cuts) >= maxCacheSize {
+		for name, e := range v.cuts {
+			if !now.Before(e.expires) {
+				delete(v.cuts, name)
+			}
+		}
+		if len(v.cuts) >= maxCacheSize {
+			clear(v.cuts)
+		}
+	}
+	d := min(time.Duration(ttl)*time.Second, maxCacheTTL)
+	v.cuts[name] = cutEntry{zone: z, expires: now.Add(d)}
+}
+
+// typeName returns the name of a record type, for errors.
+func typeName(t dnsmessage.Type) string {
+	switch t {
+	case typeDS:
+		return "DS"
+	case typeRRSIG:
+		return "RRSIG"
+	case typeNSEC:
+		return "NSEC"
+	case typeDNSKEY:
+		return "DNSKEY"
+	case typeNSEC3:
+		return "NSEC3"
+	}
+	return strings.TrimPrefix(t.String(), "Type")
+}
@@ -0,0 +1,740 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dnssec
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var testNow = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// A testKey is the signing key of a test zone.
+type testKey struct {
+	alg    uint8
+	signer crypto.Signer
+	rdata  []byte // of the DNSKEY record
+	tag    uint16
+}
+
+func newTestKey(t *testing.T, alg uint8) *testKey {
+	t.Helper()
+	k := &testKey{alg: alg}
+	var pub []byte
+	switch alg {
+	case algECDSAP256SHA256:
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := priv.PublicKey.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		k.signer, pub = priv, b[1:]
+	case algED25519:
+		p, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k.signer, pub = priv, p
+	case algRSASHA256:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := big32(uint32(priv.E))
+		pub = append([]byte{byte(len(e))}, e...)
+		pub = append(pub, priv.N.Bytes()...)
+		k.signer = priv
+	}
+	k.rdata = append([]byte{0x01, 0x01, 3, alg}, pub...) // a zone key, and a secure entry point
+	k.tag = keyTag(k.rdata)
+	return k
+}
+
+func big32(v uint32) []byte {
+	b := binary.BigEndian.AppendUint32(nil, v)
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
+
+func (k *testKey) sign(t *testing.T, data []byte) []byte {
+	t.Helper()
+	switch k.alg {
+	case algECDSAP256SHA256:
+		h := sha256.Sum256(data)
+		r, s, err := ecdsa.Sign(rand.Reader, k.signer.(*ecdsa.PrivateKey), h[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig
+	case algED25519:
+		return ed25519.Sign(k.signer.(ed25519.PrivateKey), data)
+	case algRSASHA256:
+		h := sha256.Sum256(data)
+		sig, err := rsa.SignPKCS1v15(nil, k.signer.(*rsa.PrivateKey), crypto.SHA256, h[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	t.Fatalf("unknown algorithm %d", k.alg)
+	return nil
+}
+
+// ds returns the RDATA of the DS record of k, owned by zone.
+func (k *testKey) ds(zone string) []byte {
+	h := sha256.New()
+	h.Write(appendName(nil, zone))
+	h.Write(k.rdata)
+	b := binary.BigEndian.AppendUint16(nil, k.tag)
+	b = append(b, k.alg, digestSHA256)
+	return h.Sum(b)
+}
+
+// A testRR is a record of a test zone, whose owner name is lower case
+// and whose data is in canonical form.
+type testRR struct {
+	name string
+	typ  dnsmessage.Type
+	data []byte
+}
+
+func testA(name string, a ...byte) testRR {
+	return testRR{name, dnsmessage.TypeA, a}
+}
+
+func testTXT(name, txt string) testRR {
+	return testRR{name, dnsmessage.TypeTXT, append([]byte{byte(len(txt))}, txt...)}
+}
+
+func testCNAME(name, target string) testRR {
+	return testRR{name, dnsmessage.TypeCNAME, appendName(nil, target)}
+}
+
+// A testZone is a zone served by a testServer.
+type testZone struct {
+	name  string
+	key   *testKey // nil for an unsigned zone
+	nsec3 bool     // deny with NSEC3 records rather than NSEC records
+	rrs   []testRR
+
+	delegations []string // the child zones
+}
+
+const testIterations = 2
+
+var testSalt = []byte{0xAB, 0xCD}
+
+func (z *testZone) get(name string, typ dnsmessage.Type) []testRR {
+	var rrs []testRR
+	for _, rr := range z.rrs {
+		if rr.name == name && rr.typ == typ {
+			rrs = append(rrs, rr)
+		}
+	}
+	return rrs
+}
+
+func (z *testZone) exists(name string) bool {
+	return slices.Contains(z.names(), name)
+}
+
+// names returns the names of z in canonical order.
+func (z *testZone) names() []string {
+	names := []string{z.name}
+	for _, rr := range z.rrs {
+		names = append(names, rr.name)
+	}
+	names = append(names, z.delegations...)
+	slices.SortFunc(names, compareNames)
+	return slices.Compact(names)
+}
+
+// types returns the type bit map of name.
+func (z *testZone) types(name string) []byte {
+	var bits [32]byte
+	set := func(t dnsmessage.Type) { bits[t/8] |= 0x80 >> (t % 8) }
+	for _, rr := range z.rrs {
+		if rr.name == name {
+			set(rr.typ)
+		}
+	}
+	if slices.Contains(z.delegations, name) {
+		set(dnsmessage.TypeNS)
+	}
+	if z.nsec3 {
+		if len(z.get(name, typeDS)) > 0 || !slices.Contains(z.delegations, name) {
+			set(typeRRSIG)
+		}
+	} else {
+		set(typeRRSIG)
+		set(typeNSEC)
+	}
+	n := len(bits)
+	for n > 0 && bits[n-1] == 0 {
+		n--
+	}
+	return append([]byte{0, byte(n)}, bits[:n]...)
+}
+
+func (z *testZone) hash(name string) []byte {
+	return (&nsec3{salt: testSalt, iterations: testIterations}).hashName(name)
+}
+
+// nsec returns the NSEC or NSEC3 record of name, and whether name
+// exists.
+func (z *testZone) nsec(name string) (testRR, bool) {
+	if z.nsec3 {
+		return z.nsec3Record(z.hash(name))
+	}
+	names := z.names()
+	i, found := slices.BinarySearchFunc(names, name, compareNames)
+	if !found {
+		i--
+	}
+	next := names[(i+1)%len(names)]
+	return testRR{names[i], typeNSEC, append(appendName(nil, next), z.types(names[i])...)}, found
+}
+
+// nsec3Record returns the NSEC3 record matching or covering hash.
+func (z *testZone) nsec3Record(hash []byte) (testRR, bool) {
+	byHash := make(map[string]string)
+	var hashes [][]byte
+	for _, name := range z.names() {
+		h := z.hash(name)
+		byHash[string(h)] = name
+		hashes = append(hashes, h)
+	}
+	slices.SortFunc(hashes, bytes.Compare)
+	i, found := slices.BinarySearchFunc(hashes, hash, bytes.Compare)
+	if !found {
+		i = (i + len(hashes) - 1) % len(hashes)
+	}
+	h, next := hashes[i], hashes[(i+1)%len(hashes)]
+	data := []byte{1, 0}
+	data = binary.BigEndian.AppendUint16(data, testIterations)
+	data = append(data, byte(len(testSalt)))
+	data = append(data, testSalt...)
+	data = append(data, byte(len(next)))
+	data = append(data, next...)
+	data = append(data, z.types(byHash[string(h)])...)
+	owner := strings.ToLower(base32Hex.EncodeToString(h)) + "." + z.name
+	return testRR{owner, typeNSEC3, data}, found
+}
+
+// proveNXDOMAIN returns the records proving that name doesn't exist.
+func (z *testZone) proveNXDOMAIN(name string) []testRR {
+	if !z.nsec3 {
+		cover, _ := z.nsec(name)
+		ce := commonAncestor(name, cover.name)
+		next, _, _ := readName(cover.data)
+		if a := commonAncestor(name, next); len(a) > len(ce) {
+			ce = a
+		}
+		wildcard, _ := z.nsec("*." + ce)
+		return []testRR{cover, wildcard}
+	}
+	ce := name
+	for !z.exists(ce) {
+		ce = parent(ce)
+	}
+	nc := name
+	for parent(nc) != ce {
+		nc = parent(nc)
+	}
+	match, _ := z.nsec(ce)
+	cover, _ := z.nsec(nc)
+	wildcard, _ := z.nsec("*." + ce)
+	return []testRR{match, cover, wildcard}
+}
+
+// sign returns rrs, owned by owner, followed by their signature.
+// nlabels is the number of labels of the signature.
+func (z *testZone) sign(t *testing.T, owner string, rrs []testRR, nlabels int, now time.Time) []testRR {
+	if z.key == nil || len(rrs) == 0 {
+		return rrs
+	}
+	rrs = slices.Clone(rrs)
+	for i := range rrs {
+		rrs[i].name = owner
+	}
+	rdata := binary.BigEndian.AppendUint16(nil, uint16(rrs[0].typ))
+	rdata = append(rdata, z.key.alg, byte(nlabels))
+	rdata = binary.BigEndian.AppendUint32(rdata, 3600)
+	rdata = binary.BigEndian.AppendUint32(rdata, uint32(now.Add(24*time.Hour).Unix()))
+	rdata = binary.BigEndian.AppendUint32(rdata, uint32(now.Add(-time.Hour).Unix()))
+	rdata = binary.BigEndian.AppendUint16(rdata, z.key.tag)
+	rdata = appendName(rdata, z.name)
+
+	signed := slices.Clone(rdata)
+	sorted := slices.Clone(rrs)
+	slices.SortFunc(sorted, func(a, b testRR) int { return bytes.Compare(a.data, b.data) })
+	for _, rr := range sorted {
+		owner := strings.ToLower(owner)
+		if l := labels(owner); nlabels < len(l) {
+			owner = "*." + strings.Join(l[len(l)-nlabels:], ".") + "."
+		}
+		signed = appendName(signed, owner)
+		signed = binary.BigEndian.AppendUint16(signed, uint16(rr.typ))
+		signed = binary.BigEndian.AppendUint16(signed, uint16(dnsmessage.ClassINET))
+		signed = binary.BigEndian.AppendUint32(signed, 3600)
+		signed = binary.BigEndian.AppendUint16(signed, uint16(len(rr.data)))
+		signed = append(signed, rr.data...)
+	}
+	return append(rrs, testRR{owner, typeRRSIG, append(rdata, z.key.sign(t, signed)...)})
+}
+
+// sigLabels returns the number of labels of the signatures of the
+// records of name.
+func sigLabels(name string) int {
+	l := labels(name)
+	if len(l) > 0 && l[0] == "*" {
+		return len(l) - 1
+	}
+	return len(l)
+}
+
+// A testServer answers queries like a recursive resolver would, from its
+// zones.
+type testServer struct {
+	t     *testing.T
+	zones []*testZone
+	now   time.Time
+
+	// tamper, if not nil, modifies the responses.
+	tamper func(qname string, m *dnsmessage.Message)
+
+	queries int
+}
+
+func (s *testServer) zone(name string, typ dnsmessage.Type) *testZone {
+	var best *testZone
+	for _, z := range s.zones {
+		if typ == typeDS && name == z.name && name != "." {
+			// DS records are served by the parent zone.
+			continue
+		}
+		if isSubdomain(name, z.name) && (best == nil || len(z.name) > len(best.name)) {
+			best = z
+		}
+	}
+	return best
+}
+
+func (s *testServer) query(ctx context.Context, qname string, qtype uint16) ([]byte, error) {
+	s.queries++
+	m := s.response(qname, dnsmessage.Type(qtype))
+	if s.tamper != nil {
+		s.tamper(strings.ToLower(qname), &m)
+	}
+	return m.Pack()
+}
+
+func (s *testServer) response(qname string, qtype dnsmessage.Type) dnsmessage.Message {
+	m := dnsmessage.Message{
+		Header: dnsmessage.Header{Response: true, RecursionDesired: true, RecursionAvailable: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(qname),
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	owner := qname
+	for range 8 {
+		name := strings.ToLower(owner)
+		z := s.zone(name, qtype)
+		if rrs := z.get(name, qtype); len(rrs) > 0 {
+			m.Answers = append(m.Answers, s.resources(z.sign(s.t, owner, rrs, sigLabels(name), s.now))...)
+			return m
+		}
+		if rrs := z.get(name, dnsmessage.TypeCNAME); len(rrs) > 0 {
+			m.Answers = append(m.Answers, s.resources(z.sign(s.t, owner, rrs, sigLabels(name), s.now))...)
+			owner, _, _ = readName(rrs[0].data)
+			continue
+		}
+		if !z.exists(name) && name != "." {
+			if rrs := z.get("*."+parent(name), qtype); len(rrs) > 0 {
+				m.Answers = append(m.Answers, s.resources(z.sign(s.t, owner, rrs, sigLabels(name)-1, s.now))...)
+				cover, _ := z.nsec(name)
+				m.Authorities = s.resources(z.sign(s.t, cover.name, []testRR{cover}, sigLabels(cover.name), s.now))
+				return m
+			}
+		}
+		if z.key == nil {
+			if !z.exists(name) {
+				m.RCode = dnsmessage.RCodeNameError
+			}
+			return m
+		}
+		var proof []testRR
+		if z.exists(name) {
+			rr, _ := z.nsec(name)
+			proof = []testRR{rr}
+		} else {
+			m.RCode = dnsmessage.RCodeNameError
+			proof = z.proveNXDOMAIN(name)
+		}
+		for _, rr := range proof {
+			if slices.ContainsFunc(m.Authorities, func(r dnsmessage.Resource) bool {
+				return strings.EqualFold(r.Header.Name.String(), rr.name)
+			}) {
+				continue
+			}
+			m.Authorities = append(m.Authorities, s.resources(z.sign(s.t, rr.name, []testRR{rr}, sigLabels(rr.name), s.now))...)
+		}
+		return m
+	}
+	s.t.Fatalf("CNAME loop for %s", qname)
+	return m
+}
+
+func (s *testServer) resources(rrs []testRR) []dnsmessage.Resource {
+	var rs []dnsmessage.Resource
+	for _, rr := range rrs {
+		h := dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName(rr.name),
+			Type:  rr.typ,
+			Class: dnsmessage.ClassINET,
+			TTL:   3600,
+		}
+		var body dnsmessage.ResourceBody
+		switch rr.typ {
+		case dnsmessage.TypeA:
+			body = &dnsmessage.AResource{A: [4]byte(rr.data)}
+		case dnsmessage.TypeTXT:
+			body = &dnsmessage.TXTResource{TXT: []string{string(rr.data[1:])}}
+		case dnsmessage.TypeCNAME:
+			target, _, err := readName(rr.data)
+			if err != nil {
+				s.t.Fatal(err)
+			}
+			body = &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target)}
+		default:
+			body = &dnsmessage.UnknownResource{Type: rr.typ, Data: rr.data}
+		}
+		rs = append(rs, dnsmessage.Resource{Header: h, Body: body})
+	}
+	return rs
+}
+
+// newTestServer returns a server for a tree of zones:
+//
+//	.                    ECDSA, NSEC
+//	example.             Ed25519, NSEC
+//	insecure.            unsigned
+//	hashed.              RSA, NSEC3
+//	unsupported.example. signed with an unsupported algorithm
+func newTestServer(t *testing.T) (*testServer, *Validator) {
+	root := &testZone{name: ".", key: newTestKey(t, algECDSAP256SHA256)}
+	example := &testZone{name: "example.", key: newTestKey(t, algED25519)}
+	insecure := &testZone{name: "insecure."}
+	hashed := &testZone{name: "hashed.", key: newTestKey(t, algRSASHA256), nsec3: true}
+	unsupported := &testZone{name: "unsupported.example."}
+
+	root.delegations = []string{"example.", "insecure.", "hashed."}
+	root.rrs = []testRR{
+		{".", typeDNSKEY, root.key.rdata},
+		{"example.", typeDS, example.key.ds("example.")},
+		{"hashed.", typeDS, hashed.key.ds("hashed.")},
+	}
+
+	unsupportedDS := example.key.ds("unsupported.example.")
+	unsupportedDS[2] = 253 // a private algorithm
+	example.delegations = []string{"unsupported.example."}
+	example.rrs = []testRR{
+		{"example.", typeDNSKEY, example.key.rdata},
+		testA("www.example.", 192, 0, 2, 1),
+		testA("www.example.", 192, 0, 2, 2),
+		testTXT("www.example.", "hello"),
+		testCNAME("alias.example.", "www.example."),
+		testCNAME("outside.example.", "host.insecure."),
+		testA("*.wild.example.", 192, 0, 2, 3),
+		testA("wild.example.", 192, 0, 2, 4),
+		{"unsupported.example.", typeDS, unsupportedDS},
+	}
+
+	insecure.rrs = []testRR{testA("host.insecure.", 192, 0, 2, 5)}
+	unsupported.rrs = []testRR{testA("host.unsupported.example.", 192, 0, 2, 6)}
+
+	hashed.rrs = []testRR{
+		{"hashed.", typeDNSKEY, hashed.key.rdata},
+		testA("www.hashed.", 192, 0, 2, 7),
+		testTXT("txt.hashed.", "hashed"),
+	}
+
+	s := &testServer{
+		t:     t,
+		zones: []*testZone{root, example, insecure, hashed, unsupported},
+		now:   testNow,
+	}
+	v := &Validator{
+		TrustAnchors: []DS{{
+			Zone:       ".",
+			KeyTag:     root.key.tag,
+			Algorithm:  root.key.alg,
+			DigestType: digestSHA256,
+			Digest:     root.key.ds(".")[4:],
+		}},
+		Time: func() time.Time { return testNow },
+	}
+	return s, v
+}
+
+func (s *testServer) validate(v *Validator, name string, typ dnsmessage.Type) (net.DNSSECStatus, error) {
+	b, err := s.query(context.Background(), name, uint16(typ))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return v.Validate(context.Background(), b, s.query)
+}
+
+func TestValidate(t *testing.T) {
+	s, v := newTestServer(t)
+	for _, tt := range []struct {
+		name string
+		typ  dnsmessage.Type
+		want net.DNSSECStatus
+	}{
+		{"www.example.", dnsmessage.TypeA, net.DNSSECSecure},
+		{"WWW.Example.", dnsmessage.TypeA, net.DNSSECSecure},
+		{"www.example.", dnsmessage.TypeTXT, net.DNSSECSecure},
+		{"www.example.", dnsmessage.TypeAAAA, net.DNSSECSecure},   // NODATA
+		{"nowhere.example.", dnsmessage.TypeA, net.DNSSECSecure},  // NXDOMAIN
+		{"a.b.c.example.", dnsmessage.TypeA, net.DNSSECSecure},    // NXDOMAIN
+		{"alias.example.", dnsmessage.TypeA, net.DNSSECSecure},    // CNAME
+		{"foo.wild.example.", dnsmessage.TypeA, net.DNSSECSecure}, // wildcard
+		{"outside.example.", dnsmessage.TypeA, net.DNSSECInsecure},
+		{"host.insecure.", dnsmessage.TypeA, net.DNSSECInsecure},
+		{"nowhere.insecure.", dnsmessage.TypeA, net.DNSSECInsecure},
+		{"host.unsupported.example.", dnsmessage.TypeA, net.DNSSECInsecure},
+		{"www.hashed.", dnsmessage.TypeA, net.DNSSECSecure},
+		{"www.hashed.", dnsmessage.TypeAAAA, net.DNSSECSecure},  // NODATA
+		{"nowhere.hashed.", dnsmessage.TypeA, net.DNSSECSecure}, // NXDOMAIN
+		{"a.b.www.hashed.", dnsmessage.TypeA, net.DNSSECSecure}, // NXDOMAIN
+		{"example.", typeDNSKEY, net.DNSSECSecure},              // DNSKEY
+		{"example.", typeDS, net.DNSSECSecure},                  // DS
+		{"insecure.", typeDS, net.DNSSECSecure},                 // NODATA
+		{"nowhere.", dnsmessage.TypeTXT, net.DNSSECSecure},      // NXDOMAIN in the root zone
+	} {
+		status, err := s.validate(v, tt.name, tt.typ)
+		if status != tt.want || err != nil {
+			t.Errorf("%s %s: got %v, %v; want %v", tt.name, typeName(tt.typ), status, err, tt.want)
+		}
+	}
+
+	// The zone cuts are cached.
+	s.queries = 0
+	if status, err := s.validate(v, "www.example.", dnsmessage.TypeA); status != net.DNSSECSecure || err != nil {
+		t.Fatalf("www.example.: got %v, %v", status, err)
+	}
+	if s.queries != 1 {
+		t.Errorf("validation with a warm cache sent %d queries, want none", s.queries-1)
+	}
+}
+
+func TestValidateBogus(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		name    string
+		typ     dnsmessage.Type
+		tamper  func(s *testServer, qname string, m *dnsmessage.Message)
+		time    time.Time
+		wantErr string
+	}{
+		{
+			desc: "changed address",
+			name: "www.example.",
+			typ:  dnsmessage.TypeA,
+			tamper: func(s *testServer, qname string, m *dnsmessage.Message) {
+				if qname == "www.example." {
+					m.Answers[0].Body = &dnsmessage.AResource{A: [4]byte{203, 0, 113, 1}}
+				}
+			},
+			wantErr: "invalid signature",
+		},
+		{
+			desc: "removed signature",
+			name: "www.example.",
+			typ:  dnsmessage.TypeA,
+			tamper: func(s *testServer, qname string, m *dnsmessage.Message) {
+				if qname != "www.example." {
+					return
+				}
+				m.Answers = slices.DeleteFunc(m.Answers, func(r dnsmessage.Resource) bool { return r.Header.Type == typeRRSIG })
+			},
+			wantErr: "not signed",
+		},
+		{
+			desc: "removed denial",
+			name: "nowhere.example.",
+			typ:  dnsmessage.TypeA,
+			tamper: func(s *testServer, qname string, m *dnsmessage.Message) {
+				m.Authorities = nil
+			},
+			wantErr: "no proof",
+		},
+		{
+			desc: "NXDOMAIN for existing name",
+			name: "www.example.",
+			typ:  dnsmessage.TypeA,
+			tamper: func(s *testServer, qname string, m *dnsmessage.Message) {
+				if qname == "www.example." {
+					nx := s.response("www0.example.", dnsmessage.TypeA)
+					m.RCode = dnsmessage.RCodeNameError
+					m.Answers = nil
+					m.Authorities = nx.Authorities
+				}
+			},
+			wantErr: "no proof",
+		},
+		{
+			desc: "removed DS",
+			name: "www.example.",
+			typ:  dnsmessage.TypeA,
+			tamper: func(s *testServer, qname string, m *dnsmessage.Message) {
+				if qname == "example." && m.Questions[0].Type == typeDS {
+					m.Answers = nil
+				}
+			},
+			wantErr: "no proof that example. has no DS records",
+		},
+		{
+			desc: "removed wildcard proof",
+			name: "foo.wild.example.",
+			typ:  dnsmessage.TypeA,
+			tamper: func(s *testServer, qname string, m *dnsmessage.Message) {
+				m.Authorities = nil
+			},
+			wantErr: "wildcard",
+		},
+		{
+			desc:    "expired",
+			name:    "www.example.",
+			typ:     dnsmessage.TypeA,
+			time:    testNow.Add(48 * time.Hour),
+			wantErr: "expired",
+		},
+		{
+			desc:    "not yet valid",
+			name:    "www.hashed.",
+			typ:     dnsmessage.TypeA,
+			time:    testNow.Add(-48 * time.Hour),
+			wantErr: "not yet valid",
+		},
+	} {
+		s, v := newTestServer(t)
+		if tt.tamper != nil {
+			s.tamper = func(qname string, m *dnsmessage.Message) { tt.tamper(s, qname, m) }
+		}
+		if !tt.time.IsZero() {
+			v.Time = func() time.Time { return tt.time }
+		}
+		status, err := s.validate(v, tt.name, tt.typ)
+		if status != net.DNSSECBogus || err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got %v, %v; want %v, error containing %q", tt.desc, status, err, net.DNSSECBogus, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateTrustAnchor(t *testing.T) {
+	s, v := newTestServer(t)
+	v.TrustAnchors[0].Digest = bytes.Repeat([]byte{1}, sha256.Size)
+	if status, err := s.validate(v, "www.example.", dnsmessage.TypeA); status != net.DNSSECBogus || err == nil {
+		t.Errorf("with another trust anchor: got %v, %v; want %v, error", status, err, net.DNSSECBogus)
+	}
+
+	// A trust anchor for a zone below the root makes it secure on its
+	// own.
+	s, v = newTestServer(t)
+	hashed := s.zones[3]
+	v.TrustAnchors = []DS{{
+		Zone:       "Hashed",
+		KeyTag:     hashed.key.tag,
+		Algorithm:  hashed.key.alg,
+		DigestType: digestSHA256,
+		Digest:     hashed.key.ds("hashed.")[4:],
+	}}
+	s.tamper = func(qname string, m *dnsmessage.Message) {
+		if !isSubdomain(qname, "hashed.") || qname == "hashed." && m.Questions[0].Type == typeDS {
+			t.Errorf("unexpected query for %s", qname)
+		}
+	}
+	if status, err := s.validate(v, "www.hashed.", dnsmessage.TypeA); status != net.DNSSECSecure || err != nil {
+		t.Errorf("with a trust anchor for hashed.: got %v, %v; want %v", status, err, net.DNSSECSecure)
+	}
+}
+
+func TestValidateQueryError(t *testing.T) {
+	s, v := newTestServer(t)
+	b, err := s.query(context.Background(), "www.example.", uint16(dnsmessage.TypeA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errFail := errors.New("no server")
+	status, err := v.Validate(context.Background(), b, func(context.Context, string, uint16) ([]byte, error) {
+		return nil, errFail
+	})
+	if status != net.DNSSECUnvalidated || !errors.Is(err, errFail) {
+		t.Errorf("got %v, %v; want %v, %v", status, err, net.DNSSECUnvalidated, errFail)
+	}
+}
+
+func TestCompareNames(t *testing.T) {
+	// The example of RFC 4034, Section 6.1, with names in lower case.
+	names := []string{
+		"example.",
+		"a.example.",
+		"yljkjljk.a.example.",
+		"z.a.example.",
+		"zabc.a.example.",
+		"z.example.",
+		"\001.z.example.",
+		"*.z.example.",
+		"\200.z.example.",
+	}
+	for i := range names {
+		for j := range names {
+			if got, want := compareNames(names[i], names[j]), i-j; got < 0 != (want < 0) || got == 0 != (want == 0) {
+				t.Errorf("compareNames(%q, %q) = %d, want sign of %d", names[i], names[j], got, want)
+			}
+		}
+	}
+}
+
+func TestNSEC3Hash(t *testing.T) {
+	// The example of RFC 5155, Appendix A.
+	n := &nsec3{salt: []byte{0xaa, 0xbb, 0xcc, 0xdd}, iterations: 12}
+	for name, want := range map[string]string{
+		"example.":   "0p9mhaveqvm6t7vbl5lop2u3t2rp3tom",
+		"a.example.": "35mthgpgcu1qg68fab165klnsnk3dpvl",
+	} {
+		if got := strings.ToLower(base32Hex.EncodeToString(n.hashName(name))); got != want {
+			t.Errorf("hash of %s = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dnssec_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/dnssec"
+)
+
+func ExampleValidator() {
+	r := &net.Resolver{
+		ValidateDNSSEC: new(dnssec.Validator).Validate,
+	}
+	addrs, status, err := r.LookupHostDNSSEC(context.Background(), "go.dev")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if status != net.DNSSECSecure {
+		log.Fatalf("the addresses of go.dev are %v", status)
+	}
+	fmt.Println(addrs)
+}
@@ -0,0 +1,493 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dnssec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// The types of the DNSSEC resource records, which package dnsmessage
+// parses as unknown resources.
+const (
+	typeDS     dnsmessage.Type = 43
+	typeRRSIG  dnsmessage.Type = 46
+	typeNSEC   dnsmessage.Type = 47
+	typeDNSKEY dnsmessage.Type = 48
+	typeNSEC3  dnsmessage.Type = 50
+)
+
+var errMalformed = errors.New("dnssec: malformed record")
+
+// A record is a resource record of a message. Its owner name and its
+// data are in the canonical form of RFC 4034, Section 6.2.
+type record struct {
+	name  string // lower case, rooted
+	typ   dnsmessage.Type
+	class dnsmessage.Class
+	ttl   uint32
+	data  []byte
+}
+
+// A message is a parsed DNS response.
+type message struct {
+	rcode       dnsmessage.RCode
+	qname       string
+	qtype       dnsmessage.Type
+	answers     []record
+	authorities []record
+}
+
+func parseMessage(b []byte) (*message, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(b)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, err
+	}
+	m := &message{
+		rcode: h.RCode,
+		qname: canonicalName(q.Name.String()),
+		qtype: q.Type,
+	}
+	answers, err := p.AllAnswers()
+	if err != nil {
+		return nil, err
+	}
+	authorities, err := p.AllAuthorities()
+	if err != nil {
+		return nil, err
+	}
+	if m.answers, err = records(answers); err != nil {
+		return nil, err
+	}
+	if m.authorities, err = records(authorities); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func records(rs []dnsmessage.Resource) ([]record, error) {
+	var records []record
+	for _, r := range rs {
+		if r.Header.Type == dnsmessage.TypeOPT {
+			continue
+		}
+		data, err := canonicalData(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record{
+			name:  canonicalName(r.Header.Name.String()),
+			typ:   r.Header.Type,
+			class: r.Header.Class,
+			ttl:   r.Header.TTL,
+			data:  data,
+		})
+	}
+	return records, nil
+}
+
+// canonicalData returns the RDATA of body in canonical form: without
+// name compression, and with the names of the record types listed in
+// RFC 4034, Section 6.2, in lower case.
+func canonicalData(body dnsmessage.ResourceBody) ([]byte, error) {
+	var b []byte
+	switch body := body.(type) {
+	case *dnsmessage.AResource:
+		b = append(b, body.A[:]...)
+	case *dnsmessage.AAAAResource:
+		b = append(b, body.AAAA[:]...)
+	case *dnsmessage.CNAMEResource:
+		b = appendName(b, canonicalName(body.CNAME.String()))
+	case *dnsmessage.NSResource:
+		b = appendName(b, canonicalName(body.NS.String()))
+	case *dnsmessage.PTRResource:
+		b = appendName(b, canonicalName(body.PTR.String()))
+	case *dnsmessage.MXResource:
+		b = binary.BigEndian.AppendUint16(b, body.Pref)
+		b = appendName(b, canonicalName(body.MX.String()))
+	case *dnsmessage.SOAResource:
+		b = appendName(b, canonicalName(body.NS.String()))
+		b = appendName(b, canonicalName(body.MBox.String()))
+		b = binary.BigEndian.AppendUint32(b, body.Serial)
+		b = binary.BigEndian.AppendUint32(b, body.Refresh)
+		b = binary.BigEndian.AppendUint32(b, body.Retry)
+		b = binary.BigEndian.AppendUint32(b, body.Expire)
+		b = binary.BigEndian.AppendUint32(b, body.MinTTL)
+	case *dnsmessage.SRVResource:
+		b = binary.BigEndian.AppendUint16(b, body.Priority)
+		b = binary.BigEndian.AppendUint16(b, body.Weight)
+		b = binary.BigEndian.AppendUint16(b, body.Port)
+		b = appendName(b, canonicalName(body.Target.String()))
+	case *dnsmessage.TXTResource:
+		for _, s := range body.TXT {
+			if len(s) > 255 {
+				return nil, errMalformed
+			}
+			b = append(b, byte(len(s)))
+			b = append(b, s...)
+		}
+	case *dnsmessage.SVCBResource:
+		b = appendSVCB(b, body)
+	case *dnsmessage.HTTPSResource:
+		b = appendSVCB(b, &body.SVCBResource)
+	case *dnsmessage.UnknownResource:
+		b = append(b, body.Data...)
+	default:
+		return nil, errMalformed
+	}
+	return b, nil
+}
+
+func appendSVCB(b []byte, r *dnsmessage.SVCBResource) []byte {
+	b = binary.BigEndian.AppendUint16(b, r.Priority)
+	// The target name is not converted to lower case (RFC 9460).
+	b = appendName(b, r.Target.String())
+	for _, p := range r.Params {
+		b = binary.BigEndian.AppendUint16(b, uint16(p.Key))
+		b = binary.BigEndian.AppendUint16(b, uint16(len(p.Value)))
+		b = append(b, p.Value...)
+	}
+	return b
+}
+
+// canonicalName returns name in lower case and rooted.
+func canonicalName(name string) string {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return strings.ToLower(name)
+}
+
+// appendName appends the wire format of the rooted name to b.
+func appendName(b []byte, name string) []byte {
+	if name != "." {
+		for label := range strings.SplitSeq(strings.TrimSuffix(name, "."), ".") {
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+	}
+	return append(b, 0)
+}
+
+// readName reads an uncompressed name from b. It returns the name,
+// in canonical form, and the rest of b.
+func readName(b []byte) (string, []byte, error) {
+	var name []byte
+	for {
+		if len(b) == 0 {
+			return "", nil, errMalformed
+		}
+		n := int(b[0])
+		if n == 0 {
+			b = b[1:]
+			break
+		}
+		// Compression pointers are not allowed in the DNSSEC
+		// records, and labels containing dots can't be represented.
+		if n > 63 || len(b) < 1+n || bytes.IndexByte(b[1:1+n], '.') >= 0 {
+			return "", nil, errMalformed
+		}
+		name = append(name, b[1:1+n]...)
+		name = append(name, '.')
+		b = b[1+n:]
+	}
+	if len(name) == 0 {
+		return ".", b, nil
+	}
+	if len(name) > 254 {
+		return "", nil, errMalformed
+	}
+	return strings.ToLower(string(name)), b, nil
+}
+
+// labels returns the labels of the rooted name, from the leftmost one.
+func labels(name string) []string {
+	if name == "." {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(name, "."), ".")
+}
+
+// isSubdomain reports whether name is domain or a name below it.
+func isSubdomain(name, domain string) bool {
+	return domain == "." || name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// parent returns the parent of name, which must not be the root.
+func parent(name string) string {
+	_, p, _ := strings.Cut(name, ".")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// compareNames compares the names a and b in the canonical order of
+// RFC 4034, Section 6.1: label by label, from the rightmost one.
+func compareNames(a, b string) int {
+	la, lb := labels(a), labels(b)
+	for len(la) > 0 && len(lb) > 0 {
+		if c := strings.Compare(la[len(la)-1], lb[len(lb)-1]); c != 0 {
+			return c
+		}
+		la, lb = la[:len(la)-1], lb[:len(lb)-1]
+	}
+	return len(la) - len(lb)
+}
+
+// An rrset is a set of records with the same owner name, class, and
+// type, and the signatures covering it.
+type rrset struct {
+	name    string
+	typ     dnsmessage.Type
+	class   dnsmessage.Class
+	ttl     uint32
+	records []record // sorted by data, without duplicates
+	sigs    []*rrsig
+}
+
+// rrsets groups records into rrsets, in the order of their first
+// records, and attaches the signatures to the rrsets they cover.
+// Signatures that don't parse are ignored.
+func rrsets(records []record) []*rrset {
+	var sets []*rrset
+	find := func(name string, typ dnsmessage.Type, class dnsmessage.Class) *rrset {
+		for _, s := range sets {
+			if s.name == name && s.typ == typ && s.class == class {
+				return s
+			}
+		}
+		return nil
+	}
+	for _, r := range records {
+		if r.typ == typeRRSIG {
+			continue
+		}
+		s := find(r.name, r.typ, r.class)
+		if s == nil {
+			s = &rrset{name: r.name, typ: r.typ, class: r.class, ttl: r.ttl}
+			sets = append(sets, s)
+		}
+		s.ttl = min(s.ttl, r.ttl)
+		s.records = append(s.records, r)
+	}
+	for _, r := range records {
+		if r.typ != typeRRSIG {
+			continue
+		}
+		sig, err := parseRRSIG(r)
+		if err != nil {
+			continue
+		}
+		if s := find(r.name, sig.typeCovered, r.class); s != nil {
+			s.sigs = append(s.sigs, sig)
+		}
+	}
+	for _, s := range sets {
+		slices.SortFunc(s.records, func(a, b record) int { return bytes.Compare(a.data, b.data) })
+		s.records = slices.CompactFunc(s.records, func(a, b record) bool { return bytes.Equal(a.data, b.data) })
+	}
+	return sets
+}
+
+// findRRset returns the rrset of sets with the name and type, or nil.
+func findRRset(sets []*rrset, name string, typ dnsmessage.Type) *rrset {
+	for _, s := range sets {
+		if s.name == name && s.typ == typ {
+			return s
+		}
+	}
+	return nil
+}
+
+// An rrsig is an RRSIG record (RFC 4034, Section 3).
+type rrsig struct {
+	typeCovered dnsmessage.Type
+	algorithm   uint8
+	labels      uint8
+	origTTL     uint32
+	expiration  uint32
+	inception   uint32
+	keyTag      uint16
+	signer      string
+	signature   []byte
+
+	// signed is the RDATA without the signature, with the signer's
+	// name in canonical form: the start of the signed data.
+	signed []byte
+}
+
+func parseRRSIG(r record) (*rrsig, error) {
+	b := r.data
+	if len(b) < 18 {
+		return nil, errMalformed
+	}
+	sig := &rrsig{
+		typeCovered: dnsmessage.Type(binary.BigEndian.Uint16(b)),
+		algorithm:   b[2],
+		labels:      b[3],
+		origTTL:     binary.BigEndian.Uint32(b[4:]),
+		expiration:  binary.BigEndian.Uint32(b[8:]),
+		inception:   binary.BigEndian.Uint32(b[12:]),
+		keyTag:      binary.BigEndian.Uint16(b[16:]),
+	}
+	signer, rest, err := readName(b[18:])
+	if err != nil {
+		return nil, err
+	}
+	sig.signer = signer
+	sig.signature = rest
+	sig.signed = appendName(slices.Clip(b[:18]), signer)
+	return sig, nil
+}
+
+// A dnskey is a DNSKEY record (RFC 4034, Section 2).
+type dnskey struct {
+	flags     uint16
+	algorithm uint8
+	publicKey []byte
+	tag       uint16
+	data      []byte // RDATA
+}
+
+// Flags of DNSKEY records.
+const (
+	flagZone   = 0x0100
+	flagRevoke = 0x0080 // RFC 5011
+)
+
+func parseDNSKEY(r record) (*dnskey, error) {
+	b := r.data
+	if len(b) < 4 || b[2] != 3 { // the protocol must be 3
+		return nil, errMalformed
+	}
+	return &dnskey{
+		flags:     binary.BigEndian.Uint16(b),
+		algorithm: b[3],
+		publicKey: b[4:],
+		tag:       keyTag(b),
+		data:      b,
+	}, nil
+}
+
+// keyTag returns the key tag of the RDATA of a DNSKEY record, as
+// computed in RFC 4034, Appendix B.
+func keyTag(data []byte) uint16 {
+	var ac uint32
+	for i, b := range data {
+		if i&1 == 0 {
+			ac += uint32(b) << 8
+		} else {
+			ac += uint32(b)
+		}
+	}
+	ac += ac >> 16 & 0xffff
+	return uint16(ac)
+}
+
+func parseDS(r record) (DS, error) {
+	b := r.data
+	if len(b) < 5 {
+		return DS{}, errMalformed
+	}
+	return DS{
+		Zone:       r.name,
+		KeyTag:     binary.BigEndian.Uint16(b),
+		Algorithm:  b[2],
+		DigestType: b[3],
+		Digest:     b[4:],
+	}, nil
+}
+
+// An nsec is an NSEC record (RFC 4034, Section 4).
+type nsec struct {
+	name  string
+	next  string
+	types []byte // type bit maps
+}
+
+func parseNSEC(r record) (*nsec, error) {
+	next, types, err := readName(r.data)
+	if err != nil {
+		return nil, err
+	}
+	return &nsec{name: r.name, next: next, types: types}, nil
+}
+
+// An nsec3 is an NSEC3 record (RFC 5155, Section 3).
+type nsec3 struct {
+	zone       string
+	hash       []byte // the hash of the owner name
+	algorithm  uint8
+	flags      uint8
+	iterations uint16
+	salt       []byte
+	next       []byte // the next hashed owner name
+	types      []byte // type bit maps
+}
+
+// NSEC3 flags.
+const flagOptOut = 0x01
+
+func parseNSEC3(r record, zone string) (*nsec3, error) {
+	label, rest, _ := strings.Cut(r.name, ".")
+	if rest != zone && (zone != "." || rest != "") {
+		return nil, errMalformed
+	}
+	hash, err := base32Hex.DecodeString(strings.ToUpper(label))
+	if err != nil {
+		return nil, errMalformed
+	}
+	b := r.data
+	if len(b) < 5 || len(b) < 5+int(b[4]) {
+		return nil, errMalformed
+	}
+	n := &nsec3{
+		zone:       zone,
+		hash:       hash,
+		algorithm:  b[0],
+		flags:      b[1],
+		iterations: binary.BigEndian.Uint16(b[2:]),
+		salt:       b[5 : 5+b[4]],
+	}
+	b = b[5+b[4]:]
+	if len(b) < 1 || len(b) < 1+int(b[0]) {
+		return nil, errMalformed
+	}
+	n.next = b[1 : 1+b[0]]
+	n.types = b[1+b[0]:]
+	return n, nil
+}
+
+// hasType reports whether the type bit maps of an NSEC or NSEC3 record
+// include typ.
+func hasType(types []byte, typ dnsmessage.Type) bool {
+	window, bit := byte(typ>>8), byte(typ)
+	for len(types) >= 2 {
+		w, n := types[0], int(types[1])
+		if len(types) < 2+n {
+			return false
+		}
+		if w == window {
+			i := int(bit / 8)
+			return i < n && types[2+i]&(0x80>>(bit%8)) != 0
+		}
+		types = types[2+n:]
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dnssec
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// DNSSEC algorithm numbers (RFC 8624).
+const (
+	algRSASHA256       = 8
+	algRSASHA512       = 10
+	algECDSAP256SHA256 = 13
+	algECDSAP384SHA384 = 14
+	algED25519         = 15
+)
+
+// DS digest types.
+const (
+	digestSHA1   = 1
+	digestSHA256 = 2
+	digestSHA384 = 4
+)
+
+var (
+	errBadSignature     = errors.New("dnssec: invalid signature")
+	errSignatureExpired = errors.New("dnssec: signature expired or not yet valid")
+	errBadKey           = errors.New("dnssec: invalid public key")
+)
+
+// supportedAlgorithm reports whether signatures of the algorithm can be
+// verified.
+func supportedAlgorithm(alg uint8) bool {
+	switch alg {
+	case algRSASHA256, algRSASHA512, algECDSAP256SHA256, algECDSAP384SHA384, algED25519:
+		return true
+	}
+	return false
+}
+
+// newDigest returns the hash function of a DS digest type, or nil.
+func newDigest(digestType uint8) hash.Hash {
+	switch digestType {
+	case digestSHA1:
+		return sha1.New()
+	case digestSHA256:
+		return sha256.New()
+	case digestSHA384:
+		return sha512.New384()
+	}
+	return nil
+}
+
+// matchDS reports whether ds is the digest of key, owned by zone.
+func matchDS(ds DS, zone string, key *dnskey) bool {
+	if ds.KeyTag != key.tag || ds.Algorithm != key.algorithm {
+		return false
+	}
+	h := newDigest(ds.DigestType)
+	if h == nil {
+		return false
+	}
+	h.Write(appendName(nil, zone))
+	h.Write(key.data)
+	return subtle.ConstantTimeCompare(h.Sum(nil), ds.Digest) == 1
+}
+
+// signedData returns the data signed by sig for set, as described in
+// RFC 4034, Section 3.1.8.1.
+func signedData(set *rrset, sig *rrsig) ([]byte, error) {
+	owner := set.name
+	l := labels(owner)
+	if len(l) > 0 && l[0] == "*" {
+		l = l[1:]
+	}
+	switch {
+	case int(sig.labels) > len(l):
+		return nil, errBadSignature
+	case int(sig.labels) < len(l):
+		// The records were expanded from a wildcard (RFC 4035,
+		// Section 5.3.2).
+		owner = "*." + strings.Join(l[len(l)-int(sig.labels):], ".") + "."
+	}
+	name := appendName(nil, owner)
+	b := append([]byte(nil), sig.signed...)
+	for _, r := range set.records {
+		b = append(b, name...)
+		b = binary.BigEndian.AppendUint16(b, uint16(r.typ))
+		b = binary.BigEndian.AppendUint16(b, uint16(r.class))
+		b = binary.BigEndian.AppendUint32(b, sig.origTTL)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(r.data)))
+		b = append(b, r.data...)
+	}
+	return b, nil
+}
+
+// wildcard reports whether sig signs records expanded from a wildcard.
+func (sig *rrsig) wildcard(owner string) bool {
+	l := labels(owner)
+	return int(sig.labels) < len(l) && l[0] != "*"
+}
+
+// validAt reports whether sig is valid at time now, comparing times with
+// serial number arithmetic (RFC 4034, Section 3.1.5).
+func (sig *rrsig) validAt(now time.Time) bool {
+	t := uint32(now.Unix())
+	return int32(t-sig.inception) >= 0 && int32(sig.expiration-t) >= 0
+}
+
+// verify checks that sig is a valid signature of set by key at time now.
+func verify(set *rrset, sig *rrsig, key *dnskey, now time.Time) error {
+	if sig.keyTag != key.tag || sig.algorithm != key.algorithm || sig.typeCovered != set.typ {
+		return errBadSignature
+	}
+	if !sig.validAt(now) {
+		return errSignatureExpired
+	}
+	data, err := signedData(set, sig)
+	if err != nil {
+		return err
+	}
+	var h crypto.Hash
+	switch sig.algorithm {
+	case algRSASHA256, algECDSAP256SHA256:
+		h = crypto.SHA256
+	case algECDSAP384SHA384:
+		h = crypto.SHA384
+	case algRSASHA512:
+		h = crypto.SHA512
+	}
+	var digest []byte
+	if h != 0 {
+		hh := h.New()
+		hh.Write(data)
+		digest = hh.Sum(nil)
+	}
+
+	switch sig.algorithm {
+	case algRSASHA256, algRSASHA512:
+		pub, err := parseRSAKey(key.publicKey)
+		if err != nil {
+			return err
+		}
+		if rsa.VerifyPKCS1v15(pub, h, digest, sig.signature) != nil {
+			return errBadSignature
+		}
+	case algECDSAP256SHA256, algECDSAP384SHA384:
+		curve := elliptic.P256()
+		if sig.algorithm == algECDSAP384SHA384 {
+			curve = elliptic.P384()
+		}
+		// The public key is the two coordinates of the point, and the
+		// signature the two integers r and s (RFC 6605, Section 4).
+		pub, err := ecdsa.ParseUncompressedPublicKey(curve, append([]byte{4}, key.publicKey...))
+		if err != nil {
+			return errBadKey
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(sig.signature) != 2*size {
+			return errBadSignature
+		}
+		r := new(big.Int).SetBytes(sig.signature[:size])
+		s := new(big.Int).SetBytes(sig.signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errBadSignature
+		}
+	case algED25519:
+		if len(key.publicKey) != ed25519.PublicKeySize {
+			return errBadKey
+		}
+		if !ed25519.Verify(ed25519.PublicKey(key.publicKey), data, sig.signature) {
+			return errBadSignature
+		}
+	default:
+		return errBadSignature
+	}
+	return nil
+}
+
+// parseRSAKey parses an RSA public key in the format of RFC 3110,
+// Section 2.
+func parseRSAKey(b []byte) (*rsa.PublicKey, error) {
+	if len(b) < 1 {
+		return nil, errBadKey
+	}
+	n := int(b[0])
+	b = b[1:]
+	if n == 0 {
+		if len(b) < 2 {
+			return nil, errBadKey
+		}
+		n = int(binary.BigEndian.Uint16(b))
+		b = b[2:]
+	}
+	if n == 0 || n > 8 || len(b) <= n {
+		return nil, errBadKey
+	}
+	var e uint64
+	for _, c := range b[:n] {
+		e = e<<8 | uint64(c)
+	}
+	if e > 1<<31-1 {
+		return nil, errBadKey
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(b[n:]), E: int(e)}, nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A DNSClient exchanges DNS messages with DNS servers over encrypted
+// transports: DNS over TLS (RFC 7858) and DNS over HTTPS (RFC 8484).
+// Its Exchange method is suitable for [net.Resolver.Exchange]:
+//
+//	var dc http.DNSClient
+//	net.DefaultResolver.Exchange = dc.Exchange
+//
+// A DNSClient keeps its connections to DNS servers open for later
+// queries. It is safe for concurrent use by multiple goroutines.
+//
+// The zero value is a valid DNSClient that uses [DefaultClient] for DNS
+// over HTTPS, and verifies the certificates of DNS over TLS servers
+// with the system roots.
+type DNSClient struct {
+	// Client sends the requests of DNS over HTTPS.
+	// If nil, DefaultClient is used.
+	//
+	// The host of a server URL is an IP address when the server comes
+	// from the system configuration. For other servers, Client must
+	// not resolve their host name with a resolver that uses this
+	// DNSClient.
+	Client *Client
+
+	// TLSConfig is the TLS configuration of DNS over TLS. If nil, the
+	// default configuration is used. If TLSConfig.ServerName is empty,
+	// the certificate of a server must be valid for the host of its
+	// URL, typically its IP address.
+	TLSConfig *tls.Config
+
+	mu   sync.Mutex
+	idle map[string][]idleDNSConn // idle DNS over TLS connections, by address
+}
+
+type idleDNSConn struct {
+	conn  *tls.Conn
+	since time.Time
+}
+
+const (
+	dnsMessageType = "application/dns-message"
+
+	// maxDNSMessageSize is the maximum size of a DNS message sent
+	// over TLS, whose length is prefixed as a 16-bit integer.
+	maxDNSMessageSize = 65535
+
+	// maxIdleDNSConns is the maximum number of idle DNS over TLS
+	// connections to each server.
+	maxIdleDNSConns = 2
+
+	// idleDNSConnTimeout is how long a DNS over TLS connection may
+	// stay idle before it is closed instead of used again. Servers
+	// usually close idle connections after about 10 seconds.
+	idleDNSConnTimeout = 8 * time.Second
+)
+
+// Exchange sends the DNS query message query to server and returns the
+// response message. The server is a URL: tls://host:port for DNS over
+// TLS, or an https URL for DNS over HTTPS, to which Exchange sends a
+// POST request.
+func (c *DNSClient) Exchange(ctx context.Context, server string, query []byte) ([]byte, error) {
+	if len(query) > maxDNSMessageSize {
+		return nil, errors.New("http: DNS query too large")
+	}
+	if addr, ok := strings.CutPrefix(server, "tls://"); ok {
+		return c.exchangeTLS(ctx, addr, query)
+	}
+	if strings.HasPrefix(server, "https://") {
+		return c.exchangeHTTPS(ctx, server, query)
+	}
+	return nil, fmt.Errorf("http: unsupported DNS server %q", server)
+}
+
+func (c *DNSClient) exchangeHTTPS(ctx context.Context, server string, query []byte) ([]byte, error) {
+	req, err := NewRequestWithContext(ctx, "POST", server, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dnsMessageType)
+	req.Header.Set("Accept", dnsMessageType)
+	client := c.Client
+	if client == nil {
+		client = DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != StatusOK {
+		return nil, fmt.Errorf("http: DNS server %s returned %s", server, resp.Status)
+	}
+	if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct != dnsMessageType {
+		return nil, fmt.Errorf("http: DNS server %s returned unexpected Content-Type %q", server, ct)
+	}
+	msg, err := io.ReadAll(io.LimitReader(resp.Body, maxDNSMessageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) > maxDNSMessageSize {
+		return nil, fmt.Errorf("http: DNS server %s returned a too large response", server)
+	}
+	return msg, nil
+}
+
+func (c *DNSClient) exchangeTLS(ctx context.Context, addr string, query []byte) ([]byte, error) {
+	msg := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(msg, uint16(len(query)))
+	copy(msg[2:], query)
+	if conn := c.getIdleConn(addr); conn != nil {
+		resp, err := dnsRoundTrip(ctx, conn, msg)
+		if err == nil {
+			c.putIdleConn(addr, conn)
+			return resp, nil
+		}
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// The server may have closed the idle connection: try
+		// once more, with a new one.
+	}
+	conn, err := c.dialTLS(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := dnsRoundTrip(ctx, conn, msg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c.putIdleConn(addr, conn)
+	return resp, nil
+}
+
+func (c *DNSClient) dialTLS(ctx context.Context, addr string) (*tls.Conn, error) {
+	config := c.TLSConfig.Clone()
+	if config == nil {
+		config = new(tls.Config)
+	}
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		config.ServerName = host
+	}
+	d := tls.Dialer{Config: config}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*tls.Conn), nil
+}
+
+// dnsRoundTrip sends the length-prefixed DNS message msg on conn and
+// returns the response.
+func dnsRoundTrip(ctx context.Context, conn *tls.Conn, msg []byte) ([]byte, error) {
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(aLongTimeAgo)
+	})
+	resp, err := func() ([]byte, error) {
+		if _, err := conn.Write(msg); err != nil {
+			return nil, err
+		}
+		var n [2]byte
+		if _, err := io.ReadFull(conn, n[:]); err != nil {
+			return nil, err
+		}
+		resp := make([]byte, binary.BigEndian.Uint16(n[:]))
+		if _, err := io.ReadFull(conn, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}()
+	if !stop() {
+		// ctx is done, and conn may have a deadline in the past.
+		return nil, ctx.Err()
+	}
+	return resp, err
+}
+
+// getIdleConn returns an idle connection to addr, or nil if there is
+// none.
+func (c *DNSClient) getIdleConn(addr string) *tls.Conn {
+	var stale []idleDNSConn
+	defer func() {
+		for _, ic := range stale {
+			ic.conn.Close()
+		}
+	}()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	conns := c.idle[addr]
+	for len(conns) > 0 {
+		ic := conns[len(conns)-1]
+		conns = conns[:len(conns)-1]
+		if time.Since(ic.since) < idleDNSConnTimeout {
+			c.idle[addr] = conns
+			return ic.conn
+		}
+		stale = append(stale, ic)
+	}
+	delete(c.idle, addr)
+	return nil
+}
+
+// putIdleConn keeps conn to addr for later queries.
+func (c *DNSClient) putIdleConn(addr string, conn *tls.Conn) {
+	c.mu.Lock()
+	if len(c.idle[addr]) < maxIdleDNSConns {
+		if c.idle == nil {
+			c.idle = make(map[string][]idleDNSConn)
+		}
+		c.idle[addr] = append(c.idle[addr], idleDNSConn{conn, time.Now()})
+		conn = nil
+	}
+	c.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// CloseIdleConnections closes the DNS over TLS connections that c keeps
+// open, and the idle connections of c.Client if it is not nil.
+func (c *DNSClient) CloseIdleConnections() {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.mu.Unlock()
+	for _, conns := range idle {
+		for _, ic := range conns {
+			ic.conn.Close()
+		}
+	}
+	if c.Client != nil {
+		c.Client.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"io"
+	. "net/http"
+	"net/http/httptest"
+	"net/http/internal/testcert"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// dnsResponse returns a fake response to the DNS query q: q with the
+// QR bit set.
+func dnsResponse(q []byte) []byte {
+	r := bytes.Clone(q)
+	r[2] |= 0x80
+	return r
+}
+
+var testDNSQuery = []byte{0x12, 0x34, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1}
+
+func TestDNSClientHTTPS(t *testing.T) {
+	ts := httptest.NewTLSServer(HandlerFunc(func(w ResponseWriter, r *Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/dns-message" {
+			t.Errorf("unexpected request %s %s with Content-Type %q", r.Method, r.URL, r.Header.Get("Content-Type"))
+		}
+		q, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(dnsResponse(q))
+	}))
+	defer ts.Close()
+
+	c := &DNSClient{Client: ts.Client()}
+	defer c.CloseIdleConnections()
+	for range 3 {
+		resp, err := c.Exchange(context.Background(), ts.URL+"/dns-query", testDNSQuery)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := dnsResponse(testDNSQuery); !bytes.Equal(resp, want) {
+			t.Fatalf("Exchange = %x, want %x", resp, want)
+		}
+	}
+
+	if _, err := c.Exchange(context.Background(), ts.URL+"/other", testDNSQuery); err != nil {
+		t.Errorf("Exchange with another path: %v", err)
+	}
+	if _, err := c.Exchange(context.Background(), "http://"+ts.Listener.Addr().String(), testDNSQuery); err == nil {
+		t.Errorf("Exchange with an http URL succeeded")
+	}
+}
+
+func TestDNSClientHTTPSError(t *testing.T) {
+	ts := httptest.NewTLSServer(HandlerFunc(func(w ResponseWriter, r *Request) {
+		switch r.URL.Path {
+		case "/text":
+			w.Write([]byte("not a DNS message"))
+		default:
+			Error(w, "bad", StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	c := &DNSClient{Client: ts.Client()}
+	defer c.CloseIdleConnections()
+	for _, path := range []string{"/text", "/error"} {
+		if resp, err := c.Exchange(context.Background(), ts.URL+path, testDNSQuery); err == nil {
+			t.Errorf("Exchange with %s = %x, want error", path, resp)
+		}
+	}
+}
+
+// newDNSOverTLSServer returns the address of a DNS over TLS server that
+// answers queries with dnsResponse, and the number of connections it
+// accepted. If perConn is positive, the server closes each connection
+// after answering perConn queries.
+func newDNSOverTLSServer(t *testing.T, perConn int) (string, *atomic.Int32) {
+	cert, err := tls.X509KeyPair(testcert.LocalhostCert, testcert.LocalhostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	var conns atomic.Int32
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns.Add(1)
+			go func() {
+				defer c.Close()
+				for i := 0; perConn <= 0 || i < perConn; i++ {
+					var n [2]byte
+					if _, err := io.ReadFull(c, n[:]); err != nil {
+						return
+					}
+					q := make([]byte, binary.BigEndian.Uint16(n[:]))
+					if _, err := io.ReadFull(c, q); err != nil {
+						return
+					}
+					c.Write(append(n[:], dnsResponse(q)...))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String(), &conns
+}
+
+func testDNSClientTLSConfig(t *testing.T) *tls.Config {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(testcert.LocalhostCert) {
+		t.Fatal("bad test certificate")
+	}
+	return &tls.Config{RootCAs: roots}
+}
+
+func TestDNSClientTLS(t *testing.T) {
+	addr, conns := newDNSOverTLSServer(t, 0)
+	c := &DNSClient{TLSConfig: testDNSClientTLSConfig(t)}
+	defer c.CloseIdleConnections()
+	for range 3 {
+		resp, err := c.Exchange(context.Background(), "tls://"+addr, testDNSQuery)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := dnsResponse(testDNSQuery); !bytes.Equal(resp, want) {
+			t.Fatalf("Exchange = %x, want %x", resp, want)
+		}
+	}
+	if n := conns.Load(); n != 1 {
+		t.Errorf("server accepted %d connections, want 1", n)
+	}
+
+	// The certificate is valid for 127.0.0.1, not for the name of
+	// another server.
+	c2 := &DNSClient{TLSConfig: testDNSClientTLSConfig(t)}
+	c2.TLSConfig.ServerName = "dns.invalid"
+	if _, err := c2.Exchange(context.Background(), "tls://"+addr, testDNSQuery); err == nil {
+		t.Errorf("Exchange with a wrong server name succeeded")
+	}
+}
+
+func TestDNSClientTLSClosedConn(t *testing.T) {
+	addr, conns := newDNSOverTLSServer(t, 1)
+	c := &DNSClient{TLSConfig: testDNSClientTLSConfig(t)}
+	defer c.CloseIdleConnections()
+
+	// The idle connections that the server closed are replaced.
+	for range 3 {
+		if _, err := c.Exchange(context.Background(), "tls://"+addr, testDNSQuery); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := conns.Load(); n != 3 {
+		t.Errorf("server accepted %d connections, want 3", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Exchange(ctx, "tls://"+addr, testDNSQuery); err == nil {
+		t.Errorf("Exchange with a canceled context succeeded")
+	}
+}
+
+func TestDNSClientTLSRetryOnce(t *testing.T) {
+	addr, conns := newDNSOverTLSServer(t, 1)
+	c := &DNSClient{TLSConfig: testDNSClientTLSConfig(t)}
+	defer c.CloseIdleConnections()
+
+	// Concurrent exchanges leave two idle connections, which the
+	// server closes.
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() {
+			if _, err := c.Exchange(context.Background(), "tls://"+addr, testDNSQuery); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := c.IdleConnCountForTesting(addr); n != 2 {
+		t.Skipf("got %d idle connections, want 2", n)
+	}
+
+	// After one of them fails, the exchange uses a new connection
+	// rather than the other one.
+	if _, err := c.Exchange(context.Background(), "tls://"+addr, testDNSQuery); err != nil {
+		t.Fatal(err)
+	}
+	if n := conns.Load(); n != 3 {
+		t.Errorf("server accepted %d connections, want 3", n)
+	}
+	if n := c.IdleConnCountForTesting(addr); n != 2 {
+		t.Errorf("got %d idle connections after the retry, want 2", n)
+	}
+}
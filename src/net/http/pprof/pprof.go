@@ -36,6 +36,8 @@
 //   - gc=N (heap profile): N > 0: run a garbage collection cycle before profiling
 //   - seconds=N (allocs, block, goroutine, heap, mutex, threadcreate profiles): return a delta profile
 //   - seconds=N (cpu (profile), trace profiles): profile for the given duration
+//   - format=json (goroutine profile): return the goroutines described by
+//     [runtime/pprof.Goroutines] as a JSON array
 //
 // # Usage examples
 //
@@ -57,6 +59,11 @@
 //
 //	go tool pprof http://localhost:6060/debug/pprof/mutex
 //
+// Or to get the stacks, states, and labels of all goroutines in a
+// machine-readable form:
+//
+//	curl http://localhost:6060/debug/pprof/goroutine?format=json
+//
 // The package also exports a handler that serves execution trace data
 // for the "go tool trace" command. To collect a 5-second execution trace:
 //
@@ -74,6 +81,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"html"
 	"internal/godebug"
@@ -255,6 +263,10 @@ func (name handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		serveError(w, http.StatusNotFound, "Unknown profile")
 		return
 	}
+	if format := r.FormValue("format"); format != "" {
+		name.serveFormat(w, r, format)
+		return
+	}
 	if sec := r.FormValue("seconds"); sec != "" {
 		name.serveDeltaProfile(w, r, p, sec)
 		return
@@ -273,6 +285,27 @@ func (name handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	p.WriteTo(w, debug)
 }
 
+// serveFormat serves the profile in an alternative format. The only one
+// supported is format=json for the goroutine profile, which serves the
+// goroutines reported by [runtime/pprof.Goroutines].
+func (name handler) serveFormat(w http.ResponseWriter, r *http.Request, format string) {
+	if name != "goroutine" || format != "json" {
+		serveError(w, http.StatusBadRequest, fmt.Sprintf("format %q is not supported for this profile type", format))
+		return
+	}
+	if r.FormValue("seconds") != "" || r.FormValue("debug") != "" {
+		serveError(w, http.StatusBadRequest, "format is incompatible with the seconds and debug params")
+		return
+	}
+	data, err := json.Marshal(pprof.Goroutines())
+	if err != nil {
+		serveError(w, http.StatusInternalServerError, "failed to encode goroutines")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
 func (name handler) serveDeltaProfile(w http.ResponseWriter, r *http.Request, p *pprof.Profile, secStr string) {
 	sec, err := strconv.ParseInt(secStr, 10, 64)
 	if err != nil || sec <= 0 {
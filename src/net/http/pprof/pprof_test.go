@@ -7,6 +7,7 @@ package pprof
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"internal/profile"
 	"internal/testenv"
@@ -53,6 +54,10 @@ func TestHandlers(t *testing.T) {
 		{"/debug/pprof/mutex", Index, http.StatusOK, "application/octet-stream", `attachment; filename="mutex"`, nil},
 		{"/debug/pprof/block?seconds=1", Index, http.StatusOK, "application/octet-stream", `attachment; filename="block-delta"`, nil},
 		{"/debug/pprof/goroutine?seconds=1", Index, http.StatusOK, "application/octet-stream", `attachment; filename="goroutine-delta"`, nil},
+		{"/debug/pprof/goroutine?format=json", Index, http.StatusOK, "application/json", "", nil},
+		{"/debug/pprof/goroutine?format=xml", Index, http.StatusBadRequest, "text/plain; charset=utf-8", "", []byte("format \"xml\" is not supported for this profile type\n")},
+		{"/debug/pprof/heap?format=json", Index, http.StatusBadRequest, "text/plain; charset=utf-8", "", []byte("format \"json\" is not supported for this profile type\n")},
+		{"/debug/pprof/goroutine?format=json&debug=1", Index, http.StatusBadRequest, "text/plain; charset=utf-8", "", []byte("format is incompatible with the seconds and debug params\n")},
 		{"/debug/pprof/", Index, http.StatusOK, "text/html; charset=utf-8", "", []byte("Types of profiles available:")},
 	}
 	for _, tc := range testCases {
@@ -93,6 +98,31 @@ func TestHandlers(t *testing.T) {
 	}
 }
 
+func TestGoroutinesJSON(t *testing.T) {
+	c := make(chan struct{})
+	defer close(c)
+	go func() { <-c }()
+
+	req := httptest.NewRequest("GET", "http://example.com/debug/pprof/goroutine?format=json", nil)
+	w := httptest.NewRecorder()
+	Index(w, req)
+	var gs []pprof.Goroutine
+	if err := json.Unmarshal(w.Body.Bytes(), &gs); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(gs) < 2 {
+		t.Fatalf("got %d goroutines, want at least 2", len(gs))
+	}
+	for _, g := range gs {
+		if g.ID == 0 || g.State == "" {
+			t.Errorf("goroutine %+v has no ID or state", g)
+		}
+	}
+	if len(gs[0].Stack) == 0 || gs[0].Stack[0].Function != "runtime/pprof.Goroutines" || gs[0].Stack[0].Line == 0 {
+		t.Errorf("stack of the serving goroutine = %+v, want to start with runtime/pprof.Goroutines", gs[0].Stack)
+	}
+}
+
 var Sink uint32
 
 func mutexHog1(mu1, mu2 *sync.Mutex, start time.Time, dt time.Duration) {
@@ -160,6 +160,47 @@ type Resolver struct {
 	// If nil, the default dialer is used.
 	Dial func(ctx context.Context, network, address string) (Conn, error)
 
+	// Exchange optionally specifies a function for use by Go's
+	// built-in DNS resolver to exchange DNS messages with DNS services
+	// over encrypted transports. It sends the DNS query message query
+	// to server and returns the response message. The server parameter
+	// is a URL: tls://host:port for DNS over TLS (RFC 7858), or an
+	// https URL for DNS over HTTPS (RFC 8484). Its host is always a
+	// literal IP address.
+	//
+	// The resolver uses Exchange, rather than Dial, for all its queries
+	// if the system configuration asks for an encrypted transport; see
+	// the package documentation. If Exchange is nil, the resolver
+	// ignores that configuration and sends its queries in cleartext.
+	// The net/http package's DNSClient implements Exchange.
+	Exchange func(ctx context.Context, server string, query []byte) (response []byte, err error)
+
+	// ValidateDNSSEC optionally specifies a function that validates
+	// the DNS responses received by Go's built-in DNS resolver with
+	// DNSSEC (RFC 4035). If it is set, the resolver is always used
+	// instead of the C library resolver, and its queries ask for the
+	// DNSSEC records.
+	//
+	// ValidateDNSSEC is called with each response message. It may send
+	// other queries, such as queries for the DNSKEY and DS records of
+	// the zones of the response, with query, which returns the
+	// response message of the resolver's DNS servers without
+	// validating it. ValidateDNSSEC returns the status of the records
+	// of the response. The resolver doesn't use responses whose status
+	// is DNSSECBogus, or for which ValidateDNSSEC returns an error.
+	// The status of lookups is reported by methods such as
+	// [Resolver.LookupHostDNSSEC].
+	//
+	// The net/dnssec package's Validator implements ValidateDNSSEC.
+	ValidateDNSSEC func(ctx context.Context, response []byte, query func(ctx context.Context, name string, qtype uint16) ([]byte, error)) (DNSSECStatus, error)
+
+	// MulticastInterface optionally specifies the network interface
+	// on which Go's built-in DNS resolver sends multicast DNS queries
+	// for names in the .local domain, and on which
+	// [Resolver.BrowseServices] browses for services.
+	// If nil, the system-assigned multicast interface is used.
+	MulticastInterface *Interface
+
 	// lookupGroup merges LookupIPAddr calls together for lookups for the same
 	// host. The lookupGroup key is the LookupIPAddr.host argument.
 	// The return values are ([]IPAddr, error).
@@ -330,9 +371,22 @@ func (r *Resolver) lookupIPAddr(ctx context.Context, network, host string) ([]IP
 	lookupGroupCtx, lookupGroupCancel := context.WithCancel(withUnexpiredValuesPreserved(ctx))
 
 	lookupKey := network + "\000" + host
+	// The DNSSEC status of a lookup is collected through its context,
+	// which only the first of the merged lookups passes on. So lookups
+	// that collect it are merged separately, and collect it in a
+	// result that every one of them copies.
+	dnssecRes, _ := ctx.Value(dnssecResultKey{}).(*dnssecResult)
+	if dnssecRes != nil {
+		lookupKey += "\000dnssec"
+	}
 	dnsWaitGroup.Add(1)
 	ch := r.getLookupGroup().DoChan(lookupKey, func() (any, error) {
-		return testHookLookupIP(lookupGroupCtx, resolverFunc, network, host)
+		if dnssecRes == nil {
+			return testHookLookupIP(lookupGroupCtx, resolverFunc, network, host)
+		}
+		shared := new(dnssecResult)
+		addrs, err := testHookLookupIP(context.WithValue(lookupGroupCtx, dnssecResultKey{}, shared), resolverFunc, network, host)
+		return dnssecAddrs{addrs, shared}, err
 	})
 
 	dnsWaitGroupDone := func(ch <-chan singleflight.Result, cancelFn context.CancelFunc) {
@@ -363,6 +417,10 @@ func (r *Resolver) lookupIPAddr(ctx context.Context, network, host string) ([]IP
 	case r := <-ch:
 		dnsWaitGroup.Done()
 		lookupGroupCancel()
+		if v, ok := r.Val.(dnssecAddrs); ok {
+			dnssecRes.merge(v.res)
+			r.Val = v.addrs
+		}
 		err := r.Err
 		if err != nil {
 			if _, ok := err.(*DNSError); !ok {
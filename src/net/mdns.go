@@ -0,0 +1,240 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Multicast DNS (RFC 6762).
+
+package net
+
+import (
+	"context"
+	"internal/stringslite"
+	"net/netip"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	mdnsPort = 5353
+
+	// mdnsMaxPacketSize is the largest multicast DNS message
+	// (RFC 6762, Section 17).
+	mdnsMaxPacketSize = 9000
+
+	// mdnsClassBit is the top bit of the class of a question or a
+	// resource record. In a question it asks for a unicast response
+	// (RFC 6762, Section 5.4), and in a record it marks the record
+	// as unique to its responder (Section 10.2).
+	mdnsClassBit = 1 << 15
+)
+
+var (
+	mdnsGroup4 = IPv4(224, 0, 0, 251)
+	mdnsGroup6 = IP{0xff, 0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xfb}
+)
+
+// mdnsTimeout is how long one-shot queries wait for responses.
+// It is a variable for testing.
+var mdnsTimeout = 1 * time.Second
+
+// isMDNSName reports whether name is in the .local domain, whose names
+// are looked up with multicast DNS (RFC 6762, Section 3).
+func isMDNSName(name string) bool {
+	return stringsHasSuffixFold(stringslite.TrimSuffix(name, "."), ".local")
+}
+
+// mdnsGroups returns the multicast DNS group addresses on ifi.
+func mdnsGroups(ifi *Interface) []*UDPAddr {
+	var zone string
+	if ifi != nil {
+		zone = ifi.Name
+	}
+	return []*UDPAddr{
+		{IP: mdnsGroup4, Port: mdnsPort},
+		{IP: mdnsGroup6, Port: mdnsPort, Zone: zone},
+	}
+}
+
+// mdnsNetwork returns the network of the multicast DNS group g.
+func mdnsNetwork(g *UDPAddr) string {
+	if g.IP.To4() != nil {
+		return "udp4"
+	}
+	return "udp6"
+}
+
+// mdnsReceive reads multicast DNS messages from c and calls fn with
+// each of them and its source, until reading fails.
+func mdnsReceive(c *UDPConn, fn func(m *dnsmessage.Message, from netip.AddrPort)) {
+	b := make([]byte, mdnsMaxPacketSize)
+	for {
+		n, from, err := c.ReadFromUDPAddrPort(b)
+		if err != nil {
+			return
+		}
+		var m dnsmessage.Message
+		if m.Unpack(b[:n]) != nil {
+			continue
+		}
+		fn(&m, from)
+	}
+}
+
+// mdnsQuery sends a one-shot multicast DNS query (RFC 6762, Section
+// 5.1) with the questions qs on the interface ifi, or on the
+// system-assigned multicast interface if ifi is nil. It calls fn with
+// each response until fn returns true, mdnsTimeout elapses, or the
+// deadline of ctx passes. It returns an error only if the query can't
+// be sent or ctx is canceled.
+func mdnsQuery(ctx context.Context, ifi *Interface, qs []dnsmessage.Question, fn func(m *dnsmessage.Message) bool) error {
+	q := dnsmessage.Message{Questions: qs}
+	b, err := q.Pack()
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(mdnsTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	responses := make(chan *dnsmessage.Message)
+	done := make(chan struct{})
+	var conns []*UDPConn
+	var wg sync.WaitGroup
+	defer func() {
+		close(done)
+		for _, c := range conns {
+			c.Close()
+		}
+		wg.Wait()
+	}()
+	var firstErr error
+	for _, g := range mdnsGroups(ifi) {
+		c, err := ListenUDP(mdnsNetwork(g), &UDPAddr{})
+		if err == nil && ifi != nil {
+			err = setMulticastInterface(c, g.IP.To4() == nil, ifi)
+		}
+		if err == nil {
+			_, err = c.WriteTo(b, g)
+		}
+		if err != nil {
+			if c != nil {
+				c.Close()
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		conns = append(conns, c)
+		wg.Go(func() {
+			mdnsReceive(c, func(m *dnsmessage.Message, from netip.AddrPort) {
+				// Responses must come from the multicast DNS port
+				// (RFC 6762, Section 6), and responses with other
+				// codes than success must be ignored (Section 18.11).
+				if from.Port() != mdnsPort || !m.Response || m.RCode != dnsmessage.RCodeSuccess {
+					return
+				}
+				select {
+				case responses <- m:
+				case <-done:
+				}
+			})
+		})
+	}
+	if len(conns) == 0 {
+		return firstErr
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		select {
+		case m := <-responses:
+			if fn(m) {
+				return nil
+			}
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			if err := ctx.Err(); err != context.DeadlineExceeded {
+				return mapErr(err)
+			}
+			return nil
+		}
+	}
+}
+
+// mdnsLookupIP looks up the addresses of name, in the .local domain,
+// with multicast DNS on the interface ifi.
+func mdnsLookupIP(ctx context.Context, ifi *Interface, network, name string) ([]IPAddr, dnsmessage.Name, error) {
+	fqdn := name
+	if !stringslite.HasSuffix(fqdn, ".") {
+		fqdn += "."
+	}
+	qname, err := dnsmessage.NewName(fqdn)
+	if err != nil {
+		return nil, dnsmessage.Name{}, newDNSError(errCannotMarshalDNSMessage, name, "")
+	}
+	qtypes := []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA}
+	switch ipVersion(network) {
+	case '4':
+		qtypes = qtypes[:1]
+	case '6':
+		qtypes = qtypes[1:]
+	}
+	var qs []dnsmessage.Question
+	for _, qtype := range qtypes {
+		qs = append(qs, dnsmessage.Question{Name: qname, Type: qtype, Class: dnsmessage.ClassINET})
+	}
+
+	var addrs []IPAddr
+	err = mdnsQuery(ctx, ifi, qs, func(m *dnsmessage.Message) bool {
+		for _, rr := range m.Answers {
+			if rr.Header.Class&^mdnsClassBit != dnsmessage.ClassINET || !stringsEqualFold(rr.Header.Name.String(), fqdn) {
+				continue
+			}
+			var ip IP
+			switch b := rr.Body.(type) {
+			case *dnsmessage.AResource:
+				if ipVersion(network) == '6' {
+					continue
+				}
+				ip = IP(b.A[:])
+			case *dnsmessage.AAAAResource:
+				if ipVersion(network) == '4' {
+					continue
+				}
+				ip = IP(b.AAAA[:])
+			default:
+				continue
+			}
+			if !ipAddrsContain(addrs, ip) {
+				addrs = append(addrs, IPAddr{IP: ip})
+			}
+		}
+		// Responders answer with all the addresses of a
+		// name at once (RFC 6762, Section 6.2).
+		return len(addrs) > 0
+	})
+	if err != nil {
+		return nil, dnsmessage.Name{}, newDNSError(err, name, "")
+	}
+	if len(addrs) == 0 {
+		return nil, dnsmessage.Name{}, newDNSError(errNoSuchHost, name, "")
+	}
+	sortByRFC6724(addrs)
+	return addrs, qname, nil
+}
+
+func ipAddrsContain(addrs []IPAddr, ip IP) bool {
+	for _, a := range addrs {
+		if a.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
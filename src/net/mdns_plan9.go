@@ -0,0 +1,11 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import "syscall"
+
+func setMulticastInterface(c *UDPConn, ipv6 bool, ifi *Interface) error {
+	return syscall.EPLAN9
+}
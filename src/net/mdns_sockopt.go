@@ -0,0 +1,16 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+
+package net
+
+// setMulticastInterface sets the interface on which c sends multicast
+// datagrams.
+func setMulticastInterface(c *UDPConn, ipv6 bool, ifi *Interface) error {
+	if ipv6 {
+		return setIPv6MulticastInterface(c.fd, ifi)
+	}
+	return setIPv4MulticastInterface(c.fd, ifi)
+}
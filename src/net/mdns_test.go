@@ -0,0 +1,159 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestIsMDNSName(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want bool
+	}{
+		{"printer.local", true},
+		{"printer.local.", true},
+		{"Printer.LOCAL", true},
+		{"a.b.local.", true},
+		{"local", false},
+		{"local.", false},
+		{"printer.locals", false},
+		{"local.go.dev", false},
+		{"go.dev", false},
+	} {
+		if got := isMDNSName(tt.name); got != tt.want {
+			t.Errorf("isMDNSName(%q) = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServiceTypeName(t *testing.T) {
+	for _, tt := range []struct {
+		service string
+		want    string
+	}{
+		{"_ipp._tcp", "_ipp._tcp.local."},
+		{"_ipp._tcp.", "_ipp._tcp.local."},
+		{"_sleep-proxy._udp", "_sleep-proxy._udp.local."},
+		{"ipp._tcp", ""},
+		{"_ipp._sctp", ""},
+		{"_ipp", ""},
+		{"_._tcp", ""},
+		{"_this-is-too-long._tcp", ""},
+	} {
+		n, err := serviceTypeName(tt.service)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("serviceTypeName(%q) = %v; want error", tt.service, n)
+			}
+			continue
+		}
+		if err != nil || n.String() != tt.want {
+			t.Errorf("serviceTypeName(%q) = %v, %v; want %v", tt.service, n, err, tt.want)
+		}
+	}
+}
+
+// mdnsLoopback returns the loopback interface if multicast DNS works
+// on it, and otherwise skips the test. It shortens the timing of
+// multicast DNS for the test.
+func mdnsLoopback(t *testing.T) *Interface {
+	switch runtime.GOOS {
+	case "android", "js", "plan9", "wasip1", "windows":
+		t.Skipf("not supported on %s", runtime.GOOS)
+	}
+	if !supportsIPv4() {
+		t.Skip("IPv4 is not supported")
+	}
+	ifi := loopbackInterface()
+	if ifi == nil {
+		t.Skip("loopback interface not found")
+	}
+	c, err := ListenMulticastUDP("udp4", ifi, &UDPAddr{IP: mdnsGroup4, Port: mdnsPort})
+	if err != nil {
+		t.Skipf("multicast DNS is not supported on %s: %v", ifi.Name, err)
+	}
+	c.Close()
+
+	timeout, probe := mdnsTimeout, mdnsProbeInterval
+	mdnsTimeout, mdnsProbeInterval = 200*time.Millisecond, 20*time.Millisecond
+	t.Cleanup(func() {
+		mdnsTimeout, mdnsProbeInterval = timeout, probe
+	})
+	return ifi
+}
+
+func TestServiceDiscovery(t *testing.T) {
+	ifi := mdnsLoopback(t)
+	ctx := context.Background()
+
+	host := fmt.Sprintf("gotest-%d.local.", os.Getpid())
+	s := &ServiceInstance{
+		Instance: fmt.Sprintf("Go Test %d", os.Getpid()),
+		Service:  "_gotest._tcp",
+		Host:     host,
+		Port:     8080,
+		Text:     []string{"path=/", "v=1"},
+		Addrs:    []netip.Addr{netip.MustParseAddr("127.0.0.1")},
+	}
+	reg, err := RegisterService(ifi, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Close()
+
+	r := &Resolver{PreferGo: true, MulticastInterface: ifi}
+	insts, err := r.BrowseServices(ctx, s.Service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []*ServiceInstance{s}; !reflect.DeepEqual(insts, want) {
+		t.Errorf("BrowseServices(%q) = %v; want %v", s.Service, insts, want)
+	}
+
+	addrs, _, err := r.goLookupIPCNAMEOrder(ctx, "ip", host, hostLookupFilesDNS, nil)
+	if err != nil || len(addrs) != 1 || !addrs[0].IP.Equal(IPv4(127, 0, 0, 1)) {
+		t.Errorf("looking up %s = %v, %v; want [127.0.0.1]", host, addrs, err)
+	}
+
+	// Another instance can't take the name.
+	dup := *s
+	dup.Port = 8081
+	if reg, err := RegisterService(ifi, &dup); !errors.Is(err, errServiceConflict) {
+		if err == nil {
+			reg.Close()
+		}
+		t.Errorf("RegisterService of a duplicate instance = %v; want %v", err, errServiceConflict)
+	}
+
+	if err := reg.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if insts, err := r.BrowseServices(ctx, s.Service); err != nil || len(insts) != 0 {
+		t.Errorf("BrowseServices(%q) after Close = %v, %v; want no instances", s.Service, insts, err)
+	}
+}
+
+func TestRegisterServiceErrors(t *testing.T) {
+	for _, s := range []*ServiceInstance{
+		{Instance: "Go Test", Service: "gotest"},
+		{Instance: "", Service: "_gotest._tcp"},
+		{Instance: "go.test", Service: "_gotest._tcp"},
+		{Instance: "Go Test", Service: "_gotest._tcp", Port: 70000},
+	} {
+		if reg, err := RegisterService(nil, s); err == nil {
+			reg.Close()
+			t.Errorf("RegisterService(%+v) succeeded; want error", s)
+		}
+	}
+}
@@ -79,6 +79,36 @@ to print debugging information about its decisions.
 To force a particular resolver while also printing debugging information,
 join the two settings by a plus sign, as in GODEBUG=netdns=go+1.
 
+The Go resolver honors two options of /etc/resolv.conf that other
+resolvers ignore, for the lookups of a [Resolver] with an Exchange
+function, which the net/http package's DNSClient implements. The
+"dns-over-tls" option makes it send the queries to the name servers
+with DNS over TLS (RFC 7858), on port 853, using Exchange. The
+"dns-over-https" option makes it send them with DNS over HTTPS
+(RFC 8484), to the URL path /dns-query of the name servers, or to the
+path given after a colon, as in "dns-over-https:/resolve". With either
+option, such a Resolver uses the Go resolver even if other lines of
+/etc/resolv.conf or /etc/nsswitch.conf specify features that it does
+not implement. The options have no effect on the lookups of a Resolver
+without an Exchange function, including [DefaultResolver], which send
+the queries in cleartext as usual.
+
+The Go resolver looks up names in the .local domain with multicast DNS
+(RFC 6762) and with the name servers at the same time, and uses the
+first answer with addresses. It doesn't send multicast queries, which
+can't be encrypted, if it sends its queries to the name servers with
+an encrypted transport. Setting GODEBUG=netmdns=0 makes it look up
+.local names with the name servers only. Services on the local link
+can be found and advertised with DNS-Based Service Discovery (RFC 6763)
+by [Resolver.BrowseServices] and [RegisterService].
+
+The Go resolver can validate DNS responses with DNSSEC: a [Resolver]
+whose ValidateDNSSEC function is set, such as one using the net/dnssec
+package, always uses the Go resolver and reports the validation status
+of the records through methods such as [Resolver.LookupHostDNSSEC].
+Without such a function, the status reflects the AD bit of the
+responses if /etc/resolv.conf has the "trust-ad" option.
+
 The Go resolver will send an EDNS0 additional header with a DNS request,
 to signal a willingness to accept a larger DNS packet size.
 This can reportedly cause sporadic failures with the DNS server run
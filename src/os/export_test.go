@@ -16,3 +16,11 @@ func init() {
 }
 
 var ExportReadFileContents = readFileContents
+
+var WatchPollInterval = &watchPollInterval
+
+// NewPollWatcher returns a Watcher that polls the watched files, even
+// where a native implementation is available.
+func NewPollWatcher() *Watcher {
+	return startWatcher(newPollWatcher())
+}
@@ -742,7 +742,9 @@ func (f *File) Fd() uintptr {
 // The directory dir must not be "".
 //
 // The result implements [io/fs.StatFS], [io/fs.ReadFileFS], [io/fs.ReadDirFS], and
-// [io/fs.ReadLinkFS].
+// [io/fs.ReadLinkFS]. It also implements the interfaces of package fs for
+// writing files: [io/fs.OpenFileFS], [io/fs.CreateFS], [io/fs.WriteFileFS],
+// [io/fs.MkdirFS], [io/fs.RemoveFS], and [io/fs.RenameFS].
 func DirFS(dir string) fs.FS {
 	return dirFS(dir)
 }
@@ -751,6 +753,12 @@ var _ fs.StatFS = dirFS("")
 var _ fs.ReadFileFS = dirFS("")
 var _ fs.ReadDirFS = dirFS("")
 var _ fs.ReadLinkFS = dirFS("")
+var _ fs.OpenFileFS = dirFS("")
+var _ fs.CreateFS = dirFS("")
+var _ fs.WriteFileFS = dirFS("")
+var _ fs.MkdirFS = dirFS("")
+var _ fs.RemoveFS = dirFS("")
+var _ fs.RenameFS = dirFS("")
 
 type dirFS string
 
@@ -845,6 +853,94 @@ func (dir dirFS) ReadLink(name string) (string, error) {
 	return Readlink(fullname)
 }
 
+// OpenFile opens the named file in the directory as [OpenFile] does.
+// Through this method, dirFS implements [io/fs.OpenFileFS].
+func (dir dirFS) OpenFile(name string, flag int, perm FileMode) (fs.File, error) {
+	fullname, err := dir.join(name)
+	if err != nil {
+		return nil, &PathError{Op: "open", Path: name, Err: err}
+	}
+	f, err := OpenFile(fullname, flag, perm)
+	if err != nil {
+		// See comment in dirFS.Open.
+		err.(*PathError).Path = name
+		return nil, err
+	}
+	return f, nil
+}
+
+// Create creates or truncates the named file in the directory as
+// [Create] does. Through this method, dirFS implements [io/fs.CreateFS].
+func (dir dirFS) Create(name string) (fs.File, error) {
+	return dir.OpenFile(name, O_RDWR|O_CREATE|O_TRUNC, 0666)
+}
+
+// WriteFile writes data to the named file in the directory as
+// [WriteFile] does. Through this method, dirFS implements
+// [io/fs.WriteFileFS].
+func (dir dirFS) WriteFile(name string, data []byte, perm FileMode) error {
+	fullname, err := dir.join(name)
+	if err != nil {
+		return &PathError{Op: "open", Path: name, Err: err}
+	}
+	err = WriteFile(fullname, data, perm)
+	if e, ok := err.(*PathError); ok {
+		// See comment in dirFS.Open.
+		e.Path = name
+	}
+	return err
+}
+
+// Mkdir creates the named directory in the directory as [Mkdir] does.
+// Through this method, dirFS implements [io/fs.MkdirFS].
+func (dir dirFS) Mkdir(name string, perm FileMode) error {
+	fullname, err := dir.join(name)
+	if err != nil {
+		return &PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	err = Mkdir(fullname, perm)
+	if e, ok := err.(*PathError); ok {
+		// See comment in dirFS.Open.
+		e.Path = name
+	}
+	return err
+}
+
+// Remove removes the named file or empty directory in the directory as
+// [Remove] does. Through this method, dirFS implements [io/fs.RemoveFS].
+func (dir dirFS) Remove(name string) error {
+	fullname, err := dir.join(name)
+	if err != nil {
+		return &PathError{Op: "remove", Path: name, Err: err}
+	}
+	err = Remove(fullname)
+	if e, ok := err.(*PathError); ok {
+		// See comment in dirFS.Open.
+		e.Path = name
+	}
+	return err
+}
+
+// Rename renames (moves) oldname to newname in the directory as
+// [Rename] does. Through this method, dirFS implements
+// [io/fs.RenameFS].
+func (dir dirFS) Rename(oldname, newname string) error {
+	fulloldname, err := dir.join(oldname)
+	if err != nil {
+		return &LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	fullnewname, err := dir.join(newname)
+	if err != nil {
+		return &LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	err = Rename(fulloldname, fullnewname)
+	if e, ok := err.(*LinkError); ok {
+		// See comment in dirFS.Open.
+		e.Old, e.New = oldname, newname
+	}
+	return err
+}
+
 // join returns the path for name in dir.
 func (dir dirFS) join(name string) (string, error) {
 	if dir == "" {
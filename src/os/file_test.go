@@ -6,6 +6,7 @@ package os_test
 
 import (
 	"internal/testenv"
+	"io"
 	"io/fs"
 	. "os"
 	"path/filepath"
@@ -154,3 +155,77 @@ func TestDirFSWalkDir(t *testing.T) {
 		}
 	})
 }
+
+func TestDirFSWrite(t *testing.T) {
+	dir := t.TempDir()
+	testWritableFS(t, DirFS(dir), dir)
+}
+
+func TestRootFSWrite(t *testing.T) {
+	dir := t.TempDir()
+	root, err := OpenRoot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+	testWritableFS(t, root.FS(), dir)
+}
+
+// testWritableFS tests the functions of package fs for writing files on
+// fsys, a file system for the directory dir.
+func testWritableFS(t *testing.T, fsys fs.FS, dir string) {
+	if err := fs.MkdirAll(fsys, "a/b", 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile(fsys, "a/b/f", []byte("hello"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.Create(fsys, "a/g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.(io.Writer).Write([]byte("world")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err = fs.OpenFile(fsys, "a/g", O_WRONLY|O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.(io.Writer).Write([]byte("!")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := fs.Rename(fsys, "a/b/f", "a/h"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove(fsys, "a/b"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{"a/g": "world!", "a/h": "hello"} {
+		got, err := ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if string(got) != want || err != nil {
+			t.Errorf("ReadFile(%q) = %q, %v; want %q, <nil>", name, got, err, want)
+		}
+	}
+	if _, err := Stat(filepath.Join(dir, "a", "b")); !IsNotExist(err) {
+		t.Errorf("Stat of removed directory: %v", err)
+	}
+
+	if err := fs.Mkdir(fsys, "a", 0o777); !IsExist(err) {
+		t.Errorf("Mkdir of existing directory: got %v, want ErrExist", err)
+	}
+	if err := fs.Remove(fsys, "a"); err == nil {
+		t.Errorf("Remove of non-empty directory succeeded")
+	}
+	for _, name := range []string{"../x", "/x", "a/../x"} {
+		if err := fs.WriteFile(fsys, name, nil, 0o666); err == nil {
+			t.Errorf("WriteFile(%q) succeeded", name)
+		} else if pe, ok := err.(*PathError); !ok || pe.Path != name {
+			t.Errorf("WriteFile(%q): got error %v, want a *PathError for %q", name, err, name)
+		}
+	}
+}
@@ -353,7 +353,10 @@ func splitPathInRoot(s string, prefix, suffix []string) (_ []string, suffixSep s
 // FS returns a file system (an fs.FS) for the tree of files in the root.
 //
 // The result implements [io/fs.StatFS], [io/fs.ReadFileFS],
-// [io/fs.ReadDirFS], and [io/fs.ReadLinkFS].
+// [io/fs.ReadDirFS], and [io/fs.ReadLinkFS]. It also implements the
+// interfaces of package fs for writing files: [io/fs.OpenFileFS],
+// [io/fs.CreateFS], [io/fs.WriteFileFS], [io/fs.MkdirFS],
+// [io/fs.RemoveFS], and [io/fs.RenameFS].
 func (r *Root) FS() fs.FS {
 	return (*rootFS)(r)
 }
@@ -433,6 +436,54 @@ func (rfs *rootFS) Lstat(name string) (FileInfo, error) {
 	return r.Lstat(name)
 }
 
+func (rfs *rootFS) OpenFile(name string, flag int, perm FileMode) (fs.File, error) {
+	r := (*Root)(rfs)
+	if !isValidRootFSPath(name) {
+		return nil, &PathError{Op: "open", Path: name, Err: ErrInvalid}
+	}
+	f, err := r.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (rfs *rootFS) Create(name string) (fs.File, error) {
+	return rfs.OpenFile(name, O_RDWR|O_CREATE|O_TRUNC, 0666)
+}
+
+func (rfs *rootFS) WriteFile(name string, data []byte, perm FileMode) error {
+	r := (*Root)(rfs)
+	if !isValidRootFSPath(name) {
+		return &PathError{Op: "open", Path: name, Err: ErrInvalid}
+	}
+	return r.WriteFile(name, data, perm)
+}
+
+func (rfs *rootFS) Mkdir(name string, perm FileMode) error {
+	r := (*Root)(rfs)
+	if !isValidRootFSPath(name) {
+		return &PathError{Op: "mkdir", Path: name, Err: ErrInvalid}
+	}
+	return r.Mkdir(name, perm)
+}
+
+func (rfs *rootFS) Remove(name string) error {
+	r := (*Root)(rfs)
+	if !isValidRootFSPath(name) {
+		return &PathError{Op: "remove", Path: name, Err: ErrInvalid}
+	}
+	return r.Remove(name)
+}
+
+func (rfs *rootFS) Rename(oldname, newname string) error {
+	r := (*Root)(rfs)
+	if !isValidRootFSPath(oldname) || !isValidRootFSPath(newname) {
+		return &LinkError{Op: "rename", Old: oldname, New: newname, Err: ErrInvalid}
+	}
+	return r.Rename(oldname, newname)
+}
+
 // isValidRootFSPath reports whether name is a valid filename to pass a Root.FS method.
 func isValidRootFSPath(name string) bool {
 	if !fs.ValidPath(name) {
@@ -0,0 +1,355 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os
+
+import (
+	"cmp"
+	"errors"
+	"internal/filepathlite"
+	"slices"
+	"sync"
+	"time"
+)
+
+// A WatchOp describes a change reported by a [Watcher].
+type WatchOp uint32
+
+const (
+	WatchCreate WatchOp = 1 << iota // a file was created, or renamed to this name
+	WatchWrite                      // a file was written to
+	WatchRemove                     // a file was removed
+	WatchRename                     // a file was renamed from this name
+	WatchChmod                      // the attributes of a file changed
+
+	// WatchOverflow reports that events were lost because too many
+	// changes happened before they could be reported. The event has no
+	// name: the watched files should be inspected again.
+	WatchOverflow
+)
+
+var watchOpNames = [...]string{"Create", "Write", "Remove", "Rename", "Chmod", "Overflow"}
+
+func (op WatchOp) String() string {
+	var b []byte
+	for i, name := range watchOpNames {
+		if op&(1<<i) != 0 {
+			if b != nil {
+				b = append(b, '|')
+			}
+			b = append(b, name...)
+		}
+	}
+	if b == nil {
+		return "0"
+	}
+	return string(b)
+}
+
+// A WatchEvent is a change to a file reported by a [Watcher].
+type WatchEvent struct {
+	// Name is the name of the changed file: either a name passed to
+	// Watcher.Add, or such a name joined with the path of a file
+	// inside the watched directory. It is empty for WatchOverflow.
+	Name string
+
+	Op WatchOp
+}
+
+func (e WatchEvent) String() string {
+	if e.Name == "" {
+		return e.Op.String()
+	}
+	return e.Op.String() + " " + e.Name
+}
+
+// A Watcher reports changes to files and directories.
+//
+// Watching a file reports changes to it. Watching a directory reports
+// changes to the directory and to the files directly inside it, or,
+// for [Watcher.AddRecursive], to all the files and directories below
+// it. A file renamed within a watched directory is reported as a
+// WatchRename of its old name followed by a WatchCreate of its new one.
+//
+// On Linux, a Watcher uses inotify(7), and its events are delivered
+// through the runtime's network poller. On other systems, or if inotify
+// is not available, a Watcher polls the watched files about twice a
+// second: it reports the differences between successive scans, so
+// short-lived files may not be reported, and changes to a file between
+// two scans are reported as a single event.
+//
+// A Watcher is safe for concurrent use by multiple goroutines.
+type Watcher struct {
+	events    chan WatchEvent
+	done      chan struct{} // closed by Close
+	exited    chan struct{} // closed when the events goroutine exits
+	closeOnce sync.Once
+	err       error // why the events goroutine exited, set before exited is closed
+	sys       watcherSys
+}
+
+// watcherSys is the implementation of a Watcher.
+type watcherSys interface {
+	add(name string, recursive bool) error
+	remove(name string) error
+
+	// run sends events to w until w is closed or an error occurs.
+	run(w *Watcher) error
+
+	// close stops the implementation, which makes run return.
+	close() error
+}
+
+// NewWatcher returns a new [Watcher] that doesn't watch any files yet.
+func NewWatcher() (*Watcher, error) {
+	sys, err := newNativeWatcher()
+	if sys == nil || err != nil {
+		sys = newPollWatcher()
+	}
+	return startWatcher(sys), nil
+}
+
+func startWatcher(sys watcherSys) *Watcher {
+	w := &Watcher{
+		events: make(chan WatchEvent, 64),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+		sys:    sys,
+	}
+	go func() {
+		w.err = sys.run(w)
+		close(w.events)
+		close(w.exited)
+	}()
+	return w
+}
+
+// Events returns the channel on which w reports events. The channel is
+// closed when w is closed, or if w fails, in which case [Watcher.Close]
+// reports the error. Events must be received promptly: a Watcher only
+// buffers a few of them before it stops reading changes from the
+// system, which may then report a WatchOverflow.
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Add starts watching the named file or directory. If name is a
+// symbolic link, Add watches the file it refers to.
+func (w *Watcher) Add(name string) error {
+	return w.add(name, false)
+}
+
+// AddRecursive starts watching the named directory and all the
+// directories below it, including the ones created later. It doesn't
+// follow symbolic links below name. When a directory is created in the
+// tree, AddRecursive reports a WatchCreate event for it and for each of
+// the files already inside it when it starts watching it.
+func (w *Watcher) AddRecursive(name string) error {
+	return w.add(name, true)
+}
+
+func (w *Watcher) add(name string, recursive bool) error {
+	select {
+	case <-w.done:
+		return &PathError{Op: "watch", Path: name, Err: ErrClosed}
+	default:
+	}
+	return w.sys.add(filepathlite.Clean(name), recursive)
+}
+
+// Remove stops watching the named file or directory, which must have
+// been passed to [Watcher.Add] or [Watcher.AddRecursive]. Events that
+// w read before Remove returns may still be reported.
+func (w *Watcher) Remove(name string) error {
+	return w.sys.remove(filepathlite.Clean(name))
+}
+
+// Close stops watching all files and closes the channel returned by
+// [Watcher.Events]. It returns the error that made w fail, if any.
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.sys.close()
+	})
+	<-w.exited
+	if w.err != nil {
+		return w.err
+	}
+	return err
+}
+
+// send sends ev on the events channel. It reports false if w was
+// closed first.
+func (w *Watcher) send(ev WatchEvent) bool {
+	select {
+	case w.events <- ev:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// errNotWatched is returned by Remove for names that are not watched.
+var errNotWatched = errors.New("not watched")
+
+// watchPollInterval is the time between two scans of a polling Watcher.
+var watchPollInterval = 500 * time.Millisecond
+
+// A pollWatcher is a Watcher that scans the watched files periodically
+// and reports the differences between successive scans.
+type pollWatcher struct {
+	mu      sync.Mutex
+	watches map[string]*pollWatch // by name passed to add
+}
+
+type pollWatch struct {
+	recursive bool
+	files     map[string]FileInfo // the watched file and its watched descendants
+}
+
+func newPollWatcher() *pollWatcher {
+	return &pollWatcher{watches: make(map[string]*pollWatch)}
+}
+
+func (p *pollWatcher) add(name string, recursive bool) error {
+	files, err := pollScan(name, recursive)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.watches[name] = &pollWatch{recursive: recursive, files: files}
+	return nil
+}
+
+func (p *pollWatcher) remove(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.watches[name] == nil {
+		return &PathError{Op: "unwatch", Path: name, Err: errNotWatched}
+	}
+	delete(p.watches, name)
+	return nil
+}
+
+func (p *pollWatcher) run(w *Watcher) error {
+	t := time.NewTicker(watchPollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.done:
+			return nil
+		case <-t.C:
+		}
+		for _, ev := range p.poll() {
+			if !w.send(ev) {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *pollWatcher) close() error {
+	return nil
+}
+
+// poll scans the watched files again and returns the changes since the
+// previous scan.
+func (p *pollWatcher) poll() []WatchEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.watches))
+	for name := range p.watches {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var events []WatchEvent
+	for _, name := range names {
+		pw := p.watches[name]
+		files, err := pollScan(name, pw.recursive)
+		if err != nil {
+			if !IsNotExist(err) {
+				// Try again at the next scan.
+				continue
+			}
+			// The watched file is gone: report its removal
+			// and stop watching it.
+			delete(p.watches, name)
+		}
+		events = append(events, pollDiff(pw.files, files)...)
+		pw.files = files
+	}
+	return events
+}
+
+// pollScan returns the information about the named file and, if it is
+// a directory, about the files inside it, recursively if recursive is
+// set.
+func pollScan(name string, recursive bool) (map[string]FileInfo, error) {
+	fi, err := Stat(name)
+	if err != nil {
+		return nil, &PathError{Op: "watch", Path: name, Err: underlyingError(err)}
+	}
+	files := map[string]FileInfo{name: fi}
+	if fi.IsDir() {
+		pollScanDir(files, name, recursive)
+	}
+	return files, nil
+}
+
+func pollScanDir(files map[string]FileInfo, dir string, recursive bool) {
+	// Errors are ignored: files that can't be read, maybe because
+	// they were just removed, are not reported.
+	entries, _ := ReadDir(dir)
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		name := joinPath(dir, e.Name())
+		files[name] = fi
+		if recursive && fi.IsDir() {
+			pollScanDir(files, name, true)
+		}
+	}
+}
+
+// pollDiff returns the events that turn the files of old into those of
+// new. Removals and renames come first, children before their parents,
+// then creations, parents before their children, then other changes.
+func pollDiff(old, new map[string]FileInfo) []WatchEvent {
+	var removed, created, changed []WatchEvent
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			removed = append(removed, WatchEvent{name, WatchRemove})
+		}
+	}
+	for name, nfi := range new {
+		ofi, ok := old[name]
+		switch {
+		case !ok || !SameFile(ofi, nfi):
+			created = append(created, WatchEvent{name, WatchCreate})
+		case ofi.Mode() != nfi.Mode():
+			changed = append(changed, WatchEvent{name, WatchChmod})
+		case !nfi.IsDir() && (ofi.Size() != nfi.Size() || !ofi.ModTime().Equal(nfi.ModTime())):
+			changed = append(changed, WatchEvent{name, WatchWrite})
+		}
+	}
+	// A removed file that is the same as a created one was renamed.
+	for i, r := range removed {
+		for _, c := range created {
+			if _, ok := old[c.Name]; !ok && SameFile(old[r.Name], new[c.Name]) {
+				removed[i].Op = WatchRename
+				break
+			}
+		}
+	}
+	byName := func(a, b WatchEvent) int { return cmp.Compare(a.Name, b.Name) }
+	slices.SortFunc(removed, byName)
+	slices.Reverse(removed)
+	slices.SortFunc(created, byName)
+	slices.SortFunc(changed, byName)
+	return slices.Concat(removed, created, changed)
+}
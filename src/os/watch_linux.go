@@ -0,0 +1,285 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os
+
+import (
+	"internal/bytealg"
+	"internal/poll"
+	"slices"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// An inotifyWatcher is a Watcher using inotify(7). Its descriptor is
+// registered with the network poller, so that reading events only
+// blocks the goroutine that sends them.
+type inotifyWatcher struct {
+	pfd poll.FD
+
+	mu      sync.Mutex
+	watches map[int32]*inotifyWatch // by watch descriptor
+	wds     map[string]int32        // watch descriptors by name
+}
+
+type inotifyWatch struct {
+	name string
+
+	// owners are the names passed to add that made this watch: the
+	// watches of the directories below a recursive root have that root
+	// among their owners. inotify returns the same watch descriptor for
+	// a file watched more than once, so overlapping roots share it.
+	owners []inotifyOwner
+}
+
+type inotifyOwner struct {
+	root      string
+	recursive bool
+}
+
+// isRoot reports whether w was added for its own name.
+func (w *inotifyWatch) isRoot() bool {
+	for _, o := range w.owners {
+		if o.root == w.name {
+			return true
+		}
+	}
+	return false
+}
+
+// recursive reports whether a recursive root owns w.
+func (w *inotifyWatch) recursive() bool {
+	return slices.ContainsFunc(w.owners, func(o inotifyOwner) bool { return o.recursive })
+}
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_ATTRIB |
+	syscall.IN_DELETE | syscall.IN_DELETE_SELF |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_MOVE_SELF |
+	syscall.IN_EXCL_UNLINK
+
+func newNativeWatcher() (watcherSys, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, NewSyscallError("inotify_init1", err)
+	}
+	iw := &inotifyWatcher{
+		pfd: poll.FD{
+			Sysfd:         fd,
+			IsStream:      true,
+			ZeroReadIsEOF: true,
+		},
+		watches: make(map[int32]*inotifyWatch),
+		wds:     make(map[string]int32),
+	}
+	if err := iw.pfd.Init("file", true); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return iw, nil
+}
+
+func (iw *inotifyWatcher) add(name string, recursive bool) error {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	if err := iw.addWatch(name, name, recursive); err != nil {
+		return err
+	}
+	if recursive {
+		iw.addTree(name, name, nil, false)
+	}
+	return nil
+}
+
+// addWatch adds a watch for name. iw.mu must be held.
+func (iw *inotifyWatcher) addWatch(name, root string, recursive bool) error {
+	wd, err := syscall.InotifyAddWatch(iw.pfd.Sysfd, name, inotifyMask)
+	if err != nil {
+		return &PathError{Op: "watch", Path: name, Err: err}
+	}
+	w := iw.watches[int32(wd)]
+	if w == nil {
+		w = &inotifyWatch{name: name}
+		iw.watches[int32(wd)] = w
+		iw.wds[name] = int32(wd)
+	}
+	for i := range w.owners {
+		if w.owners[i].root == root {
+			w.owners[i].recursive = w.owners[i].recursive || recursive
+			return nil
+		}
+	}
+	w.owners = append(w.owners, inotifyOwner{root, recursive})
+	return nil
+}
+
+// addTree adds watches for the directories below dir. If report is
+// set, it appends to events a WatchCreate event for each file found
+// below dir. iw.mu must be held.
+func (iw *inotifyWatcher) addTree(dir, root string, events []WatchEvent, report bool) []WatchEvent {
+	// Errors are ignored: the directories that can't be read or
+	// watched, maybe because they were just removed, are skipped.
+	entries, _ := ReadDir(dir)
+	for _, e := range entries {
+		name := joinPath(dir, e.Name())
+		if report {
+			events = append(events, WatchEvent{name, WatchCreate})
+		}
+		if e.IsDir() && iw.addWatch(name, root, true) == nil {
+			events = iw.addTree(name, root, events, report)
+		}
+	}
+	return events
+}
+
+func (iw *inotifyWatcher) remove(name string) error {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	wd, ok := iw.wds[name]
+	if !ok || !iw.watches[wd].isRoot() {
+		return &PathError{Op: "unwatch", Path: name, Err: errNotWatched}
+	}
+	iw.removeRoot(name)
+	return nil
+}
+
+// removeRoot removes root from the owners of the watches, and removes
+// the watches left without owners. iw.mu must be held.
+func (iw *inotifyWatcher) removeRoot(root string) {
+	for _, w := range iw.watches {
+		w.owners = slices.DeleteFunc(w.owners, func(o inotifyOwner) bool { return o.root == root })
+	}
+	iw.removeWatches(func(w *inotifyWatch) bool { return len(w.owners) == 0 })
+}
+
+// removeWatches removes the watches for which match returns true. iw.mu
+// must be held.
+func (iw *inotifyWatcher) removeWatches(match func(*inotifyWatch) bool) {
+	for wd, w := range iw.watches {
+		if match(w) {
+			// The kernel may have removed the watch already,
+			// in which case this fails with EINVAL.
+			syscall.InotifyRmWatch(iw.pfd.Sysfd, uint32(wd))
+			delete(iw.watches, wd)
+			if iw.wds[w.name] == wd {
+				delete(iw.wds, w.name)
+			}
+		}
+	}
+}
+
+func (iw *inotifyWatcher) run(w *Watcher) error {
+	// Large enough for any event, whose name is at most NAME_MAX bytes.
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := iw.pfd.Read(buf)
+		if err != nil {
+			if err == poll.ErrFileClosing {
+				return nil
+			}
+			return NewSyscallError("read inotify", err)
+		}
+		for _, ev := range iw.parse(buf[:n]) {
+			if !w.send(ev) {
+				return nil
+			}
+		}
+	}
+}
+
+func (iw *inotifyWatcher) close() error {
+	return iw.pfd.Close()
+}
+
+// parse returns the events reported by the raw inotify events of buf.
+func (iw *inotifyWatcher) parse(buf []byte) []WatchEvent {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	var events []WatchEvent
+	for len(buf) >= syscall.SizeofInotifyEvent {
+		raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[0]))
+		end := syscall.SizeofInotifyEvent + int(raw.Len)
+		if end > len(buf) {
+			break
+		}
+		// The name is padded with NUL bytes.
+		name := buf[syscall.SizeofInotifyEvent:end]
+		if i := bytealg.IndexByte(name, 0); i >= 0 {
+			name = name[:i]
+		}
+		events = iw.handle(events, raw.Wd, raw.Mask, string(name))
+		buf = buf[end:]
+	}
+	return events
+}
+
+// handle appends to events the events reported by a raw inotify event
+// for the watch wd, about the file name in the watched directory, or
+// about the watched file itself if name is empty. iw.mu must be held.
+func (iw *inotifyWatcher) handle(events []WatchEvent, wd int32, mask uint32, name string) []WatchEvent {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		return append(events, WatchEvent{Op: WatchOverflow})
+	}
+	w := iw.watches[wd]
+	if w == nil {
+		// An event for a watch that was just removed.
+		return events
+	}
+	if mask&syscall.IN_IGNORED != 0 {
+		// The kernel removed the watch.
+		delete(iw.watches, wd)
+		if iw.wds[w.name] == wd {
+			delete(iw.wds, w.name)
+		}
+		return events
+	}
+	if name == "" && !w.isRoot() {
+		// The parent directory of w reports the same event.
+		return events
+	}
+
+	path := w.name
+	if name != "" {
+		path = joinPath(w.name, name)
+	}
+	var op WatchOp
+	switch {
+	case mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		op = WatchCreate
+	case mask&syscall.IN_MODIFY != 0:
+		op = WatchWrite
+	case mask&syscall.IN_ATTRIB != 0:
+		op = WatchChmod
+	case mask&(syscall.IN_DELETE|syscall.IN_DELETE_SELF) != 0:
+		op = WatchRemove
+	case mask&(syscall.IN_MOVED_FROM|syscall.IN_MOVE_SELF) != 0:
+		op = WatchRename
+	default:
+		return events
+	}
+	events = append(events, WatchEvent{path, op})
+
+	switch {
+	case mask&syscall.IN_MOVE_SELF != 0:
+		// The watched file has a new name, which we don't know.
+		iw.removeRoot(w.name)
+	case w.recursive() && mask&syscall.IN_ISDIR != 0 && op == WatchCreate:
+		// Watch the new directory for each recursive owner of w,
+		// reporting the files inside it only once.
+		report := true
+		for _, o := range slices.Clone(w.owners) {
+			if o.recursive && iw.addWatch(path, o.root, true) == nil {
+				events = iw.addTree(path, o.root, events, report)
+				report = false
+			}
+		}
+	case w.recursive() && mask&syscall.IN_ISDIR != 0 && op == WatchRename:
+		// The watches below the directory now have other names.
+		prefix := path + string(PathSeparator)
+		iw.removeWatches(func(w *inotifyWatch) bool {
+			return w.name == path || len(w.name) > len(prefix) && w.name[:len(prefix)] == prefix
+		})
+	}
+	return events
+}
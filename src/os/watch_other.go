@@ -0,0 +1,12 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+
+package os
+
+// newNativeWatcher returns nil: Watchers poll the watched files.
+func newNativeWatcher() (watcherSys, error) {
+	return nil, nil
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"errors"
+	. "os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// testWatcher runs f with a new Watcher from NewWatcher, and with a new
+// polling Watcher.
+func testWatcher(t *testing.T, f func(t *testing.T, w *Watcher)) {
+	t.Run("native", func(t *testing.T) {
+		w, err := NewWatcher()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer w.Close()
+		f(t, w)
+	})
+	t.Run("poll", func(t *testing.T) {
+		defer func(d time.Duration) { *WatchPollInterval = d }(*WatchPollInterval)
+		*WatchPollInterval = 10 * time.Millisecond
+		w := NewPollWatcher()
+		defer w.Close()
+		f(t, w)
+	})
+}
+
+// expectEvents reads events from w until it has seen the events of want,
+// in order, possibly with others in between.
+func expectEvents(t *testing.T, w *Watcher, want ...WatchEvent) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	var got []WatchEvent
+	for len(want) > 0 {
+		select {
+		case ev, ok := <-w.Events():
+			if !ok {
+				t.Fatalf("Events closed; got %v, still want %v", got, want)
+			}
+			got = append(got, ev)
+			if ev == want[0] {
+				want = want[1:]
+			}
+		case <-timeout:
+			t.Fatalf("timed out; got %v, still want %v", got, want)
+		}
+	}
+}
+
+func TestWatcherDir(t *testing.T) {
+	testWatcher(t, func(t *testing.T, w *Watcher) {
+		dir := t.TempDir()
+		a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+		if err := w.Add(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := WriteFile(a, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{a, WatchCreate})
+
+		f, err := OpenFile(a, O_WRONLY|O_APPEND, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString("yz")
+		f.Close()
+		expectEvents(t, w, WatchEvent{a, WatchWrite})
+
+		if runtime.GOOS != "windows" && runtime.GOOS != "plan9" {
+			if err := Chmod(a, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			expectEvents(t, w, WatchEvent{a, WatchChmod})
+		}
+
+		if err := Rename(a, b); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{a, WatchRename}, WatchEvent{b, WatchCreate})
+
+		if err := Remove(b); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{b, WatchRemove})
+
+		if err := w.Remove(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFile(a, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		for ev := range w.Events() {
+			if ev.Name == a && ev.Op == WatchCreate {
+				t.Errorf("got %v after Remove", ev)
+			}
+		}
+	})
+}
+
+func TestWatcherFile(t *testing.T) {
+	testWatcher(t, func(t *testing.T, w *Watcher) {
+		name := filepath.Join(t.TempDir(), "f")
+		if err := WriteFile(name, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Add(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFile(name, []byte("xyz"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{name, WatchWrite})
+		if err := Remove(name); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{name, WatchRemove})
+	})
+}
+
+func TestWatcherRecursive(t *testing.T) {
+	testWatcher(t, func(t *testing.T, w *Watcher) {
+		dir := t.TempDir()
+		sub := filepath.Join(dir, "sub")
+		if err := Mkdir(sub, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.AddRecursive(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		// A file in a directory that existed before AddRecursive.
+		f := filepath.Join(sub, "f")
+		if err := WriteFile(f, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{f, WatchCreate})
+
+		// Directories created after AddRecursive, with files
+		// created before they are watched.
+		x := filepath.Join(sub, "x")
+		y := filepath.Join(x, "y")
+		g := filepath.Join(y, "g")
+		if err := MkdirAll(y, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFile(g, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{x, WatchCreate}, WatchEvent{y, WatchCreate}, WatchEvent{g, WatchCreate})
+
+		// Changes in the new directories are reported.
+		if err := WriteFile(g, []byte("xyz"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{g, WatchWrite})
+		if err := RemoveAll(x); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{g, WatchRemove}, WatchEvent{x, WatchRemove})
+	})
+}
+
+func TestWatcherOverlapping(t *testing.T) {
+	testWatcher(t, func(t *testing.T, w *Watcher) {
+		dir := t.TempDir()
+		sub := filepath.Join(dir, "sub")
+		other := filepath.Join(dir, "other")
+		for _, d := range []string{sub, other} {
+			if err := Mkdir(d, 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Removing a watch inside a recursive tree leaves the tree
+		// watched.
+		if err := w.AddRecursive(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Add(sub); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Remove(sub); err != nil {
+			t.Fatal(err)
+		}
+		f := filepath.Join(sub, "f")
+		if err := WriteFile(f, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{f, WatchCreate})
+
+		// Removing the tree removes all its watches.
+		if err := w.Remove(dir); err != nil {
+			t.Fatal(err)
+		}
+		g := filepath.Join(sub, "g")
+		if err := WriteFile(g, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Add(other); err != nil {
+			t.Fatal(err)
+		}
+		h := filepath.Join(other, "h")
+		if err := WriteFile(h, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expectNoEvent(t, w, WatchEvent{h, WatchCreate}, g)
+
+		// Removing a recursive tree leaves the watches added for the
+		// directories inside it.
+		if err := w.Add(sub); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.AddRecursive(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Remove(dir); err != nil {
+			t.Fatal(err)
+		}
+		i := filepath.Join(sub, "i")
+		if err := WriteFile(i, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		expectEvents(t, w, WatchEvent{i, WatchCreate})
+	})
+}
+
+// expectNoEvent reads events from w until it sees until, and fails if
+// one of them is about the file name.
+func expectNoEvent(t *testing.T, w *Watcher, until WatchEvent, name string) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case ev, ok := <-w.Events():
+			if !ok {
+				t.Fatalf("Events closed; still want %v", until)
+			}
+			if ev.Name == name {
+				t.Errorf("got %v after the watch was removed", ev)
+			}
+			if ev == until {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out; still want %v", until)
+		}
+	}
+}
+
+func TestWatcherErrors(t *testing.T) {
+	testWatcher(t, func(t *testing.T, w *Watcher) {
+		dir := t.TempDir()
+		if err := w.Add(filepath.Join(dir, "missing")); !IsNotExist(err) {
+			t.Errorf("Add of missing file: got error %v, want one for which IsNotExist is true", err)
+		}
+		if err := w.Remove(dir); err == nil {
+			t.Errorf("Remove of unwatched directory succeeded")
+		}
+		if err := w.Add(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := <-w.Events(); ok {
+			t.Errorf("Events not closed after Close")
+		}
+		if err := w.Add(dir); !errors.Is(err, ErrClosed) {
+			t.Errorf("Add after Close: got error %v, want ErrClosed", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("second Close: %v", err)
+		}
+	})
+}
+
+func TestWatchOpString(t *testing.T) {
+	for _, tt := range []struct {
+		op   WatchOp
+		want string
+	}{
+		{0, "0"},
+		{WatchCreate, "Create"},
+		{WatchRemove | WatchChmod, "Remove|Chmod"},
+		{WatchOverflow, "Overflow"},
+	} {
+		if got := tt.op.String(); got != tt.want {
+			t.Errorf("WatchOp(%#x).String() = %q, want %q", uint32(tt.op), got, tt.want)
+		}
+	}
+	ev := WatchEvent{"a/b", WatchWrite}
+	if got, want := ev.String(), "Write a/b"; got != want {
+		t.Errorf("%#v.String() = %q, want %q", ev, got, want)
+	}
+}
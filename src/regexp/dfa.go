@@ -0,0 +1,550 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// dfa is a lazily constructed deterministic finite automaton that
+// decides whether a text contains a match, without tracking where.
+// Each DFA state is the set of NFA instructions that may run on the
+// next rune, together with the class of the previous rune, which is
+// needed to evaluate empty-width assertions such as ^ and \b. States
+// and transitions are built on demand the first time a search needs
+// them and cached, so that a search mostly costs one table lookup per
+// rune of the input. See https://swtch.com/~rsc/regexp/regexp3.html.
+//
+// The cache of each search is bounded. When it fills up, it is thrown
+// away and rebuilt from scratch, and if that happens too often to make
+// progress, the search gives up and the caller falls back to the NFA.
+//
+// Since it doesn't track positions, the DFA doesn't depend on the
+// preference for leftmost-first or leftmost-longest matches, and it can
+// answer Match-only calls by itself. Searches for submatches use it to
+// reject texts without a match before running the NFA.
+
+package regexp
+
+import (
+	"bytes"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// dfaMemoryBudget is the approximate number of bytes of states that a
+// search may cache. It is a variable for testing.
+var dfaMemoryBudget = 1 << 20
+
+// dfaMinProgress is the minimum number of bytes per cached state that
+// a search must scan between two resets of its cache. Searches that
+// make less progress give up.
+const dfaMinProgress = 10
+
+// A dfaResult is the result of a DFA search.
+type dfaResult int8
+
+const (
+	dfaNoMatch dfaResult = iota // the text has no match
+	dfaMatch                    // the text has a match
+	dfaFailed                   // the cache thrashed; use another engine
+)
+
+// A dfaFlag is the class of the rune preceding a DFA state, which
+// determines the empty-width assertions satisfied before the next rune.
+type dfaFlag uint8
+
+const (
+	dfaFlagOther     dfaFlag = iota // any other rune
+	dfaFlagWord                     // a word character, as in \b
+	dfaFlagNewline                  // '\n'
+	dfaFlagBeginText                // no rune: the beginning of the text
+)
+
+func newDFAFlag(r rune) dfaFlag {
+	switch {
+	case r < 0:
+		return dfaFlagBeginText
+	case r == '\n':
+		return dfaFlagNewline
+	case syntax.IsWordChar(r):
+		return dfaFlagWord
+	}
+	return dfaFlagOther
+}
+
+// rune returns a rune of class f, to evaluate empty-width assertions.
+func (f dfaFlag) rune() rune {
+	switch f {
+	case dfaFlagWord:
+		return 'a'
+	case dfaFlagNewline:
+		return '\n'
+	case dfaFlagBeginText:
+		return endOfText
+	}
+	return ' '
+}
+
+// A dfa holds the parameters of the lazily built DFA of a Regexp.
+// The states themselves are built in a dfaCache.
+type dfa struct {
+	prog        *syntax.Prog
+	anchored    bool   // matches must begin at the beginning of the text
+	prefix      string // required prefix of unanchored matches
+	prefixBytes []byte // prefix, as a []byte
+	set         bool   // the program is that of a Set
+
+	// classes maps each ASCII rune to its equivalence class: runes
+	// in the same class match the same instructions and have the
+	// same dfaFlag, so they have the same transitions.
+	classes  [utf8.RuneSelf]uint8
+	nclasses int
+
+	// cachePool holds the caches of the DFA between searches.
+	cachePool sync.Pool
+}
+
+func newDFA(re *Regexp) *dfa {
+	d := &dfa{
+		prog:        re.prog,
+		anchored:    re.cond&syntax.EmptyBeginText != 0,
+		prefix:      re.prefix,
+		prefixBytes: re.prefixBytes,
+	}
+
+	// Split the ASCII runes into intervals at the boundaries of the
+	// ranges of runes matched by instructions and of the dfaFlags.
+	// The intervals may be finer than needed, which is harmless.
+	var split [utf8.RuneSelf + 1]bool
+	mark := func(lo, hi rune) {
+		if lo < utf8.RuneSelf {
+			split[lo] = true
+			split[min(hi+1, utf8.RuneSelf)] = true
+		}
+	}
+	for r := rune(1); r < utf8.RuneSelf; r++ {
+		if newDFAFlag(r) != newDFAFlag(r-1) {
+			split[r] = true
+		}
+	}
+	for i := range d.prog.Inst {
+		inst := &d.prog.Inst[i]
+		switch inst.Op {
+		case syntax.InstRune:
+			if len(inst.Rune) == 1 {
+				// A single rune matches its case variants
+				// if FoldCase is set.
+				r := inst.Rune[0]
+				mark(r, r)
+				if syntax.Flags(inst.Arg)&syntax.FoldCase != 0 {
+					for r1 := unicode.SimpleFold(r); r1 != r; r1 = unicode.SimpleFold(r1) {
+						mark(r1, r1)
+					}
+				}
+				break
+			}
+			for j := 0; j+1 < len(inst.Rune); j += 2 {
+				mark(inst.Rune[j], inst.Rune[j+1])
+			}
+		case syntax.InstRune1:
+			mark(inst.Rune[0], inst.Rune[0])
+		case syntax.InstRuneAnyNotNL:
+			mark('\n', '\n')
+		}
+	}
+	id := uint8(0)
+	for r := range rune(utf8.RuneSelf) {
+		if r > 0 && split[r] {
+			id++
+		}
+		d.classes[r] = id
+	}
+	d.nclasses = int(id) + 1
+	return d
+}
+
+// A dfaState is a state of the DFA.
+type dfaState struct {
+	pcs  []uint32 // instructions to run on the next rune, in increasing order
+	flag dfaFlag  // class of the previous rune
+
+	// For the program of a Set, matches holds the indexes of the
+	// expressions that matched before the rune leading to the state,
+	// in increasing order. The DFA of a Set doesn't stop at the first
+	// match, to find all the matching expressions.
+	matches []uint32
+
+	next       []*dfaState        // transitions on ASCII runes, by class
+	more       map[rune]*dfaState // transitions on other runes
+	atEnd      int8               // whether the text may end here: 0 unknown, 1 no, 2 yes
+	endMatches []uint32           // for a Set, the expressions that match at the end
+}
+
+// A dfaSet is a sparse set of instructions.
+type dfaSet struct {
+	sparse []uint32
+	dense  []uint32
+}
+
+func (s *dfaSet) contains(pc uint32) bool {
+	j := s.sparse[pc]
+	return j < uint32(len(s.dense)) && s.dense[j] == pc
+}
+
+func (s *dfaSet) add(pc uint32) {
+	s.sparse[pc] = uint32(len(s.dense))
+	s.dense = append(s.dense, pc)
+}
+
+// A dfaCache holds the states of a DFA built by searches. Each search
+// uses its own cache, and caches are reused through the cachePool of
+// the DFA, so they keep their states from one search to the next.
+type dfaCache struct {
+	d        *dfa // DFA of the states
+	states   map[string]*dfaState
+	mem      int          // approximate memory used by states
+	match    *dfaState    // sentinel target of transitions that find a match
+	q, nq    dfaSet       // closure of the current state, next state
+	stack    []uint32     // closure stack
+	mq       []uint32     // for a Set, expressions matched by the closure
+	key      []byte       // key of states
+	resetPos int          // position in the text of the last reset
+	resets   int          // number of resets during the current search
+	starts   [4]*dfaState // start states, by flag
+}
+
+// get returns a cache to use for a search with d.
+func (d *dfa) get() *dfaCache {
+	c, ok := d.cachePool.Get().(*dfaCache)
+	if !ok {
+		n := len(d.prog.Inst)
+		c = &dfaCache{
+			d:      d,
+			states: make(map[string]*dfaState),
+			match:  new(dfaState),
+			q:      dfaSet{make([]uint32, n), make([]uint32, 0, n)},
+			nq:     dfaSet{make([]uint32, n), make([]uint32, 0, n)},
+		}
+	}
+	c.resets = 0
+	return c
+}
+
+// put returns c to the cachePool of d.
+func (d *dfa) put(c *dfaCache) {
+	d.cachePool.Put(c)
+}
+
+// reset throws away the states of c.
+func (c *dfaCache) reset() {
+	clear(c.states)
+	c.mem = 0
+	c.starts = [4]*dfaState{}
+}
+
+// state returns the state for pcs, matches and flag, adding it to c if
+// needed. It returns nil if the search should give up, because c is full
+// and was already reset recently. pos is the current position in the text.
+func (c *dfaCache) state(pcs, matches []uint32, flag dfaFlag, pos int) *dfaState {
+	c.key = append(c.key[:0], byte(flag))
+	for _, pc := range pcs {
+		c.key = append(c.key, byte(pc), byte(pc>>8), byte(pc>>16), byte(pc>>24))
+	}
+	if len(matches) > 0 {
+		// No instruction has this pc.
+		c.key = append(c.key, 0xff, 0xff, 0xff, 0xff)
+		for _, m := range matches {
+			c.key = append(c.key, byte(m), byte(m>>8), byte(m>>16), byte(m>>24))
+		}
+	}
+	if s := c.states[string(c.key)]; s != nil {
+		return s
+	}
+
+	// A state costs its own size, its transition table and about
+	// twice its key for the map entry.
+	mem := 96 + 8*c.d.nclasses + 4*len(pcs) + 4*len(matches) + 2*len(c.key)
+	if c.mem+mem > dfaMemoryBudget && len(c.states) > 0 {
+		if pos-c.resetPos < dfaMinProgress*len(c.states) && c.resets > 0 {
+			return nil
+		}
+		c.reset()
+		c.resetPos = pos
+		c.resets++
+	}
+	s := &dfaState{
+		pcs:     slices.Clone(pcs),
+		flag:    flag,
+		matches: slices.Clone(matches),
+		next:    make([]*dfaState, c.d.nclasses),
+	}
+	c.states[string(c.key)] = s
+	c.mem += mem
+	return s
+}
+
+// start returns the state at the beginning of a search whose previous
+// rune has class flag.
+func (c *dfaCache) start(flag dfaFlag, pos int) *dfaState {
+	if s := c.starts[flag]; s != nil {
+		return s
+	}
+	var pcs []uint32
+	if c.d.anchored {
+		pcs = []uint32{uint32(c.d.prog.Start)}
+	}
+	s := c.state(pcs, nil, flag, pos)
+	c.starts[flag] = s
+	return s
+}
+
+// closure computes in c.q the instructions that may run on rune r
+// from state s, following empty-width instructions, and reports
+// whether a match ends before r. For a Set, it also computes in c.mq
+// the expressions that match, in increasing order.
+func (c *dfaCache) closure(s *dfaState, r rune) bool {
+	prog := c.d.prog
+	flag := newLazyFlag(s.flag.rune(), r)
+	c.q.dense = c.q.dense[:0]
+	c.mq = c.mq[:0]
+	c.stack = append(c.stack[:0], s.pcs...)
+	if !c.d.anchored {
+		// Start a new thread at every position.
+		c.stack = append(c.stack, uint32(prog.Start))
+	}
+	matched := false
+	for len(c.stack) > 0 {
+		pc := c.stack[len(c.stack)-1]
+		c.stack = c.stack[:len(c.stack)-1]
+		if pc == 0 || c.q.contains(pc) {
+			continue
+		}
+		c.q.add(pc)
+		i := &prog.Inst[pc]
+		switch i.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			c.stack = append(c.stack, i.Arg, i.Out)
+		case syntax.InstEmptyWidth:
+			if flag.match(syntax.EmptyOp(i.Arg)) {
+				c.stack = append(c.stack, i.Out)
+			}
+		case syntax.InstNop, syntax.InstCapture:
+			c.stack = append(c.stack, i.Out)
+		case syntax.InstMatch:
+			matched = true
+			if c.d.set {
+				c.mq = append(c.mq, i.Arg)
+			}
+		}
+	}
+	slices.Sort(c.mq)
+	return matched
+}
+
+// transition returns the state following s on rune r, which is not
+// endOfText. Except for a Set, it returns c.match if a match ends
+// before r. It returns nil if the search should give up. pos is the
+// position of r in the text.
+func (c *dfaCache) transition(s *dfaState, r rune, pos int) *dfaState {
+	var next *dfaState
+	if c.closure(s, r) && !c.d.set {
+		next = c.match
+	} else if next = c.next(r, pos); next == nil {
+		return nil
+	}
+	if r < utf8.RuneSelf {
+		s.next[c.d.classes[r]] = next
+	} else {
+		if s.more == nil {
+			s.more = make(map[rune]*dfaState)
+		}
+		s.more[r] = next
+		c.mem += 32
+	}
+	return next
+}
+
+// next returns the state of the instructions in c.q that match r,
+// after the matches in c.mq.
+func (c *dfaCache) next(r rune, pos int) *dfaState {
+	prog := c.d.prog
+	c.nq.dense = c.nq.dense[:0]
+	for _, pc := range c.q.dense {
+		i := &prog.Inst[pc]
+		var ok bool
+		switch i.Op {
+		case syntax.InstRune:
+			ok = i.MatchRune(r)
+		case syntax.InstRune1:
+			ok = r == i.Rune[0]
+		case syntax.InstRuneAny:
+			ok = true
+		case syntax.InstRuneAnyNotNL:
+			ok = r != '\n'
+		}
+		if ok && i.Out != 0 && !c.nq.contains(i.Out) {
+			c.nq.add(i.Out)
+		}
+	}
+	slices.Sort(c.nq.dense)
+	return c.state(c.nq.dense, c.mq, newDFAFlag(r), pos)
+}
+
+// matchAtEnd reports whether a match ends at the end of the text
+// in state s. For a Set, s.endMatches holds the matching expressions.
+func (c *dfaCache) matchAtEnd(s *dfaState) bool {
+	if s.atEnd == 0 {
+		s.atEnd = 1
+		if c.closure(s, endOfText) {
+			s.atEnd = 2
+			s.endMatches = slices.Clone(c.mq)
+		}
+	}
+	return s.atEnd == 2
+}
+
+// match reports whether b or s, whichever is non-empty, has a match
+// starting at or after pos.
+func (d *dfa) match(b []byte, s string, pos int) dfaResult {
+	return d.search(b, s, pos, nil)
+}
+
+// matchSet records in matched which expressions of a Set match b or s,
+// whichever is non-empty, and returns the number of new entries in
+// matched. It returns -1 if the search gave up, with matched in an
+// unspecified state.
+func (d *dfa) matchSet(b []byte, s string, matched []bool) int {
+	if d.search(b, s, 0, matched) == dfaFailed {
+		return -1
+	}
+	n := 0
+	for _, m := range matched {
+		if m {
+			n++
+		}
+	}
+	return n
+}
+
+// search implements match and matchSet. If matched is nil, it stops at
+// the first match.
+func (d *dfa) search(b []byte, s string, pos int, matched []bool) dfaResult {
+	n := len(b) + len(s)
+	if d.anchored && pos != 0 {
+		return dfaNoMatch
+	}
+	prev := endOfText
+	if pos > 0 {
+		if b != nil {
+			prev, _ = utf8.DecodeLastRune(b[:pos])
+		} else {
+			prev, _ = utf8.DecodeLastRuneInString(s[:pos])
+		}
+	}
+
+	c := d.get()
+	c.resetPos = pos
+	st := c.start(newDFAFlag(prev), pos)
+	result := dfaFailed
+	found := 0
+	for st != nil {
+		if len(st.matches) > 0 {
+			if matched == nil {
+				result = dfaMatch
+				break
+			}
+			found = recordSetMatches(matched, st.matches, found)
+			if found == len(matched) {
+				// All the expressions match.
+				result = dfaMatch
+				break
+			}
+		}
+		if len(st.pcs) == 0 {
+			if d.anchored {
+				// No thread left.
+				result = dfaNoMatch
+				break
+			}
+			if d.prefix != "" && pos < n {
+				// Matches require a literal prefix; fast search for it.
+				var advance int
+				if b != nil {
+					advance = bytes.Index(b[pos:], d.prefixBytes)
+				} else {
+					advance = strings.Index(s[pos:], d.prefix)
+				}
+				if advance < 0 {
+					result = dfaNoMatch
+					break
+				}
+				if advance > 0 {
+					pos += advance
+					if b != nil {
+						prev, _ = utf8.DecodeLastRune(b[:pos])
+					} else {
+						prev, _ = utf8.DecodeLastRuneInString(s[:pos])
+					}
+					st = c.start(newDFAFlag(prev), pos)
+					continue
+				}
+			}
+		}
+		if pos >= n {
+			result = dfaNoMatch
+			if c.matchAtEnd(st) {
+				result = dfaMatch
+				if matched != nil {
+					found = recordSetMatches(matched, st.endMatches, found)
+				}
+			}
+			break
+		}
+
+		r, width := rune(0), 1
+		if b != nil {
+			r = rune(b[pos])
+			if r >= utf8.RuneSelf {
+				r, width = utf8.DecodeRune(b[pos:])
+			}
+		} else {
+			r = rune(s[pos])
+			if r >= utf8.RuneSelf {
+				r, width = utf8.DecodeRuneInString(s[pos:])
+			}
+		}
+		var next *dfaState
+		if r < utf8.RuneSelf {
+			next = st.next[d.classes[r]]
+		} else {
+			next = st.more[r]
+		}
+		if next == nil {
+			next = c.transition(st, r, pos)
+		}
+		if next == c.match {
+			result = dfaMatch
+			break
+		}
+		st = next
+		pos += width
+	}
+	d.put(c)
+	if result == dfaNoMatch && found > 0 {
+		result = dfaMatch
+	}
+	return result
+}
+
+// recordSetMatches sets the entries of matched for the expressions in
+// matches, and returns found plus the number of entries it set.
+func recordSetMatches(matched []bool, matches []uint32, found int) int {
+	for _, m := range matches {
+		if !matched[m] {
+			matched[m] = true
+			found++
+		}
+	}
+	return found
+}
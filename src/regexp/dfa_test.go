@@ -0,0 +1,158 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package regexp
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+var dfaTests = []string{
+	`a`,
+	`abc`,
+	`a*b`,
+	`(a|b)*abb`,
+	`x*`,
+	`^abc`,
+	`abc$`,
+	`(?m)^b$`,
+	`(?m)a$\n^b`,
+	`\bab\b`,
+	`\Bb\B`,
+	`(?i)aBc`,
+	`[^a]b`,
+	`.b`,
+	`(?s).b`,
+	`a.*c`,
+	`(a+)(b+)`,
+	`\Aa|b\z`,
+	`é+b`,
+	`[α-ω]+`,
+	`(ab|ba|a)*$`,
+	`a{3,5}b`,
+	`\d+\.\d+`,
+	`^(a|ab)(c|bcd)`,
+	`(?m)^a|b$`,
+}
+
+// nfaMatch reports whether the NFA finds a match in s starting at or
+// after pos.
+func nfaMatch(re *Regexp, s string, pos int) bool {
+	m := re.get()
+	i, _ := m.inputs.init(nil, nil, s)
+	m.init(0)
+	matched := m.match(i, pos)
+	re.put(m)
+	return matched
+}
+
+func testDFA(t *testing.T, allowFail bool) {
+	const alphabet = "aab b\nc.1éα"
+	runes := []rune(alphabet)
+	rng := rand.New(rand.NewPCG(1, 2))
+	failed := 0
+	for _, expr := range dfaTests {
+		re := MustCompile(expr)
+		if re.dfa == nil {
+			// One-pass regexps don't use the DFA.
+			continue
+		}
+		for range 200 {
+			var b strings.Builder
+			for range rng.IntN(20) {
+				b.WriteRune(runes[rng.IntN(len(runes))])
+			}
+			s := b.String()
+			for pos := range len(s) + 1 {
+				if pos > 2 {
+					break
+				}
+				if pos < len(s) && s[pos]&0xC0 == 0x80 {
+					continue // not a rune boundary
+				}
+				want := dfaNoMatch
+				if nfaMatch(re, s, pos) {
+					want = dfaMatch
+				}
+				got := re.dfa.match(nil, s, pos)
+				if got == dfaFailed && allowFail {
+					failed++
+					continue
+				}
+				if got != want {
+					t.Errorf("%#q on %q at %d: DFA returned %d, want %d", expr, s, pos, got, want)
+				}
+				if got := re.dfa.match([]byte(s), "", pos); got != want && (got != dfaFailed || !allowFail) {
+					t.Errorf("%#q on []byte(%q) at %d: DFA returned %d, want %d", expr, s, pos, got, want)
+				}
+			}
+		}
+	}
+	if allowFail && failed == 0 {
+		t.Errorf("DFA never gave up")
+	}
+}
+
+func TestDFA(t *testing.T) {
+	testDFA(t, false)
+}
+
+func TestDFASmallCache(t *testing.T) {
+	// Force the cache to be reset constantly, so that searches
+	// either rebuild states or give up.
+	defer func(budget int) { dfaMemoryBudget = budget }(dfaMemoryBudget)
+	dfaMemoryBudget = 256
+	testDFA(t, true)
+
+	// Searches that give up fall back to the other engines.
+	re := MustCompile(`(a|b)*a(a|b)(a|b)(a|b)(a|b)$`)
+	s := strings.Repeat("ab", 1000) + "abbbb"
+	if !re.MatchString(s) {
+		t.Errorf("%#q.MatchString(...) = false, want true", re)
+	}
+	if re.MatchString(s[:len(s)-5] + "bbbbb") {
+		t.Errorf("%#q.MatchString(...) = true, want false", re)
+	}
+}
+
+func BenchmarkMatchDFA(b *testing.B) {
+	// A log that doesn't match, too long for the backtracker.
+	re := MustCompile(`(error|warning): .*(timeout|refused)`)
+	line := strings.Repeat("info: request served in 42ms from cache ", 200)
+	if len(line) < re.maxBitStateLen {
+		b.Fatalf("text of %d bytes is short enough for the backtracker", len(line))
+	}
+	b.SetBytes(int64(len(line)))
+	for b.Loop() {
+		if re.MatchString(line) {
+			b.Fatal("match!")
+		}
+	}
+}
+
+func BenchmarkMatchManyShort(b *testing.B) {
+	// Several expressions matched against each short line, as in
+	// log filtering: each uses its own DFA states.
+	var res []*Regexp
+	for _, expr := range []string{
+		`(error|warning): .*(timeout|refused)`,
+		`panic: [a-z]+`,
+		`user=[0-9]+ denied`,
+		`^\d{4}-\d{2}-\d{2}T`,
+		`(?i)deadline exceeded`,
+		`ms from (disk|network)$`,
+	} {
+		res = append(res, MustCompile(expr))
+	}
+	line := "info: request served in 42ms from cache"
+	for b.Loop() {
+		for _, re := range res {
+			if re.MatchString(line) {
+				b.Fatal("match!")
+			}
+		}
+	}
+}
@@ -445,3 +445,35 @@ func ExampleRegexp_FindAllIndex() {
 	// [[1 3]]
 	// [[1 3] [4 6]]
 }
+
+func ExampleSet_MatchString() {
+	set := regexp.MustCompileSet([]string{
+		`error`,
+		`timeout after \d+s`,
+		`^GET `,
+	})
+	fmt.Println(set.MatchString("GET /index.html: timeout after 30s"))
+	fmt.Println(set.MatchString("POST /upload: ok"))
+	// Output:
+	// [1 2]
+	// []
+}
+
+func ExampleSet_FindStringIndex() {
+	// A tiny lexer: the first matching token wins.
+	set := regexp.MustCompileSet([]string{
+		`if|else`,
+		`[a-z]+`,
+		`[0-9]+`,
+	})
+	for _, s := range []string{"ifdef", "else x", "42"} {
+		fmt.Println(set.FindStringIndex(s))
+	}
+	set.Longest()
+	fmt.Println(set.FindStringIndex("ifdef"))
+	// Output:
+	// 0 [0 2]
+	// 0 [0 4]
+	// 2 [0 2]
+	// 1 [0 5]
+}
@@ -42,6 +42,7 @@ type machine struct {
 	pool     []*thread    // pool of available threads
 	matched  bool         // whether a match was found
 	matchcap []int        // capture information for the match
+	matchArg uint32       // Arg of the InstMatch of the match, for a Set
 
 	inputs inputs
 }
@@ -279,6 +280,7 @@ func (m *machine) step(runq, nextq *queue, pos, nextPos int, c rune, nextCond *l
 			if len(t.cap) > 0 && (!longest || !m.matched || m.matchcap[1] < pos) {
 				t.cap[1] = pos
 				copy(m.matchcap, t.cap)
+				m.matchArg = i.Arg
 			}
 			if !longest {
 				// First-match mode: cut off all lower-priority threads.
@@ -531,6 +533,20 @@ func (re *Regexp) doExecute(r io.RuneReader, b []byte, s string, pos int, ncap i
 	if re.onepass != nil {
 		return re.doOnePass(r, b, s, pos, ncap, dstCap)
 	}
+	if r == nil && len(b)+len(s) >= re.maxBitStateLen {
+		// The lazy DFA spares the NFA a slow scan of long texts
+		// without a match, and answers Match-only calls by itself.
+		// The backtracker handles shorter texts faster than a DFA
+		// that hasn't built its states yet.
+		switch re.dfa.match(b, s, pos) {
+		case dfaNoMatch:
+			return nil
+		case dfaMatch:
+			if ncap == 0 {
+				return dstCap
+			}
+		}
+	}
 	if r == nil && len(b)+len(s) < re.maxBitStateLen {
 		return re.backtrack(b, s, pos, ncap, dstCap)
 	}
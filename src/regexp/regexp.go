@@ -81,6 +81,7 @@ type Regexp struct {
 	expr           string       // as passed to Compile
 	prog           *syntax.Prog // compiled program
 	onepass        *onePassProg // onepass program or nil
+	dfa            *dfa         // lazy DFA, if onepass is nil
 	numSubexp      int
 	maxBitStateLen int
 	subexpNames    []string
@@ -204,15 +205,23 @@ func compile(expr string, mode syntax.Flags, longest bool) (*Regexp, error) {
 		regexp.prefixBytes = []byte(regexp.prefix)
 		regexp.prefixRune, _ = utf8.DecodeRuneInString(regexp.prefix)
 	}
+	if regexp.onepass == nil {
+		regexp.dfa = newDFA(regexp)
+	}
+
+	regexp.mpool = matchPoolIndex(prog)
+
+	return regexp, nil
+}
 
+// matchPoolIndex returns the index in matchPool of the machines for prog.
+func matchPoolIndex(prog *syntax.Prog) int {
 	n := len(prog.Inst)
 	i := 0
 	for matchSize[i] != 0 && matchSize[i] < n {
 		i++
 	}
-	regexp.mpool = i
-
-	return regexp, nil
+	return i
 }
 
 // Pools of *machine for use during (*Regexp).doExecute,
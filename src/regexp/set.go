@@ -0,0 +1,217 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package regexp
+
+import (
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// A Set is a set of regular expressions compiled into a single program,
+// to find which of them match a text in one pass over it, rather than
+// one pass per expression. Like a [Regexp], a Set runs in time linear in
+// the size of the text and of the expressions.
+//
+// A Set is safe for concurrent use by multiple goroutines, except for
+// configuration methods, such as [Set.Longest].
+type Set struct {
+	exprs []*Regexp // the expressions, compiled separately
+
+	// union is the union of the expressions. The InstMatch
+	// instruction of each expression holds its index in Arg, and
+	// capturing groups are ignored.
+	union *Regexp
+}
+
+// CompileSet parses the regular expressions exprs and returns, if
+// successful, a [Set] that matches them. The index of an expression in
+// exprs identifies it in the results of the methods of the Set.
+//
+// Like [Compile], CompileSet uses leftmost-first semantics: among the
+// expressions matching at the leftmost position, [Set.FindIndex] prefers
+// the match that a backtracking search of the alternation of the
+// expressions, in order, would have found first. For leftmost-longest
+// semantics, see [Set.Longest].
+func CompileSet(exprs []string) (*Set, error) {
+	set := &Set{exprs: make([]*Regexp, len(exprs))}
+	for i, expr := range exprs {
+		re, err := Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		set.exprs[i] = re
+	}
+	set.union = unionRegexp(set.exprs)
+	return set, nil
+}
+
+// MustCompileSet is like [CompileSet] but panics if one of the expressions
+// cannot be parsed.
+func MustCompileSet(exprs []string) *Set {
+	set, err := CompileSet(exprs)
+	if err != nil {
+		panic(`regexp: CompileSet: ` + err.Error())
+	}
+	return set
+}
+
+// unionRegexp returns a Regexp matching any of res, whose program
+// records in the Arg of each InstMatch the index of its expression.
+func unionRegexp(res []*Regexp) *Regexp {
+	prog := &syntax.Prog{
+		Inst:   []syntax.Inst{{Op: syntax.InstFail}},
+		NumCap: 2,
+	}
+	starts := make([]uint32, len(res))
+	minLen := -1
+	for i, re := range res {
+		// Append the instructions of re, relocated.
+		off := uint32(len(prog.Inst))
+		for _, inst := range re.prog.Inst {
+			switch inst.Op {
+			case syntax.InstFail:
+				// No operands.
+			case syntax.InstMatch:
+				inst.Arg = uint32(i)
+			case syntax.InstAlt, syntax.InstAltMatch:
+				inst.Out += off
+				inst.Arg += off
+			case syntax.InstCapture:
+				inst.Op = syntax.InstNop
+				inst.Arg = 0
+				inst.Out += off
+			default:
+				inst.Out += off
+			}
+			prog.Inst = append(prog.Inst, inst)
+		}
+		starts[i] = off + uint32(re.prog.Start)
+		if minLen < 0 || re.minInputLen < minLen {
+			minLen = re.minInputLen
+		}
+	}
+
+	// Start with an alternation of the expressions, in order.
+	if len(starts) > 0 {
+		pc := starts[len(starts)-1]
+		for i := len(starts) - 2; i >= 0; i-- {
+			prog.Inst = append(prog.Inst, syntax.Inst{Op: syntax.InstAlt, Out: starts[i], Arg: pc})
+			pc = uint32(len(prog.Inst) - 1)
+		}
+		prog.Start = int(pc)
+	}
+
+	union := &Regexp{
+		prog:        prog,
+		cond:        prog.StartCond(),
+		matchcap:    2,
+		minInputLen: max(minLen, 0),
+		mpool:       matchPoolIndex(prog),
+	}
+	union.prefix, union.prefixComplete = prog.Prefix()
+	if union.prefix != "" {
+		union.prefixBytes = []byte(union.prefix)
+		union.prefixRune, _ = utf8.DecodeRuneInString(union.prefix)
+	}
+	union.dfa = newDFA(union)
+	union.dfa.set = true
+	return union
+}
+
+// Len returns the number of expressions in the set.
+func (set *Set) Len() int {
+	return len(set.exprs)
+}
+
+// Longest makes future calls to [Set.FindIndex] and [Set.FindStringIndex]
+// prefer the leftmost-longest match. That is, among the expressions
+// matching at the leftmost position, they choose the one with the
+// longest match, and the first such expression in case of a tie.
+// This method modifies the [Set] and may not be called concurrently
+// with any other methods.
+func (set *Set) Longest() {
+	set.union.longest = true
+}
+
+// Match returns the indexes of the expressions that match b, in
+// increasing order. A return value of nil indicates no match.
+func (set *Set) Match(b []byte) []int {
+	return set.doMatch(b, "")
+}
+
+// MatchString returns the indexes of the expressions that match s, in
+// increasing order. A return value of nil indicates no match.
+func (set *Set) MatchString(s string) []int {
+	return set.doMatch(nil, s)
+}
+
+func (set *Set) doMatch(b []byte, s string) []int {
+	if len(set.exprs) == 0 {
+		return nil
+	}
+	matched := make([]bool, len(set.exprs))
+	n := set.union.dfa.matchSet(b, s, matched)
+	if n < 0 {
+		// The DFA gave up. Match the expressions one at a time,
+		// which is still linear in the size of the text.
+		n = 0
+		for i, re := range set.exprs {
+			matched[i] = re.doMatch(nil, b, s)
+			if matched[i] {
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, n)
+	for i, m := range matched {
+		if m {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// FindIndex returns the index of the expression with the leftmost match
+// in b, and a two-element slice of integers defining the location of the
+// match, at b[loc[0]:loc[1]]. A return value of -1, nil indicates no
+// match.
+//
+// When several expressions match at the leftmost position, FindIndex
+// chooses among them as described for [CompileSet] and [Set.Longest].
+func (set *Set) FindIndex(b []byte) (index int, loc []int) {
+	return set.doFind(b, "")
+}
+
+// FindStringIndex returns the index of the expression with the leftmost
+// match in s, and a two-element slice of integers defining the location
+// of the match, at s[loc[0]:loc[1]]. A return value of -1, nil indicates
+// no match.
+//
+// When several expressions match at the leftmost position,
+// FindStringIndex chooses among them as described for [CompileSet] and
+// [Set.Longest].
+func (set *Set) FindStringIndex(s string) (index int, loc []int) {
+	return set.doFind(nil, s)
+}
+
+func (set *Set) doFind(b []byte, s string) (index int, loc []int) {
+	re := set.union
+	if len(b)+len(s) < re.minInputLen || re.dfa.match(b, s, 0) == dfaNoMatch {
+		return -1, nil
+	}
+	m := re.get()
+	i, _ := m.inputs.init(nil, b, s)
+	m.init(2)
+	if !m.match(i, 0) {
+		re.put(m)
+		return -1, nil
+	}
+	index, loc = int(m.matchArg), []int{m.matchcap[0], m.matchcap[1]}
+	re.put(m)
+	return index, loc
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package regexp
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// setTestExprs are expressions without capturing groups, so that their
+// alternation can tell which of them matched.
+var setTestExprs = []string{
+	`a+b`,
+	`ab`,
+	`^b`,
+	`b$`,
+	`\bc\w*`,
+	`(?i:AB)c`,
+	`a.*?c`,
+	`(?:ab|ba)+`,
+	`é`,
+	`x`,
+	`(?m:^a$)`,
+	``,
+}
+
+func TestSet(t *testing.T) {
+	for _, longest := range []bool{false, true} {
+		for n := range len(setTestExprs) + 1 {
+			testSet(t, setTestExprs[:n], longest)
+		}
+		testSet(t, setTestExprs[:len(setTestExprs)-1], longest)
+		testSet(t, setTestExprs[3:6], longest)
+	}
+}
+
+func testSet(t *testing.T, exprs []string, longest bool) {
+	set := MustCompileSet(exprs)
+	if set.Len() != len(exprs) {
+		t.Fatalf("Set of %d expressions has Len %d", len(exprs), set.Len())
+	}
+	res := make([]*Regexp, len(exprs))
+	for i, expr := range exprs {
+		res[i] = MustCompile(expr)
+	}
+	var alt *Regexp
+	if len(exprs) > 0 {
+		alt = MustCompile("(" + strings.Join(exprs, ")|(") + ")")
+	}
+	if longest {
+		set.Longest()
+		if alt != nil {
+			alt.Longest()
+		}
+	}
+
+	const alphabet = "aabbc \néx"
+	runes := []rune(alphabet)
+	rng := rand.New(rand.NewPCG(3, 4))
+	for range 500 {
+		var b strings.Builder
+		for range rng.IntN(12) {
+			b.WriteRune(runes[rng.IntN(len(runes))])
+		}
+		s := b.String()
+
+		var want []int
+		for i, re := range res {
+			if re.MatchString(s) {
+				want = append(want, i)
+			}
+		}
+		if got := set.MatchString(s); !slices.Equal(got, want) {
+			t.Errorf("Set%q.MatchString(%q) = %v, want %v", exprs, s, got, want)
+		}
+		if got := set.Match([]byte(s)); !slices.Equal(got, want) {
+			t.Errorf("Set%q.Match(%q) = %v, want %v", exprs, s, got, want)
+		}
+
+		wantIndex, wantLoc := -1, []int(nil)
+		if alt != nil {
+			if a := alt.FindStringSubmatchIndex(s); a != nil {
+				wantLoc = a[:2]
+				for i := range exprs {
+					if a[2+2*i] >= 0 {
+						wantIndex = i
+						break
+					}
+				}
+			}
+		}
+		index, loc := set.FindStringIndex(s)
+		if index != wantIndex || !slices.Equal(loc, wantLoc) {
+			t.Errorf("Set%q.FindStringIndex(%q) (longest=%v) = %d, %v, want %d, %v", exprs, s, longest, index, loc, wantIndex, wantLoc)
+		}
+		index, loc = set.FindIndex([]byte(s))
+		if index != wantIndex || !slices.Equal(loc, wantLoc) {
+			t.Errorf("Set%q.FindIndex(%q) (longest=%v) = %d, %v, want %d, %v", exprs, s, longest, index, loc, wantIndex, wantLoc)
+		}
+	}
+}
+
+func TestSetSmallCache(t *testing.T) {
+	// Force the DFA to give up, so that Match falls back to
+	// matching the expressions one at a time.
+	defer func(budget int) { dfaMemoryBudget = budget }(dfaMemoryBudget)
+	dfaMemoryBudget = 256
+	testSet(t, setTestExprs, false)
+}
+
+func TestCompileSetError(t *testing.T) {
+	if _, err := CompileSet([]string{`a`, `(b`}); err == nil {
+		t.Fatalf("CompileSet succeeded with an invalid expression")
+	}
+}
+
+func BenchmarkSetMatch(b *testing.B) {
+	// A log router with many rules, most of which don't match.
+	var exprs []string
+	for i := range 100 {
+		exprs = append(exprs, `service=svc`+strings.Repeat("x", i%7)+`\d+ .*(error|timeout)`)
+	}
+	exprs = append(exprs, `latency=\d+ms`)
+	set := MustCompileSet(exprs)
+	line := "ts=2026-01-01T00:00:00Z service=svcxx12 path=/api/v1/items latency=42ms status=200"
+	b.SetBytes(int64(len(line)))
+	for b.Loop() {
+		if m := set.MatchString(line); len(m) != 1 {
+			b.Fatalf("got matches %v", m)
+		}
+	}
+}
@@ -256,6 +256,10 @@ type userArena struct {
 	// This is just a best-effort way to discover a concurrent allocation
 	// and free. Also used to detect a double-free.
 	defunct atomic.Bool
+
+	// checks holds the allocation records of the chunks of refs, in the
+	// same order, if debug.arenacheck is set.
+	checks []*userArenaChunkRecord
 }
 
 // newUserArena creates a new userArena ready to be used.
@@ -322,6 +326,15 @@ func (a *userArena) free() {
 	a.defunct.Store(true)
 	SetFinalizer(a, nil)
 
+	if debug.arenacheck > 0 {
+		// Record where the arena is freed, for use-after-free reports.
+		stk := make([]uintptr, userArenaCheckStackDepth)
+		stk = stk[:callers(2, stk)]
+		for _, r := range a.checks {
+			r.free = stk
+		}
+	}
+
 	// Free all the full arenas.
 	//
 	// The refs on this list are in reverse order from the second-to-last.
@@ -345,9 +358,9 @@ func (a *userArena) free() {
 	// Note that active's reference is always the last reference in refs.
 	s = a.active
 	if s != nil {
-		if raceenabled || msanenabled || asanenabled {
-			// Don't reuse arenas with sanitizers enabled. We want to catch
-			// any use-after-free errors aggressively.
+		if raceenabled || msanenabled || asanenabled || debug.arenacheck > 0 {
+			// Don't reuse arenas with sanitizers or checks enabled. We want
+			// to catch any use-after-free errors aggressively.
 			freeUserArenaChunk(s, a.refs[len(a.refs)-1])
 		} else {
 			lock(&userArenaState.lock)
@@ -358,6 +371,13 @@ func (a *userArena) free() {
 	// nil out a.active so that a race with freeing will more likely cause a crash.
 	a.active = nil
 	a.refs = nil
+	a.checks = nil
+
+	if debug.arenacheck > 0 {
+		// Make sure the chunks fault once free returns, rather than at
+		// the end of the current GC cycle.
+		userArenaCheckFault()
+	}
 }
 
 // alloc reserves space in the current chunk or calls refill and reserves space
@@ -374,6 +394,13 @@ func (a *userArena) alloc(typ *_type, cap int) unsafe.Pointer {
 		}
 		s = a.refill()
 	}
+	if debug.arenacheck > 0 && uintptr(x) >= s.base() && uintptr(x) < s.limit {
+		size := typ.Size_
+		if cap >= 0 {
+			size *= uintptr(cap)
+		}
+		a.checks[len(a.checks)-1].recordAlloc(uintptr(x), size)
+	}
 	return x
 }
 
@@ -418,6 +445,9 @@ func (a *userArena) refill() *mspan {
 	}
 	a.refs = append(a.refs, x)
 	a.active = s
+	if debug.arenacheck > 0 {
+		a.checks = append(a.checks, newUserArenaChunkRecord(s))
+	}
 	return s
 }
 
@@ -442,6 +472,13 @@ var userArenaState struct {
 	//
 	// Protected by lock.
 	fault []liveUserArenaChunk
+
+	// check contains the allocation records of the user arena chunks,
+	// if debug.arenacheck is set. There's at most one record for each
+	// chunk address.
+	//
+	// Protected by lock.
+	check []*userArenaChunkRecord
 }
 
 // userArenaNextFree reserves space in the user arena for an item of the specified
@@ -1128,3 +1165,136 @@ func (h *mheap) allocUserArenaChunk() *mspan {
 
 	return s
 }
+
+// userArenaCheckStackDepth is the maximum depth of the stacks recorded for
+// debug.arenacheck.
+const userArenaCheckStackDepth = 32
+
+// userArenaChunkRecord records the objects allocated in a user arena chunk,
+// and where the arena was freed, if debug.arenacheck is set.
+type userArenaChunkRecord struct {
+	base uintptr
+	objs []userArenaObjRecord
+	free []uintptr // stack of the call to free
+}
+
+type userArenaObjRecord struct {
+	addr, size uintptr
+	stk        []uintptr // stack of the allocation
+}
+
+// newUserArenaChunkRecord returns an empty allocation record for s, replacing
+// any previous record for the same chunk.
+func newUserArenaChunkRecord(s *mspan) *userArenaChunkRecord {
+	r := &userArenaChunkRecord{base: s.base()}
+	for {
+		lock(&userArenaState.lock)
+		check := userArenaState.check
+		for i := range check {
+			if check[i].base == r.base {
+				check[i] = r
+				unlock(&userArenaState.lock)
+				return r
+			}
+		}
+		if len(check) < cap(check) {
+			userArenaState.check = append(check, r)
+			unlock(&userArenaState.lock)
+			return r
+		}
+		unlock(&userArenaState.lock)
+
+		// Grow the list without holding the lock, and retry.
+		grown := make([]*userArenaChunkRecord, 0, 2*cap(check)+8)
+		lock(&userArenaState.lock)
+		if cap(userArenaState.check) == cap(check) {
+			userArenaState.check = append(grown, userArenaState.check...)
+		}
+		unlock(&userArenaState.lock)
+	}
+}
+
+// userArenaCheckFault waits for the end of the current GC cycle, if user
+// arena chunks were freed during it, and sets these chunks to fault.
+func userArenaCheckFault() {
+	for {
+		lock(&userArenaState.lock)
+		pending := len(userArenaState.fault) > 0
+		unlock(&userArenaState.lock)
+		if !pending {
+			return
+		}
+		gcWaitOnMark(work.cycles.Load())
+
+		// The end of the cycle sets the chunks to fault too, but it may
+		// not have happened yet.
+		mp := acquirem()
+		if gcphase == _GCoff {
+			lock(&userArenaState.lock)
+			faultList := userArenaState.fault
+			userArenaState.fault = nil
+			unlock(&userArenaState.lock)
+			for _, lc := range faultList {
+				lc.mspan.setUserArenaChunkToFault()
+			}
+			KeepAlive(faultList)
+		}
+		releasem(mp)
+	}
+}
+
+// recordAlloc records the allocation of an object of size bytes at addr.
+func (r *userArenaChunkRecord) recordAlloc(addr, size uintptr) {
+	stk := make([]uintptr, userArenaCheckStackDepth)
+	// Start at the caller of the arena_ function, in package arena.
+	stk = stk[:callers(4, stk)]
+	r.objs = append(r.objs, userArenaObjRecord{addr, size, stk})
+}
+
+// userArenaCheckReport prints the allocation and free stacks of the object
+// at addr in a freed user arena chunk, if debug.arenacheck recorded them.
+//
+// It's called while crashing, so it doesn't acquire userArenaState.lock.
+func userArenaCheckReport(addr uintptr) {
+	if debug.arenacheck == 0 {
+		return
+	}
+	for _, r := range userArenaState.check {
+		if addr < r.base || addr >= r.base+userArenaChunkBytes {
+			continue
+		}
+		for _, o := range r.objs {
+			if addr >= o.addr && addr < o.addr+o.size {
+				print("\nobject ", hex(o.addr), " of size ", o.size, " allocated at:\n")
+				userArenaPrintStack(o.stk)
+				break
+			}
+		}
+		if r.free != nil {
+			print("\narena freed at:\n")
+			userArenaPrintStack(r.free)
+		}
+		print("\n")
+		return
+	}
+}
+
+// userArenaPrintStack prints a stack recorded by callers. It doesn't use
+// CallersFrames, which allocates.
+func userArenaPrintStack(stk []uintptr) {
+	for _, pc := range stk {
+		f := findfunc(pc)
+		if !f.valid() {
+			print("?(...)\n")
+			continue
+		}
+		// pc is a return address, and there is one for each
+		// logical frame, so the first inlined frame at pc-1 is
+		// the one it stands for.
+		u, uf := newInlineUnwinder(f, pc-1)
+		file, line := u.fileLine(uf)
+		printFuncName(u.srcFunc(uf).name())
+		print("(...)\n")
+		print("\t", file, ":", line, "\n")
+	}
+}
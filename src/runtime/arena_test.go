@@ -8,6 +8,7 @@ import (
 	"internal/goarch"
 	"internal/runtime/atomic"
 	"reflect"
+	"regexp"
 	. "runtime"
 	"runtime/debug"
 	"testing"
@@ -539,3 +540,17 @@ func TestUserArenaClonePanic(t *testing.T) {
 		t.Errorf("expected panic from Clone")
 	}
 }
+
+func TestUserArenaCheck(t *testing.T) {
+	switch GOOS {
+	case "js", "plan9", "wasip1", "windows":
+		t.Skipf("use-after-free reports are not supported on %s", GOOS)
+	}
+	output := runTestProg(t, "testprog", "ArenaUseAfterFree", "GODEBUG=arenacheck=1")
+	want := regexp.MustCompile(`(?s)accessed data from freed user arena 0x[0-9a-f]+\n\n` +
+		`object 0x[0-9a-f]+ of size \d+ allocated at:\narena\.New\[\.\.\.\]\(\.\.\.\)\n.*main\.newArenaValue.*` +
+		`arena freed at:\narena\.\(\*Arena\)\.Free\(\.\.\.\)\n.*main\.freeArena.*fatal error: fault`)
+	if !want.MatchString(output) {
+		t.Errorf("output does not match %s:\n%s", want, output)
+	}
+}
@@ -167,6 +167,27 @@ func SetPanicOnFault(enabled bool) bool {
 // The heap dump format is defined at https://golang.org/s/go15heapdump.
 func WriteHeapDump(fd uintptr)
 
+// WriteHeapSnapshot runs a garbage collection and writes a snapshot of
+// the heap to the given file descriptor. The snapshot records the heap
+// objects, with their sizes, their types when known, and the pointers
+// between them, and the roots of the heap: global variables, stack frames
+// and finalizers. It can be analyzed with "go tool heapsnapshot".
+//
+// Like [WriteHeapDump], WriteHeapSnapshot suspends the execution of all
+// goroutines until the snapshot is completely written, so the file
+// descriptor must not be connected to a pipe or socket whose other end
+// is in the same Go process.
+//
+// The snapshot format is documented, and snapshots can be read, by the
+// [runtime/debug/heapsnapshot] package.
+func WriteHeapSnapshot(fd uintptr) {
+	runtime.GC()
+	writeHeapSnapshot(fd)
+}
+
+// Implemented in package runtime.
+func writeHeapSnapshot(fd uintptr)
+
 // SetTraceback sets the amount of detail printed by the runtime in
 // the traceback it prints before exiting due to an unrecovered panic
 // or an internal runtime error.
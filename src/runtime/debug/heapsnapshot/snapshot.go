@@ -0,0 +1,254 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package heapsnapshot reads the heap snapshots written by
+// [runtime/debug.WriteHeapSnapshot].
+//
+// # Format
+//
+// A heap snapshot starts with the line "go heap snapshot 1\n", followed by
+// a sequence of records. All integers are unsigned varints, as written by
+// [encoding/binary.AppendUvarint], and all strings are a length followed by
+// that many bytes. Each record starts with a tag:
+//
+//	0 end of the snapshot.
+//	1 params: pointer size, GOARCH, GOOS, Go version. This is the first record.
+//	2 type: address of the type descriptor, name, size.
+//	3 object: address, size, address of the type descriptor or 0, edges.
+//	4 root: kind, description, address or 0, edges.
+//
+// The edges of an object or root are a sequence of pairs of the address
+// of a heap object and the offset in the object or root of the pointer to
+// it, terminated by a 0 address. Pointers into the middle of an object
+// reference the object. The root kinds are those of [RootKind]. The roots
+// of global variables are the data and bss segments of the program, and
+// their address is the start of the segment; the roots of stacks are
+// stack frames, and their address is the stack pointer of the frame.
+//
+// The address and size of an object are those of its allocation slot,
+// which may include an 8-byte header holding the type of the object, or
+// padding. The runtime records the type of objects larger than 512 bytes
+// that contain pointers and of objects allocated directly from the page
+// heap. Other objects have no type. A type record precedes the first
+// object of that type; it may be repeated.
+package heapsnapshot
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"internal/saferio"
+	"io"
+	"slices"
+	"strings"
+)
+
+// A Snapshot is a heap snapshot.
+type Snapshot struct {
+	PtrSize   int    // size of a pointer in bytes
+	GOARCH    string // architecture of the program
+	GOOS      string // operating system of the program
+	GoVersion string // Go version of the program
+
+	Types   []*Type  // types of the objects, sorted by name
+	Objects []Object // heap objects, sorted by address
+	Roots   []Root   // roots, in the order of the snapshot
+}
+
+// A Type is the type of heap objects.
+type Type struct {
+	ID   uint64 // address of the type descriptor in the program
+	Name string // name of the type, like "[]*main.T"
+	Size uint64 // size of a value; an object may hold several values
+}
+
+// An Object is a heap object.
+type Object struct {
+	Addr  uint64
+	Size  uint64
+	Type  *Type // nil if the type is unknown
+	Edges []Edge
+}
+
+// An Edge is a pointer to a heap object.
+type Edge struct {
+	To     uint64 // address of the object
+	Offset uint64 // offset of the pointer in the object or root
+}
+
+// A Root is a root of the heap, such as a stack frame.
+type Root struct {
+	Kind        RootKind
+	Description string // like "bss" or "goroutine 1 main.main"
+	Addr        uint64 // address of the root, or 0 if not applicable
+	Edges       []Edge
+}
+
+// A RootKind is the kind of a [Root].
+type RootKind uint8
+
+const (
+	RootGlobal    RootKind = 1 + iota // data or bss segment
+	RootStack                         // stack frame of a goroutine
+	RootFinalizer                     // finalizer or cleanup
+)
+
+func (k RootKind) String() string {
+	switch k {
+	case RootGlobal:
+		return "global"
+	case RootStack:
+		return "stack"
+	case RootFinalizer:
+		return "finalizer"
+	}
+	return fmt.Sprintf("RootKind(%d)", uint8(k))
+}
+
+// Lookup returns the index in s.Objects of the object at address addr,
+// or -1 if there is none.
+func (s *Snapshot) Lookup(addr uint64) int {
+	i, ok := slices.BinarySearchFunc(s.Objects, addr, func(o Object, addr uint64) int {
+		return cmp.Compare(o.Addr, addr)
+	})
+	if !ok {
+		return -1
+	}
+	return i
+}
+
+const header = "go heap snapshot 1\n"
+
+const (
+	tagEOF = iota
+	tagParams
+	tagType
+	tagObject
+	tagRoot
+)
+
+// ErrFormat is returned when the data is not a valid heap snapshot.
+var ErrFormat = errors.New("heapsnapshot: invalid format")
+
+// Read reads a heap snapshot from r.
+func Read(r io.Reader) (*Snapshot, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	hdr := make([]byte, len(header))
+	if _, err := io.ReadFull(d.r, hdr); err != nil || string(hdr) != header {
+		return nil, fmt.Errorf("%w: bad header", ErrFormat)
+	}
+	s := new(Snapshot)
+	types := make(map[uint64]*Type)
+	for i := 0; ; i++ {
+		tag := d.uvarint()
+		if d.err != nil {
+			break
+		}
+		if (i == 0) != (tag == tagParams) {
+			d.fail("params record is not first")
+			break
+		}
+		switch tag {
+		case tagEOF:
+			for _, t := range types {
+				s.Types = append(s.Types, t)
+			}
+			slices.SortFunc(s.Types, func(a, b *Type) int {
+				return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+			})
+			slices.SortFunc(s.Objects, func(a, b Object) int {
+				return cmp.Compare(a.Addr, b.Addr)
+			})
+			return s, nil
+		case tagParams:
+			s.PtrSize = int(d.uvarint())
+			s.GOARCH = d.string()
+			s.GOOS = d.string()
+			s.GoVersion = d.string()
+		case tagType:
+			t := &Type{ID: d.uvarint(), Name: d.string(), Size: d.uvarint()}
+			if t.ID == 0 {
+				d.fail("type with address 0")
+			}
+			if _, ok := types[t.ID]; !ok {
+				types[t.ID] = t
+			}
+		case tagObject:
+			o := Object{Addr: d.uvarint(), Size: d.uvarint()}
+			if id := d.uvarint(); id != 0 {
+				o.Type = types[id]
+				if o.Type == nil && d.err == nil {
+					d.fail(fmt.Sprintf("object %#x of undefined type %#x", o.Addr, id))
+				}
+			}
+			o.Edges = d.edges()
+			s.Objects = append(s.Objects, o)
+		case tagRoot:
+			var rt Root
+			rt.Kind = RootKind(d.uvarint())
+			rt.Description = d.string()
+			rt.Addr = d.uvarint()
+			rt.Edges = d.edges()
+			s.Roots = append(s.Roots, rt)
+		default:
+			d.fail(fmt.Sprintf("unknown record tag %d", tag))
+		}
+	}
+	return nil, d.err
+}
+
+// A decoder decodes the records of a snapshot. After the first error,
+// its methods return zero values.
+type decoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *decoder) fail(msg string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: %s", ErrFormat, msg)
+	}
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		d.err = err
+	}
+	return x
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	b, err := saferio.ReadData(d.r, n)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		d.err = err
+	}
+	return string(b)
+}
+
+func (d *decoder) edges() []Edge {
+	var edges []Edge
+	for {
+		to := d.uvarint()
+		if to == 0 || d.err != nil {
+			return edges
+		}
+		edges = append(edges, Edge{To: to, Offset: d.uvarint()})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package heapsnapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// encode encodes a snapshot from a sequence of integers and strings.
+func encode(vals ...any) []byte {
+	b := []byte(header)
+	for _, v := range vals {
+		switch v := v.(type) {
+		case int:
+			b = binary.AppendUvarint(b, uint64(v))
+		case string:
+			b = binary.AppendUvarint(b, uint64(len(v)))
+			b = append(b, v...)
+		}
+	}
+	return b
+}
+
+var params = []any{tagParams, 8, "amd64", "linux", "go1.27"}
+
+func TestRead(t *testing.T) {
+	data := encode(append(params,
+		tagType, 0x100, "main.T", 64,
+		tagObject, 0x2000, 80, 0x100, 0x1000, 8, 0x2000, 16, 0,
+		tagType, 0x100, "main.T", 64, // repeated
+		tagObject, 0x1000, 16, 0, 0,
+		tagRoot, 1, "bss", 0x500, 0x2000, 24, 0,
+		tagRoot, 3, "finalizer", 0, 0,
+		tagEOF)...)
+	s, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := &Type{ID: 0x100, Name: "main.T", Size: 64}
+	want := &Snapshot{
+		PtrSize:   8,
+		GOARCH:    "amd64",
+		GOOS:      "linux",
+		GoVersion: "go1.27",
+		Types:     []*Type{typ},
+		Objects: []Object{
+			{Addr: 0x1000, Size: 16},
+			{Addr: 0x2000, Size: 80, Type: typ, Edges: []Edge{{0x1000, 8}, {0x2000, 16}}},
+		},
+		Roots: []Root{
+			{Kind: RootGlobal, Description: "bss", Addr: 0x500, Edges: []Edge{{0x2000, 24}}},
+			{Kind: RootFinalizer, Description: "finalizer"},
+		},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v\nwant %+v", s, want)
+	}
+	for _, tc := range []struct {
+		addr uint64
+		want int
+	}{{0x1000, 0}, {0x2000, 1}, {0x1008, -1}, {0, -1}} {
+		if got := s.Lookup(tc.addr); got != tc.want {
+			t.Errorf("Lookup(%#x) = %d, want %d", tc.addr, got, tc.want)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrFormat},
+		{"bad header", []byte("go heap dump 1\n"), ErrFormat},
+		{"no params", encode(tagEOF), ErrFormat},
+		{"no EOF", encode(params...), io.ErrUnexpectedEOF},
+		{"truncated string", encode(tagParams, 8, 100), io.ErrUnexpectedEOF},
+		{"truncated edges", encode(append(params, tagObject, 0x1000, 16, 0, 0x1000)...), io.ErrUnexpectedEOF},
+		{"unknown tag", encode(append(params, 42, tagEOF)...), ErrFormat},
+		{"undefined type", encode(append(params, tagObject, 0x1000, 16, 0x100, 0, tagEOF)...), ErrFormat},
+		{"second params", encode(append(params, params...)...), ErrFormat},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(tc.data))
+			if !errors.Is(err, tc.want) {
+				t.Errorf("Read returned %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
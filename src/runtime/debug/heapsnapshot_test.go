@@ -0,0 +1,86 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"os"
+	"runtime"
+	. "runtime/debug"
+	"runtime/debug/heapsnapshot"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// snapNode is large enough for its objects to have a malloc header.
+type snapNode struct {
+	next *snapNode
+	pad  [1000]byte
+}
+
+var snapGlobal *snapNode
+
+func TestWriteHeapSnapshot(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skipf("WriteHeapSnapshot is not available on %s.", runtime.GOOS)
+	}
+	f, err := os.CreateTemp(t.TempDir(), "heapsnapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	snapGlobal = &snapNode{next: new(snapNode)}
+	// local keeps the pointer to the second node on the stack.
+	local := snapGlobal.next
+	WriteHeapSnapshot(f.Fd())
+	globalAddr := uint64(uintptr(unsafe.Pointer(snapGlobal)))
+	nextAddr := uint64(uintptr(unsafe.Pointer(local)))
+
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	s, err := heapsnapshot.Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PtrSize != int(unsafe.Sizeof(uintptr(0))) || s.GOARCH != runtime.GOARCH || s.GOOS != runtime.GOOS || s.GoVersion != runtime.Version() {
+		t.Errorf("got params %d %s/%s %s", s.PtrSize, s.GOOS, s.GOARCH, s.GoVersion)
+	}
+
+	// The objects are the malloc header followed by the node.
+	i := s.Lookup(globalAddr - 8)
+	if i < 0 {
+		t.Fatalf("no object at %#x", globalAddr-8)
+	}
+	o := s.Objects[i]
+	if o.Type == nil || o.Type.Name != "debug_test.snapNode" || o.Type.Size != uint64(unsafe.Sizeof(snapNode{})) {
+		t.Errorf("object type is %+v, want debug_test.snapNode", o.Type)
+	}
+	if o.Size < 8+uint64(unsafe.Sizeof(snapNode{})) {
+		t.Errorf("object size is %d, too small", o.Size)
+	}
+	if len(o.Edges) != 1 || o.Edges[0] != (heapsnapshot.Edge{To: nextAddr - 8, Offset: 8}) {
+		t.Errorf("object edges are %+v, want [{%#x 8}]", o.Edges, nextAddr-8)
+	}
+
+	var global, stack bool
+	for _, r := range s.Roots {
+		for _, e := range r.Edges {
+			switch {
+			case e.To == globalAddr-8 && r.Kind == heapsnapshot.RootGlobal:
+				global = true
+			case e.To == nextAddr-8 && r.Kind == heapsnapshot.RootStack && strings.HasSuffix(r.Description, "TestWriteHeapSnapshot"):
+				stack = true
+			}
+		}
+	}
+	if !global {
+		t.Errorf("no global root referencing %#x", globalAddr)
+	}
+	if !stack {
+		t.Errorf("no stack root of TestWriteHeapSnapshot referencing %#x", nextAddr)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug
+
+import (
+	"math"
+	"slices"
+	"sync"
+)
+
+// Implemented in package runtime.
+func memoryPressureWait() (total, heapLive uint64, limit int64, cpuLimited bool)
+
+// MemoryPressure describes the memory use of the program relative to its
+// memory limit at the end of a garbage collection cycle. It is sent by the
+// runtime on the channels registered with [NotifyMemoryPressure].
+type MemoryPressure struct {
+	// Total is the memory used by the Go runtime, in bytes, as counted
+	// against the memory limit. See [SetMemoryLimit].
+	Total uint64
+
+	// HeapLive is the size of the heap objects found reachable by the
+	// garbage collection cycle, in bytes.
+	HeapLive uint64
+
+	// Limit is the memory limit, as set by [SetMemoryLimit].
+	Limit int64
+
+	// Level is the largest of the levels passed to NotifyMemoryPressure
+	// that Total reached, as a fraction of Limit, or 0 if Total is below
+	// all the levels.
+	Level float64
+
+	// CPULimited reports whether the garbage collector was limiting its
+	// CPU use during the cycle, which it does when it runs nearly
+	// continuously, typically because the memory limit is too low for the
+	// live heap. The memory limit may then be exceeded.
+	CPULimited bool
+}
+
+var memoryPressure struct {
+	once     sync.Once
+	mu       sync.Mutex
+	handlers map[chan<- MemoryPressure]*memoryPressureHandler
+}
+
+type memoryPressureHandler struct {
+	levels     []float64 // sorted
+	level      float64   // Level of the last sample
+	cpuLimited bool      // CPULimited of the last sample
+}
+
+// NotifyMemoryPressure causes the runtime to send a [MemoryPressure] on c
+// when the memory use of the program reaches one of the given levels, which
+// are fractions of the memory limit, or when the garbage collector starts
+// limiting its CPU use, so that the program can release memory, for example
+// by evicting entries from caches.
+//
+// The memory use is checked at the end of each garbage collection cycle.
+// A notification is sent when the memory use reaches a level that it was
+// below at the previous check, or when the garbage collector started
+// limiting its CPU use since the previous check. Levels may be greater
+// than 1, since the memory limit is a soft limit. No notifications for
+// levels are sent while the memory limit is [math.MaxInt64], which disables
+// it.
+//
+// The runtime does not block sending to c: the caller must ensure that c
+// has sufficient buffer space to keep up with the expected rate of
+// notifications. A buffer of size 1 suffices for a single consumer that
+// only needs the latest state.
+//
+// It is allowed to call NotifyMemoryPressure many times with different
+// channels. Calling it again with the same channel replaces the levels of
+// the previous call. NotifyMemoryPressure panics if a level is not
+// positive.
+func NotifyMemoryPressure(c chan<- MemoryPressure, levels ...float64) {
+	if c == nil {
+		panic("runtime/debug: NotifyMemoryPressure using nil channel")
+	}
+	for _, l := range levels {
+		if !(l > 0) || math.IsInf(l, 1) {
+			panic("runtime/debug: NotifyMemoryPressure using invalid level")
+		}
+	}
+	levels = slices.Clone(levels)
+	slices.Sort(levels)
+
+	memoryPressure.mu.Lock()
+	defer memoryPressure.mu.Unlock()
+	if memoryPressure.handlers == nil {
+		memoryPressure.handlers = make(map[chan<- MemoryPressure]*memoryPressureHandler)
+	}
+	memoryPressure.handlers[c] = &memoryPressureHandler{levels: levels}
+	memoryPressure.once.Do(func() {
+		go memoryPressureLoop()
+	})
+}
+
+// StopMemoryPressure causes the runtime to stop sending notifications on
+// c. When StopMemoryPressure returns, it is guaranteed that c will receive
+// no more notifications.
+func StopMemoryPressure(c chan<- MemoryPressure) {
+	memoryPressure.mu.Lock()
+	defer memoryPressure.mu.Unlock()
+	delete(memoryPressure.handlers, c)
+}
+
+// memoryPressureLoop delivers the memory pressure notifications after each
+// garbage collection cycle.
+func memoryPressureLoop() {
+	for {
+		total, heapLive, limit, cpuLimited := memoryPressureWait()
+		p := MemoryPressure{
+			Total:      total,
+			HeapLive:   heapLive,
+			Limit:      limit,
+			CPULimited: cpuLimited,
+		}
+		memoryPressure.mu.Lock()
+		for c, h := range memoryPressure.handlers {
+			p.Level = h.levelOf(total, limit)
+			if p.Level > h.level || cpuLimited && !h.cpuLimited {
+				select {
+				case c <- p:
+				default:
+				}
+			}
+			h.level, h.cpuLimited = p.Level, cpuLimited
+		}
+		memoryPressure.mu.Unlock()
+	}
+}
+
+// levelOf returns the largest level of h reached by total, given limit.
+func (h *memoryPressureHandler) levelOf(total uint64, limit int64) float64 {
+	if limit == math.MaxInt64 {
+		return 0
+	}
+	frac := float64(total) / float64(limit)
+	if limit <= 0 {
+		frac = math.Inf(1)
+	}
+	level := 0.0
+	for _, l := range h.levels {
+		if frac >= l {
+			level = l
+		}
+	}
+	return level
+}
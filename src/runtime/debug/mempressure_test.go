@@ -0,0 +1,86 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"math"
+	"runtime"
+	. "runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestNotifyMemoryPressure(t *testing.T) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	used := int64(ms.Sys - ms.HeapReleased)
+
+	c := make(chan MemoryPressure, 1)
+	NotifyMemoryPressure(c, 0.5, 8)
+	defer StopMemoryPressure(c)
+	quiet := make(chan MemoryPressure, 1)
+	NotifyMemoryPressure(quiet, 100)
+	defer StopMemoryPressure(quiet)
+
+	// With a limit well above the memory use, no level is reached.
+	defer SetMemoryLimit(SetMemoryLimit(100 * used))
+	runtime.GC()
+	runtime.GC()
+	select {
+	case p := <-c:
+		t.Fatalf("got notification %+v with no memory pressure", p)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Lower the limit so that the memory use is between the two levels.
+	SetMemoryLimit(used)
+	var p MemoryPressure
+	for i := 0; ; i++ {
+		if i == 100 {
+			t.Fatal("no notification")
+		}
+		runtime.GC()
+		select {
+		case p = <-c:
+		case <-time.After(10 * time.Millisecond):
+			continue
+		}
+		break
+	}
+	if p.Level != 0.5 || p.Limit != used || p.Total == 0 || p.HeapLive == 0 {
+		t.Errorf("got %+v, want Level 0.5 and Limit %d", p, used)
+	}
+
+	// The level was already reached, so there is no new notification.
+	runtime.GC()
+	runtime.GC()
+	select {
+	case p := <-c:
+		if !p.CPULimited {
+			t.Errorf("got notification %+v for a level already reached", p)
+		}
+	case <-time.After(10 * time.Millisecond):
+	}
+	select {
+	case p := <-quiet:
+		t.Errorf("got notification %+v for an unreached level", p)
+	default:
+	}
+
+	SetMemoryLimit(math.MaxInt64)
+}
+
+func TestNotifyMemoryPressurePanics(t *testing.T) {
+	for _, level := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NotifyMemoryPressure with level %v did not panic", level)
+				}
+			}()
+			NotifyMemoryPressure(make(chan MemoryPressure), level)
+		}()
+	}
+}
@@ -35,6 +35,16 @@ time.
 The GODEBUG variable controls debugging variables within the runtime.
 It is a comma-separated list of name=val pairs setting these named variables:
 
+	arenacheck: setting arenacheck=1 makes every access to a value allocated in
+	an arena of package arena fault once the arena is freed, except for values too
+	large for an arena chunk, which are allocated in the heap: the memory of a freed
+	arena is never reused before the garbage collector proves it unreferenced, and
+	Free waits for the end of any running garbage collection to make the memory
+	fault. The runtime also records the stacks of the allocations and of the calls
+	to Free, and the crash report for such an access includes the stacks of the
+	allocation of the value and of the freeing of its arena. This makes arena
+	allocation much slower.
+
 	clobberfree: setting clobberfree=1 causes the garbage collector to
 	clobber the memory content of an object with bad content when it frees
 	the object.
@@ -152,6 +162,15 @@ It is a comma-separated list of name=val pairs setting these named variables:
 		# bytes     memory allocated on the heap
 		# allocs    number of heap allocations
 
+	lockorder: setting lockorder=1 in programs built with -race makes the runtime
+	check the order in which goroutines acquire sync.Mutex and sync.RWMutex locks.
+	When a goroutine acquires a lock B while holding a lock A, and a goroutine
+	previously acquired A while holding B, possibly through a chain of other locks,
+	the program prints a report with the stacks of these acquisitions to standard
+	error, because goroutines doing so concurrently may deadlock, even if they
+	did not in this run. Setting lockorder=2 makes the program crash after the
+	first report. Without -race, the setting has no effect.
+
 	madvdontneed: setting madvdontneed=0 will use MADV_FREE
 	instead of MADV_DONTNEED on Linux when returning memory to the
 	kernel. This is more efficient, but means RSS numbers will
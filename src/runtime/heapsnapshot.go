@@ -0,0 +1,301 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Implementation of runtime/debug.WriteHeapSnapshot. Writes all
+// objects in the heap, with their types when known and the pointers
+// between them, and the roots of the heap, in the format documented by
+// package runtime/debug/heapsnapshot.
+//
+// The snapshot is written with the world stopped, using the buffered
+// writer of heapdump.go.
+
+package runtime
+
+import (
+	"internal/abi"
+	"internal/goarch"
+	"internal/goos"
+	"unsafe"
+)
+
+const (
+	snapTagEOF    = 0
+	snapTagParams = 1
+	snapTagType   = 2
+	snapTagObject = 3
+	snapTagRoot   = 4
+
+	snapRootGlobal    = 1
+	snapRootStack     = 2
+	snapRootFinalizer = 3
+)
+
+var snapHeader = []byte("go heap snapshot 1\n")
+
+//go:linkname runtime_debug_writeHeapSnapshot runtime/debug.writeHeapSnapshot
+func runtime_debug_writeHeapSnapshot(fd uintptr) {
+	stw := stopTheWorld(stwWriteHeapDump)
+	systemstack(func() {
+		writeheapsnapshot_m(fd)
+	})
+	startTheWorld(stw)
+}
+
+func writeheapsnapshot_m(fd uintptr) {
+	assertWorldStopped()
+
+	gp := getg()
+	casGToWaiting(gp.m.curg, _Grunning, waitReasonDumpingHeap)
+	dumpfd = fd
+
+	for _, s := range mheap_.allspans {
+		if s.state.get() == mSpanInUse {
+			s.ensureSwept()
+		}
+	}
+	// Types are identified by their address, and the type cache of
+	// heapdump.go only remembers which ones were written.
+	memclrNoHeapPointers(unsafe.Pointer(&typecache), unsafe.Sizeof(typecache))
+
+	dwrite(unsafe.Pointer(&snapHeader[0]), uintptr(len(snapHeader)))
+	dumpint(snapTagParams)
+	dumpint(goarch.PtrSize)
+	dumpstr(goarch.GOARCH)
+	dumpstr(goos.GOOS)
+	dumpstr(buildVersion)
+	snapObjects()
+	snapGlobals()
+	snapGoroutines()
+	snapFinalizers()
+	dumpint(snapTagEOF)
+	flush()
+
+	dumpfd = 0
+	casgstatus(gp.m.curg, _Gwaiting, _Grunning)
+}
+
+// snapBase returns the address of the live heap object containing p,
+// or 0 if p doesn't point into a live heap object.
+func snapBase(p uintptr) uintptr {
+	s := spanOfHeap(p)
+	if s == nil {
+		return 0
+	}
+	i := s.objIndex(p)
+	if s.isFree(i) {
+		return 0
+	}
+	return s.base() + i*s.elemsize
+}
+
+// snapEdge writes an edge for the pointer slot at addr if it points into a
+// heap object. off is the offset of the slot in the object or root.
+func snapEdge(addr, off uintptr) {
+	if to := snapBase(*(*uintptr)(unsafe.Pointer(addr))); to != 0 {
+		dumpint(uint64(to))
+		dumpint(uint64(off))
+	}
+}
+
+// snapBlock writes the edges of the pointer slots of [b, b+n) described by
+// the pointer bitmap ptrmask, or of all the slots if ptrmask is nil.
+// off is the offset of b in the root.
+func snapBlock(b, n uintptr, ptrmask *uint8, off uintptr) {
+	for i := uintptr(0); i < n/goarch.PtrSize; i++ {
+		if ptrmask == nil || *addb(ptrmask, i/8)>>(i%8)&1 != 0 {
+			snapEdge(b+i*goarch.PtrSize, off+i*goarch.PtrSize)
+		}
+	}
+}
+
+// snapType writes the type record of t, unless it was already written.
+func snapType(t *_type) {
+	b := &typecache[t.Hash&(typeCacheBuckets-1)]
+	for i := range b.t {
+		if b.t[i] == t {
+			return
+		}
+	}
+	copy(b.t[1:], b.t[:typeCacheAssoc-1])
+	b.t[0] = t
+
+	dumpint(snapTagType)
+	dumpint(uint64(uintptr(unsafe.Pointer(t))))
+	dumpstr(toRType(t).string())
+	dumpint(uint64(t.Size_))
+}
+
+func snapObjects() {
+	for _, s := range mheap_.allspans {
+		if s.state.get() != mSpanInUse {
+			continue
+		}
+		size := s.elemsize
+		for i := uintptr(0); i < uintptr(s.nelems); i++ {
+			if s.isFree(i) {
+				continue
+			}
+			p := s.base() + i*size
+			// The runtime only knows the type of objects with a malloc
+			// header and of large objects.
+			var typ *_type
+			if s.spanclass.sizeclass() == 0 {
+				typ = s.largeType
+			} else if !s.spanclass.noscan() && !heapBitsInSpan(size) {
+				typ = *(**_type)(unsafe.Pointer(p))
+			}
+			if typ != nil {
+				snapType(typ)
+			}
+			dumpint(snapTagObject)
+			dumpint(uint64(p))
+			dumpint(uint64(size))
+			dumpint(uint64(uintptr(unsafe.Pointer(typ))))
+			tp := s.typePointersOfUnchecked(p)
+			for {
+				var addr uintptr
+				if tp, addr = tp.next(p + size); addr == 0 {
+					break
+				}
+				snapEdge(addr, addr-p)
+			}
+			dumpint(0)
+		}
+	}
+}
+
+func snapGlobals() {
+	for _, datap := range activeModules() {
+		dumpint(snapTagRoot)
+		dumpint(snapRootGlobal)
+		dumpstr("data")
+		dumpint(uint64(datap.data))
+		snapBlock(datap.data, datap.edata-datap.data, datap.gcdatamask.bytedata, 0)
+		dumpint(0)
+
+		dumpint(snapTagRoot)
+		dumpint(snapRootGlobal)
+		dumpstr("bss")
+		dumpint(uint64(datap.bss))
+		snapBlock(datap.bss, datap.ebss-datap.bss, datap.gcbssmask.bytedata, 0)
+		dumpint(0)
+	}
+}
+
+func snapGoroutines() {
+	forEachG(func(gp *g) {
+		status := readgstatus(gp)
+		if status == _Gdead || status == _Gdeadextra || status == _Grunning && gp.syscallsp == 0 {
+			return
+		}
+		var sp, pc, lr uintptr
+		if gp.syscallsp != 0 {
+			sp, pc = gp.syscallsp, gp.syscallpc
+		} else {
+			sp, pc, lr = gp.sched.sp, gp.sched.pc, gp.sched.lr
+		}
+		conservative := false
+		var u unwinder
+		for u.initAt(pc, sp, lr, gp, 0); u.valid(); u.next() {
+			conservative = snapFrame(gp, &u.frame, conservative)
+		}
+	})
+}
+
+// snapFrame writes the root of a stack frame of gp, and reports whether
+// the next frame must be scanned conservatively, like scanframeworker.
+func snapFrame(gp *g, frame *stkframe, conservative bool) bool {
+	dumpint(snapTagRoot)
+	dumpint(snapRootStack)
+	// "goroutine 1 main.main"
+	var buf [20]byte
+	name := funcname(frame.fn)
+	id := itoa(buf[:], gp.goid)
+	dumpint(uint64(len("goroutine  ") + len(id) + len(name)))
+	dwrite(unsafe.Pointer(unsafe.StringData("goroutine ")), uintptr(len("goroutine ")))
+	dwrite(unsafe.Pointer(&id[0]), uintptr(len(id)))
+	dwritebyte(' ')
+	dwrite(unsafe.Pointer(unsafe.StringData(name)), uintptr(len(name)))
+	dumpint(uint64(frame.sp))
+
+	isAsyncPreempt := frame.fn.funcID == abi.FuncID_asyncPreempt
+	isDebugCall := frame.fn.funcID == abi.FuncID_debugCallV2
+	if conservative || isAsyncPreempt || isDebugCall {
+		if frame.varp != 0 {
+			snapBlock(frame.sp, frame.varp-frame.sp, nil, 0)
+		}
+		if n := frame.argBytes(); n != 0 {
+			snapBlock(frame.argp, n, nil, frame.argp-frame.sp)
+		}
+		dumpint(0)
+		return isAsyncPreempt || isDebugCall
+	}
+
+	locals, args, objs := frame.getStackMap(false)
+	if locals.n > 0 {
+		size := uintptr(locals.n) * goarch.PtrSize
+		snapBlock(frame.varp-size, size, locals.bytedata, frame.varp-size-frame.sp)
+	}
+	if args.n > 0 {
+		snapBlock(frame.argp, uintptr(args.n)*goarch.PtrSize, args.bytedata, frame.argp-frame.sp)
+	}
+	// Stack objects are treated as live, although the garbage collector
+	// only keeps the ones referenced from the stack.
+	if frame.varp != 0 {
+		for i := range objs {
+			obj := &objs[i]
+			base := frame.varp
+			if obj.off >= 0 {
+				base = frame.argp
+			}
+			p := base + uintptr(obj.off)
+			if p < frame.sp {
+				continue
+			}
+			n, mask := obj.gcdata()
+			snapBlock(p, n, mask, p-frame.sp)
+		}
+	}
+	dumpint(0)
+	return false
+}
+
+func snapFinalizers() {
+	for _, s := range mheap_.allspans {
+		if s.state.get() != mSpanInUse {
+			continue
+		}
+		for sp := s.specials; sp != nil; sp = sp.next {
+			var desc string
+			var p1, p2 unsafe.Pointer
+			switch sp.kind {
+			case _KindSpecialFinalizer:
+				spf := (*specialfinalizer)(unsafe.Pointer(sp))
+				desc = "finalizer"
+				p1, p2 = unsafe.Pointer(s.base()+sp.offset), unsafe.Pointer(spf.fn)
+			case _KindSpecialCleanup:
+				spc := (*specialCleanup)(unsafe.Pointer(sp))
+				desc = "cleanup"
+				p1, p2 = unsafe.Pointer(spc.cleanup.fn), spc.cleanup.arg
+			default:
+				continue
+			}
+			snapRoot(snapRootFinalizer, desc, p1, p2)
+		}
+	}
+	iterate_finq(func(fn *funcval, obj unsafe.Pointer, nret uintptr, fint *_type, ot *ptrtype) {
+		snapRoot(snapRootFinalizer, "queued finalizer", obj, unsafe.Pointer(fn))
+	})
+}
+
+// snapRoot writes a root with edges to the objects containing p1 and p2.
+func snapRoot(kind uint64, desc string, p1, p2 unsafe.Pointer) {
+	dumpint(snapTagRoot)
+	dumpint(kind)
+	dumpstr(desc)
+	dumpint(0)
+	snapEdge(uintptr(unsafe.Pointer(&p1)), 0)
+	snapEdge(uintptr(unsafe.Pointer(&p2)), 0)
+	dumpint(0)
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"internal/stringslite"
+	"unsafe"
+)
+
+// Lock order checking for package sync.
+//
+// With GODEBUG=lockorder=1, sync.Mutex and sync.RWMutex in race-enabled
+// builds report their acquisitions and releases to the runtime, which
+// maintains a graph of the order in which goroutines acquire the locks. An
+// edge a → b means that some goroutine acquired b while holding a, and a
+// cycle means that goroutines acquiring the locks on the cycle concurrently
+// may deadlock. Cycles are reported when the edge closing them is first
+// added, whether or not the goroutines involved ever ran concurrently.
+//
+// Locks are identified by address, so a lock that is freed and whose memory
+// is reused for another lock merges the two in the graph. Successful
+// TryLock calls don't add edges, since they can't block.
+//
+// The checker state is only accessed from the runtime, which is not
+// instrumented by the race detector, and is protected by a semaphore, which
+// doesn't introduce happens-before edges between goroutines. It therefore
+// neither masks nor causes data race reports. For the same reason, it
+// doesn't use maps, whose implementation reports accesses to the race
+// detector.
+
+// lockOrderSema protects the lock order graph and the lockOrder field of
+// all goroutines.
+var lockOrderSema uint32 = 1
+
+// lockOrderStackDepth is the maximum depth of the stacks of lock
+// acquisitions recorded in the graph.
+const lockOrderStackDepth = 32
+
+// lockOrderHeld is the set of locks held by a goroutine.
+type lockOrderHeld struct {
+	goid  uint64 // ID of the goroutine, to detect the reuse of the g
+	locks []uintptr
+}
+
+// A lockOrderNode is a lock in the lock order graph.
+type lockOrderNode struct {
+	addr uintptr
+	out  []*lockOrderEdge
+	mark uint32 // last search visiting the node, see lockOrderPath
+}
+
+// A lockOrderEdge records that goroutine goid acquired to while holding from.
+type lockOrderEdge struct {
+	from, to *lockOrderNode
+	goid     uint64
+	stack    []uintptr
+}
+
+var lockOrder struct {
+	nodes  []*lockOrderNode // open addressing hash table, by address
+	count  int              // number of non-nil entries in nodes
+	search uint32           // number of path searches
+}
+
+// lockOrderLookup returns the node of the lock at addr, adding it to the
+// graph if needed.
+func lockOrderLookup(addr uintptr) *lockOrderNode {
+	if 4*(lockOrder.count+1) > 3*len(lockOrder.nodes) {
+		old := lockOrder.nodes
+		lockOrder.nodes = make([]*lockOrderNode, max(2*len(old), 64))
+		for _, n := range old {
+			if n != nil {
+				lockOrderInsert(n)
+			}
+		}
+	}
+	mask := uintptr(len(lockOrder.nodes) - 1)
+	for i := (addr >> 3) & mask; ; i = (i + 1) & mask {
+		n := lockOrder.nodes[i]
+		if n == nil {
+			n = &lockOrderNode{addr: addr}
+			lockOrder.nodes[i] = n
+			lockOrder.count++
+			return n
+		}
+		if n.addr == addr {
+			return n
+		}
+	}
+}
+
+// lockOrderInsert adds n to the hash table, which must have room for it.
+func lockOrderInsert(n *lockOrderNode) {
+	mask := uintptr(len(lockOrder.nodes) - 1)
+	i := (n.addr >> 3) & mask
+	for lockOrder.nodes[i] != nil {
+		i = (i + 1) & mask
+	}
+	lockOrder.nodes[i] = n
+}
+
+// lockOrderPath returns the edges of a path from n to target, appended to
+// path, and reports whether there is one. Nodes marked with the current
+// search have already been visited.
+func lockOrderPath(n, target *lockOrderNode, path []*lockOrderEdge) ([]*lockOrderEdge, bool) {
+	if n == target {
+		return path, true
+	}
+	n.mark = lockOrder.search
+	for _, e := range n.out {
+		if e.to.mark == lockOrder.search {
+			continue
+		}
+		if p, ok := lockOrderPath(e.to, target, append(path, e)); ok {
+			return p, true
+		}
+	}
+	return path, false
+}
+
+// sync_runtime_lockOrderAcquire records that the calling goroutine is about
+// to acquire the lock at addr, or that it acquired it with TryLock if try
+// is set, and reports the cycles closed by the new edges of the lock order
+// graph.
+//
+//go:linkname sync_runtime_lockOrderAcquire sync.runtime_lockOrderAcquire
+func sync_runtime_lockOrderAcquire(addr unsafe.Pointer, try bool) {
+	gp := getg()
+	a := uintptr(addr)
+	reported := false
+	semacquire(&lockOrderSema)
+	held := gp.lockOrder
+	if held == nil || held.goid != gp.goid {
+		held = &lockOrderHeld{goid: gp.goid}
+		gp.lockOrder = held
+	}
+	if !try {
+		var stk []uintptr
+		for _, h := range held.locks {
+			if h == a {
+				continue
+			}
+			from, to := lockOrderLookup(h), lockOrderLookup(a)
+			known := false
+			for _, e := range from.out {
+				if e.to == to {
+					known = true
+					break
+				}
+			}
+			if known {
+				continue
+			}
+			if stk == nil {
+				stk = make([]uintptr, lockOrderStackDepth)
+				stk = stk[:callers(1, stk)]
+			}
+			e := &lockOrderEdge{from: from, to: to, goid: gp.goid, stack: stk}
+			lockOrder.search++
+			if path, ok := lockOrderPath(to, from, nil); ok {
+				lockOrderReport(e, path)
+				reported = true
+			}
+			from.out = append(from.out, e)
+		}
+	}
+	held.locks = append(held.locks, a)
+	semrelease(&lockOrderSema)
+	if reported && debug.lockorder >= 2 {
+		fatal("sync: lock order inversion")
+	}
+}
+
+// sync_runtime_lockOrderRelease records the release of the lock at addr.
+// The lock may be released by a goroutine other than the one holding it.
+//
+//go:linkname sync_runtime_lockOrderRelease sync.runtime_lockOrderRelease
+func sync_runtime_lockOrderRelease(addr unsafe.Pointer) {
+	a := uintptr(addr)
+	semacquire(&lockOrderSema)
+	if !lockOrderForget(getg(), a) {
+		found := false
+		forEachG(func(gp *g) {
+			if !found {
+				found = lockOrderForget(gp, a)
+			}
+		})
+	}
+	semrelease(&lockOrderSema)
+}
+
+// lockOrderForget removes the last acquisition of the lock at addr from the
+// locks held by gp, and reports whether it found one.
+func lockOrderForget(gp *g, addr uintptr) bool {
+	held := gp.lockOrder
+	if held == nil || held.goid != gp.goid {
+		return false
+	}
+	for i := len(held.locks) - 1; i >= 0; i-- {
+		if held.locks[i] == addr {
+			held.locks = append(held.locks[:i], held.locks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// sync_runtime_lockOrderEnabled reports whether the lock order checker is
+// enabled. It is called once, during the initialization of package sync.
+//
+//go:linkname sync_runtime_lockOrderEnabled sync.runtime_lockOrderEnabled
+func sync_runtime_lockOrderEnabled() bool {
+	return raceenabled && debug.lockorder > 0
+}
+
+// lockOrderReport prints the cycle closed by e, where path is the path from
+// e.to to e.from.
+func lockOrderReport(e *lockOrderEdge, path []*lockOrderEdge) {
+	print("==================\n")
+	print("WARNING: POTENTIAL DEADLOCK\n")
+	print("Lock ", hex(e.to.addr), " acquired while holding lock ", hex(e.from.addr), " by goroutine ", e.goid, ":\n")
+	lockOrderPrintStack(e.stack)
+	for _, p := range path {
+		print("\nPrevious acquisition of lock ", hex(p.to.addr), " while holding lock ", hex(p.from.addr), " by goroutine ", p.goid, ":\n")
+		lockOrderPrintStack(p.stack)
+	}
+	print("==================\n")
+}
+
+// lockOrderPrintStack prints the frames of stk, omitting the leading frames
+// in package sync.
+func lockOrderPrintStack(stk []uintptr) {
+	frames := CallersFrames(stk)
+	inSync := true
+	for more := true; more; {
+		var f Frame
+		f, more = frames.Next()
+		if inSync && stringslite.HasPrefix(f.Function, "sync.") {
+			continue
+		}
+		inSync = false
+		print("  ", f.Function, "()\n")
+		print("      ", f.File, ":", f.Line, "\n")
+	}
+}
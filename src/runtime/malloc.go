@@ -1244,6 +1244,13 @@ func mallocgc(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	// Charge the allocation to the tracked profiler labels, if any.
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	// Post-malloc debug hooks.
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
@@ -169,6 +169,12 @@ func mallocgcSmallScanNoHeaderSC1(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -334,6 +340,12 @@ func mallocgcSmallScanNoHeaderSC2(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -499,6 +511,12 @@ func mallocgcSmallScanNoHeaderSC3(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -664,6 +682,12 @@ func mallocgcSmallScanNoHeaderSC4(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -829,6 +853,12 @@ func mallocgcSmallScanNoHeaderSC5(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -994,6 +1024,12 @@ func mallocgcSmallScanNoHeaderSC6(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -1159,6 +1195,12 @@ func mallocgcSmallScanNoHeaderSC7(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -1324,6 +1366,12 @@ func mallocgcSmallScanNoHeaderSC8(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -1489,6 +1537,12 @@ func mallocgcSmallScanNoHeaderSC9(size uintptr, typ *_type, needzero bool) unsaf
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -1654,6 +1708,12 @@ func mallocgcSmallScanNoHeaderSC10(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -1819,6 +1879,12 @@ func mallocgcSmallScanNoHeaderSC11(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -1984,6 +2050,12 @@ func mallocgcSmallScanNoHeaderSC12(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -2149,6 +2221,12 @@ func mallocgcSmallScanNoHeaderSC13(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -2314,6 +2392,12 @@ func mallocgcSmallScanNoHeaderSC14(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -2479,6 +2563,12 @@ func mallocgcSmallScanNoHeaderSC15(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -2644,6 +2734,12 @@ func mallocgcSmallScanNoHeaderSC16(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -2809,6 +2905,12 @@ func mallocgcSmallScanNoHeaderSC17(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -2974,6 +3076,12 @@ func mallocgcSmallScanNoHeaderSC18(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -3139,6 +3247,12 @@ func mallocgcSmallScanNoHeaderSC19(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -3304,6 +3418,12 @@ func mallocgcSmallScanNoHeaderSC20(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -3469,6 +3589,12 @@ func mallocgcSmallScanNoHeaderSC21(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -3634,6 +3760,12 @@ func mallocgcSmallScanNoHeaderSC22(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -3799,6 +3931,12 @@ func mallocgcSmallScanNoHeaderSC23(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -3964,6 +4102,12 @@ func mallocgcSmallScanNoHeaderSC24(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -4129,6 +4273,12 @@ func mallocgcSmallScanNoHeaderSC25(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -4294,6 +4444,12 @@ func mallocgcSmallScanNoHeaderSC26(size uintptr, typ *_type, needzero bool) unsa
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -4368,6 +4524,12 @@ func mallocgcTinySize1(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -4450,6 +4612,12 @@ func mallocgcTinySize1(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -4524,6 +4692,12 @@ func mallocgcTinySize2(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -4606,6 +4780,12 @@ func mallocgcTinySize2(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -4680,6 +4860,12 @@ func mallocgcTinySize3(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -4762,6 +4948,12 @@ func mallocgcTinySize3(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -4836,6 +5028,12 @@ func mallocgcTinySize4(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -4918,6 +5116,12 @@ func mallocgcTinySize4(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -4992,6 +5196,12 @@ func mallocgcTinySize5(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -5074,6 +5284,12 @@ func mallocgcTinySize5(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -5148,6 +5364,12 @@ func mallocgcTinySize6(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -5230,6 +5452,12 @@ func mallocgcTinySize6(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -5304,6 +5532,12 @@ func mallocgcTinySize7(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -5386,6 +5620,12 @@ func mallocgcTinySize7(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -5460,6 +5700,12 @@ func mallocgcTinySize8(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -5542,6 +5788,12 @@ func mallocgcTinySize8(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -5616,6 +5868,12 @@ func mallocgcTinySize9(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -5698,6 +5956,12 @@ func mallocgcTinySize9(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -5772,6 +6036,12 @@ func mallocgcTinySize10(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -5854,6 +6124,12 @@ func mallocgcTinySize10(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -5928,6 +6204,12 @@ func mallocgcTinySize11(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -6010,6 +6292,12 @@ func mallocgcTinySize11(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -6084,6 +6372,12 @@ func mallocgcTinySize12(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -6166,6 +6460,12 @@ func mallocgcTinySize12(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -6240,6 +6540,12 @@ func mallocgcTinySize13(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -6322,6 +6628,12 @@ func mallocgcTinySize13(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -6396,6 +6708,12 @@ func mallocgcTinySize14(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -6478,6 +6796,12 @@ func mallocgcTinySize14(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -6552,6 +6876,12 @@ func mallocgcTinySize15(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -6634,6 +6964,12 @@ func mallocgcTinySize15(size uintptr, typ *_type, needzero bool) unsafe.Pointer
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -6699,6 +7035,12 @@ func mallocgcSmallNoScanSC2(size uintptr, typ *_type, needzero bool) unsafe.Poin
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -6770,6 +7112,12 @@ func mallocgcSmallNoScanSC2(size uintptr, typ *_type, needzero bool) unsafe.Poin
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -6835,6 +7183,12 @@ func mallocgcSmallNoScanSC3(size uintptr, typ *_type, needzero bool) unsafe.Poin
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -6906,6 +7260,12 @@ func mallocgcSmallNoScanSC3(size uintptr, typ *_type, needzero bool) unsafe.Poin
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -6971,6 +7331,12 @@ func mallocgcSmallNoScanSC4(size uintptr, typ *_type, needzero bool) unsafe.Poin
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -7042,6 +7408,12 @@ func mallocgcSmallNoScanSC4(size uintptr, typ *_type, needzero bool) unsafe.Poin
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -7107,6 +7479,12 @@ func mallocgcSmallNoScanSC5(size uintptr, typ *_type, needzero bool) unsafe.Poin
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -7178,6 +7556,12 @@ func mallocgcSmallNoScanSC5(size uintptr, typ *_type, needzero bool) unsafe.Poin
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -7243,6 +7627,12 @@ func mallocgcSmallNoScanSC6(size uintptr, typ *_type, needzero bool) unsafe.Poin
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -7314,6 +7704,12 @@ func mallocgcSmallNoScanSC6(size uintptr, typ *_type, needzero bool) unsafe.Poin
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -7379,6 +7775,12 @@ func mallocgcSmallNoScanSC7(size uintptr, typ *_type, needzero bool) unsafe.Poin
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -7450,6 +7852,12 @@ func mallocgcSmallNoScanSC7(size uintptr, typ *_type, needzero bool) unsafe.Poin
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -7515,6 +7923,12 @@ func mallocgcSmallNoScanSC8(size uintptr, typ *_type, needzero bool) unsafe.Poin
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -7586,6 +8000,12 @@ func mallocgcSmallNoScanSC8(size uintptr, typ *_type, needzero bool) unsafe.Poin
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -7651,6 +8071,12 @@ func mallocgcSmallNoScanSC9(size uintptr, typ *_type, needzero bool) unsafe.Poin
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -7722,6 +8148,12 @@ func mallocgcSmallNoScanSC9(size uintptr, typ *_type, needzero bool) unsafe.Poin
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -7787,6 +8219,12 @@ func mallocgcSmallNoScanSC10(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -7858,6 +8296,12 @@ func mallocgcSmallNoScanSC10(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -7923,6 +8367,12 @@ func mallocgcSmallNoScanSC11(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -7994,6 +8444,12 @@ func mallocgcSmallNoScanSC11(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -8059,6 +8515,12 @@ func mallocgcSmallNoScanSC12(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -8130,6 +8592,12 @@ func mallocgcSmallNoScanSC12(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -8195,6 +8663,12 @@ func mallocgcSmallNoScanSC13(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -8266,6 +8740,12 @@ func mallocgcSmallNoScanSC13(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -8331,6 +8811,12 @@ func mallocgcSmallNoScanSC14(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -8402,6 +8888,12 @@ func mallocgcSmallNoScanSC14(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -8467,6 +8959,12 @@ func mallocgcSmallNoScanSC15(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -8538,6 +9036,12 @@ func mallocgcSmallNoScanSC15(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -8603,6 +9107,12 @@ func mallocgcSmallNoScanSC16(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -8674,6 +9184,12 @@ func mallocgcSmallNoScanSC16(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -8739,6 +9255,12 @@ func mallocgcSmallNoScanSC17(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -8810,6 +9332,12 @@ func mallocgcSmallNoScanSC17(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -8875,6 +9403,12 @@ func mallocgcSmallNoScanSC18(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -8946,6 +9480,12 @@ func mallocgcSmallNoScanSC18(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -9011,6 +9551,12 @@ func mallocgcSmallNoScanSC19(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -9082,6 +9628,12 @@ func mallocgcSmallNoScanSC19(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -9147,6 +9699,12 @@ func mallocgcSmallNoScanSC20(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -9218,6 +9776,12 @@ func mallocgcSmallNoScanSC20(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -9283,6 +9847,12 @@ func mallocgcSmallNoScanSC21(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -9354,6 +9924,12 @@ func mallocgcSmallNoScanSC21(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -9419,6 +9995,12 @@ func mallocgcSmallNoScanSC22(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -9490,6 +10072,12 @@ func mallocgcSmallNoScanSC22(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -9555,6 +10143,12 @@ func mallocgcSmallNoScanSC23(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -9626,6 +10220,12 @@ func mallocgcSmallNoScanSC23(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -9691,6 +10291,12 @@ func mallocgcSmallNoScanSC24(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -9762,6 +10368,12 @@ func mallocgcSmallNoScanSC24(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -9827,6 +10439,12 @@ func mallocgcSmallNoScanSC25(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -9898,6 +10516,12 @@ func mallocgcSmallNoScanSC25(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -9963,6 +10587,12 @@ func mallocgcSmallNoScanSC26(size uintptr, typ *_type, needzero bool) unsafe.Poi
 				}
 			}
 
+			if acctEnabled {
+				if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+					acctG.acctAllocBytes += int64(size)
+				}
+			}
+
 			if debug.malloc {
 				postMallocgcDebug(x, elemsize, typ)
 			}
@@ -10034,6 +10664,12 @@ func mallocgcSmallNoScanSC26(size uintptr, typ *_type, needzero bool) unsafe.Poi
 		}
 	}
 
+	if acctEnabled {
+		if acctG := getg().m.curg; acctG != nil && acctG.acct != nil {
+			acctG.acctAllocBytes += int64(size)
+		}
+	}
+
 	if debug.malloc {
 		postMallocgcDebug(x, elemsize, typ)
 	}
@@ -0,0 +1,63 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"internal/runtime/atomic"
+	_ "unsafe" // for linkname
+)
+
+// Memory pressure notifications.
+//
+// At the end of each GC cycle, if package runtime/debug asked for memory
+// pressure notifications, the runtime records the memory use of the program
+// relative to its memory limit and wakes the goroutine of runtime/debug
+// waiting in debug_memoryPressureWait, which delivers the notifications to
+// the subscribers.
+var memPressure struct {
+	// enabled is set once runtime/debug has waited for a sample.
+	enabled atomic.Bool
+
+	// pending is 1 when sema was released and the sample wasn't read yet.
+	pending atomic.Uint32
+
+	// sema is released when a new sample is available.
+	sema uint32
+
+	// The last sample.
+	total      atomic.Uint64
+	heapLive   atomic.Uint64
+	limit      atomic.Int64
+	cpuLimited atomic.Bool
+}
+
+// memPressureUpdate records a memory pressure sample at the end of a GC
+// cycle. It must be called while holding worldsema, after memstats.numgc
+// was incremented.
+func memPressureUpdate() {
+	if !memPressure.enabled.Load() {
+		return
+	}
+	memPressure.total.Store(gcController.mappedReady.Load())
+	memPressure.heapLive.Store(gcController.heapMarked)
+	memPressure.limit.Store(gcController.memoryLimit.Load())
+	memPressure.cpuLimited.Store(gcCPULimiter.lastEnabledCycle.Load() == memstats.numgc)
+	if memPressure.pending.CompareAndSwap(0, 1) {
+		semrelease(&memPressure.sema)
+	}
+}
+
+// debug_memoryPressureWait waits for the end of the next GC cycle, or returns
+// immediately if a GC cycle ended since the last call, and returns the total
+// memory used by the runtime, the size of the live heap, the memory limit,
+// and whether the GC CPU limiter was enabled during the cycle.
+//
+//go:linkname debug_memoryPressureWait runtime/debug.memoryPressureWait
+func debug_memoryPressureWait() (total, heapLive uint64, limit int64, cpuLimited bool) {
+	memPressure.enabled.Store(true)
+	semacquire(&memPressure.sema)
+	memPressure.pending.Store(0)
+	return memPressure.total.Load(), memPressure.heapLive.Load(), memPressure.limit.Load(), memPressure.cpuLimited.Load()
+}
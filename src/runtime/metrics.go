@@ -10,6 +10,7 @@ import (
 	"internal/godebugs"
 	"internal/runtime/atomic"
 	"internal/runtime/gc"
+	"internal/runtime/sys"
 	"unsafe"
 )
 
@@ -26,6 +27,7 @@ var (
 
 	sizeClassBuckets []float64
 	timeHistBuckets  []float64
+	runqLocalBuckets []float64
 )
 
 type metricData struct {
@@ -86,6 +88,9 @@ func initMetrics() {
 	sizeClassBuckets = append(sizeClassBuckets, float64Inf())
 
 	timeHistBuckets = timeHistogramMetricsBuckets()
+	// Local run queue lengths are bucketed by powers of two, with
+	// an empty run queue in a bucket of its own.
+	runqLocalBuckets = []float64{0, 1, 2, 4, 8, 16, 32, 64, 128, 256, float64Inf()}
 	metrics = map[string]metricData{
 		"/cgo/go-to-c-calls:calls": {
 			compute: func(_ *statAggregate, out *metricValue) {
@@ -512,6 +517,18 @@ func initMetrics() {
 				sched.timeToRun.write(out)
 			},
 		},
+		"/sched/netpoll/blocked:seconds": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindFloat64
+				out.scalar = float64bits(nsToSec(sched.netpollBlockedTime.Load()))
+			},
+		},
+		"/sched/netpoll/total:seconds": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindFloat64
+				out.scalar = float64bits(nsToSec(sched.netpollTime.Load()))
+			},
+		},
 		"/sched/pauses/stopping/gc:seconds": {
 			compute: func(_ *statAggregate, out *metricValue) {
 				sched.stwStoppingTimeGC.write(out)
@@ -532,6 +549,53 @@ func initMetrics() {
 				sched.stwTotalTimeOther.write(out)
 			},
 		},
+		"/sched/preemptions/requests:events": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = sched.preemptRequests.Load()
+			},
+		},
+		"/sched/runqueue/global:goroutines": {
+			deps: makeStatDepSet(schedStatsDep),
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.schedStats.runqGlobal
+			},
+		},
+		"/sched/runqueue/local:goroutines": {
+			deps: makeStatDepSet(schedStatsDep),
+			compute: func(in *statAggregate, out *metricValue) {
+				hist := out.float64HistOrInit(runqLocalBuckets)
+				copy(hist.counts, in.schedStats.runqLocal[:])
+			},
+		},
+		"/sched/steals/attempts:events": {
+			deps: makeStatDepSet(schedStatsDep),
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.schedStats.stealAttempts
+			},
+		},
+		"/sched/steals/goroutines:goroutines": {
+			deps: makeStatDepSet(schedStatsDep),
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.schedStats.stolenGoroutines
+			},
+		},
+		"/sched/steals/successes:events": {
+			deps: makeStatDepSet(schedStatsDep),
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.schedStats.stealSuccesses
+			},
+		},
+		"/sched/syscalls/handoffs:events": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = sched.syscallHandoffs.Load()
+			},
+		},
 		"/sched/threads/total:threads": {
 			deps: makeStatDepSet(schedStatsDep),
 			compute: func(in *statAggregate, out *metricValue) {
@@ -795,6 +859,12 @@ type schedStatsAggregate struct {
 	gWaiting  uint64
 	gCreated  uint64
 	threads   uint64
+
+	runqGlobal       uint64
+	runqLocal        [10]uint64 // number of Ps by local run queue length, in runqLocalBuckets
+	stealAttempts    uint64
+	stealSuccesses   uint64
+	stolenGoroutines uint64
 }
 
 // compute populates the schedStatsAggregate with values from the runtime.
@@ -811,11 +881,17 @@ func (a *schedStatsAggregate) compute() {
 
 	// Collect running/runnable from per-P run queues.
 	a.gCreated += sched.goroutinesCreated.Load()
+	a.stealAttempts += sched.stealAttempts.Load()
+	a.stealSuccesses += sched.stealSuccesses.Load()
+	a.stolenGoroutines += sched.stolenGoroutines.Load()
 	for _, p := range allp {
 		if p == nil || p.status == _Pdead {
 			break
 		}
 		a.gCreated += p.goroutinesCreated
+		a.stealAttempts += p.stealAttempts
+		a.stealSuccesses += p.stealSuccesses
+		a.stolenGoroutines += p.stolenGoroutines
 		switch p.status {
 		case _Prunning:
 			if thread, ok := setBlockOnExitSyscall(p); ok {
@@ -843,12 +919,14 @@ func (a *schedStatsAggregate) compute() {
 				runnable++
 			}
 			a.gRunnable += uint64(runnable)
+			a.runqLocal[min(sys.Len64(uint64(runnable)), len(a.runqLocal)-1)]++
 			break
 		}
 	}
 
 	// Global run queue.
-	a.gRunnable += uint64(sched.runq.size)
+	a.runqGlobal = uint64(sched.runq.size)
+	a.gRunnable += a.runqGlobal
 
 	// Account for Gs that are in _Gsyscall without a P.
 	nGsyscallNoP := sched.nGsyscallNoP.Load()
@@ -474,6 +474,18 @@ var allDesc = []Description{
 		Kind:        KindFloat64Histogram,
 		Cumulative:  true,
 	},
+	{
+		Name:        "/sched/netpoll/blocked:seconds",
+		Description: "Approximate total time threads have spent blocked in the network poller waiting for network I/O or timers, because there was no other work to do. This is a subset of /sched/netpoll/total:seconds.",
+		Kind:        KindFloat64,
+		Cumulative:  true,
+	},
+	{
+		Name:        "/sched/netpoll/total:seconds",
+		Description: "Approximate total time threads have spent polling the network for ready goroutines on behalf of the scheduler, including time spent blocked waiting for network I/O.",
+		Kind:        KindFloat64,
+		Cumulative:  true,
+	},
 	{
 		Name:        "/sched/pauses/stopping/gc:seconds",
 		Description: "Distribution of individual GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total GC-related stop-the-world time (/sched/pauses/total/gc:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.",
@@ -498,6 +510,46 @@ var allDesc = []Description{
 		Kind:        KindFloat64Histogram,
 		Cumulative:  true,
 	},
+	{
+		Name:        "/sched/preemptions/requests:events",
+		Description: "Count of requests to preempt a running goroutine, made by the scheduler when a goroutine runs for too long and by the garbage collector. A request may not succeed.",
+		Kind:        KindUint64,
+		Cumulative:  true,
+	},
+	{
+		Name:        "/sched/runqueue/global:goroutines",
+		Description: "Count of goroutines in the global run queue.",
+		Kind:        KindUint64,
+	},
+	{
+		Name:        "/sched/runqueue/local:goroutines",
+		Description: "Distribution of the lengths of the local run queues of the Ps, including the goroutine that will run next, at the time the metric is read. There is one sample per P, so an uneven distribution means that some Ps have more work than others.",
+		Kind:        KindFloat64Histogram,
+	},
+	{
+		Name:        "/sched/steals/attempts:events",
+		Description: "Count of attempts by a P to steal goroutines from the local run queue of another P, which happens when a P runs out of work.",
+		Kind:        KindUint64,
+		Cumulative:  true,
+	},
+	{
+		Name:        "/sched/steals/goroutines:goroutines",
+		Description: "Count of goroutines stolen by Ps from the local run queues of other Ps.",
+		Kind:        KindUint64,
+		Cumulative:  true,
+	},
+	{
+		Name:        "/sched/steals/successes:events",
+		Description: "Count of attempts to steal goroutines that stole at least one goroutine. This is a subset of /sched/steals/attempts:events.",
+		Kind:        KindUint64,
+		Cumulative:  true,
+	},
+	{
+		Name:        "/sched/syscalls/handoffs:events",
+		Description: "Count of times a P was handed off to another thread because the goroutine running on it was blocked in a system call or cgo call.",
+		Kind:        KindUint64,
+		Cumulative:  true,
+	},
 	{
 		Name:        "/sched/threads/total:threads",
 		Description: "The current count of live threads that are owned by the Go runtime.",
@@ -351,6 +351,10 @@ Below is the full list of supported metrics, ordered lexicographically.
 		The number of non-default behaviors executed by the net package
 		due to a non-default GODEBUG=netedns0=... setting.
 
+	/godebug/non-default-behavior/netmdns:events
+		The number of non-default behaviors executed by the net package
+		due to a non-default GODEBUG=netmdns=... setting.
+
 	/godebug/non-default-behavior/panicnil:events
 		The number of non-default behaviors executed by the runtime
 		package due to a non-default GODEBUG=panicnil=... setting.
@@ -556,6 +560,17 @@ Below is the full list of supported metrics, ordered lexicographically.
 		in a runnable state before actually running. Bucket counts
 		increase monotonically.
 
+	/sched/netpoll/blocked:seconds
+		Approximate total time threads have spent blocked in
+		the network poller waiting for network I/O or timers,
+		because there was no other work to do. This is a subset of
+		/sched/netpoll/total:seconds.
+
+	/sched/netpoll/total:seconds
+		Approximate total time threads have spent polling the network
+		for ready goroutines on behalf of the scheduler, including time
+		spent blocked waiting for network I/O.
+
 	/sched/pauses/stopping/gc:seconds
 		Distribution of individual GC-related stop-the-world stopping
 		latencies. This is the time it takes from deciding to stop the
@@ -588,6 +603,37 @@ Below is the full list of supported metrics, ordered lexicographically.
 		/sched/pauses/stopping/other:seconds). Bucket counts increase
 		monotonically.
 
+	/sched/preemptions/requests:events
+		Count of requests to preempt a running goroutine, made by the
+		scheduler when a goroutine runs for too long and by the garbage
+		collector. A request may not succeed.
+
+	/sched/runqueue/global:goroutines
+		Count of goroutines in the global run queue.
+
+	/sched/runqueue/local:goroutines
+		Distribution of the lengths of the local run queues of the Ps,
+		including the goroutine that will run next, at the time
+		the metric is read. There is one sample per P, so an uneven
+		distribution means that some Ps have more work than others.
+
+	/sched/steals/attempts:events
+		Count of attempts by a P to steal goroutines from the local run
+		queue of another P, which happens when a P runs out of work.
+
+	/sched/steals/goroutines:goroutines
+		Count of goroutines stolen by Ps from the local run queues of
+		other Ps.
+
+	/sched/steals/successes:events
+		Count of attempts to steal goroutines that stole at least one
+		goroutine. This is a subset of /sched/steals/attempts:events.
+
+	/sched/syscalls/handoffs:events
+		Count of times a P was handed off to another thread because
+		the goroutine running on it was blocked in a system call or cgo
+		call.
+
 	/sched/threads/total:threads
 		The current count of live threads that are owned by the Go
 		runtime.
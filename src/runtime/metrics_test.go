@@ -1584,3 +1584,105 @@ func TestReadMetricsSched(t *testing.T) {
 		t.Fatalf("output:\n%s\n\nwanted:\n%s", output, want)
 	}
 }
+
+func TestReadMetricsSchedWork(t *testing.T) {
+	if runtime.GOARCH == "wasm" {
+		t.Skip("GOMAXPROCS >1 not supported on wasm")
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	names := []string{
+		"/sched/netpoll/blocked:seconds",
+		"/sched/netpoll/total:seconds",
+		"/sched/preemptions/requests:events",
+		"/sched/runqueue/local:goroutines",
+		"/sched/steals/attempts:events",
+		"/sched/steals/goroutines:goroutines",
+		"/sched/steals/successes:events",
+		"/sched/syscalls/handoffs:events",
+	}
+	read := func() []metrics.Sample {
+		s := make([]metrics.Sample, len(names))
+		for i, name := range names {
+			s[i].Name = name
+		}
+		metrics.Read(s)
+		return s
+	}
+	before := read()
+
+	// Start goroutines from a single goroutine so that the other Ps steal them.
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Go(func() {
+			var wg2 sync.WaitGroup
+			for range 10 {
+				wg2.Go(func() {
+					time.Sleep(time.Millisecond)
+				})
+			}
+			wg2.Wait()
+		})
+	}
+	wg.Wait()
+
+	// Run a goroutine long enough for sysmon to preempt it.
+	var stop atomic.Bool
+	done := make(chan bool)
+	go func() {
+		for !stop.Load() {
+		}
+		done <- true
+	}()
+	time.Sleep(100 * time.Millisecond)
+	stop.Store(true)
+	<-done
+
+	// Block in a system call long enough for sysmon to hand the P off.
+	// Calling Fd puts the pipe in blocking mode.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	r.Fd()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte{0})
+	}()
+	if _, err := r.Read(make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	after := read()
+	value := func(s metrics.Sample) float64 {
+		if s.Value.Kind() == metrics.KindFloat64 {
+			return s.Value.Float64()
+		}
+		return float64(s.Value.Uint64())
+	}
+	for i, name := range names {
+		if name == "/sched/runqueue/local:goroutines" {
+			var n uint64
+			for _, c := range after[i].Value.Float64Histogram().Counts {
+				n += c
+			}
+			if n != 4 {
+				t.Errorf("%s has %d samples, want one per P", name, n)
+			}
+			continue
+		}
+		if b, a := value(before[i]), value(after[i]); a < b {
+			t.Errorf("%s decreased from %v to %v", name, b, a)
+		} else if a == b && name != "/sched/netpoll/blocked:seconds" && name != "/sched/netpoll/total:seconds" {
+			t.Errorf("%s did not increase from %v", name, b)
+		}
+	}
+	if a, s, g := value(after[4]), value(after[6]), value(after[5]); s > a || g < s {
+		t.Errorf("got %v steal attempts, %v successes and %v stolen goroutines", a, s, g)
+	}
+	if b, n := value(after[0]), value(after[1]); b > n {
+		t.Errorf("blocked netpoll time %v is greater than the total %v", b, n)
+	}
+}
@@ -1651,6 +1651,10 @@ func gcMarkTermination(stw worldStop) {
 		})
 	}
 
+	// Notify runtime/debug of the memory use after this cycle. Like
+	// gctrace, do this before dropping worldsema.
+	memPressureUpdate()
+
 	semrelease(&worldsema)
 	semrelease(&gcsema)
 	// Careful: another GC cycle may start now.
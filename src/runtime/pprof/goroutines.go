@@ -0,0 +1,130 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"internal/profilerecord"
+	"time"
+)
+
+// runtime_goroutineRecords is defined in runtime/tracebackrecord.go.
+func runtime_goroutineRecords() []profilerecord.GoroutineRecord
+
+// A Goroutine is a structured description of a goroutine, holding the
+// information printed by [runtime.Stack] for it.
+type Goroutine struct {
+	// ID is the goroutine ID.
+	ID uint64
+
+	// State is the scheduling state of the goroutine, such as "running",
+	// "runnable", "waiting", or "syscall".
+	State string
+
+	// WaitReason is the reason the goroutine is waiting, such as
+	// "chan receive" or "sync.Mutex.Lock", if State is "waiting".
+	WaitReason string
+
+	// WaitDuration is approximately how long the goroutine has been
+	// waiting or in a system call. It is zero if the goroutine is not
+	// blocked, and may be zero for goroutines that blocked recently.
+	WaitDuration time.Duration
+
+	// LockedToThread reports whether the goroutine is locked to its
+	// thread by [runtime.LockOSThread].
+	LockedToThread bool
+
+	// CreatorID is the ID of the goroutine that created this one, or 0
+	// if the goroutine was not created by another goroutine.
+	CreatorID uint64
+
+	// CreatedBy is the go statement that created the goroutine, or nil
+	// if it is not known or is hidden from tracebacks, as for the main
+	// goroutine.
+	CreatedBy *GoroutineFrame
+
+	// Labels are the profiler labels of the goroutine, as set by [Do] or
+	// [SetGoroutineLabels], or nil if it has none.
+	Labels map[string]string
+
+	// Stack is the call stack of the goroutine, innermost frame first,
+	// with the frames hidden in tracebacks omitted. It is nil if the
+	// stack couldn't be collected, which happens for goroutines running
+	// on another thread.
+	Stack []GoroutineFrame
+
+	// StackTruncated reports whether the outermost frames of Stack were
+	// dropped because the stack is too deep.
+	StackTruncated bool
+}
+
+// A GoroutineFrame is a frame of a goroutine stack.
+type GoroutineFrame struct {
+	// Function is the package path-qualified function name.
+	Function string
+
+	// File and Line are the source position of the frame.
+	File string
+	Line int
+
+	// Args is the textual representation of the function arguments in the
+	// format of tracebacks, without the parentheses, such as
+	// "0x1, {0xc000012345, 0x5}". It is empty for functions without
+	// arguments and for inlined frames, whose arguments are not available.
+	Args string
+
+	// Inlined reports whether the call was inlined into its caller.
+	Inlined bool
+}
+
+// Goroutines returns a description of every goroutine, starting with the
+// calling goroutine and excluding the goroutines internal to the runtime,
+// like [runtime.Stack] with all set to true. The stack of the calling
+// goroutine starts with the call to Goroutines.
+//
+// Goroutines stops the world while it collects the stacks, so it is
+// expensive in programs with many goroutines.
+func Goroutines() []Goroutine {
+	records := runtime_goroutineRecords()
+	gs := make([]Goroutine, len(records))
+	for i, r := range records {
+		g := &gs[i]
+		*g = Goroutine{
+			ID:             r.ID,
+			State:          r.Status,
+			WaitReason:     r.WaitReason,
+			WaitDuration:   time.Duration(r.WaitDuration),
+			LockedToThread: r.LockedToThread,
+			CreatorID:      r.CreatorID,
+			StackTruncated: r.FramesTruncated,
+		}
+		if r.CreatedBy.Function != "" {
+			f := goroutineFrame(r.CreatedBy)
+			g.CreatedBy = &f
+		}
+		if l := (*labelMap)(r.Labels); l != nil && len(l.Set.List) > 0 {
+			g.Labels = make(map[string]string, len(l.Set.List))
+			for _, lbl := range l.Set.List {
+				g.Labels[lbl.Key] = lbl.Value
+			}
+		}
+		if !r.StackUnavailable {
+			g.Stack = make([]GoroutineFrame, len(r.Frames))
+			for j, f := range r.Frames {
+				g.Stack[j] = goroutineFrame(f)
+			}
+		}
+	}
+	return gs
+}
+
+func goroutineFrame(f profilerecord.FrameRecord) GoroutineFrame {
+	return GoroutineFrame{
+		Function: f.Function,
+		File:     f.File,
+		Line:     f.Line,
+		Args:     f.Args,
+		Inlined:  f.Inlined,
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+//go:noinline
+func goroutinesBlockedFunc(c chan struct{}) {
+	<-c
+}
+
+func TestGoroutines(t *testing.T) {
+	c := make(chan struct{})
+	defer close(c)
+	Do(context.Background(), Labels("goroutines-test", "blocked"), func(ctx context.Context) {
+		go goroutinesBlockedFunc(c)
+	})
+
+	var self, blocked *Goroutine
+	for i := 0; blocked == nil || blocked.WaitReason != "chan receive"; i++ {
+		if i == 1000 {
+			t.Fatalf("goroutine blocked in goroutinesBlockedFunc not found")
+		}
+		runtime.Gosched()
+		gs := Goroutines()
+		if len(gs) == 0 {
+			t.Fatal("Goroutines returned no goroutines")
+		}
+		self, blocked = &gs[0], nil
+		for i := range gs {
+			for _, f := range gs[i].Stack {
+				if strings.HasSuffix(f.Function, "pprof.goroutinesBlockedFunc") {
+					blocked = &gs[i]
+				}
+			}
+		}
+	}
+
+	if self.State != "running" || len(self.Stack) < 2 || !strings.HasSuffix(self.Stack[1].Function, "pprof.TestGoroutines") {
+		t.Errorf("first goroutine = %+v, want the calling goroutine", self)
+	}
+
+	if blocked.State != "waiting" || blocked.WaitReason != "chan receive" {
+		t.Errorf("state = %q (%q), want waiting (chan receive)", blocked.State, blocked.WaitReason)
+	}
+	if blocked.CreatorID != self.ID {
+		t.Errorf("CreatorID = %d, want %d", blocked.CreatorID, self.ID)
+	}
+	if blocked.CreatedBy == nil || !strings.Contains(blocked.CreatedBy.Function, "TestGoroutines") || !strings.HasSuffix(blocked.CreatedBy.File, "goroutines_test.go") {
+		t.Errorf("CreatedBy = %+v, want TestGoroutines", blocked.CreatedBy)
+	}
+	if got := blocked.Labels["goroutines-test"]; got != "blocked" || len(blocked.Labels) != 1 {
+		t.Errorf("Labels = %v, want map[goroutines-test:blocked]", blocked.Labels)
+	}
+	for _, f := range blocked.Stack {
+		if strings.HasSuffix(f.Function, "pprof.goroutinesBlockedFunc") {
+			if !strings.HasSuffix(f.File, "goroutines_test.go") || f.Line == 0 {
+				t.Errorf("frame %+v has no source position", f)
+			}
+			if f.Args == "" {
+				t.Errorf("frame %+v has no arguments", f)
+			}
+		}
+		if strings.HasPrefix(f.Function, "runtime.") {
+			t.Errorf("unexpected runtime frame %+v", f)
+		}
+	}
+}
+
+//go:noinline
+func goroutinesDeepFunc(depth int, c chan struct{}) {
+	if depth == 0 {
+		<-c
+		return
+	}
+	goroutinesDeepFunc(depth-1, c)
+}
+
+func TestGoroutinesDeepStacks(t *testing.T) {
+	// Many deep stacks don't fit in the initial frame storage, so
+	// collecting them needs to retry with more room.
+	const n, depth = 50, 200
+	c := make(chan struct{})
+	defer close(c)
+	for range n {
+		go goroutinesDeepFunc(depth, c)
+	}
+
+	for i := 0; ; i++ {
+		found := 0
+		for _, g := range Goroutines() {
+			deep := 0
+			for _, f := range g.Stack {
+				if strings.HasSuffix(f.Function, "pprof.goroutinesDeepFunc") {
+					deep++
+				}
+			}
+			if deep == depth+1 && g.WaitReason == "chan receive" {
+				found++
+			}
+		}
+		if found == n {
+			break
+		}
+		if i == 1000 {
+			t.Fatalf("found %d goroutines with complete stacks, want %d", found, n)
+		}
+		runtime.Gosched()
+	}
+}
@@ -33,6 +33,11 @@ func labelValue(ctx context.Context) labelMap {
 // that admits incremental immutable modification more efficiently.
 type labelMap struct {
 	label.Set
+
+	// usage is the usage set of goroutines with these labels, as of the
+	// usageGen generation of tracked keys. See TrackLabel.
+	usage    *label.UsageSet
+	usageGen uint64
 }
 
 // String satisfies Stringer and returns key, value pairs in a consistent
@@ -55,7 +60,11 @@ func (l *labelMap) String() string {
 // A label overwrites a prior label with the same key.
 func WithLabels(ctx context.Context, labels LabelSet) context.Context {
 	parentLabels := labelValue(ctx)
-	return context.WithValue(ctx, labelContextKey{}, &labelMap{mergeLabelSets(parentLabels.Set, labels)})
+	m := &labelMap{Set: mergeLabelSets(parentLabels.Set, labels)}
+	if labelUsage.gen.Load() != 0 {
+		m.usage, m.usageGen = labelUsageSet(m.Set)
+	}
+	return context.WithValue(ctx, labelContextKey{}, m)
 }
 
 func mergeLabelSets(left label.Set, right LabelSet) label.Set {
@@ -377,7 +377,12 @@ func (b *profileBuilder) build() error {
 
 		b.pbSample(values, locs, labels)
 	}
+	return b.finish()
+}
 
+// finish writes the mappings and the string table of the profile, and
+// flushes it.
+func (b *profileBuilder) finish() error {
 	for i, m := range b.mem {
 		hasFunctions := m.funcs == lookupTried // lookupTried but not lookupFailed
 		b.pbMapping(tagProfile_Mapping, uint64(i+1), uint64(m.start), uint64(m.end), m.offset, m.file, m.buildID, hasFunctions)
@@ -41,6 +41,9 @@ func runtime_goroutineLeakGC()
 func SetGoroutineLabels(ctx context.Context) {
 	ctxLabels, _ := ctx.Value(labelContextKey{}).(*labelMap)
 	runtime_setProfLabel(unsafe.Pointer(ctxLabels))
+	if labelUsage.gen.Load() != 0 {
+		runtime_setLabelUsage(ctxLabels.usageSet())
+	}
 }
 
 // Do calls f with a copy of the parent context with the
@@ -0,0 +1,139 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"internal/runtime/pprof/label"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runtime_setLabelUsage is defined in runtime/proflabel.go.
+func runtime_setLabelUsage(u *label.UsageSet)
+
+// LabelUsage reports the resources consumed by the goroutines having a
+// tracked label with a given value. See [TrackLabel].
+type LabelUsage struct {
+	// Value is the value of the label.
+	Value string
+
+	// CPUTime is the time the goroutines spent running on a thread,
+	// including in system calls.
+	CPUTime time.Duration
+
+	// AllocBytes is the total size of the heap allocations requested by
+	// the goroutines.
+	AllocBytes int64
+
+	// BlockTime is the time the goroutines spent blocked on channel
+	// operations, select statements, and synchronization primitives of the
+	// sync package, that is, the events reported by the block profile.
+	BlockTime time.Duration
+}
+
+var labelUsage struct {
+	mu    sync.Mutex
+	gen   atomic.Uint64 // number of tracked keys, bumped by TrackLabel
+	keys  []string
+	usage map[label.Label]*label.Usage
+}
+
+// TrackLabel enables resource accounting for the label key. From then on,
+// the runtime aggregates the CPU time, heap allocations, and blocking time of
+// goroutines whose labels, as set by [Do] or [SetGoroutineLabels], include
+// key, by label value, without the need to run a profiler. The aggregates
+// are reported by [ReadLabelUsage].
+//
+// Goroutines that have labels when TrackLabel is called are only accounted
+// for after their labels are set again. Tracking can't be disabled, and the
+// aggregates of every value ever seen for key are retained, so key should
+// have a bounded number of values, such as tenant or endpoint names.
+//
+// The accounting adds a small cost to goroutine scheduling and to allocation
+// for goroutines with tracked labels. Calling TrackLabel more than once
+// with the same key has no effect.
+func TrackLabel(key string) {
+	labelUsage.mu.Lock()
+	defer labelUsage.mu.Unlock()
+	if slices.Contains(labelUsage.keys, key) {
+		return
+	}
+	labelUsage.keys = append(labelUsage.keys, key)
+	if labelUsage.usage == nil {
+		labelUsage.usage = make(map[label.Label]*label.Usage)
+	}
+	labelUsage.gen.Add(1)
+}
+
+// ReadLabelUsage returns the resources consumed by goroutines for each value
+// of the tracked label key, sorted by value. It returns nil if key is not
+// tracked.
+//
+// Resources are charged when a goroutine stops running, changes labels, or
+// exits, so the usage of goroutines that are currently running is not
+// included, and a goroutine running for a long time without being
+// descheduled is only charged when it is preempted.
+func ReadLabelUsage(key string) []LabelUsage {
+	labelUsage.mu.Lock()
+	defer labelUsage.mu.Unlock()
+	var usage []LabelUsage
+	for l, u := range labelUsage.usage {
+		if l.Key != key {
+			continue
+		}
+		usage = append(usage, LabelUsage{
+			Value:      l.Value,
+			CPUTime:    time.Duration(atomic.LoadInt64(&u.CPUTime)),
+			AllocBytes: atomic.LoadInt64(&u.AllocBytes),
+			BlockTime:  time.Duration(atomic.LoadInt64(&u.BlockTime)),
+		})
+	}
+	slices.SortFunc(usage, func(a, b LabelUsage) int {
+		return strings.Compare(a.Value, b.Value)
+	})
+	return usage
+}
+
+// labelUsageSet returns the usage set to charge for goroutines with labels
+// s, or nil if s has no tracked labels, and the tracking generation it
+// reflects.
+func labelUsageSet(s label.Set) (*label.UsageSet, uint64) {
+	labelUsage.mu.Lock()
+	defer labelUsage.mu.Unlock()
+	var set *label.UsageSet
+	for _, key := range labelUsage.keys {
+		for _, l := range s.List {
+			if l.Key != key {
+				continue
+			}
+			u := labelUsage.usage[l]
+			if u == nil {
+				u = new(label.Usage)
+				labelUsage.usage[l] = u
+			}
+			if set == nil {
+				set = new(label.UsageSet)
+			}
+			set.List = append(set.List, u)
+		}
+	}
+	return set, labelUsage.gen.Load()
+}
+
+// usageSet returns the usage set to charge for goroutines with labels l.
+func (l *labelMap) usageSet() *label.UsageSet {
+	if l == nil {
+		return nil
+	}
+	if l.usageGen == labelUsage.gen.Load() {
+		return l.usage
+	}
+	// TrackLabel was called after l was created.
+	u, _ := labelUsageSet(l.Set)
+	return u
+}
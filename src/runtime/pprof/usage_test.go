@@ -0,0 +1,111 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var usageSink []byte
+
+func allocMiB() {
+	for range 1024 {
+		usageSink = make([]byte, 1024)
+	}
+}
+
+var usageTinySink *int64
+
+// allocTinyMiB allocates 1 MiB in objects small enough for the tiny
+// allocator.
+func allocTinyMiB() {
+	for range 1 << 17 {
+		usageTinySink = new(int64)
+	}
+}
+
+func findLabelUsage(key, value string) LabelUsage {
+	for _, u := range ReadLabelUsage(key) {
+		if u.Value == value {
+			return u
+		}
+	}
+	return LabelUsage{}
+}
+
+func TestLabelUsage(t *testing.T) {
+	const key = "usage-test-tenant"
+	TrackLabel(key)
+	TrackLabel(key)
+
+	Do(context.Background(), Labels(key, "a", "other", "x"), func(ctx context.Context) {
+		allocMiB()
+
+		c := make(chan struct{})
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			close(c)
+		}()
+		<-c
+
+		for start := time.Now(); time.Since(start) < 20*time.Millisecond; {
+		}
+	})
+	// The usage of the current goroutine is charged when Do restores its
+	// labels.
+	u := findLabelUsage(key, "a")
+	if u.AllocBytes < 1<<20 {
+		t.Errorf("AllocBytes = %d, want at least %d", u.AllocBytes, 1<<20)
+	}
+	if u.BlockTime < 10*time.Millisecond {
+		t.Errorf("BlockTime = %v, want at least 10ms", u.BlockTime)
+	}
+	if u.CPUTime < 10*time.Millisecond {
+		t.Errorf("CPUTime = %v, want at least 10ms", u.CPUTime)
+	}
+
+	// New goroutines inherit the accounting, and are charged when they exit.
+	done := make(chan struct{})
+	Do(context.Background(), Labels(key, "b"), func(ctx context.Context) {
+		go func() {
+			defer close(done)
+			allocMiB()
+		}()
+	})
+	<-done
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		u := findLabelUsage(key, "b")
+		if u.AllocBytes >= 1<<20 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("AllocBytes = %d for the child goroutine, want at least %d", u.AllocBytes, 1<<20)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := ReadLabelUsage("other"); got != nil {
+		t.Errorf("ReadLabelUsage for an untracked key = %v, want nil", got)
+	}
+	for _, u := range ReadLabelUsage(key) {
+		if u.Value != "a" && u.Value != "b" {
+			t.Errorf("unexpected label value %q", u.Value)
+		}
+	}
+}
+
+func TestLabelUsageTinyAllocs(t *testing.T) {
+	const key = "usage-test-tiny"
+	TrackLabel(key)
+
+	Do(context.Background(), Labels(key, "a"), func(ctx context.Context) {
+		allocTinyMiB()
+	})
+	if u := findLabelUsage(key, "a"); u.AllocBytes < 1<<20 {
+		t.Errorf("AllocBytes = %d, want at least %d", u.AllocBytes, 1<<20)
+	}
+}
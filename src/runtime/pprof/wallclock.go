@@ -0,0 +1,196 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"fmt"
+	"internal/profilerecord"
+	"io"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// runtime_wallClockSample is defined in runtime/wallprof.go.
+func runtime_wallClockSample(p []profilerecord.StackRecord, pcs []uintptr, labels []unsafe.Pointer, states []string) (n, npc int, ok bool)
+
+var wall struct {
+	sync.Mutex
+	profiling bool
+	stop      chan bool
+	done      chan bool
+}
+
+// StartWallClockProfile enables wall-clock profiling for the current
+// process. While profiling, the profile will be buffered and written to w
+// when [StopWallClockProfile] is called. StartWallClockProfile returns an
+// error if wall-clock profiling is already enabled.
+//
+// Unlike the CPU profile, which only samples goroutines running on a CPU,
+// the wall-clock profile periodically samples the stacks of all
+// goroutines, whether they are running, runnable, or blocked, for
+// example in a system call, a channel operation, or network I/O. Each
+// sample is labeled with the state of the goroutine under the key
+// "state", whose value is "running", "runnable", "syscall", or the reason
+// the goroutine is blocked, such as "chan receive" or "IO wait", in
+// addition to the goroutine's profiler labels. The samples of a goroutine
+// add up to the time it existed during profiling, so filtering by state
+// shows where goroutines spend their time off the CPU.
+//
+// Taking a sample does not stop the world: goroutines are suspended one at
+// a time, just long enough to record their stack, so the profile does not
+// reflect a consistent snapshot of the program. Running goroutines are
+// preempted to be suspended. The cost is proportional to the number of
+// goroutines, on the order of a microsecond per goroutine per sample, so
+// a program with 10,000 goroutines spends roughly a tenth of a CPU on
+// wall-clock profiling.
+func StartWallClockProfile(w io.Writer) error {
+	// Each sample costs a stack walk per goroutine, which for a
+	// program with many goroutines is much more than a CPU profile
+	// sample. 10 Hz keeps that cost moderate for programs with up to
+	// tens of thousands of goroutines while still producing useful
+	// data for blocked goroutines, which tend to stay in the same
+	// place for long. Instead of requiring each client to specify the
+	// frequency, we hard code it.
+	const hz = 10
+
+	wall.Lock()
+	defer wall.Unlock()
+	if wall.done == nil {
+		wall.done = make(chan bool)
+	}
+	if wall.profiling {
+		return fmt.Errorf("wall-clock profiling already in use")
+	}
+	wall.profiling = true
+	wall.stop = make(chan bool)
+	go wallClockProfileWriter(w, hz, wall.stop)
+	return nil
+}
+
+// StopWallClockProfile stops the current wall-clock profile, if any.
+// StopWallClockProfile only returns after all the writes for the
+// profile have completed.
+func StopWallClockProfile() {
+	wall.Lock()
+	defer wall.Unlock()
+
+	if !wall.profiling {
+		return
+	}
+	wall.profiling = false
+	close(wall.stop)
+	<-wall.done
+}
+
+// A wallClockTag identifies the samples of goroutines with the same
+// profiler labels in the same state.
+type wallClockTag struct {
+	labels unsafe.Pointer // *labelMap
+	state  string
+}
+
+// wallClockProfile accumulates the samples of a wall-clock profile.
+type wallClockProfile struct {
+	m    profMap
+	tags map[wallClockTag]*wallClockTag // interned tags of m's entries
+
+	p      []profilerecord.StackRecord
+	pcs    []uintptr // storage for the stacks of p
+	labels []unsafe.Pointer
+	states []string
+	stk    []uint64
+}
+
+// sample adds a sample of every goroutine to the profile.
+func (wp *wallClockProfile) sample() {
+	n, npc, ok := runtime_wallClockSample(wp.p, wp.pcs, wp.labels, wp.states)
+	for !ok {
+		// Allocate room for a slightly bigger profile,
+		// in case a few more entries have been added
+		// since the call. The buffers are kept for the
+		// following samples.
+		wp.p = make([]profilerecord.StackRecord, n+10)
+		wp.pcs = make([]uintptr, npc+npc/4)
+		wp.labels = make([]unsafe.Pointer, n+10)
+		wp.states = make([]string, n+10)
+		n, npc, ok = runtime_wallClockSample(wp.p, wp.pcs, wp.labels, wp.states)
+	}
+
+	for i := range n {
+		wp.stk = wp.stk[:0]
+		for _, pc := range wp.p[i].Stack {
+			wp.stk = append(wp.stk, uint64(pc))
+		}
+		key := wallClockTag{wp.labels[i], wp.states[i]}
+		tag := wp.tags[key]
+		if tag == nil {
+			tag = &wallClockTag{key.labels, key.state}
+			wp.tags[key] = tag
+		}
+		wp.m.lookup(wp.stk, unsafe.Pointer(tag)).count++
+
+		// Don't retain the labels of the goroutines until the
+		// next sample.
+		wp.labels[i] = nil
+	}
+}
+
+func wallClockProfileWriter(w io.Writer, hz int, stop chan bool) {
+	b := newProfileBuilder(w)
+	b.period = 1e9 / int64(hz)
+	wp := &wallClockProfile{tags: make(map[wallClockTag]*wallClockTag)}
+
+	t := time.NewTicker(time.Second / time.Duration(hz))
+Loop:
+	for {
+		select {
+		case <-t.C:
+			wp.sample()
+		case <-stop:
+			break Loop
+		}
+	}
+	t.Stop()
+
+	b.buildWallClock(&wp.m)
+	wall.done <- true
+}
+
+// buildWallClock completes and writes a wall-clock profile with the
+// samples in m, whose tags are *wallClockTag.
+func (b *profileBuilder) buildWallClock(m *profMap) error {
+	b.end = time.Now()
+
+	b.pb.int64Opt(tagProfile_TimeNanos, b.start.UnixNano())
+	b.pbValueType(tagProfile_SampleType, "samples", "count")
+	b.pbValueType(tagProfile_SampleType, "wall", "nanoseconds")
+	b.pb.int64Opt(tagProfile_DurationNanos, b.end.Sub(b.start).Nanoseconds())
+	b.pbValueType(tagProfile_PeriodType, "wall", "nanoseconds")
+	b.pb.int64Opt(tagProfile_Period, b.period)
+
+	values := []int64{0, 0}
+	var locs []uint64
+
+	for e := m.all; e != nil; e = e.nextAll {
+		values[0] = e.count
+		values[1] = e.count * b.period
+
+		tag := (*wallClockTag)(e.tag)
+		labels := func() {
+			b.pbLabel(tagSample_Label, "state", tag.state, 0)
+			if tag.labels != nil {
+				for _, lbl := range (*labelMap)(tag.labels).Set.List {
+					b.pbLabel(tagSample_Label, lbl.Key, lbl.Value, 0)
+				}
+			}
+		}
+
+		locs = b.appendLocsForStack(locs[:0], e.stk)
+
+		b.pbSample(values, locs, labels)
+	}
+	return b.finish()
+}
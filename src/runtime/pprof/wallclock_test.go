@@ -0,0 +1,122 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"internal/profile"
+	"strings"
+	"testing"
+	"time"
+)
+
+//go:noinline
+func wallClockBlockedFunc(c chan struct{}) {
+	<-c
+}
+
+//go:noinline
+func wallClockSleepFunc(c chan struct{}) {
+	for {
+		select {
+		case <-c:
+			return
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestWallClockProfile(t *testing.T) {
+	c := make(chan struct{})
+	defer close(c)
+	Do(context.Background(), Labels("wall-test", "blocked"), func(ctx context.Context) {
+		go wallClockBlockedFunc(c)
+	})
+	go wallClockSleepFunc(c)
+
+	var buf bytes.Buffer
+	if err := StartWallClockProfile(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := StartWallClockProfile(&bytes.Buffer{}); err == nil {
+		t.Errorf("second StartWallClockProfile succeeded")
+	}
+	time.Sleep(500 * time.Millisecond)
+	StopWallClockProfile()
+
+	p, err := profile.Parse(&buf)
+	if err != nil {
+		t.Fatalf("failed to parse profile: %v", err)
+	}
+	if err := p.CheckValid(); err != nil {
+		t.Fatalf("invalid profile: %v", err)
+	}
+	if len(p.SampleType) != 2 || p.SampleType[1].Type != "wall" || p.SampleType[1].Unit != "nanoseconds" {
+		t.Errorf("got sample types %v, want samples/count and wall/nanoseconds", p.SampleType)
+	}
+	if p.Period != 1e8 {
+		t.Errorf("got period %d, want 1e8", p.Period)
+	}
+
+	hasFunc := func(s *profile.Sample, name string) bool {
+		for _, loc := range s.Location {
+			for _, line := range loc.Line {
+				if strings.HasSuffix(line.Function.Name, name) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	var blocked, sleeping int64
+	for _, s := range p.Sample {
+		state := s.Label["state"]
+		if len(state) != 1 {
+			t.Fatalf("sample has state labels %v, want one", state)
+		}
+		switch {
+		case hasFunc(s, ".wallClockBlockedFunc"):
+			if state[0] != "chan receive" {
+				t.Errorf("wallClockBlockedFunc in state %q, want %q", state[0], "chan receive")
+			}
+			if got := s.Label["wall-test"]; len(got) != 1 || got[0] != "blocked" {
+				t.Errorf("wallClockBlockedFunc has labels %v, want wall-test=blocked", s.Label)
+			}
+			blocked += s.Value[0]
+		case hasFunc(s, ".wallClockSleepFunc"):
+			sleeping += s.Value[0]
+		}
+	}
+	// Sampling runs at 10 Hz for 500ms, but timers may be late on
+	// loaded machines, so expect at least one sample.
+	if blocked == 0 {
+		t.Errorf("no samples of the goroutine blocked in wallClockBlockedFunc")
+	}
+	if sleeping == 0 {
+		t.Errorf("no samples of the goroutine sleeping in wallClockSleepFunc")
+	}
+}
+
+func BenchmarkWallClockSample(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("goroutines=%d", n), func(b *testing.B) {
+			c := make(chan struct{})
+			defer close(c)
+			for range n {
+				go wallClockBlockedFunc(c)
+			}
+			wp := &wallClockProfile{tags: make(map[wallClockTag]*wallClockTag)}
+			wp.sample()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				wp.sample()
+			}
+		})
+	}
+}
@@ -1762,7 +1762,7 @@ func startTheWorldWithSema(now int64, w worldStop) int64 {
 
 	mp := acquirem() // disable preemption because it can be holding p in a local var
 	if netpollinited() {
-		list, delta := netpoll(0) // non-blocking
+		list, delta := netpollTimed(0) // non-blocking
 		injectglist(&list)
 		netpollAdjustWaiters(delta)
 	}
@@ -3367,6 +3367,10 @@ func execute(gp *g, inheritTime bool) {
 		}
 	}
 
+	if gp.acct != nil {
+		acctStart(gp, nanotime())
+	}
+
 	// Check whether the profiler needs to be turned on or off.
 	hz := sched.profilehz
 	if mp.profilehz != hz {
@@ -3376,6 +3380,7 @@ func execute(gp *g, inheritTime bool) {
 	trace := traceAcquire()
 	if trace.ok() {
 		trace.GoStart()
+		trace.UserRegionInherited(gp)
 		traceRelease(trace)
 	}
 
@@ -3493,7 +3498,7 @@ top:
 	// We only poll from one thread at a time to avoid kernel contention
 	// on machines with many cores.
 	if netpollinited() && netpollAnyWaiters() && sched.lastpoll.Load() != 0 && sched.pollingNet.Swap(1) == 0 {
-		list, delta := netpoll(0)
+		list, delta := netpollTimed(0)
 		sched.pollingNet.Store(0)
 		if !list.empty() { // non-blocking
 			gp := list.pop()
@@ -3751,7 +3756,7 @@ top:
 			// When using fake time, just poll.
 			delay = 0
 		}
-		list, delta := netpoll(delay) // block until new work is available
+		list, delta := netpollTimed(delay) // block until new work is available
 		// Refresh now again, after potentially blocking.
 		now = nanotime()
 		sched.pollUntil.Store(0)
@@ -3797,6 +3802,19 @@ top:
 	goto top
 }
 
+// netpollTimed is like netpoll, but it also adds the time spent polling
+// to the scheduler's netpoll statistics for runtime/metrics.
+func netpollTimed(delay int64) (gList, int32) {
+	start := nanotime()
+	list, delta := netpoll(delay)
+	t := nanotime() - start
+	sched.netpollTime.Add(t)
+	if delay != 0 {
+		sched.netpollBlockedTime.Add(t)
+	}
+	return list, delta
+}
+
 // pollWork reports whether there is non-background work this P could
 // be doing. This is a fairly lightweight check to be used for
 // background work loops, like idle GC. It checks a subset of the
@@ -3810,7 +3828,7 @@ func pollWork() bool {
 		return true
 	}
 	if netpollinited() && netpollAnyWaiters() && sched.lastpoll.Load() != 0 {
-		if list, delta := netpoll(0); !list.empty() {
+		if list, delta := netpollTimed(0); !list.empty() {
 			injectglist(&list)
 			netpollAdjustWaiters(delta)
 			return true
@@ -4240,6 +4258,13 @@ top:
 func dropg() {
 	gp := getg()
 
+	if curg := gp.m.curg; curg.acct != nil {
+		now := nanotime()
+		acctStop(curg, now)
+		if readgstatus(curg)&^_Gscan == _Gwaiting && acctBlockingWait(curg.waitreason) {
+			curg.acctBlocked = now
+		}
+	}
 	setMNoWB(&gp.m.curg.m, nil)
 	setGNoWB(&gp.m.curg, nil)
 }
@@ -4481,6 +4506,7 @@ func goexit1() {
 	}
 	trace := traceAcquire()
 	if trace.ok() {
+		trace.UserRegionInheritedEnd(getg())
 		trace.GoEnd()
 		traceRelease(trace)
 	}
@@ -4521,6 +4547,11 @@ func gdestroy(gp *g) {
 	gp.waitreason = waitReasonZero
 	gp.param = nil
 	gp.labels = nil
+	gp.userTask = gUserTaskState{}
+	if gp.acct != nil {
+		acctStop(gp, nanotime())
+		gp.acct = nil
+	}
 	gp.timer = nil
 	gp.bubble = nil
 	gp.fipsOnlyBypass = false
@@ -4827,6 +4858,7 @@ func entersyscallblock() {
 		if trace.ok() {
 			trace.GoSysCall()
 		}
+		sched.syscallHandoffs.Add(1)
 		handoffp(releasep())
 	})
 	// <--
@@ -5362,6 +5394,10 @@ func newproc1(fn *funcval, callergp *g, callerpc uintptr, parked bool, waitreaso
 		newg.bubble = callergp.bubble
 		if mp.curg != nil {
 			newg.labels = mp.curg.labels
+			newg.acct = mp.curg.acct
+			if traceInheritTasks.Load() {
+				newg.userTask.inherit(&mp.curg.userTask)
+			}
 		}
 		if goroutineProfile.active {
 			// A concurrent goroutine profile is running. It should include
@@ -6015,6 +6051,12 @@ func (pp *p) destroy() {
 	pp.cleanupsQueued = 0
 	sched.goroutinesCreated.Add(int64(pp.goroutinesCreated))
 	pp.goroutinesCreated = 0
+	sched.stealAttempts.Add(int64(pp.stealAttempts))
+	sched.stealSuccesses.Add(int64(pp.stealSuccesses))
+	sched.stolenGoroutines.Add(int64(pp.stolenGoroutines))
+	pp.stealAttempts = 0
+	pp.stealSuccesses = 0
+	pp.stolenGoroutines = 0
 	pp.xRegs.free()
 	pp.status = _Pdead
 }
@@ -6568,7 +6610,7 @@ func sysmon() {
 		lastpoll := sched.lastpoll.Load()
 		if netpollinited() && lastpoll != 0 && lastpoll+10*1000*1000 < now {
 			sched.lastpoll.CompareAndSwap(lastpoll, now)
-			list, delta := netpoll(0) // non-blocking - returns list of goroutines
+			list, delta := netpollTimed(0) // non-blocking - returns list of goroutines
 			if !list.empty() {
 				// Need to decrement number of idle locked M's
 				// (pretending that one more is running) before injectglist.
@@ -6712,6 +6754,7 @@ func retake(now int64) uint32 {
 		n++
 
 		// Handoff the P for some other thread to run it.
+		sched.syscallHandoffs.Add(1)
 		handoffp(pp)
 
 		// The P has been handed off to another thread, so risk of a false
@@ -6878,6 +6921,7 @@ func preemptone(pp *p) bool {
 	}
 
 	gp.preempt = true
+	sched.preemptRequests.Add(1)
 
 	// Every call in a goroutine checks for stack overflow by
 	// comparing the current stack pointer to gp->stackguard0.
@@ -7730,9 +7774,12 @@ func runqgrab(pp *p, batch *[256]guintptr, batchHead uint32, stealRunNextG bool)
 func runqsteal(pp, p2 *p, stealRunNextG bool) *g {
 	t := pp.runqtail
 	n := runqgrab(p2, &pp.runq, t, stealRunNextG)
+	pp.stealAttempts++
 	if n == 0 {
 		return nil
 	}
+	pp.stealSuccesses++
+	pp.stolenGoroutines += uint64(n)
 	n--
 	gp := pp.runq[(t+n)%uint32(len(pp.runq))].ptr()
 	if n == 0 {
@@ -4,7 +4,11 @@
 
 package runtime
 
-import "unsafe"
+import (
+	"internal/runtime/atomic"
+	"internal/runtime/pprof/label"
+	"unsafe"
+)
 
 var labelSync uintptr
 
@@ -54,3 +58,69 @@ func runtime_setProfLabel(labels unsafe.Pointer) {
 func runtime_getProfLabel() unsafe.Pointer {
 	return getg().labels
 }
+
+// acctEnabled is set once any goroutine has a usage set. It is never
+// cleared, and lets the allocator skip the check of the current goroutine's
+// usage set in programs that don't use label accounting.
+var acctEnabled bool
+
+// runtime_setLabelUsage sets the usage set that the resources consumed by the
+// current goroutine are charged to, for runtime/pprof.TrackLabel. The usage
+// set is inherited by new goroutines, like the profiler labels.
+//
+//go:linkname runtime_setLabelUsage runtime/pprof.runtime_setLabelUsage
+func runtime_setLabelUsage(u *label.UsageSet) {
+	// Don't let the scheduler observe a half-updated state.
+	mp := acquirem()
+	gp := mp.curg
+	now := nanotime()
+	if gp.acct != nil {
+		acctStop(gp, now)
+	}
+	if u != nil {
+		acctEnabled = true
+	}
+	gp.acct = u
+	gp.acctRunning = now
+	releasem(mp)
+}
+
+// acctStart records that gp, which has a usage set, starts running at now,
+// and charges the time it spent blocked, if any.
+func acctStart(gp *g, now int64) {
+	if gp.acctBlocked != 0 {
+		for _, u := range gp.acct.List {
+			acctAdd(&u.BlockTime, now-gp.acctBlocked)
+		}
+		gp.acctBlocked = 0
+	}
+	gp.acctRunning = now
+}
+
+// acctStop charges the time gp, which has a usage set, spent running until
+// now, and the memory it allocated, to its usage set.
+func acctStop(gp *g, now int64) {
+	cpu := now - gp.acctRunning
+	for _, u := range gp.acct.List {
+		acctAdd(&u.CPUTime, cpu)
+		if gp.acctAllocBytes != 0 {
+			acctAdd(&u.AllocBytes, gp.acctAllocBytes)
+		}
+	}
+	gp.acctRunning = now
+	gp.acctAllocBytes = 0
+}
+
+// acctAdd atomically adds delta to *p, a field of a label.Usage. The fields
+// are 64-bit aligned, since they are at the start of the struct, which is
+// allocated by runtime/pprof.
+func acctAdd(p *int64, delta int64) {
+	(*atomic.Int64)(unsafe.Pointer(p)).Add(delta)
+}
+
+// acctBlockingWait reports whether a goroutine waiting for reason w is
+// blocked, for the purpose of label accounting. These are the same events
+// as reported by the block profile.
+func acctBlockingWait(w waitReason) bool {
+	return w.isChanWait() || w.isSyncWait() || w == waitReasonSemacquire
+}
@@ -9,6 +9,7 @@ import (
 	"internal/chacha8rand"
 	"internal/goarch"
 	"internal/runtime/atomic"
+	"internal/runtime/pprof/label"
 	"internal/runtime/sys"
 	"unsafe"
 )
@@ -574,6 +575,20 @@ type g struct {
 	coroarg *coro // argument during coroutine transfers
 	bubble  *synctestBubble
 
+	// Resource accounting for tracked profiler labels, see proflabel.go.
+	// The other fields are only maintained if acct is not nil.
+	acct           *label.UsageSet
+	acctRunning    int64 // nanotime when the g started running
+	acctBlocked    int64 // nanotime when the g blocked, or 0
+	acctAllocBytes int64 // bytes allocated and not yet charged to acct
+
+	// Trace task inheritance state, see tracetask.go.
+	userTask gUserTaskState
+
+	// lockOrder is the set of sync locks held by the goroutine, maintained
+	// by the lock order checker. See lockorder.go.
+	lockOrder *lockOrderHeld
+
 	// xRegs stores the extended register state if this G has been
 	// asynchronously preempted.
 	xRegs xRegPerG
@@ -919,6 +934,15 @@ type p struct {
 	// goroutinesCreated is the total count of goroutines created by this P.
 	goroutinesCreated uint64
 
+	// Work stealing statistics, for runtime/metrics. stealAttempts is the
+	// number of times this P tried to steal goroutines from another P's
+	// run queue, stealSuccesses the number of those attempts that stole
+	// at least one goroutine, and stolenGoroutines the total number of
+	// goroutines stolen.
+	stealAttempts    uint64
+	stealSuccesses   uint64
+	stolenGoroutines uint64
+
 	// xRegs is the per-P extended register state used by asynchronous
 	// preemption. This is an empty struct on platforms that don't use extended
 	// register state.
@@ -1051,6 +1075,28 @@ type schedt struct {
 	// goroutinesCreated (plus the value of goroutinesCreated on each P in allp)
 	// is the sum of all goroutines created by the program.
 	goroutinesCreated atomic.Uint64
+
+	// stealAttempts, stealSuccesses and stolenGoroutines (plus the values
+	// of the same fields on each P in allp) are the work stealing
+	// statistics of the program. These fields store the values for Ps
+	// that have been destroyed.
+	stealAttempts    atomic.Uint64
+	stealSuccesses   atomic.Uint64
+	stolenGoroutines atomic.Uint64
+
+	// netpollTime is the total time spent in the network poller by the
+	// scheduler, and netpollBlockedTime the part of that time spent
+	// blocked waiting for network I/O or timers.
+	netpollTime        atomic.Int64
+	netpollBlockedTime atomic.Int64
+
+	// syscallHandoffs is the number of times a P was handed off to
+	// another thread because its goroutine blocked in a system call.
+	syscallHandoffs atomic.Uint64
+
+	// preemptRequests is the number of preemption requests made to
+	// running goroutines.
+	preemptRequests atomic.Uint64
 }
 
 // Values for the flags field of a sigTabT.
@@ -1268,6 +1314,7 @@ const (
 	waitReasonSynctestSelect                          // "select (durable)"
 	waitReasonSynctestWaitGroupWait                   // "sync.WaitGroup.Wait (durable)"
 	waitReasonCleanupWait                             // "cleanup wait"
+	waitReasonWallClockProfile                        // "wall-clock profile"
 )
 
 var waitReasonStrings = [...]string{
@@ -1318,6 +1365,7 @@ var waitReasonStrings = [...]string{
 	waitReasonSynctestSelect:        "select (durable)",
 	waitReasonSynctestWaitGroupWait: "sync.WaitGroup.Wait (durable)",
 	waitReasonCleanupWait:           "cleanup wait",
+	waitReasonWallClockProfile:      "wall-clock profile",
 }
 
 func (w waitReason) String() string {
@@ -1376,6 +1424,7 @@ var isWaitingForSuspendG = [len(waitReasonStrings)]bool{
 	waitReasonGCAssistMarking:       true,
 	waitReasonGCWorkerActive:        true,
 	waitReasonFlushProcCaches:       true,
+	waitReasonWallClockProfile:      true,
 }
 
 func (w waitReason) isIdleInSynctest() bool {
@@ -0,0 +1,35 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "arena"
+
+func init() {
+	register("ArenaUseAfterFree", ArenaUseAfterFree)
+}
+
+type arenaValue struct {
+	next *arenaValue
+	n    int
+}
+
+var arenaSink *arenaValue
+
+func ArenaUseAfterFree() {
+	a := arena.NewArena()
+	arenaSink = newArenaValue(a)
+	freeArena(a)
+	println(arenaSink.n)
+}
+
+//go:noinline
+func newArenaValue(a *arena.Arena) *arenaValue {
+	return arena.New[arenaValue](a)
+}
+
+//go:noinline
+func freeArena(a *arena.Arena) {
+	a.Free()
+}
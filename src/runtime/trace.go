@@ -282,6 +282,7 @@ var trace struct {
 	// Trace generation counter.
 	gen            atomic.Uintptr
 	lastNonZeroGen uintptr // last non-zero value of gen
+	firstGen       uintptr // first generation of the current trace
 
 	// shutdown is set when we are waiting for trace reader to finish after setting gen to 0
 	//
@@ -401,6 +402,7 @@ func StartTrace() error {
 		trace.enabledWithAllocFree = true
 		debug.malloc = true
 	}
+	trace.firstGen = firstGen
 	trace.gen.Store(firstGen)
 
 	// Wait for exitingSyscall to drain.
@@ -40,6 +40,10 @@ func NewTask(pctx context.Context, taskType string) (ctx context.Context, task *
 	id := newID()
 	userTaskCreate(id, pid, taskType)
 	s := &Task{id: id}
+	if inheritTasks.Load() {
+		s.prevID, s.prevRegion = goroutineTask()
+		setGoroutineTask(id, taskType)
+	}
 	return context.WithValue(pctx, traceContextKey{}, s), s
 
 	// We allocate a new task even when
@@ -73,11 +77,21 @@ func fromContext(ctx context.Context) *Task {
 type Task struct {
 	id uint64
 	// TODO(hyangah): record parent id?
+
+	// The task and region type of the goroutine that created the
+	// task, restored by End when task inheritance is enabled.
+	prevID     uint64
+	prevRegion string
 }
 
 // End marks the end of the operation represented by the [Task].
 func (t *Task) End() {
 	userTaskEnd(t.id)
+	if inheritTasks.Load() {
+		if id, _ := goroutineTask(); id == t.id {
+			setGoroutineTask(t.prevID, t.prevRegion)
+		}
+	}
 }
 
 var lastTaskID uint64 = 0 // task id issued last time
@@ -138,6 +152,9 @@ func WithRegion(ctx context.Context, regionType string, fn func()) {
 	id := fromContext(ctx).id
 	userRegion(id, regionStartCode, regionType)
 	defer userRegion(id, regionEndCode, regionType)
+	prevID, prevRegion := goroutineTask()
+	setGoroutineTask(id, regionType)
+	defer setGoroutineTask(prevID, prevRegion)
 	fn()
 }
 
@@ -155,13 +172,24 @@ func StartRegion(ctx context.Context, regionType string) *Region {
 	}
 	id := fromContext(ctx).id
 	userRegion(id, regionStartCode, regionType)
-	return &Region{id, regionType}
+	r := &Region{id: id, regionType: regionType}
+	// Keep the current region of the goroutine up to date even if task
+	// inheritance is disabled: the runtime only begins an inherited region
+	// again in a new trace generation while it is the innermost one.
+	r.prevID, r.prevRegion = goroutineTask()
+	setGoroutineTask(id, regionType)
+	return r
 }
 
 // Region is a region of code whose execution time interval is traced.
 type Region struct {
 	id         uint64
 	regionType string
+
+	// The task and region type of the goroutine before the region
+	// started, restored by End.
+	prevID     uint64
+	prevRegion string
 }
 
 var noopRegion = &Region{}
@@ -172,6 +200,34 @@ func (r *Region) End() {
 		return
 	}
 	userRegion(r.id, regionEndCode, r.regionType)
+	setGoroutineTask(r.prevID, r.prevRegion)
+}
+
+var inheritTasks atomic.Bool
+
+// SetTaskInheritance enables or disables task inheritance, which is
+// disabled by default.
+//
+// When task inheritance is enabled, each goroutine has a current task and
+// region: [NewTask] makes the new task current in the calling goroutine
+// until [Task.End] is called on that goroutine, and [StartRegion] and
+// [WithRegion] make the region current until it ends. A goroutine created
+// by a go statement inherits the current task and region of the goroutine
+// that created it: while tracing, it runs in a region of the same type,
+// or of the type of the task if there is no current region, associated
+// with the same task, from when it first runs until it exits. Work that
+// is fanned out to new goroutines, even by code that does not receive
+// the task's context, thus appears under the task in the trace.
+//
+// Only goroutines created while task inheritance is enabled inherit the
+// task and region of their creator, and only tasks created while it is
+// enabled are tracked. Regions started by [StartRegion] while tracing is
+// disabled are not tracked either. Profiler labels set with the
+// runtime/pprof package are always inherited by new goroutines,
+// regardless of this setting.
+func SetTaskInheritance(enabled bool) {
+	inheritTasks.Store(enabled)
+	setTaskInheritance(enabled)
 }
 
 // IsEnabled reports whether tracing is enabled.
@@ -196,3 +252,12 @@ func userRegion(id, mode uint64, regionType string)
 
 // emits UserLog event.
 func userLog(id uint64, category, message string)
+
+// sets whether new goroutines inherit the task and region of their creator.
+func setTaskInheritance(enabled bool)
+
+// returns the current task and region type of the calling goroutine.
+func goroutineTask() (id uint64, regionType string)
+
+// sets the current task and region type of the calling goroutine.
+func setGoroutineTask(id uint64, regionType string)
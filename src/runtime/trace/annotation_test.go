@@ -5,12 +5,195 @@
 package trace_test
 
 import (
+	"bytes"
 	"context"
+	inttrace "internal/trace"
+	"internal/trace/testtrace"
 	"io"
 	. "runtime/trace"
+	"sync"
 	"testing"
 )
 
+func TestTaskInheritance(t *testing.T) {
+	if IsEnabled() {
+		t.Skip("skipping because -test.trace is set")
+	}
+	SetTaskInheritance(true)
+	defer SetTaskInheritance(false)
+
+	var buf bytes.Buffer
+	if err := Start(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	ctx, task := NewTask(context.Background(), "request")
+	wg.Go(func() {})
+	r := StartRegion(ctx, "fanout")
+	wg.Go(func() {
+		wg.Go(func() {})
+	})
+	wg.Wait()
+	r.End()
+	task.End()
+	wg.Go(func() {})
+	wg.Wait()
+	Stop()
+
+	tb := buf.Bytes()
+	testReader(t, tb, testtrace.ExpectSuccess())
+	rd, err := inttrace.NewReader(bytes.NewReader(tb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var taskID inttrace.TaskID
+	regions := make(map[string]int) // goroutines in inherited regions, by type
+	for {
+		ev, err := rd.ReadEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch ev.Kind() {
+		case inttrace.EventTaskBegin:
+			if ev.Task().Type == "request" {
+				taskID = ev.Task().ID
+			}
+		case inttrace.EventRegionBegin:
+			// The regions of the goroutines started by the test have
+			// the start function of the goroutine as their stack.
+			region := ev.Region()
+			for f := range ev.Stack().Frames() {
+				if f.Func != "runtime/trace_test.TestTaskInheritance" && region.Task == taskID {
+					regions[region.Type]++
+				}
+				break
+			}
+		}
+	}
+	if regions["request"] != 1 || regions["fanout"] != 2 || len(regions) != 2 {
+		t.Errorf("got goroutines in inherited regions %v, want 1 request and 2 fanout", regions)
+	}
+}
+
+func TestStartRegionInheritanceAllocs(t *testing.T) {
+	if IsEnabled() {
+		t.Skip("skipping because -test.trace is set")
+	}
+	SetTaskInheritance(true)
+	defer SetTaskInheritance(false)
+
+	ctx := context.Background()
+	if n := testing.AllocsPerRun(100, func() { StartRegion(ctx, "region").End() }); n != 0 {
+		t.Errorf("StartRegion allocated %v times with tracing disabled, want 0", n)
+	}
+}
+
+func TestTaskInheritanceNewGeneration(t *testing.T) {
+	if IsEnabled() {
+		t.Skip("skipping because -test.trace is set")
+	}
+	SetTaskInheritance(true)
+	defer SetTaskInheritance(false)
+
+	// inheritingGoroutine starts a goroutine in an inherited region, waits
+	// for it to begin, and returns a function that lets it exit.
+	inheritingGoroutine := func() (exit func()) {
+		ctx, task := NewTask(context.Background(), "request")
+		defer task.End()
+		defer StartRegion(ctx, "fanout").End()
+		started, resume := make(chan struct{}), make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			close(started)
+			<-resume
+		})
+		<-started
+		return func() {
+			close(resume)
+			wg.Wait()
+		}
+	}
+
+	t.Run("NewTrace", func(t *testing.T) {
+		// The region begins in one trace, and must begin again in the
+		// next one, without ending first.
+		if err := Start(io.Discard); err != nil {
+			t.Fatal(err)
+		}
+		exit := inheritingGoroutine()
+		Stop()
+
+		var buf bytes.Buffer
+		if err := Start(&buf); err != nil {
+			t.Fatal(err)
+		}
+		exit()
+		Stop()
+		checkInheritedRegions(t, buf.Bytes(), 1)
+	})
+
+	t.Run("FlightRecorder", func(t *testing.T) {
+		// The region begins in one generation, and must end and begin
+		// again in the next one, as a snapshot may start from either.
+		fr := NewFlightRecorder(FlightRecorderConfig{})
+		if err := fr.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer fr.Stop()
+		exit := inheritingGoroutine()
+		if _, err := fr.WriteTo(io.Discard); err != nil {
+			t.Fatal(err)
+		}
+		exit()
+		var buf bytes.Buffer
+		if _, err := fr.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		checkInheritedRegions(t, buf.Bytes(), 2)
+	})
+}
+
+// checkInheritedRegions checks that the trace tb parses, and that the
+// inherited "fanout" region begins and ends want times in it.
+func checkInheritedRegions(t *testing.T, tb []byte, want int) {
+	t.Helper()
+	testReader(t, tb, testtrace.ExpectSuccess())
+	rd, err := inttrace.NewReader(bytes.NewReader(tb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ignore the region that the creator of the task starts itself.
+	creator := inttrace.NoGoroutine
+	var begins, ends int
+	for {
+		ev, err := rd.ReadEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch ev.Kind() {
+		case inttrace.EventTaskBegin:
+			creator = ev.Goroutine()
+		case inttrace.EventRegionBegin:
+			if ev.Region().Type == "fanout" && ev.Goroutine() != creator {
+				begins++
+			}
+		case inttrace.EventRegionEnd:
+			if ev.Region().Type == "fanout" && ev.Goroutine() != creator {
+				ends++
+			}
+		}
+	}
+	if begins != want || ends != want {
+		t.Errorf("got %d begins and %d ends of the inherited region, want %d of each", begins, ends, want)
+	}
+}
+
 func BenchmarkStartRegion(b *testing.B) {
 	b.ReportAllocs()
 	ctx, task := NewTask(context.Background(), "benchmark")
@@ -0,0 +1,228 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"internal/abi"
+	"internal/profilerecord"
+	"internal/runtime/sys"
+	"unsafe"
+)
+
+// maxRecordFrames is the maximum number of logical frames in a goroutine
+// record. Deeper stacks are truncated.
+const maxRecordFrames = 1024
+
+// pprof_goroutineRecords returns structured tracebacks of the calling
+// goroutine and of all other user goroutines, in the order of
+// Stack(buf, true). The records hold the same information as the tracebacks,
+// without the elision of frames in the middle of deep stacks.
+//
+//go:linkname pprof_goroutineRecords runtime/pprof.runtime_goroutineRecords
+func pprof_goroutineRecords() []profilerecord.GoroutineRecord {
+	// Nothing may allocate while the world is stopped, so allocate the
+	// records along with storage for their frames and text up front, and
+	// try again with more room if they turn out to be too small.
+	rb := recordBuf{
+		records: make([]profilerecord.GoroutineRecord, 0, int(gcount(false))+10),
+		frames:  make([]profilerecord.FrameRecord, 0, 4096),
+		text:    make([]byte, 0, 64<<10),
+	}
+	for {
+		stw := stopTheWorld(stwAllGoroutinesStack)
+
+		gp := getg()
+		sp := sys.GetCallerSP()
+		pc := sys.GetCallerPC()
+		systemstack(func() {
+			// Force traceback=1 to override GOTRACEBACK setting, like Stack.
+			g0 := getg()
+			g0.m.traceback = 1
+			rb.recordGoroutine(gp, pc, sp)
+			forEachGRace(func(gp1 *g) {
+				if gp1 == gp {
+					return
+				}
+				if status := readgstatus(gp1); status == _Gdead || status == _Gdeadextra {
+					return
+				}
+				if isSystemGoroutine(gp1, false) {
+					return
+				}
+				rb.recordGoroutine(gp1, ^uintptr(0), ^uintptr(0))
+			})
+			g0.m.traceback = 0
+		})
+
+		if raceenabled {
+			raceacquire(unsafe.Pointer(&labelSync))
+		}
+
+		startTheWorld(stw)
+
+		if !rb.overflow {
+			return rb.records
+		}
+		rb = recordBuf{
+			records: make([]profilerecord.GoroutineRecord, 0, max(int(gcount(false))+10, 2*cap(rb.records))),
+			frames:  make([]profilerecord.FrameRecord, 0, 2*cap(rb.frames)),
+			text:    make([]byte, 0, 2*cap(rb.text)),
+		}
+	}
+}
+
+// A recordBuf is the preallocated storage for pprof_goroutineRecords. The
+// Frames of each record are subslices of frames, and any strings that are
+// not in the binary's read-only data point into text. Running out of room
+// in any of them sets overflow.
+type recordBuf struct {
+	records  []profilerecord.GoroutineRecord
+	frames   []profilerecord.FrameRecord
+	text     []byte
+	overflow bool
+}
+
+// addText returns a string holding the concatenation of a, b, and c, stored
+// in rb.text. It returns a directly if b and c are empty.
+func (rb *recordBuf) addText(a, b, c string) string {
+	if b == "" && c == "" {
+		return a
+	}
+	n := len(a) + len(b) + len(c)
+	if len(rb.text)+n > cap(rb.text) {
+		rb.overflow = true
+		return ""
+	}
+	start := len(rb.text)
+	rb.text = append(rb.text, a...)
+	rb.text = append(rb.text, b...)
+	rb.text = append(rb.text, c...)
+	return unsafe.String(&rb.text[start], n)
+}
+
+// recordGoroutine appends the structured equivalent of goroutineheader and
+// traceback for gp to rb.records. It must run on the system stack with the
+// world stopped.
+//
+//go:systemstack
+func (rb *recordBuf) recordGoroutine(gp *g, pc, sp uintptr) {
+	if rb.overflow {
+		return
+	}
+	if len(rb.records) == cap(rb.records) {
+		rb.overflow = true
+		return
+	}
+	status := readgstatus(gp) &^ _Gscan
+	r := profilerecord.GoroutineRecord{
+		ID:             gp.goid,
+		Status:         "???",
+		LockedToThread: gp.lockedm != 0,
+		CreatorID:      gp.parentGoid,
+		Labels:         gp.labels,
+	}
+	if status < uint32(len(gStatusStrings)) {
+		r.Status = gStatusStrings[status]
+	}
+	if (status == _Gwaiting || status == _Gleaked) && gp.waitreason != waitReasonZero {
+		r.WaitReason = gp.waitreason.String()
+	}
+	if (status == _Gwaiting || status == _Gsyscall) && gp.waitsince != 0 {
+		r.WaitDuration = nanotime() - gp.waitsince
+	}
+
+	// Show what created the goroutine, like printcreatedby.
+	if f := findfunc(gp.gopc); f.valid() && showframe(f.srcFunc(), gp, false, abi.FuncIDNormal) && gp.goid != 1 {
+		tracepc := gp.gopc
+		if tracepc > f.entry() {
+			tracepc -= sys.PCQuantum
+		}
+		file, line := funcline(f, tracepc)
+		r.CreatedBy = profilerecord.FrameRecord{Function: funcname(f), File: file, Line: int(line)}
+	}
+
+	if gp.m != getg().m && status == _Grunning && gp.syscallsp == 0 {
+		// See tracebacksomeothers.
+		r.StackUnavailable = true
+		rb.records = append(rb.records, r)
+		return
+	}
+
+	// Override registers like traceback1.
+	if status == _Gsyscall {
+		pc = gp.syscallpc
+		sp = gp.syscallsp
+	}
+	if gp.m != nil && gp.m.vdsoSP != 0 {
+		pc = gp.m.vdsoPC
+		sp = gp.m.vdsoSP
+	}
+	var u unwinder
+	u.initAt(pc, sp, 0, gp, unwindSilentErrors)
+	r.Frames, r.FramesTruncated = rb.recordFrames(&u, false)
+	if len(r.Frames) == 0 {
+		// Like traceback1, show the runtime frames if there is nothing
+		// else to show.
+		u.initAt(pc, sp, 0, gp, unwindSilentErrors)
+		r.Frames, r.FramesTruncated = rb.recordFrames(&u, true)
+	}
+	rb.records = append(rb.records, r)
+}
+
+// recordFrames appends the logical frames starting at u that traceback2
+// would print to rb.frames, and returns them along with whether the stack was
+// truncated to maxRecordFrames. Cgo frames are omitted.
+func (rb *recordBuf) recordFrames(u *unwinder, showRuntime bool) ([]profilerecord.FrameRecord, bool) {
+	gp := u.g.ptr()
+	g0 := getg()
+	start := len(rb.frames)
+	truncated := false
+outer:
+	for ; u.valid(); u.next() {
+		f := u.frame.fn
+		for iu, uf := newInlineUnwinder(f, u.symPC()); uf.valid(); uf = iu.next(uf) {
+			sf := iu.srcFunc(uf)
+			callee := u.calleeFuncID
+			u.calleeFuncID = sf.funcID
+			if !(showRuntime || showframe(sf, gp, len(rb.frames) == start, callee)) {
+				continue
+			}
+			if len(rb.frames)-start == maxRecordFrames {
+				truncated = true
+				break outer
+			}
+			if len(rb.frames) == cap(rb.frames) {
+				rb.overflow = true
+				break outer
+			}
+
+			file, line := iu.fileLine(uf)
+			fr := profilerecord.FrameRecord{
+				Function: rb.addText(funcNamePiecesForPrint(sf.name())),
+				File:     file,
+				Line:     int(line),
+				Inlined:  iu.isInlined(uf),
+			}
+			if !fr.Inlined {
+				// Capture the output of printArgs. If it fills the rest of
+				// rb.text, it may have been truncated.
+				g0.writebuf = rb.text[len(rb.text):len(rb.text):cap(rb.text)]
+				printArgs(f, unsafe.Pointer(u.frame.argp), u.symPC())
+				args := g0.writebuf
+				g0.writebuf = nil
+				if len(rb.text)+len(args) == cap(rb.text) {
+					rb.overflow = true
+					break outer
+				}
+				if len(args) > 0 {
+					fr.Args = unsafe.String(&args[0], len(args))
+					rb.text = rb.text[:len(rb.text)+len(args)]
+				}
+			}
+			rb.frames = append(rb.frames, fr)
+		}
+	}
+	return rb.frames[start:len(rb.frames):len(rb.frames)], truncated
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Trace task inheritance. See runtime/trace.SetTaskInheritance.
+//
+// When task inheritance is enabled, runtime/trace maintains the current
+// task and region of each goroutine in g.userTask. A goroutine created
+// with a go statement copies the current task and region of its creator
+// in newproc1 and, the first time it runs while tracing, begins a region
+// of the same type associated with the same task. That region ends when
+// the goroutine exits.
+//
+// Trace readers may start at any generation, as they do for the output of
+// the flight recorder, so a goroutine that runs in a later generation than
+// the one its inherited region began in ends the region and begins it
+// again. That is only done while the inherited region is the goroutine's
+// innermost, so that regions stay properly nested. The region does not end
+// in a trace that it did not begin in.
+
+package runtime
+
+import (
+	"internal/runtime/atomic"
+	"internal/trace/tracev2"
+	_ "unsafe" // for go:linkname
+)
+
+// traceInheritTasks is whether new goroutines inherit the trace task
+// and region of their creator.
+var traceInheritTasks atomic.Bool
+
+// gUserTaskState is the per-G state for trace task inheritance.
+type gUserTaskState struct {
+	// id and region are the current task ID and region type of
+	// the goroutine, maintained by runtime/trace.
+	id     uint64
+	region string
+
+	// inheritedID and inheritedRegion are the task ID and region
+	// type inherited from the creator of the goroutine, or zero if
+	// it inherited none. inheritedGen is the trace generation in
+	// which the corresponding region last began, or zero if it has
+	// not begun.
+	inheritedID     uint64
+	inheritedRegion string
+	inheritedGen    uintptr
+}
+
+// hasInherited reports whether the goroutine inherited a task or region
+// from its creator.
+func (s *gUserTaskState) hasInherited() bool {
+	return s.inheritedID != 0 || s.inheritedRegion != ""
+}
+
+// inheritedBegunInTrace reports whether the inherited region began in
+// the current trace, rather than in an earlier one or not at all. It must
+// be called while holding a traceLocker.
+func (s *gUserTaskState) inheritedBegunInTrace() bool {
+	return s.inheritedGen != 0 && s.inheritedGen >= trace.firstGen
+}
+
+// inherit sets up s for a new goroutine created by a goroutine with
+// state parent.
+func (s *gUserTaskState) inherit(parent *gUserTaskState) {
+	if parent.id == 0 && parent.region == "" {
+		return
+	}
+	s.id = parent.id
+	s.region = parent.region
+	s.inheritedID = parent.id
+	s.inheritedRegion = parent.region
+}
+
+// UserRegionInherited emits the UserRegionBegin event of the region gp
+// inherited from its creator, if it has not begun in the current
+// generation yet. gp must be the goroutine about to run on this M.
+func (tl traceLocker) UserRegionInherited(gp *g) {
+	s := &gp.userTask
+	if !s.hasInherited() || s.inheritedGen == tl.gen {
+		return
+	}
+	w := tl.eventWriter(tracev2.GoRunning, tracev2.ProcRunning)
+	if s.inheritedBegunInTrace() {
+		// The region began in an earlier generation of this trace.
+		// Begin it again in this one, but only if it is the innermost
+		// region, since it must first end.
+		if s.id != s.inheritedID || s.region != s.inheritedRegion {
+			return
+		}
+		w.event(tracev2.EvUserRegionEnd, traceArg(s.inheritedID), tl.string(s.inheritedRegion), tl.startPC(gp.startpc))
+	}
+	s.inheritedGen = tl.gen
+	w.event(tracev2.EvUserRegionBegin, traceArg(s.inheritedID), tl.string(s.inheritedRegion), tl.startPC(gp.startpc))
+}
+
+// UserRegionInheritedEnd emits the UserRegionEnd event of the region gp
+// inherited from its creator, if it began. gp must be the exiting
+// goroutine running on this M.
+func (tl traceLocker) UserRegionInheritedEnd(gp *g) {
+	if !gp.userTask.inheritedBegunInTrace() {
+		return
+	}
+	tl.eventWriter(tracev2.GoRunning, tracev2.ProcRunning).event(tracev2.EvUserRegionEnd, traceArg(gp.userTask.inheritedID), tl.string(gp.userTask.inheritedRegion), tl.stack(2))
+}
+
+// trace_setTaskInheritance enables or disables trace task inheritance.
+//
+//go:linkname trace_setTaskInheritance runtime/trace.setTaskInheritance
+func trace_setTaskInheritance(enabled bool) {
+	traceInheritTasks.Store(enabled)
+}
+
+// trace_goroutineTask returns the current task ID and region type of the
+// calling goroutine.
+//
+//go:linkname trace_goroutineTask runtime/trace.goroutineTask
+func trace_goroutineTask() (id uint64, regionType string) {
+	gp := getg()
+	return gp.userTask.id, gp.userTask.region
+}
+
+// trace_setGoroutineTask sets the current task ID and region type of the
+// calling goroutine.
+//
+//go:linkname trace_setGoroutineTask runtime/trace.setGoroutineTask
+func trace_setGoroutineTask(id uint64, regionType string) {
+	gp := getg()
+	gp.userTask.id = id
+	gp.userTask.region = regionType
+}
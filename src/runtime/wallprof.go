@@ -0,0 +1,103 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"internal/profilerecord"
+	"unsafe"
+)
+
+// pprof_wallClockSample records the call stack, labels and state of every
+// user goroutine other than the calling one into p, labels and states,
+// which must have the same length, for the wall-clock profile of
+// runtime/pprof. The stacks are stored in pcs, which the Stack fields of p
+// point into, so that the buffers can be reused for every sample. It
+// returns the number of goroutines, the number of PCs needed for their
+// stacks, and whether they all fit.
+//
+// Unlike the goroutine profile, it doesn't stop the world. Instead, it
+// suspends the goroutines one at a time, like the garbage collector does to
+// scan their stacks, so the sample is not a consistent snapshot.
+//
+// The state of a goroutine is "running", "runnable", "syscall", or, for a
+// blocked goroutine, its wait reason, such as "chan receive".
+//
+//go:linkname pprof_wallClockSample runtime/pprof.runtime_wallClockSample
+func pprof_wallClockSample(p []profilerecord.StackRecord, pcs []uintptr, labels []unsafe.Pointer, states []string) (n, npc int, ok bool) {
+	ourg := getg()
+	maxDepth := int(debug.profstackdepth)
+	ok = true
+	forEachGRace(func(gp1 *g) {
+		if gp1 == ourg || isSystemGoroutine(gp1, false) {
+			return
+		}
+		if status := readgstatus(gp1); status == _Gdead || status == _Gdeadextra {
+			return
+		}
+		if n >= len(p) || len(pcs)-npc < maxDepth {
+			// Count the goroutine as needing a maximum-sized stack.
+			ok = false
+			n++
+			npc += maxDepth
+			return
+		}
+		pcbuf := pcs[npc : npc+maxDepth]
+		var depth int
+		var lbls unsafe.Pointer
+		var state string
+		systemstack(func() {
+			depth, lbls, state = wallClockSampleG(ourg, gp1, pcbuf)
+		})
+		if state == "" {
+			// gp1 exited.
+			return
+		}
+		p[n].Stack = pcbuf[:depth:depth]
+		labels[n] = lbls
+		states[n] = state
+		n++
+		npc += depth
+	})
+
+	if raceenabled {
+		raceacquire(unsafe.Pointer(&labelSync))
+	}
+	return n, npc, ok
+}
+
+// wallClockSampleG suspends gp and records its call stack in pcbuf. It
+// returns the depth of the stack and the labels and state of gp, or an
+// empty state if gp is dead. ourg is the calling goroutine.
+//
+//go:systemstack
+func wallClockSampleG(ourg, gp *g, pcbuf []uintptr) (depth int, labels unsafe.Pointer, state string) {
+	// Let other goroutines suspend ourg while we suspend gp, to
+	// prevent deadlocks, as in markroot.
+	casGToWaitingForSuspendG(ourg, _Grunning, waitReasonWallClockProfile)
+	stopped := suspendG(gp)
+	if stopped.dead {
+		casgstatus(ourg, _Gwaiting, _Grunning)
+		return 0, nil, ""
+	}
+	switch status := readgstatus(gp) &^ _Gscan; {
+	case stopped.stopped:
+		// We preempted gp to suspend it.
+		state = "running"
+	case status == _Gwaiting && gp.waitreason.isWaitingForSuspendG():
+		// gp is running on the system stack.
+		state = "running"
+	case status == _Gwaiting && gp.waitreason != waitReasonZero:
+		state = gp.waitreason.String()
+	default:
+		state = gStatusStrings[status]
+	}
+	var u unwinder
+	u.initAt(^uintptr(0), ^uintptr(0), 0, gp, unwindSilentErrors)
+	depth = tracebackPCs(&u, 0, pcbuf)
+	labels = gp.labels
+	resumeG(stopped)
+	casgstatus(ourg, _Gwaiting, _Grunning)
+	return depth, labels, state
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package atomic
+
+// A ShardedInt64 is an int64 counter split into one slot per P (see the
+// runtime package documentation for GOMAXPROCS), each on its own cache
+// line. The zero value is zero.
+//
+// [ShardedInt64.Add] only touches the slot of the P running the calling
+// goroutine, so concurrent calls on different Ps don't contend on a cache
+// line as they do for an [Int64]. In exchange, [ShardedInt64.Load] must sum
+// all slots, which makes it slower, and it is not atomic with respect to
+// concurrent calls to Add: the result includes some subset of them.
+// ShardedInt64 is intended for counters that are updated much more often
+// than they are read, such as metrics.
+//
+// ShardedInt64 must not be copied after first use.
+type ShardedInt64 struct {
+	_     noCopy
+	slots Pointer[[]*shardedInt64Slot]
+}
+
+type shardedInt64Slot struct {
+	v Int64
+
+	// Prevents false sharing on widespread platforms with
+	// 128 mod (cache line size) = 0 .
+	_ [128 - 8]byte
+}
+
+// Add atomically adds delta to x.
+func (x *ShardedInt64) Add(delta int64) {
+	x.slot().v.Add(delta)
+}
+
+// Load returns the sum of the slots of x.
+func (x *ShardedInt64) Load() int64 {
+	slots := x.slots.Load()
+	if slots == nil {
+		return 0
+	}
+	var sum int64
+	for _, s := range *slots {
+		sum += s.v.Load()
+	}
+	return sum
+}
+
+// slot returns the slot of the current P. The goroutine may move to
+// another P right away, which is harmless since the slots are atomic.
+func (x *ShardedInt64) slot() *shardedInt64Slot {
+	pid := runtime_procPin()
+	runtime_procUnpin()
+	if slots := x.slots.Load(); slots != nil && pid < len(*slots) {
+		return (*slots)[pid]
+	}
+	return x.slotSlow(pid)
+}
+
+// slotSlow adds slots to x for Ps up to pid. Existing slots are kept,
+// so concurrent Adds to them are not lost.
+func (x *ShardedInt64) slotSlow(pid int) *shardedInt64Slot {
+	for {
+		old := x.slots.Load()
+		var n int
+		if old != nil {
+			if pid < len(*old) {
+				return (*old)[pid]
+			}
+			n = len(*old)
+		}
+		// Grow to a power of two so that raising GOMAXPROCS only
+		// takes a few rounds of growth.
+		size := 1
+		for size <= pid {
+			size <<= 1
+		}
+		slots := make([]*shardedInt64Slot, size)
+		if old != nil {
+			copy(slots, *old)
+		}
+		for i := n; i < size; i++ {
+			slots[i] = new(shardedInt64Slot)
+		}
+		if x.slots.CompareAndSwap(old, &slots) {
+			return slots[pid]
+		}
+	}
+}
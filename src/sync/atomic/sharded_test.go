@@ -0,0 +1,68 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package atomic_test
+
+import (
+	"runtime"
+	"sync"
+	. "sync/atomic"
+	"testing"
+)
+
+func TestShardedInt64(t *testing.T) {
+	var x ShardedInt64
+	if got := x.Load(); got != 0 {
+		t.Fatalf("zero ShardedInt64 has value %d", got)
+	}
+	x.Add(3)
+	x.Add(-1)
+	if got := x.Load(); got != 2 {
+		t.Fatalf("got %d, want 2", got)
+	}
+}
+
+func TestShardedInt64Parallel(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+
+	const (
+		goroutines = 8
+		adds       = 10000
+	)
+	var x ShardedInt64
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		if i == goroutines/2 {
+			// Add Ps while counting.
+			runtime.GOMAXPROCS(8)
+		}
+		wg.Go(func() {
+			for range adds {
+				x.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+	if got, want := x.Load(), int64(goroutines*adds); got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+}
+
+func BenchmarkShardedInt64Add(b *testing.B) {
+	var x ShardedInt64
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x.Add(1)
+		}
+	})
+}
+
+func BenchmarkInt64AddParallel(b *testing.B) {
+	var x Int64
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			x.Add(1)
+		}
+	})
+}
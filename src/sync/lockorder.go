@@ -0,0 +1,37 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"internal/race"
+	"unsafe"
+)
+
+// Defined in runtime/lockorder.go.
+func runtime_lockOrderEnabled() bool
+func runtime_lockOrderAcquire(l unsafe.Pointer, try bool)
+func runtime_lockOrderRelease(l unsafe.Pointer)
+
+// lockOrderEnabled reports whether the acquisitions and releases of locks
+// are reported to the lock order checker of the runtime, as enabled by the
+// GODEBUG setting lockorder in race-enabled builds.
+// Calls to the functions below must be guarded by race.Enabled, so that
+// they are compiled out of other builds.
+var lockOrderEnabled = race.Enabled && runtime_lockOrderEnabled()
+
+// lockOrderAcquire records that the calling goroutine is about to acquire
+// l, or that it acquired l with TryLock if try is set.
+func lockOrderAcquire(l unsafe.Pointer, try bool) {
+	if lockOrderEnabled {
+		runtime_lockOrderAcquire(l, try)
+	}
+}
+
+// lockOrderRelease records that l is about to be released.
+func lockOrderRelease(l unsafe.Pointer) {
+	if lockOrderEnabled {
+		runtime_lockOrderRelease(l)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync_test
+
+import (
+	"fmt"
+	"internal/race"
+	"internal/testenv"
+	"os"
+	"os/exec"
+	"strings"
+	. "sync"
+	"testing"
+)
+
+// inGoroutine runs f in a new goroutine and waits for it to return.
+func inGoroutine(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	<-done
+}
+
+//go:noinline
+func lockAB(a, b *Mutex) {
+	a.Lock()
+	b.Lock()
+	b.Unlock()
+	a.Unlock()
+}
+
+//go:noinline
+func lockBA(a, b *Mutex) {
+	b.Lock()
+	a.Lock()
+	a.Unlock()
+	b.Unlock()
+}
+
+var lockOrderTests = map[string]func(){
+	"Inversion": func() {
+		var a, b Mutex
+		inGoroutine(func() { lockAB(&a, &b) })
+		inGoroutine(func() { lockBA(&a, &b) })
+	},
+	"Cycle": func() {
+		var a, b, c Mutex
+		inGoroutine(func() { lockAB(&a, &b) })
+		inGoroutine(func() { lockAB(&b, &c) })
+		inGoroutine(func() { lockAB(&c, &a) })
+	},
+	"RWMutex": func() {
+		var rw RWMutex
+		var m Mutex
+		inGoroutine(func() {
+			rw.RLock()
+			m.Lock()
+			m.Unlock()
+			rw.RUnlock()
+		})
+		inGoroutine(func() {
+			m.Lock()
+			rw.Lock()
+			rw.Unlock()
+			m.Unlock()
+		})
+	},
+	"Consistent": func() {
+		var a, b, c Mutex
+		inGoroutine(func() { lockAB(&a, &b) })
+		inGoroutine(func() { lockAB(&b, &c) })
+		inGoroutine(func() { lockAB(&a, &c) })
+	},
+	"TryLock": func() {
+		var a, b Mutex
+		inGoroutine(func() { lockAB(&a, &b) })
+		inGoroutine(func() {
+			b.Lock()
+			if a.TryLock() {
+				a.Unlock()
+			}
+			b.Unlock()
+		})
+	},
+	"HandOff": func() {
+		var a, b Mutex
+		a.Lock()
+		inGoroutine(a.Unlock)
+		// a is no longer held, so this doesn't order a before b.
+		b.Lock()
+		b.Unlock()
+		inGoroutine(func() { lockBA(&a, &b) })
+	},
+}
+
+func init() {
+	if len(os.Args) == 3 && os.Args[1] == "TESTLOCKORDER" {
+		if f, ok := lockOrderTests[os.Args[2]]; ok {
+			f()
+			fmt.Printf("test completed\n")
+			os.Exit(0)
+		}
+		fmt.Printf("unknown test\n")
+		os.Exit(0)
+	}
+}
+
+func TestLockOrder(t *testing.T) {
+	if !race.Enabled {
+		t.Skip("lock order checking requires -race")
+	}
+	testenv.MustHaveExec(t)
+	for _, tt := range []struct {
+		name    string
+		reports int
+	}{
+		{"Inversion", 1},
+		{"Cycle", 1},
+		{"RWMutex", 1},
+		{"Consistent", 0},
+		{"TryLock", 0},
+		{"HandOff", 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(testenv.Executable(t), "TESTLOCKORDER", tt.name)
+			cmd.Env = append(os.Environ(), "GODEBUG=lockorder=1")
+			out, err := cmd.CombinedOutput()
+			if err != nil || !strings.Contains(string(out), "test completed") {
+				t.Fatalf("test failed: %v\n%s", err, out)
+			}
+			if got := strings.Count(string(out), "WARNING: POTENTIAL DEADLOCK"); got != tt.reports {
+				t.Fatalf("got %d reports, want %d:\n%s", got, tt.reports, out)
+			}
+			if tt.reports > 0 && !strings.Contains(string(out), "lockorder_test.go:") {
+				t.Errorf("report does not include the stack of the lock acquisition:\n%s", out)
+			}
+		})
+	}
+
+	// With lockorder=2, the first report is fatal.
+	cmd := exec.Command(testenv.Executable(t), "TESTLOCKORDER", "Inversion")
+	cmd.Env = append(os.Environ(), "GODEBUG=lockorder=2")
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "fatal error: sync: lock order inversion") {
+		t.Errorf("lockorder=2 did not crash the program: %v\n%s", err, out)
+	}
+}
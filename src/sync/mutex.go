@@ -11,7 +11,9 @@
 package sync
 
 import (
+	"internal/race"
 	isync "internal/sync"
+	"unsafe"
 )
 
 // A Mutex is a mutual exclusion lock.
@@ -43,6 +45,9 @@ type Locker interface {
 // If the lock is already in use, the calling goroutine
 // blocks until the mutex is available.
 func (m *Mutex) Lock() {
+	if race.Enabled {
+		lockOrderAcquire(unsafe.Pointer(m), false)
+	}
 	m.mu.Lock()
 }
 
@@ -52,7 +57,13 @@ func (m *Mutex) Lock() {
 // and use of TryLock is often a sign of a deeper problem
 // in a particular use of mutexes.
 func (m *Mutex) TryLock() bool {
-	return m.mu.TryLock()
+	if !m.mu.TryLock() {
+		return false
+	}
+	if race.Enabled {
+		lockOrderAcquire(unsafe.Pointer(m), true)
+	}
+	return true
 }
 
 // Unlock unlocks m.
@@ -62,5 +73,8 @@ func (m *Mutex) TryLock() bool {
 // It is allowed for one goroutine to lock a Mutex and then
 // arrange for another goroutine to unlock it.
 func (m *Mutex) Unlock() {
+	if race.Enabled {
+		lockOrderRelease(unsafe.Pointer(m))
+	}
 	m.mu.Unlock()
 }
@@ -68,6 +68,8 @@ func (rw *RWMutex) RLock() {
 	if race.Enabled {
 		race.Read(unsafe.Pointer(&rw.w))
 		race.Disable()
+		// rw.w identifies rw for the lock order checker, as in Lock.
+		lockOrderAcquire(unsafe.Pointer(&rw.w), false)
 	}
 	if rw.readerCount.Add(1) < 0 {
 		// A writer is pending, wait for it.
@@ -101,6 +103,7 @@ func (rw *RWMutex) TryRLock() bool {
 			if race.Enabled {
 				race.Enable()
 				race.Acquire(unsafe.Pointer(&rw.readerSem))
+				lockOrderAcquire(unsafe.Pointer(&rw.w), true)
 			}
 			return true
 		}
@@ -116,6 +119,7 @@ func (rw *RWMutex) RUnlock() {
 		race.Read(unsafe.Pointer(&rw.w))
 		race.ReleaseMerge(unsafe.Pointer(&rw.writerSem))
 		race.Disable()
+		lockOrderRelease(unsafe.Pointer(&rw.w))
 	}
 	if r := rw.readerCount.Add(-1); r < 0 {
 		// Outlined slow-path to allow the fast-path to be inlined
@@ -0,0 +1,125 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import "sync/atomic"
+
+// A Sharded is a value of type T split into one shard per P (see the
+// runtime package documentation for GOMAXPROCS), each on its own cache
+// lines. It is intended for values that are updated much more often than
+// they are read, such as counters and histograms of a metrics library,
+// where a single value guarded by a [Mutex] or updated with atomic
+// operations contends at high core counts.
+//
+// [Sharded.Update] operates on the shard of the P running the calling
+// goroutine, so concurrent updates on different Ps don't contend. Reads
+// with [Sharded.Combine] fold all shards into a single value.
+//
+// Each shard is guarded by its own mutex, so the functions passed to the
+// methods of Sharded may block or be preempted without corrupting the
+// shards; they must not call methods of the same Sharded, though. The zero
+// value of each shard is the zero value of T.
+//
+// A Sharded is safe for use by multiple goroutines simultaneously.
+//
+// A Sharded must not be copied after first use.
+//
+// For int64 counters, [sync/atomic.ShardedInt64] avoids the mutexes.
+type Sharded[T any] struct {
+	noCopy noCopy
+
+	shards atomic.Pointer[[]*shard[T]]
+}
+
+type shard[T any] struct {
+	mu  Mutex
+	val T
+
+	// Prevents false sharing with the next shard on widespread
+	// platforms with 128 mod (cache line size) = 0 .
+	pad [128]byte
+}
+
+// Update calls f with a pointer to the shard of the current P. The pointer
+// must not be retained after f returns.
+func (s *Sharded[T]) Update(f func(*T)) {
+	sh := s.shard()
+	sh.mu.Lock()
+	f(&sh.val)
+	sh.mu.Unlock()
+}
+
+// Combine returns the result of folding the shards of s with combine,
+// starting with the zero value of T. For example, the sum of a
+// Sharded[int] is
+//
+//	s.Combine(func(x, y int) int { return x + y })
+//
+// Shards are read one at a time, so the result is not a consistent
+// snapshot with respect to concurrent calls to [Sharded.Update].
+func (s *Sharded[T]) Combine(combine func(acc, shard T) T) T {
+	var acc T
+	s.Range(func(v *T) {
+		acc = combine(acc, *v)
+	})
+	return acc
+}
+
+// Range calls f with a pointer to each shard of s in turn, for example to
+// reset the shards after reading them. The pointer must not be retained
+// after f returns.
+func (s *Sharded[T]) Range(f func(*T)) {
+	shards := s.shards.Load()
+	if shards == nil {
+		return
+	}
+	for _, sh := range *shards {
+		sh.mu.Lock()
+		f(&sh.val)
+		sh.mu.Unlock()
+	}
+}
+
+// shard returns the shard of the current P. The goroutine may move to
+// another P right away, which is harmless since the shards are locked.
+func (s *Sharded[T]) shard() *shard[T] {
+	pid := runtime_procPin()
+	runtime_procUnpin()
+	if shards := s.shards.Load(); shards != nil && pid < len(*shards) {
+		return (*shards)[pid]
+	}
+	return s.shardSlow(pid)
+}
+
+// shardSlow adds shards to s for Ps up to pid. Existing shards are kept,
+// so concurrent updates to them are not lost.
+func (s *Sharded[T]) shardSlow(pid int) *shard[T] {
+	for {
+		old := s.shards.Load()
+		var n int
+		if old != nil {
+			if pid < len(*old) {
+				return (*old)[pid]
+			}
+			n = len(*old)
+		}
+		// Grow to a power of two so that raising GOMAXPROCS only
+		// takes a few rounds of growth.
+		size := 1
+		for size <= pid {
+			size <<= 1
+		}
+		shards := make([]*shard[T], size)
+		if old != nil {
+			copy(shards, *old)
+		}
+		for i := n; i < size; i++ {
+			shards[i] = new(shard[T])
+		}
+		if s.shards.CompareAndSwap(old, &shards) {
+			return shards[pid]
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync_test
+
+import (
+	"runtime"
+	. "sync"
+	"testing"
+)
+
+type shardedStats struct {
+	count int
+	max   int
+}
+
+func combineStats(acc, s shardedStats) shardedStats {
+	return shardedStats{acc.count + s.count, max(acc.max, s.max)}
+}
+
+func TestSharded(t *testing.T) {
+	var s Sharded[shardedStats]
+	if got := s.Combine(combineStats); got != (shardedStats{}) {
+		t.Fatalf("zero Sharded combines to %v", got)
+	}
+	for _, v := range []int{3, 7, 5} {
+		s.Update(func(st *shardedStats) {
+			st.count++
+			st.max = max(st.max, v)
+		})
+	}
+	if got, want := s.Combine(combineStats), (shardedStats{3, 7}); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	s.Range(func(st *shardedStats) {
+		*st = shardedStats{}
+	})
+	if got := s.Combine(combineStats); got != (shardedStats{}) {
+		t.Fatalf("got %v after reset, want zero", got)
+	}
+}
+
+func TestShardedParallel(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+
+	const (
+		goroutines = 8
+		updates    = 10000
+	)
+	var s Sharded[int]
+	var wg WaitGroup
+	for i := range goroutines {
+		if i == goroutines/2 {
+			// Add Ps while updating.
+			runtime.GOMAXPROCS(8)
+		}
+		wg.Go(func() {
+			for j := range updates {
+				s.Update(func(v *int) {
+					*v++
+					if j%100 == 0 {
+						// Let the goroutine move to another P
+						// while it holds the shard.
+						runtime.Gosched()
+					}
+				})
+			}
+		})
+	}
+	wg.Wait()
+	got := s.Combine(func(x, y int) int { return x + y })
+	if want := goroutines * updates; got != want {
+		t.Fatalf("got %d, want %d", got, want)
+	}
+}
+
+func BenchmarkShardedUpdate(b *testing.B) {
+	var s Sharded[int64]
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Update(func(v *int64) { *v++ })
+		}
+	})
+}
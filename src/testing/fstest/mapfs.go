@@ -5,11 +5,13 @@
 package fstest
 
 import (
+	"errors"
 	"io"
 	"io/fs"
 	"path"
 	"slices"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -30,6 +32,13 @@ import (
 // Another implication is that opening or reading a directory requires
 // iterating over the entire map, so a MapFS should typically be used with not more
 // than a few hundred entries or directory reads.
+//
+// A MapFS also implements the interfaces of package fs for writing files,
+// such as [fs.CreateFS] and [fs.MkdirFS], whose methods edit the map:
+// for example, [MapFS.Create] adds a [MapFile] to the map, and writing
+// to the returned file changes its Data. Like other changes to the map,
+// these methods must not run concurrently with other operations on the
+// file system.
 type MapFS map[string]*MapFile
 
 // A MapFile describes a single file in a [MapFS].
@@ -42,7 +51,14 @@ type MapFile struct {
 
 var _ fs.FS = MapFS(nil)
 var _ fs.ReadLinkFS = MapFS(nil)
+var _ fs.OpenFileFS = MapFS(nil)
+var _ fs.CreateFS = MapFS(nil)
+var _ fs.WriteFileFS = MapFS(nil)
+var _ fs.MkdirFS = MapFS(nil)
+var _ fs.RemoveFS = MapFS(nil)
+var _ fs.RenameFS = MapFS(nil)
 var _ fs.File = (*openMapFile)(nil)
+var _ io.Writer = (*openMapFile)(nil)
 
 // Open opens the named file after following any symbolic links.
 func (fsys MapFS) Open(name string) (fs.File, error) {
@@ -57,7 +73,7 @@ func (fsys MapFS) Open(name string) (fs.File, error) {
 	file := fsys[realName]
 	if file != nil && file.Mode&fs.ModeDir == 0 {
 		// Ordinary file
-		return &openMapFile{name, mapFileInfo{path.Base(name), file}, 0}, nil
+		return &openMapFile{name, mapFileInfo{path.Base(name), file}, 0, syscall.O_RDONLY, false}, nil
 	}
 
 	// Directory, possibly synthesized.
@@ -207,6 +223,172 @@ func (fsys MapFS) lstat(name string) (*mapFileInfo, error) {
 	return nil, fs.ErrNotExist
 }
 
+var errIsDir = errors.New("is a directory")
+var errNotEmpty = errors.New("directory not empty")
+
+// OpenFile opens the named file with the specified flag, a combination
+// of flags such as [os.O_RDONLY], [os.O_WRONLY], and [os.O_CREATE], as
+// for [os.OpenFile]. If the file doesn't exist and flag includes
+// os.O_CREATE, OpenFile adds it to the map with mode perm. Directories
+// can only be opened for reading.
+func (fsys MapFS) OpenFile(name string, flag int, perm fs.FileMode) (fs.File, error) {
+	if flag&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_APPEND|syscall.O_CREAT|syscall.O_EXCL|syscall.O_TRUNC) == 0 {
+		return fsys.Open(name)
+	}
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	realName, ok := fsys.resolveSymlinks(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	file := fsys[realName]
+	switch {
+	case file == nil:
+		if fsys.isDir(realName) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+		}
+		if flag&syscall.O_CREAT == 0 || !fsys.isDir(path.Dir(realName)) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		file = &MapFile{Mode: perm & fs.ModePerm}
+		fsys[realName] = file
+	case file.Mode&fs.ModeDir != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+	case flag&(syscall.O_CREAT|syscall.O_EXCL) == syscall.O_CREAT|syscall.O_EXCL:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case flag&syscall.O_TRUNC != 0:
+		file.Data = nil
+	}
+	return &openMapFile{name, mapFileInfo{path.Base(name), file}, 0, flag, false}, nil
+}
+
+// Create creates or truncates the named file, as [os.Create] does.
+func (fsys MapFS) Create(name string) (fs.File, error) {
+	return fsys.OpenFile(name, syscall.O_RDWR|syscall.O_CREAT|syscall.O_TRUNC, 0666)
+}
+
+// WriteFile writes data to the named file, creating it with mode perm if
+// necessary. The MapFile of the named file keeps a copy of data.
+func (fsys MapFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f, err := fsys.OpenFile(name, syscall.O_WRONLY|syscall.O_CREAT|syscall.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	f.(*openMapFile).f.Data = slices.Clone(data)
+	return nil
+}
+
+// Mkdir adds the named directory to the map, with mode perm.
+func (fsys MapFS) Mkdir(name string, perm fs.FileMode) error {
+	realName, err := fsys.lastElem(name)
+	if err != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	if _, err := fsys.lstat(realName); err == nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if !fsys.isDir(path.Dir(realName)) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrNotExist}
+	}
+	fsys[realName] = &MapFile{Mode: fs.ModeDir | perm&fs.ModePerm}
+	return nil
+}
+
+// Remove removes the named file or empty directory from the map. If the
+// file is a symbolic link, Remove removes the link.
+func (fsys MapFS) Remove(name string) error {
+	realName, err := fsys.lastElem(name)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: err}
+	}
+	info, err := fsys.lstat(realName)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: err}
+	}
+	if info.IsDir() && fsys.hasChildren(realName) {
+		return &fs.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+	}
+	delete(fsys, realName)
+	return nil
+}
+
+// Rename renames (moves) oldname to newname, along with the files in
+// oldname if it is a directory. If newname already exists and neither
+// oldname nor newname is a directory, Rename replaces it.
+func (fsys MapFS) Rename(oldname, newname string) error {
+	oldReal, err := fsys.lastElem(oldname)
+	if err != nil {
+		return &fs.PathError{Op: "rename", Path: oldname, Err: err}
+	}
+	newReal, err := fsys.lastElem(newname)
+	if err != nil {
+		return &fs.PathError{Op: "rename", Path: newname, Err: err}
+	}
+	info, err := fsys.lstat(oldReal)
+	if err != nil {
+		return &fs.PathError{Op: "rename", Path: oldname, Err: err}
+	}
+	if oldReal == newReal {
+		return nil
+	}
+	if newInfo, err := fsys.lstat(newReal); err == nil && (newInfo.IsDir() || info.IsDir()) {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrExist}
+	}
+	if !fsys.isDir(path.Dir(newReal)) {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrNotExist}
+	}
+	if info.IsDir() && strings.HasPrefix(newReal, oldReal+"/") {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrInvalid}
+	}
+
+	if f := fsys[oldReal]; f != nil {
+		fsys[newReal] = f
+		delete(fsys, oldReal)
+	}
+	if info.IsDir() {
+		prefix := oldReal + "/"
+		for fname, f := range fsys {
+			if strings.HasPrefix(fname, prefix) {
+				fsys[newReal+"/"+fname[len(prefix):]] = f
+				delete(fsys, fname)
+			}
+		}
+	}
+	return nil
+}
+
+// lastElem returns the name in the map of the named file, without
+// following a symbolic link in its last element. name must not be ".".
+func (fsys MapFS) lastElem(name string) (string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", fs.ErrInvalid
+	}
+	realDir, ok := fsys.resolveSymlinks(path.Dir(name))
+	if !ok {
+		return "", fs.ErrNotExist
+	}
+	return path.Join(realDir, path.Base(name)), nil
+}
+
+// isDir reports whether the named file, whose name is in the map, is a
+// directory.
+func (fsys MapFS) isDir(realName string) bool {
+	info, err := fsys.lstat(realName)
+	return err == nil && info.IsDir()
+}
+
+// hasChildren reports whether the map has files in the named directory.
+func (fsys MapFS) hasChildren(realName string) bool {
+	prefix := realName + "/"
+	for fname := range fsys {
+		if strings.HasPrefix(fname, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // fsOnly is a wrapper that hides all but the fs.FS methods,
 // to avoid an infinite recursion when implementing special
 // methods in terms of helpers that would use them.
@@ -260,11 +442,13 @@ func (i *mapFileInfo) String() string {
 	return fs.FormatFileInfo(i)
 }
 
-// An openMapFile is a regular (non-directory) fs.File open for reading.
+// An openMapFile is a regular (non-directory) fs.File.
 type openMapFile struct {
 	path string
 	mapFileInfo
-	offset int64
+	offset  int64
+	flag    int  // flag passed to OpenFile
+	written bool // Data is a copy made by the first Write
 }
 
 func (f *openMapFile) Stat() (fs.FileInfo, error) { return &f.mapFileInfo, nil }
@@ -272,6 +456,9 @@ func (f *openMapFile) Stat() (fs.FileInfo, error) { return &f.mapFileInfo, nil }
 func (f *openMapFile) Close() error { return nil }
 
 func (f *openMapFile) Read(b []byte) (int, error) {
+	if f.flag&syscall.O_WRONLY != 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrInvalid}
+	}
 	if f.offset >= int64(len(f.f.Data)) {
 		return 0, io.EOF
 	}
@@ -283,6 +470,32 @@ func (f *openMapFile) Read(b []byte) (int, error) {
 	return n, nil
 }
 
+// Write writes b to the file, which must have been opened for writing,
+// and updates the Data of its MapFile.
+func (f *openMapFile) Write(b []byte) (int, error) {
+	if f.flag&(syscall.O_WRONLY|syscall.O_RDWR) == 0 {
+		return 0, &fs.PathError{Op: "write", Path: f.path, Err: fs.ErrInvalid}
+	}
+	if f.flag&syscall.O_APPEND != 0 {
+		f.offset = int64(len(f.f.Data))
+	}
+	if !f.written {
+		// The array of Data may belong to the caller, which doesn't
+		// expect writes to it, even past the length of Data.
+		f.f.Data = slices.Clone(f.f.Data)
+		f.written = true
+	}
+	if end := f.offset + int64(len(b)); end > int64(len(f.f.Data)) {
+		n := len(f.f.Data)
+		f.f.Data = slices.Grow(f.f.Data, int(end)-n)[:end]
+		// Zero the hole left by a seek past the end of the file.
+		clear(f.f.Data[n:])
+	}
+	n := copy(f.f.Data[f.offset:], b)
+	f.offset += int64(n)
+	return n, nil
+}
+
 func (f *openMapFile) Seek(offset int64, whence int) (int64, error) {
 	switch whence {
 	case 0:
@@ -292,7 +505,10 @@ func (f *openMapFile) Seek(offset int64, whence int) (int64, error) {
 	case 2:
 		offset += int64(len(f.f.Data))
 	}
-	if offset < 0 || offset > int64(len(f.f.Data)) {
+	// Files open for writing may seek past their end: the next
+	// Write fills the hole with zeros.
+	writable := f.flag&(syscall.O_WRONLY|syscall.O_RDWR) != 0
+	if offset < 0 || offset > int64(len(f.f.Data)) && !writable {
 		return 0, &fs.PathError{Op: "seek", Path: f.path, Err: fs.ErrInvalid}
 	}
 	f.offset = offset
@@ -5,8 +5,13 @@
 package fstest
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"maps"
+	"os"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -123,3 +128,117 @@ func TestMapFSSymlink(t *testing.T) {
 		}
 	}
 }
+
+func TestMapFSWrite(t *testing.T) {
+	m := MapFS{
+		"dir/old.txt": {Data: []byte("old")},
+		"link":        {Data: []byte("dir"), Mode: fs.ModeSymlink},
+	}
+	if err := fs.MkdirAll(m, "a/b", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile(m, "a/b/c.txt", []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := m.Create("link/new.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f.(io.Writer), "abc")
+	f.(io.Seeker).Seek(1, io.SeekStart)
+	io.WriteString(f.(io.Writer), "XYZ")
+	f.Close()
+	f, err = m.OpenFile("dir/old.txt", os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f.(io.Writer), "er")
+	if _, err := f.Read(make([]byte, 1)); err == nil {
+		t.Errorf("Read of file opened with O_WRONLY succeeded")
+	}
+	f.Close()
+	if err := m.Rename("a", "dir/a2"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"dir/old.txt":    "older",
+		"dir/new.txt":    "aXYZ",
+		"dir/a2/b/c.txt": "hello",
+		"dir/a2":         "",
+		"dir/a2/b":       "",
+		"link":           "dir",
+	}
+	if got := slices.Sorted(maps.Keys(m)); !slices.Equal(got, slices.Sorted(maps.Keys(want))) {
+		t.Fatalf("MapFS has files %q, want %q", got, slices.Sorted(maps.Keys(want)))
+	}
+	for name, data := range want {
+		if got := string(m[name].Data); got != data {
+			t.Errorf("%s has data %q, want %q", name, got, data)
+		}
+	}
+	if mode := m["dir/a2/b/c.txt"].Mode; mode != 0o644 {
+		t.Errorf("WriteFile created file with mode %v, want 0644", mode)
+	}
+	if mode := m["dir/a2/b"].Mode; mode != fs.ModeDir|0o755 {
+		t.Errorf("MkdirAll created directory with mode %v, want drwxr-xr-x", mode)
+	}
+	if err := TestFS(m, "dir/old.txt", "dir/new.txt", "dir/a2/b/c.txt"); err != nil {
+		t.Error(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"Create in missing directory", fs.WriteFile(m, "missing/f", nil, 0o666), fs.ErrNotExist},
+		{"Create of directory", fs.WriteFile(m, "dir", nil, 0o666), nil},
+		{"Create with O_EXCL", func() error {
+			_, err := m.OpenFile("dir/old.txt", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+			return err
+		}(), fs.ErrExist},
+		{"Mkdir of existing directory", m.Mkdir("dir", 0o777), fs.ErrExist},
+		{"Remove of non-empty directory", m.Remove("dir/a2"), nil},
+		{"Remove of missing file", m.Remove("missing"), fs.ErrNotExist},
+		{"Rename into itself", m.Rename("dir", "dir/sub"), fs.ErrInvalid},
+		{"Rename over directory", m.Rename("dir/old.txt", "dir/a2"), fs.ErrExist},
+	} {
+		if tt.err == nil || tt.want != nil && !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: got error %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+
+	if err := m.Remove("link"); err != nil {
+		t.Fatal(err)
+	}
+	if m["link"] != nil || m["dir/old.txt"] == nil {
+		t.Errorf("Remove of a symbolic link didn't remove just the link")
+	}
+}
+
+func TestMapFSWriteData(t *testing.T) {
+	buf := make([]byte, 3, 16)
+	copy(buf, "abc")
+	m := MapFS{"f": {Data: buf}}
+	f, err := m.OpenFile("f", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := f.(io.WriteSeeker)
+	if _, err := w.Seek(5, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("xy")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(m["f"].Data), "abc\x00\x00xy"; got != want {
+		t.Errorf("Data = %q, want %q", got, want)
+	}
+	if got := string(buf[:cap(buf)]); got != "abc"+strings.Repeat("\x00", 13) {
+		t.Errorf("Write modified the array of the caller: %q", got)
+	}
+}